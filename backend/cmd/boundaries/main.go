@@ -0,0 +1,248 @@
+// Command boundaries generates the approximate province and amphoe areas embedded in
+// services/data/admin_boundaries.geojson.
+//
+// These are not administrative boundaries. No boundary dataset can be downloaded at build time, so
+// every seat (a provincial capital, an amphoe seat or an extra point inside a large province) gets
+// the part of its country's simplified outline that is closer to it than to any other seat of the
+// same country (a Voronoi cell clipped to the outline). A province area is the union of its seats'
+// cells and an amphoe area is the cell of its seat, so amphoe areas exist only where seats.go lists
+// them (the upper north). The file is marked "approximate": true, so a station whose address names
+// another province keeps the address province; set ADMIN_BOUNDARIES_FILE to an official dataset
+// for real boundaries.
+//
+//	go run ./cmd/boundaries [-o services/data/admin_boundaries.geojson]
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+)
+
+// lonScale converts degrees of longitude to the same length as degrees of latitude near 15°N
+// so that cells follow distances on the ground rather than in raw degrees.
+var lonScale = math.Cos(15 * math.Pi / 180)
+
+type point = [2]float64 // [lon, lat]
+
+// site is one Voronoi seed and the feature it belongs to.
+type site struct {
+	country, province, district string
+	at                          point
+}
+
+func main() {
+	out := flag.String("o", "services/data/admin_boundaries.geojson", "output GeoJSON file")
+	flag.Parse()
+
+	features, err := buildFeatures()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := writeFeatures(*out, features); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Wrote %d features to %s", len(features), *out)
+}
+
+type feature struct {
+	Type       string            `json:"type"`
+	Properties map[string]string `json:"properties"`
+	Geometry   geometry          `json:"geometry"`
+}
+
+type geometry struct {
+	Type        string      `json:"type"`
+	Coordinates [][][]point `json:"coordinates"`
+}
+
+func buildFeatures() ([]feature, error) {
+	outlines := countryOutlines()
+	sites := allSites()
+
+	// cells ของแต่ละ site เรียงตาม sites
+	cells := make([][]point, len(sites))
+	for i, s := range sites {
+		outline := outlines[s.country]
+		if !ringContains(outline, s.at) {
+			return nil, fmt.Errorf("%s %s %s at %v is outside the %s outline", s.country, s.province, s.district, s.at, s.country)
+		}
+		cell := voronoiCell(outline, sites, i)
+		if len(cell) < 3 || !ringContains(cell, s.at) {
+			return nil, fmt.Errorf("%s %s %s: degenerate cell", s.country, s.province, s.district)
+		}
+		cells[i] = cell
+	}
+
+	var provinces, districts []feature
+	index := map[string]int{}
+	for i, s := range sites {
+		polygon := [][]point{roundRing(cells[i])}
+		key := s.country + "/" + s.province
+		if j, ok := index[key]; ok {
+			provinces[j].Geometry.Coordinates = append(provinces[j].Geometry.Coordinates, polygon)
+		} else {
+			index[key] = len(provinces)
+			provinces = append(provinces, feature{
+				Type:       "Feature",
+				Properties: map[string]string{"level": "province", "name": s.province, "country": s.country},
+				Geometry:   geometry{Type: "MultiPolygon", Coordinates: [][][]point{polygon}},
+			})
+		}
+		if s.district != "" {
+			districts = append(districts, feature{
+				Type:       "Feature",
+				Properties: map[string]string{"level": "district", "name": s.district, "province": s.province, "country": s.country},
+				Geometry:   geometry{Type: "MultiPolygon", Coordinates: [][][]point{polygon}},
+			})
+		}
+	}
+	return append(provinces, districts...), nil
+}
+
+func allSites() []site {
+	var sites []site
+	for _, p := range thaiProvinces {
+		for _, at := range p.seats {
+			sites = append(sites, site{country: "TH", province: p.name, at: point{at[1], at[0]}})
+		}
+	}
+	for _, d := range thaiDistricts {
+		sites = append(sites, site{country: "TH", province: d.province, district: d.name, at: point{d.lon, d.lat}})
+	}
+	for _, p := range laoProvinces {
+		for _, at := range p.seats {
+			sites = append(sites, site{country: "LA", province: p.name, at: point{at[1], at[0]}})
+		}
+	}
+	return sites
+}
+
+// voronoiCell clips the outline to the half-planes that are closer to sites[i] than to the other
+// sites of the same country, nearest first, stopping once the remaining sites are too far away to
+// cut the cell.
+func voronoiCell(outline []point, sites []site, i int) []point {
+	self := sites[i]
+	var others []site
+	for j, s := range sites {
+		if j != i && s.country == self.country {
+			others = append(others, s)
+		}
+	}
+	sort.Slice(others, func(a, b int) bool { return distance(self.at, others[a].at) < distance(self.at, others[b].at) })
+
+	cell := outline
+	for _, o := range others {
+		if distance(self.at, o.at) > 2*radius(self.at, cell) {
+			break
+		}
+		// |p-self|² <= |p-o|² ในพิกัดที่ปรับสเกลแล้ว เป็นอสมการเชิงเส้น a·lon + b·lat <= c
+		k2 := lonScale * lonScale
+		a := 2 * k2 * (o.at[0] - self.at[0])
+		b := 2 * (o.at[1] - self.at[1])
+		c := k2*(o.at[0]*o.at[0]-self.at[0]*self.at[0]) + o.at[1]*o.at[1] - self.at[1]*self.at[1]
+		cell = clipHalfPlane(cell, a, b, c)
+		if len(cell) < 3 {
+			return nil
+		}
+	}
+	return cell
+}
+
+func distance(p, q point) float64 {
+	return math.Hypot((p[0]-q[0])*lonScale, p[1]-q[1])
+}
+
+func radius(center point, ring []point) float64 {
+	r := 0.0
+	for _, p := range ring {
+		r = math.Max(r, distance(center, p))
+	}
+	return r
+}
+
+// clipHalfPlane keeps the part of ring where a·lon + b·lat <= c (Sutherland–Hodgman).
+func clipHalfPlane(ring []point, a, b, c float64) []point {
+	inside := func(p point) bool { return a*p[0]+b*p[1] <= c }
+	var out []point
+	for i := range ring {
+		cur, prev := ring[i], ring[(i+len(ring)-1)%len(ring)]
+		if inside(cur) != inside(prev) {
+			t := (c - a*prev[0] - b*prev[1]) / (a*(cur[0]-prev[0]) + b*(cur[1]-prev[1]))
+			out = append(out, point{prev[0] + t*(cur[0]-prev[0]), prev[1] + t*(cur[1]-prev[1])})
+		}
+		if inside(cur) {
+			out = append(out, cur)
+		}
+	}
+	return out
+}
+
+func signedArea(ring []point) float64 {
+	area := 0.0
+	for i := range ring {
+		p, q := ring[i], ring[(i+1)%len(ring)]
+		area += p[0]*q[1] - q[0]*p[1]
+	}
+	return area / 2
+}
+
+func ringContains(ring []point, p point) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+		if (yi > p[1]) != (yj > p[1]) && p[0] < (xj-xi)*(p[1]-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// roundRing rounds to 4 decimals (about 10 m), drops repeated vertices and closes the ring.
+func roundRing(ring []point) []point {
+	var out []point
+	for _, p := range ring {
+		r := point{math.Round(p[0]*1e4) / 1e4, math.Round(p[1]*1e4) / 1e4}
+		if len(out) == 0 || out[len(out)-1] != r {
+			out = append(out, r)
+		}
+	}
+	if len(out) > 1 && out[0] == out[len(out)-1] {
+		out = out[:len(out)-1]
+	}
+	return append(out, out[0])
+}
+
+// writeFeatures writes one feature per line so regenerated files diff by feature.
+func writeFeatures(path string, features []feature) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	fmt.Fprintln(w, `{"type":"FeatureCollection","approximate":true,"features":[`)
+	for i, feat := range features {
+		raw, err := json.Marshal(feat)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		w.Write(raw)
+		if i < len(features)-1 {
+			w.WriteByte(',')
+		}
+		w.WriteByte('\n')
+	}
+	fmt.Fprintln(w, "]}")
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
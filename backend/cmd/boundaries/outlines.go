@@ -0,0 +1,75 @@
+package main
+
+// Simplified national outlines as [lon, lat] vertices (about 1–10 km accuracy). thaiLaoBorder runs
+// from the Golden Triangle tripoint down the Mekong and the land border to the Emerald Triangle
+// tripoint and is shared by both outlines so that neighbouring cells meet exactly.
+var thaiLaoBorder = [][2]float64{
+	{100.09, 20.36}, {100.09, 20.27}, {100.20, 20.21}, {100.33, 20.23}, {100.41, 20.26}, {100.55, 20.20},
+	{100.58, 20.05}, {100.54, 19.92}, {100.62, 19.72}, {100.55, 19.55}, {100.78, 19.48}, {101.00, 19.60},
+	{101.25, 19.50}, {101.25, 19.15}, {101.28, 18.70}, {101.18, 18.40}, {101.05, 18.15}, {101.08, 17.90},
+	{100.98, 17.62}, {101.10, 17.58}, {101.25, 17.70}, {101.40, 17.85}, {101.66, 17.90}, {101.85, 18.05},
+	{102.10, 18.20}, {102.26, 18.07}, {102.45, 17.96}, {102.60, 17.95}, {102.74, 17.88}, {102.95, 17.95},
+	{103.08, 18.03}, {103.25, 18.20}, {103.45, 18.25}, {103.65, 18.37}, {103.90, 18.35}, {104.05, 18.25},
+	{104.20, 18.00}, {104.40, 17.75}, {104.60, 17.57}, {104.78, 17.40}, {104.80, 17.15}, {104.73, 16.94},
+	{104.74, 16.54}, {104.95, 16.30}, {105.22, 16.04}, {105.40, 15.75}, {105.50, 15.32}, {105.62, 15.05},
+	{105.55, 14.70}, {105.21, 14.35},
+}
+
+// thailandRest continues from the Emerald Triangle along the Cambodian border, the Gulf of
+// Thailand coast, the Malaysian border, the Andaman coast and the Myanmar border back to the
+// Golden Triangle.
+var thailandRest = [][2]float64{
+	{104.80, 14.40}, {104.45, 14.36}, {104.05, 14.33}, {103.60, 14.42}, {103.15, 14.33}, {102.90, 14.15},
+	{102.72, 13.85}, {102.50, 13.65}, {102.35, 13.50}, {102.35, 13.10}, {102.25, 12.80}, {102.62, 12.55},
+	{102.75, 12.25}, {102.92, 11.65}, {102.80, 11.70}, {102.55, 12.15}, {102.30, 12.20}, {102.10, 12.45},
+	{101.75, 12.65}, {101.30, 12.65}, {100.90, 12.63}, {100.86, 12.95}, {100.95, 13.30}, {100.95, 13.50},
+	{100.60, 13.50}, {100.27, 13.48}, {100.00, 13.35}, {100.05, 13.00}, {99.99, 12.80}, {99.98, 12.55},
+	{99.98, 12.30}, {99.84, 11.85}, {99.58, 11.25}, {99.25, 10.50}, {99.18, 9.95}, {99.30, 9.40},
+	{99.40, 9.20}, {99.86, 9.22}, {99.95, 8.95}, {100.05, 8.50}, {100.20, 8.35}, {100.35, 7.80},
+	{100.60, 7.20}, {100.90, 7.00}, {101.25, 6.90}, {101.60, 6.80}, {101.83, 6.45}, {102.10, 6.22},
+	{101.97, 6.02}, {101.80, 5.78}, {101.55, 5.85}, {101.25, 5.75}, {101.10, 5.62}, {100.90, 5.90},
+	{100.75, 6.35}, {100.42, 6.55}, {100.30, 6.65}, {100.10, 6.55}, {99.72, 6.85}, {99.60, 7.15},
+	{99.40, 7.40}, {99.10, 7.80}, {98.90, 8.05}, {98.70, 8.30}, {98.50, 8.35}, {98.45, 7.95},
+	{98.35, 7.75}, {98.25, 7.90}, {98.30, 8.20}, {98.25, 8.60}, {98.25, 8.85}, {98.35, 9.25},
+	{98.40, 9.60}, {98.55, 9.95}, {98.75, 10.35}, {99.05, 10.75}, {99.45, 11.35}, {99.62, 11.80},
+	{99.55, 12.20}, {99.30, 12.60}, {99.15, 12.95}, {99.20, 13.25}, {99.15, 13.60}, {98.95, 14.05},
+	{98.60, 14.50}, {98.40, 14.90}, {98.30, 15.30}, {98.55, 15.60}, {98.60, 15.95}, {98.85, 16.25},
+	{98.75, 16.50}, {98.52, 16.70}, {98.40, 17.00}, {98.10, 17.35}, {97.90, 17.70}, {97.70, 18.00},
+	{97.50, 18.30}, {97.35, 18.55}, {97.40, 18.95}, {97.70, 19.50}, {97.90, 19.65}, {98.20, 19.75},
+	{98.50, 19.70}, {98.85, 19.80}, {99.10, 20.05}, {99.50, 20.25}, {99.70, 20.42}, {99.90, 20.45},
+}
+
+// laosRest continues from the Emerald Triangle along the Cambodian, Vietnamese, Chinese and
+// Myanmar borders back to the Golden Triangle.
+var laosRest = [][2]float64{
+	{105.70, 14.10}, {106.05, 13.95}, {106.35, 14.25}, {106.80, 14.30}, {107.15, 14.40}, {107.55, 14.70},
+	{107.50, 15.25}, {107.65, 15.50}, {107.40, 15.95}, {107.20, 16.10}, {106.90, 16.40}, {106.65, 16.70},
+	{106.50, 17.30}, {106.10, 17.75}, {105.75, 18.10}, {105.55, 18.40}, {105.20, 18.75}, {104.75, 19.15},
+	{104.20, 19.40}, {104.55, 19.65}, {104.95, 19.85}, {104.60, 20.35}, {104.40, 20.45}, {104.60, 20.70},
+	{104.00, 20.95}, {103.60, 20.75}, {103.20, 20.85}, {102.90, 21.25}, {102.85, 21.70}, {102.60, 21.85},
+	{102.55, 22.25}, {102.15, 22.40}, {101.80, 22.45}, {101.75, 22.10}, {101.60, 21.75}, {101.75, 21.35},
+	{101.68, 21.15}, {101.40, 21.35}, {101.15, 21.57}, {101.00, 21.35}, {100.85, 21.15}, {100.62, 20.92},
+	{100.40, 20.75}, {100.20, 20.55},
+}
+
+func reversed(points [][2]float64) [][2]float64 {
+	out := make([][2]float64, len(points))
+	for i, p := range points {
+		out[len(points)-1-i] = p
+	}
+	return out
+}
+
+// countryOutlines returns each country's outline as a counter-clockwise ring.
+func countryOutlines() map[string][][2]float64 {
+	outlines := map[string][][2]float64{
+		"TH": append(append([][2]float64{}, thaiLaoBorder...), thailandRest...),
+		"LA": append(reversed(thaiLaoBorder), reversed(laosRest)...),
+	}
+	for country, ring := range outlines {
+		if signedArea(ring) < 0 {
+			outlines[country] = reversed(ring)
+		}
+	}
+	return outlines
+}
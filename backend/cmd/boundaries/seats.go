@@ -0,0 +1,222 @@
+package main
+
+// provinceSeats lists a province's seats as [lat, lon]: the capital first, then extra points that
+// keep large or elongated provinces from being swallowed by their neighbours.
+type provinceSeats struct {
+	name  string
+	seats [][2]float64
+}
+
+// districtSeat is an amphoe seat; provinces listed here take their cells from their amphoe only.
+type districtSeat struct {
+	province, name string
+	lat, lon       float64
+}
+
+// Thai provinces use the canonical names of the provinces table; the upper north is in thaiDistricts.
+var thaiProvinces = []provinceSeats{
+	{"กรุงเทพมหานคร", [][2]float64{{13.7563, 100.5018}, {13.8300, 100.6800}, {13.8000, 100.8000}, {13.7000, 100.4200}, {13.6600, 100.6000}}},
+	{"กระบี่", [][2]float64{{8.0863, 98.9063}}},
+	{"กาญจนบุรี", [][2]float64{{14.0228, 99.5328}, {14.6000, 99.3000}, {14.7400, 98.6300}, {15.1500, 98.4500}}},
+	{"กาฬสินธุ์", [][2]float64{{16.4314, 103.5058}}},
+	{"กำแพงเพชร", [][2]float64{{16.4827, 99.5226}}},
+	{"ขอนแก่น", [][2]float64{{16.4322, 102.8236}}},
+	{"จันทบุรี", [][2]float64{{12.6114, 102.1039}, {12.9000, 102.2200}}},
+	{"ฉะเชิงเทรา", [][2]float64{{13.6904, 101.0780}, {13.6000, 101.5500}}},
+	{"ชลบุรี", [][2]float64{{13.3611, 100.9847}, {12.9300, 100.8800}, {12.6800, 100.9200}, {13.2200, 101.4800}}},
+	{"ชัยนาท", [][2]float64{{15.1851, 100.1251}}},
+	{"ชัยภูมิ", [][2]float64{{15.8068, 102.0316}}},
+	{"ชุมพร", [][2]float64{{10.4930, 99.1800}, {10.6700, 99.1700}}},
+	{"ตรัง", [][2]float64{{7.5563, 99.6114}}},
+	{"ตราด", [][2]float64{{12.2436, 102.5151}, {11.7700, 102.8500}}},
+	{"ตาก", [][2]float64{{16.8840, 99.1258}, {16.7130, 98.5700}, {16.0200, 98.8600}, {17.2300, 98.2700}}},
+	{"นครนายก", [][2]float64{{14.2069, 101.2131}}},
+	{"นครปฐม", [][2]float64{{13.8199, 100.0622}}},
+	{"นครพนม", [][2]float64{{17.3900, 104.7400}}},
+	{"นครราชสีมา", [][2]float64{{14.9799, 102.0977}, {14.7100, 101.4200}, {15.5900, 102.4300}, {14.4300, 102.4600}}},
+	{"นครศรีธรรมราช", [][2]float64{{8.4304, 99.9631}, {8.1600, 99.6800}, {9.0000, 99.8800}}},
+	{"นครสวรรค์", [][2]float64{{15.7030, 100.1370}}},
+	{"นนทบุรี", [][2]float64{{13.8621, 100.5144}, {13.9000, 100.4000}}},
+	{"นราธิวาส", [][2]float64{{6.4255, 101.8253}, {6.0500, 101.9500}}},
+	{"บึงกาฬ", [][2]float64{{18.3300, 103.6500}}},
+	{"บุรีรัมย์", [][2]float64{{14.9930, 103.1029}}},
+	{"ปทุมธานี", [][2]float64{{14.0208, 100.5250}}},
+	{"ประจวบคีรีขันธ์", [][2]float64{{11.8126, 99.7957}, {12.5700, 99.9550}, {11.2100, 99.5100}}},
+	{"ปราจีนบุรี", [][2]float64{{14.0509, 101.3717}}},
+	{"ปัตตานี", [][2]float64{{6.8695, 101.2501}}},
+	{"พระนครศรีอยุธยา", [][2]float64{{14.3532, 100.5689}}},
+	{"พังงา", [][2]float64{{8.4501, 98.5255}, {8.8600, 98.3500}}},
+	{"พัทลุง", [][2]float64{{7.6167, 100.0740}}},
+	{"พิจิตร", [][2]float64{{16.4429, 100.3487}}},
+	{"พิษณุโลก", [][2]float64{{16.8211, 100.2659}, {17.1000, 100.8400}}},
+	{"เพชรบุรี", [][2]float64{{13.1119, 99.9447}, {12.9500, 99.6200}}},
+	{"เพชรบูรณ์", [][2]float64{{16.4189, 101.1591}, {16.7800, 101.2400}}},
+	{"ภูเก็ต", [][2]float64{{7.8804, 98.3923}, {8.0300, 98.3300}}},
+	{"มหาสารคาม", [][2]float64{{16.1851, 103.3029}}},
+	{"มุกดาหาร", [][2]float64{{16.5450, 104.7000}}},
+	{"ยะลา", [][2]float64{{6.5411, 101.2804}, {5.7700, 101.0700}}},
+	{"ยโสธร", [][2]float64{{15.7944, 104.1453}}},
+	{"ร้อยเอ็ด", [][2]float64{{16.0538, 103.6520}}},
+	{"ระนอง", [][2]float64{{9.9529, 98.6085}}},
+	{"ระยอง", [][2]float64{{12.6814, 101.2816}}},
+	{"ราชบุรี", [][2]float64{{13.5283, 99.8134}}},
+	{"ลพบุรี", [][2]float64{{14.7995, 100.6534}, {15.2000, 101.1300}}},
+	{"เลย", [][2]float64{{17.4860, 101.7223}, {17.8600, 101.6700}, {17.2800, 101.1500}}},
+	{"ศรีสะเกษ", [][2]float64{{15.1186, 104.3220}}},
+	{"สกลนคร", [][2]float64{{17.1545, 104.1348}}},
+	{"สงขลา", [][2]float64{{7.1897, 100.5954}, {7.0086, 100.4747}, {6.6400, 100.4200}, {6.9100, 100.7400}}},
+	{"สตูล", [][2]float64{{6.6238, 100.0674}}},
+	{"สมุทรปราการ", [][2]float64{{13.5991, 100.5998}}},
+	{"สมุทรสงคราม", [][2]float64{{13.4098, 100.0023}}},
+	{"สมุทรสาคร", [][2]float64{{13.5475, 100.2744}}},
+	{"สระบุรี", [][2]float64{{14.5289, 100.9101}}},
+	{"สระแก้ว", [][2]float64{{13.8240, 102.0646}, {13.6900, 102.4800}}},
+	{"สิงห์บุรี", [][2]float64{{14.8936, 100.3967}}},
+	{"สุโขทัย", [][2]float64{{17.0070, 99.8230}}},
+	{"สุพรรณบุรี", [][2]float64{{14.4745, 100.1177}, {14.8400, 99.7000}}},
+	{"สุราษฎร์ธานี", [][2]float64{{9.1382, 99.3217}, {9.3900, 99.2000}, {8.6300, 99.3700}, {8.9200, 98.8800}}},
+	{"สุรินทร์", [][2]float64{{14.8818, 103.4936}}},
+	{"หนองคาย", [][2]float64{{17.8500, 102.7400}}},
+	{"หนองบัวลำภู", [][2]float64{{17.2218, 102.4260}}},
+	{"อ่างทอง", [][2]float64{{14.5896, 100.4551}}},
+	{"อำนาจเจริญ", [][2]float64{{15.8657, 104.6258}}},
+	{"อุดรธานี", [][2]float64{{17.4138, 102.7870}}},
+	{"อุทัยธานี", [][2]float64{{15.3835, 100.0246}, {15.0800, 99.5200}}},
+	{"อุตรดิตถ์", [][2]float64{{17.6200, 100.0993}, {17.7300, 100.6900}}},
+	{"อุบลราชธานี", [][2]float64{{15.2287, 104.8564}, {16.0400, 105.1700}, {14.9000, 105.0800}, {15.3200, 105.4500}}},
+}
+
+var thaiDistricts = []districtSeat{
+	{"เชียงใหม่", "เมืองเชียงใหม่", 18.7883, 98.9853},
+	{"เชียงใหม่", "จอมทอง", 18.4170, 98.6760},
+	{"เชียงใหม่", "แม่แจ่ม", 18.4970, 98.3630},
+	{"เชียงใหม่", "เชียงดาว", 19.3670, 98.9640},
+	{"เชียงใหม่", "ดอยสะเก็ด", 18.8720, 99.1370},
+	{"เชียงใหม่", "แม่แตง", 19.1200, 98.9460},
+	{"เชียงใหม่", "แม่ริม", 18.9140, 98.9440},
+	{"เชียงใหม่", "สะเมิง", 18.8470, 98.7300},
+	{"เชียงใหม่", "ฝาง", 19.9180, 99.2100},
+	{"เชียงใหม่", "แม่อาย", 20.0300, 99.2800},
+	{"เชียงใหม่", "พร้าว", 19.3600, 99.2000},
+	{"เชียงใหม่", "สันป่าตอง", 18.6270, 98.8950},
+	{"เชียงใหม่", "สันกำแพง", 18.7450, 99.1200},
+	{"เชียงใหม่", "สันทราย", 18.8460, 99.0420},
+	{"เชียงใหม่", "หางดง", 18.6870, 98.9190},
+	{"เชียงใหม่", "ฮอด", 18.1910, 98.6100},
+	{"เชียงใหม่", "ดอยเต่า", 17.9500, 98.6800},
+	{"เชียงใหม่", "อมก๋อย", 17.7990, 98.3590},
+	{"เชียงใหม่", "สารภี", 18.7100, 99.0370},
+	{"เชียงใหม่", "เวียงแหง", 19.5580, 98.6370},
+	{"เชียงใหม่", "ไชยปราการ", 19.7300, 99.1400},
+	{"เชียงใหม่", "แม่วาง", 18.6100, 98.7600},
+	{"เชียงใหม่", "แม่ออน", 18.8600, 99.2800},
+	{"เชียงใหม่", "ดอยหล่อ", 18.4600, 98.7800},
+	{"เชียงใหม่", "กัลยาณิวัฒนา", 19.0700, 98.3200},
+
+	{"เชียงราย", "เมืองเชียงราย", 19.9105, 99.8406},
+	{"เชียงราย", "เวียงชัย", 19.8840, 99.9330},
+	{"เชียงราย", "เชียงของ", 20.2200, 100.4000},
+	{"เชียงราย", "เทิง", 19.6870, 100.1950},
+	{"เชียงราย", "พาน", 19.5500, 99.7400},
+	{"เชียงราย", "ป่าแดด", 19.5050, 99.9900},
+	{"เชียงราย", "แม่จัน", 20.1460, 99.8520},
+	{"เชียงราย", "เชียงแสน", 20.2750, 100.0600},
+	{"เชียงราย", "แม่สาย", 20.4260, 99.8840},
+	{"เชียงราย", "แม่สรวย", 19.6560, 99.5440},
+	{"เชียงราย", "เวียงป่าเป้า", 19.3510, 99.5070},
+	{"เชียงราย", "พญาเม็งราย", 19.8470, 100.1520},
+	{"เชียงราย", "เวียงแก่น", 20.0500, 100.5000},
+	{"เชียงราย", "ขุนตาล", 19.8400, 100.2700},
+	{"เชียงราย", "แม่ฟ้าหลวง", 20.2500, 99.6300},
+	{"เชียงราย", "แม่ลาว", 19.7800, 99.7000},
+	{"เชียงราย", "เวียงเชียงรุ้ง", 20.0000, 99.9700},
+	{"เชียงราย", "ดอยหลวง", 20.1000, 100.0900},
+
+	{"ลำพูน", "เมืองลำพูน", 18.5800, 99.0080},
+	{"ลำพูน", "แม่ทา", 18.4650, 99.1400},
+	{"ลำพูน", "บ้านโฮ่ง", 18.3050, 98.8150},
+	{"ลำพูน", "ลี้", 17.8000, 98.9500},
+	{"ลำพูน", "ทุ่งหัวช้าง", 17.9900, 99.0300},
+	{"ลำพูน", "ป่าซาง", 18.5230, 98.9400},
+	{"ลำพูน", "บ้านธิ", 18.6600, 99.1400},
+	{"ลำพูน", "เวียงหนองล่อง", 18.4200, 98.7500},
+
+	{"ลำปาง", "เมืองลำปาง", 18.2900, 99.4920},
+	{"ลำปาง", "แม่เมาะ", 18.3000, 99.6700},
+	{"ลำปาง", "เกาะคา", 18.1900, 99.3900},
+	{"ลำปาง", "เสริมงาม", 18.1000, 99.2000},
+	{"ลำปาง", "งาว", 18.7500, 99.9800},
+	{"ลำปาง", "แจ้ห่ม", 18.7100, 99.5700},
+	{"ลำปาง", "วังเหนือ", 19.1400, 99.6200},
+	{"ลำปาง", "เถิน", 17.6100, 99.2200},
+	{"ลำปาง", "แม่พริก", 17.4500, 99.1100},
+	{"ลำปาง", "แม่ทะ", 18.1500, 99.5500},
+	{"ลำปาง", "สบปราบ", 17.8800, 99.3300},
+	{"ลำปาง", "ห้างฉัตร", 18.3300, 99.3500},
+	{"ลำปาง", "เมืองปาน", 18.7700, 99.4800},
+
+	{"แม่ฮ่องสอน", "เมืองแม่ฮ่องสอน", 19.3020, 97.9650},
+	{"แม่ฮ่องสอน", "ขุนยวม", 18.8300, 97.9300},
+	{"แม่ฮ่องสอน", "ปาย", 19.3580, 98.4400},
+	{"แม่ฮ่องสอน", "แม่สะเรียง", 18.1590, 97.9340},
+	{"แม่ฮ่องสอน", "แม่ลาน้อย", 18.3900, 97.9400},
+	{"แม่ฮ่องสอน", "สบเมย", 17.9300, 97.9200},
+	{"แม่ฮ่องสอน", "ปางมะผ้า", 19.5000, 98.2500},
+
+	{"พะเยา", "เมืองพะเยา", 19.1660, 99.9020},
+	{"พะเยา", "จุน", 19.3400, 100.1300},
+	{"พะเยา", "เชียงคำ", 19.5200, 100.3000},
+	{"พะเยา", "เชียงม่วน", 18.8900, 100.2900},
+	{"พะเยา", "ดอกคำใต้", 19.1600, 99.9900},
+	{"พะเยา", "ปง", 19.1500, 100.2700},
+	{"พะเยา", "แม่ใจ", 19.3500, 99.8100},
+	{"พะเยา", "ภูซาง", 19.6200, 100.3400},
+	{"พะเยา", "ภูกามยาว", 19.2800, 99.9800},
+
+	{"น่าน", "เมืองน่าน", 18.7830, 100.7790},
+	{"น่าน", "แม่จริม", 18.7200, 100.9900},
+	{"น่าน", "บ้านหลวง", 18.8500, 100.4500},
+	{"น่าน", "นาน้อย", 18.3300, 100.7200},
+	{"น่าน", "ปัว", 19.1750, 100.9100},
+	{"น่าน", "ท่าวังผา", 19.1200, 100.8000},
+	{"น่าน", "เวียงสา", 18.5700, 100.7500},
+	{"น่าน", "ทุ่งช้าง", 19.3950, 100.8800},
+	{"น่าน", "เชียงกลาง", 19.2900, 100.8700},
+	{"น่าน", "นาหมื่น", 18.1800, 100.6600},
+	{"น่าน", "สันติสุข", 18.9100, 100.9500},
+	{"น่าน", "บ่อเกลือ", 19.1500, 101.1570},
+	{"น่าน", "สองแคว", 19.3600, 100.7000},
+	{"น่าน", "ภูเพียง", 18.7500, 100.8200},
+	{"น่าน", "เฉลิมพระเกียรติ", 19.5200, 101.0500},
+
+	{"แพร่", "เมืองแพร่", 18.1450, 100.1410},
+	{"แพร่", "ร้องกวาง", 18.3400, 100.3200},
+	{"แพร่", "ลอง", 18.0800, 99.8300},
+	{"แพร่", "สูงเม่น", 18.0500, 100.1000},
+	{"แพร่", "เด่นชัย", 17.9800, 100.0500},
+	{"แพร่", "สอง", 18.4600, 100.1900},
+	{"แพร่", "วังชิ้น", 17.8900, 99.6300},
+	{"แพร่", "หนองม่วงไข่", 18.2900, 100.1600},
+}
+
+// Lao provinces keep their English names, as LocateByCoordinates does not normalize them.
+var laoProvinces = []provinceSeats{
+	{"Vientiane Capital", [][2]float64{{17.9757, 102.6331}, {18.0800, 102.8000}}},
+	{"Vientiane Province", [][2]float64{{18.5000, 102.4200}, {18.9200, 102.4500}, {19.2200, 102.2500}, {18.1000, 101.7500}}},
+	{"Luang Prabang", [][2]float64{{19.8845, 102.1347}, {20.6300, 102.4000}, {19.9300, 102.7800}}},
+	{"Bokeo", [][2]float64{{20.3000, 100.4100}, {20.4000, 100.1600}, {20.1300, 100.6100}, {20.6700, 100.6200}}},
+	{"Luang Namtha", [][2]float64{{20.9500, 101.4000}, {21.1900, 101.1600}, {20.9500, 100.8300}, {20.7000, 101.0800}}},
+	{"Oudomxay", [][2]float64{{20.6920, 101.9870}, {19.9000, 101.1300}, {20.9300, 101.8100}}},
+	{"Phongsaly", [][2]float64{{21.6819, 102.1040}, {22.1500, 102.0000}, {21.0800, 102.5100}}},
+	{"Houaphanh", [][2]float64{{20.4150, 104.0480}, {20.4200, 104.2300}, {20.0800, 104.7000}, {20.7500, 104.1200}, {20.2200, 103.3500}}},
+	{"Xiangkhouang", [][2]float64{{19.4600, 103.1900}, {19.5000, 103.9500}, {19.6200, 103.5800}}},
+	{"Xaisomboun", [][2]float64{{18.9000, 102.9500}, {18.9700, 103.6000}, {18.4500, 103.0500}}},
+	{"Sainyabuli", [][2]float64{{19.2500, 101.7500}, {19.7000, 101.3200}, {18.2000, 101.4100}, {19.0800, 101.4800}}},
+	{"Bolikhamxay", [][2]float64{{18.4200, 103.6600}, {18.1900, 104.9700}, {18.3600, 103.9900}, {18.7300, 103.8500}}},
+	{"Khammouane", [][2]float64{{17.4050, 104.8300}, {17.4000, 105.2000}, {17.2200, 105.7200}, {17.7000, 105.1500}, {17.7300, 104.5800}}},
+	{"Savannakhet", [][2]float64{{16.5570, 104.7800}, {16.6900, 106.2200}, {16.5400, 106.0300}, {16.1800, 105.2800}, {16.6800, 105.0000}, {16.9600, 105.6200}}},
+	{"Salavan", [][2]float64{{15.7170, 106.4180}, {15.5800, 105.8000}, {16.1000, 106.6500}, {15.4500, 106.1700}}},
+	{"Sekong", [][2]float64{{15.3450, 106.7250}, {15.3500, 107.3500}, {15.8000, 107.1000}}},
+	{"Champasak", [][2]float64{{15.1200, 105.7990}, {14.8900, 105.8700}, {14.1200, 105.8600}, {14.7000, 105.6200}, {15.1800, 106.2300}, {14.3500, 105.6000}}},
+	{"Attapeu", [][2]float64{{14.8100, 106.8300}, {14.9500, 107.2000}, {14.5000, 107.0500}}},
+}
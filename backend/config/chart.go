@@ -0,0 +1,44 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// defaultChartFontFiles are tried in order when CHART_FONT_FILES is not set: a Latin font
+// for digits and units first, then Thai fonts (Alpine font-dejavu/font-noto-thai and
+// Debian fonts-dejavu/fonts-thai-tlwg paths).
+var defaultChartFontFiles = []string{
+	"/usr/share/fonts/dejavu/DejaVuSans.ttf",
+	"/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf",
+	"/usr/share/fonts/noto/NotoSansThai-Regular.ttf",
+	"/usr/share/fonts/truetype/noto/NotoSansThai-Regular.ttf",
+	"/usr/share/fonts/truetype/tlwg/Garuda.ttf",
+}
+
+// ChartFontFiles lists the TrueType fonts used to draw text on PNG charts, in fallback
+// order (CHART_FONT_FILES, comma separated). When unset, REPORT_FONT_FILE and the usual
+// system font paths that exist are used.
+func ChartFontFiles() []string {
+	if raw := strings.TrimSpace(os.Getenv("CHART_FONT_FILES")); raw != "" {
+		var files []string
+		for _, f := range strings.Split(raw, ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				files = append(files, f)
+			}
+		}
+		return files
+	}
+
+	var files []string
+	candidates := defaultChartFontFiles
+	if report := ReportFontFile(); report != "" {
+		candidates = append([]string{report}, candidates...)
+	}
+	for _, f := range candidates {
+		if _, err := os.Stat(f); err == nil {
+			files = append(files, f)
+		}
+	}
+	return files
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// DeviceStaleAfter is how long a device may go without a new reading before it is
+// reported as "stale". Configurable via DEVICE_STALE_AFTER (Go duration, default 30m).
+func DeviceStaleAfter() time.Duration {
+	return durationFromEnv("DEVICE_STALE_AFTER", 30*time.Minute)
+}
+
+// DeviceOfflineAfter is how long a device may go without a new reading before it is
+// reported as "offline". Configurable via DEVICE_OFFLINE_AFTER (Go duration, default 3h).
+func DeviceOfflineAfter() time.Duration {
+	return durationFromEnv("DEVICE_OFFLINE_AFTER", 3*time.Hour)
+}
+
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		log.Printf("warning: invalid %s=%q; using %s", key, raw, fallback)
+		return fallback
+	}
+	return d
+}
@@ -0,0 +1,12 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// AdminBoundariesFile returns an optional path to an administrative boundary GeoJSON
+// (ADMIN_BOUNDARIES_FILE) that replaces the dataset bundled with the binary.
+func AdminBoundariesFile() string {
+	return strings.TrimSpace(os.Getenv("ADMIN_BOUNDARIES_FILE"))
+}
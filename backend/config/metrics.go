@@ -0,0 +1,12 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// MetricsToken, when set (METRICS_TOKEN), must be sent as "Authorization: Bearer <token>"
+// to read /metrics. Leave it empty when the endpoint is only reachable by Prometheus.
+func MetricsToken() string {
+	return strings.TrimSpace(os.Getenv("METRICS_TOKEN"))
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPSettings holds the outgoing mail server configuration.
+// Leaving SMTP_USERNAME empty sends without authentication, which also works
+// with a local SMTP stand-in such as MailHog (SMTP_HOST=localhost SMTP_PORT=1025).
+type SMTPSettings struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// Enabled reports whether an SMTP host has been configured.
+func (s SMTPSettings) Enabled() bool {
+	return s.Host != ""
+}
+
+// SMTP reads SMTP_HOST, SMTP_PORT, SMTP_USERNAME, SMTP_PASSWORD and SMTP_FROM.
+func SMTP() SMTPSettings {
+	settings := SMTPSettings{
+		Host:     strings.TrimSpace(os.Getenv("SMTP_HOST")),
+		Port:     strings.TrimSpace(os.Getenv("SMTP_PORT")),
+		Username: strings.TrimSpace(os.Getenv("SMTP_USERNAME")),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     strings.TrimSpace(os.Getenv("SMTP_FROM")),
+	}
+	if settings.Port == "" {
+		settings.Port = "587"
+	}
+	if settings.From == "" {
+		settings.From = "no-reply@yakkaw.local"
+	}
+	return settings
+}
+
+// DeviceAlertAfter is how long a device may stay silent before an alert is opened.
+// Configurable via DEVICE_ALERT_AFTER, defaulting to DeviceOfflineAfter.
+func DeviceAlertAfter() time.Duration {
+	return durationFromEnv("DEVICE_ALERT_AFTER", DeviceOfflineAfter())
+}
+
+// DeviceAlertLookback limits alerts to devices that are in the device registry or reported
+// within this window (DEVICE_ALERT_LOOKBACK, default 720h), so long-retired DVIDs found only
+// in sensor_data history do not raise alerts.
+func DeviceAlertLookback() time.Duration {
+	return durationFromEnv("DEVICE_ALERT_LOOKBACK", 30*24*time.Hour)
+}
+
+// DeviceAlertCheckInterval controls how often the device alert job runs (DEVICE_ALERT_CHECK_INTERVAL, default 5m).
+func DeviceAlertCheckInterval() time.Duration {
+	return durationFromEnv("DEVICE_ALERT_CHECK_INTERVAL", 5*time.Minute)
+}
+
+// DeviceAlertWebhook returns the optional webhook URL and signing secret for device alerts.
+func DeviceAlertWebhook() (string, string) {
+	return strings.TrimSpace(os.Getenv("DEVICE_ALERT_WEBHOOK_URL")), os.Getenv("DEVICE_ALERT_WEBHOOK_SECRET")
+}
+
+// DeviceAlertEmail returns an optional admin address that receives every device alert
+// in addition to the device's own contact email.
+func DeviceAlertEmail() string {
+	return strings.TrimSpace(os.Getenv("DEVICE_ALERT_EMAIL"))
+}
+
+// SubscriptionRateLimit is the maximum number of alerts delivered to one user per hour
+// (SUBSCRIPTION_RATE_LIMIT, default 5). Extra alerts are logged as rate limited.
+func SubscriptionRateLimit() int {
+	raw := strings.TrimSpace(os.Getenv("SUBSCRIPTION_RATE_LIMIT"))
+	if raw == "" {
+		return 5
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		log.Printf("warning: invalid SUBSCRIPTION_RATE_LIMIT=%q; using 5", raw)
+		return 5
+	}
+	return n
+}
+
+// PublicBaseURL is the externally reachable base URL of this API, used to build
+// unsubscribe links. Reads PUBLIC_BASE_URL, then QR_CONSUME_BASE_URL.
+func PublicBaseURL() string {
+	for _, key := range []string{"PUBLIC_BASE_URL", "QR_CONSUME_BASE_URL"} {
+		if base := strings.TrimRight(strings.TrimSpace(os.Getenv(key)), "/"); base != "" {
+			return base
+		}
+	}
+	return "http://localhost:8080"
+}
+
+// VAPIDKeys returns the Web Push application server keys from VAPID_PUBLIC_KEY and
+// VAPID_PRIVATE_KEY (unpadded base64url, as produced by common web-push tools).
+// When unset, a key pair is generated once and stored in the database.
+func VAPIDKeys() (string, string) {
+	return strings.TrimSpace(os.Getenv("VAPID_PUBLIC_KEY")), strings.TrimSpace(os.Getenv("VAPID_PRIVATE_KEY"))
+}
+
+// VAPIDSubject is the contact URI sent to push services (VAPID_SUBJECT, default mailto:SMTP_FROM).
+func VAPIDSubject() string {
+	if subject := strings.TrimSpace(os.Getenv("VAPID_SUBJECT")); subject != "" {
+		return subject
+	}
+	return "mailto:" + SMTP().From
+}
+
+// defaultPushServiceHosts are the push services used by current browsers: FCM (Chrome, Edge
+// on Android, Opera), Mozilla autopush (Firefox), Apple (Safari) and WNS (Edge on Windows).
+var defaultPushServiceHosts = []string{
+	"fcm.googleapis.com",
+	"android.googleapis.com",
+	"push.services.mozilla.com",
+	"push.apple.com",
+	"notify.windows.com",
+}
+
+// PushServiceHosts lists the hosts (and their subdomains) that push subscription endpoints
+// may point to. PUSH_SERVICE_HOSTS (comma-separated) replaces the default list, e.g. to add
+// a self-hosted autopush server.
+func PushServiceHosts() []string {
+	raw := strings.TrimSpace(os.Getenv("PUSH_SERVICE_HOSTS"))
+	if raw == "" {
+		return defaultPushServiceHosts
+	}
+	var hosts []string
+	for _, host := range strings.Split(raw, ",") {
+		if host = strings.ToLower(strings.Trim(strings.TrimSpace(host), ".")); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
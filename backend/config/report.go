@@ -0,0 +1,27 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// ReportsDir is where generated PDF reports are stored (REPORTS_DIR, default "reports").
+func ReportsDir() string {
+	if dir := strings.TrimSpace(os.Getenv("REPORTS_DIR")); dir != "" {
+		return dir
+	}
+	return "reports"
+}
+
+// ReportFontFile is an optional TrueType font (REPORT_FONT_FILE) embedded in reports.
+// Without it reports use Helvetica, which cannot draw Thai, so names fall back to English.
+func ReportFontFile() string {
+	return strings.TrimSpace(os.Getenv("REPORT_FONT_FILE"))
+}
+
+// ReportCheckInterval controls how often the scheduler looks for months still missing
+// a report (REPORT_CHECK_INTERVAL, default 1h).
+func ReportCheckInterval() time.Duration {
+	return durationFromEnv("REPORT_CHECK_INTERVAL", time.Hour)
+}
@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"encoding/json"
+	"log"
 	"net/http"
 	"strings"
 	"time"
@@ -8,6 +10,7 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"yakkaw_dashboard/database"
+	"yakkaw_dashboard/models"
 	"yakkaw_dashboard/services"
 )
 
@@ -19,37 +22,49 @@ func NewAirQualityController() *AirQualityController {
 
 // Handler สำหรับดึงค่าเฉลี่ย 1 สัปดาห์
 func (ctl *AirQualityController) GetOneWeekDataHandler(c echo.Context) error {
-	data, err := services.GetAirQualityOneWeek()
+	data, err := services.GetAirQualityOneWeek(parseDataOptions(c))
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
+	if format := exportFormat(c); format != "" {
+		return respondAddressAverages(c, format, "airquality-one-week", data["data"])
+	}
 	return c.JSON(http.StatusOK, data)
 }
 
 // Handler สำหรับดึงค่าเฉลี่ย 1 เดือน
 func (ctl *AirQualityController) GetOneMonthDataHandler(c echo.Context) error {
-	data, err := services.GetAirQualityOneMonth()
+	data, err := services.GetAirQualityOneMonth(parseDataOptions(c))
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
+	if format := exportFormat(c); format != "" {
+		return respondAddressAverages(c, format, "airquality-one-month", data["data"])
+	}
 	return c.JSON(http.StatusOK, data)
 }
 
 // Handler สำหรับดึงค่าเฉลี่ย 3 เดือน
 func (ctl *AirQualityController) GetThreeMonthsDataHandler(c echo.Context) error {
-	data, err := services.GetAirQualityThreeMonths()
+	data, err := services.GetAirQualityThreeMonths(parseDataOptions(c))
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
+	if format := exportFormat(c); format != "" {
+		return respondAddressAverages(c, format, "airquality-three-months", data["data"])
+	}
 	return c.JSON(http.StatusOK, data)
 }
 
 // Handler สำหรับดึงค่าเฉลี่ย 1 ปี
 func (ctl *AirQualityController) GetOneYearDataHandler(c echo.Context) error {
-	data, err := services.GetAirQualityOneYear()
+	data, err := services.GetAirQualityOneYear(parseDataOptions(c))
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
+	if format := exportFormat(c); format != "" {
+		return respondAddressAverages(c, format, "airquality-one-year", data["data"])
+	}
 	return c.JSON(http.StatusOK, data)
 }
 
@@ -78,22 +93,79 @@ func GetLatestAirQuality(c echo.Context) error {
 	return c.JSON(http.StatusOK, result)
 }
 
-// GetProvinceAveragePM25Handler ดึงค่าเฉลี่ย PM2.5 ของแต่ละจังหวัด
+// GetProvinceAveragePM25Handler ดึงค่าเฉลี่ย PM2.5 ของแต่ละจังหวัด (?group=region = รายภาค)
 func (ctl *AirQualityController) GetProvinceAveragePM25Handler(c echo.Context) error {
-	data, err := services.GetProvinceAveragePM25()
+	average, toGeoJSON := services.GetProvinceAveragePM25, services.ProvinceAveragesGeoJSON
+	group := c.QueryParam("group")
+	switch group {
+	case "", "province":
+		group = "province"
+	case "region":
+		average, toGeoJSON = services.GetRegionAveragePM25, services.RegionAveragesGeoJSON
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "group must be province or region"})
+	}
+
+	data, err := average(parseDataOptions(c))
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
+	if format := exportFormat(c); format != "" {
+		return respondAreaAverages(c, format, group, data)
+	}
+	if wantsGeoJSON(c) {
+		fc, err := toGeoJSON(data)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		return respondGeoJSON(c, fc)
+	}
 	return c.JSON(http.StatusOK, data)
 }
 
-// GetSensorData7DaysHandler ดึงข้อมูล sensor_data ย้อนหลัง 7 วัน
+// GetSensorData7DaysHandler ดึงข้อมูล sensor_data ย้อนหลัง 7 วัน (?format=csv|xlsx จะ stream ทีละแถว)
 func (ctl *AirQualityController) GetSensorData7DaysHandler(c echo.Context) error {
-	data, err := services.GetSensorData7Days()
-	if err != nil {
+	if format := exportFormat(c); format != "" {
+		return respondTable(c, format, "sensor-data-7-days", sensorDataColumns, func(write func(...interface{}) error) error {
+			return services.EachSensorData7Days(func(d models.SensorData) error {
+				return write(
+					bangkokTime(d.Timestamp), d.DVID, d.Place, d.Address, d.Province, d.District, d.Region,
+					d.Latitude, d.Longitude, d.PM25, d.PM10, d.PM100, d.AQI, d.Temperature, d.Humidity, d.Pres,
+					d.PM25Calibrated, d.PM10Calibrated, d.QualityFlags,
+				)
+			})
+		})
+	}
+
+	// เขียน JSON array ทีละแถวแทนการโหลดทั้งสัปดาห์เข้าหน่วยความจำ (ดึงแบบแบ่งหน้าได้ที่ /api/v1/readings)
+	res := c.Response()
+	enc := json.NewEncoder(res)
+	count := 0
+	err := services.EachSensorData7Days(func(d models.SensorData) error {
+		if count == 0 {
+			res.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			res.WriteHeader(http.StatusOK)
+			if _, err := res.Write([]byte("[")); err != nil {
+				return err
+			}
+		} else if _, err := res.Write([]byte(",")); err != nil {
+			return err
+		}
+		count++
+		return enc.Encode(d)
+	})
+	if err != nil && !res.Committed {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
-	return c.JSON(http.StatusOK, data)
+	if err != nil {
+		log.Printf("Error streaming sensor data: %v", err)
+		return nil
+	}
+	if count == 0 {
+		return c.JSON(http.StatusOK, []models.SensorData{})
+	}
+	_, err = res.Write([]byte("]"))
+	return err
 }
 
 func GetAirQualityOneYearSeriesByPlace(c echo.Context) error {
@@ -102,10 +174,13 @@ func GetAirQualityOneYearSeriesByPlace(c echo.Context) error {
         return c.JSON(http.StatusBadRequest, map[string]string{"error": "place is required"})
     }
 
-    data, err := services.GetAirQualityOneYearSeriesByPlace(place)
+    data, err := services.GetAirQualityOneYearSeriesByPlace(place, parseDataOptions(c))
     if err != nil {
         return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
     }
+    if format := exportFormat(c); format != "" {
+        return respondDailySeries(c, format, "one-year-series-by-place", data["data"])
+    }
     return c.JSON(http.StatusOK, data)
 }
 
@@ -115,18 +190,24 @@ func GetAirQualityOneYearSeriesByProvince(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "province is required"})
 	}
 
-	data, err := services.GetAirQualityOneYearSeriesByProvince(province)
+	data, err := services.GetAirQualityOneYearSeriesByProvince(province, parseDataOptions(c))
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
+	if format := exportFormat(c); format != "" {
+		return respondDailySeries(c, format, "one-year-series-by-province", data["data"])
+	}
 	return c.JSON(http.StatusOK, data)
 }
 
 func (ctl *AirQualityController) GetOneDayDataHandler(c echo.Context) error {
-	data, err := services.GetAirQuality24Hours()
+	data, err := services.GetAirQuality24Hours(parseDataOptions(c))
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
+	if format := exportFormat(c); format != "" {
+		return respondAddressAverages(c, format, "airquality-one-day", data["data"])
+	}
 
 	response := map[string]interface{}{
 		"current_date": time.Now(),
@@ -136,3 +217,65 @@ func (ctl *AirQualityController) GetOneDayDataHandler(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, response)
 }
+
+var sensorDataColumns = []exportColumn{
+	{"Time", "เวลา"}, {"Device ID", "รหัสอุปกรณ์"}, {"Place", "สถานที่"}, {"Address", "ที่อยู่"},
+	{"Province", "จังหวัด"}, {"District", "อำเภอ"}, {"Region", "ภาค"}, {"Latitude", "ละติจูด"}, {"Longitude", "ลองจิจูด"},
+	{"PM2.5", "PM2.5"}, {"PM10", "PM10"}, {"PM100", "PM100"}, {"AQI", "AQI"},
+	{"Temperature", "อุณหภูมิ"}, {"Humidity", "ความชื้น"}, {"Pressure", "ความกดอากาศ"},
+	{"PM2.5 calibrated", "PM2.5 ปรับเทียบ"}, {"PM10 calibrated", "PM10 ปรับเทียบ"}, {"Quality flags", "สถานะคุณภาพข้อมูล"},
+}
+
+// BackfillQualityFlags คำนวณ quality flag ของข้อมูลย้อนหลังทั้งหมดใหม่ใน background (ข้อมูลก่อนมีการตรวจคุณภาพยังไม่ถูก flag)
+func BackfillQualityFlags(c echo.Context) error {
+	if !services.BackfillQualityFlagsAsync() {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "a quality flag backfill is already running"})
+	}
+	return c.JSON(http.StatusAccepted, map[string]string{"message": "Quality flag backfill started"})
+}
+
+// respondAddressAverages export ค่าเฉลี่ยรายที่อยู่ของ endpoint one_day/one_week/...
+func respondAddressAverages(c echo.Context, format, name string, data interface{}) error {
+	rows, _ := data.([]map[string]interface{})
+	columns := []exportColumn{{"Address", "ที่อยู่"}, {"Average PM2.5", "PM2.5 เฉลี่ย"}, {"Average PM10", "PM10 เฉลี่ย"}}
+	return respondTable(c, format, name, columns, func(write func(...interface{}) error) error {
+		for _, row := range rows {
+			if err := write(row["address"], row["avg_pm25"], row["avg_pm10"]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// respondAreaAverages export ค่าเฉลี่ย PM2.5 รายจังหวัด/รายภาค (group = province | region)
+func respondAreaAverages(c echo.Context, format, group string, rows []map[string]interface{}) error {
+	area := exportColumn{"Province", "จังหวัด"}
+	if group == "region" {
+		area = exportColumn{"Region", "ภาค"}
+	}
+	columns := []exportColumn{area, {"Average PM2.5", "PM2.5 เฉลี่ย"}, {"Station count", "จำนวนสถานี"}}
+	return respondTable(c, format, group+"-average-pm25", columns, func(write func(...interface{}) error) error {
+		for _, row := range rows {
+			if err := write(row[group], row["avg_pm25"], row["station_count"]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// respondDailySeries export ข้อมูลรายวัน 1 ปี (timestamp ของ bucket คือเที่ยงคืนตามเวลาไทย)
+func respondDailySeries(c echo.Context, format, name string, data interface{}) error {
+	rows, _ := data.([]map[string]interface{})
+	columns := []exportColumn{{"Date", "วันที่"}, {"PM2.5", "PM2.5"}, {"PM10", "PM10"}, {"Readings", "จำนวนข้อมูล"}}
+	return respondTable(c, format, name, columns, func(write func(...interface{}) error) error {
+		for _, row := range rows {
+			ms, _ := row["timestamp"].(int64)
+			if err := write(bangkokDate(ms), row["pm25"], row["pm10"], row["count"]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"yakkaw_dashboard/models"
+	"yakkaw_dashboard/services"
+)
+
+type AlertRuleController struct {
+	Service *services.AlertRuleService
+}
+
+// NewAlertRuleController เป็น constructor สำหรับ AlertRuleController
+func NewAlertRuleController(s *services.AlertRuleService) *AlertRuleController {
+	return &AlertRuleController{Service: s}
+}
+
+// ListAlertRules (ADMIN ONLY) ดึง alert rule ทั้งหมด
+func (ac *AlertRuleController) ListAlertRules(c echo.Context) error {
+	rules, err := ac.Service.ListRules()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, rules)
+}
+
+// GetAlertRule (ADMIN ONLY) ดึง alert rule ตาม ID
+func (ac *AlertRuleController) GetAlertRule(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid alert rule id"})
+	}
+
+	rule, err := ac.Service.GetRule(uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "alert rule not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, rule)
+}
+
+// CreateAlertRule (ADMIN ONLY) เพิ่ม alert rule ใหม่
+func (ac *AlertRuleController) CreateAlertRule(c echo.Context) error {
+	rule := models.AlertRule{Enabled: true} // ไม่ส่ง enabled มา = เปิดใช้งาน, ส่ง false มาต้องบันทึกเป็น false
+	if err := c.Bind(&rule); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	created, err := ac.Service.CreateRule(rule)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, created)
+}
+
+// UpdateAlertRule (ADMIN ONLY) แก้ไข alert rule และล้าง state เดิมของ rule
+func (ac *AlertRuleController) UpdateAlertRule(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid alert rule id"})
+	}
+
+	var input models.AlertRule
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	updated, err := ac.Service.UpdateRule(uint(id), input)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "alert rule not found"})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, updated)
+}
+
+// DeleteAlertRule (ADMIN ONLY) ลบ alert rule
+func (ac *AlertRuleController) DeleteAlertRule(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid alert rule id"})
+	}
+
+	if err := ac.Service.DeleteRule(uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "alert rule not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Alert rule deleted successfully"})
+}
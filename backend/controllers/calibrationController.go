@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"yakkaw_dashboard/models"
+	"yakkaw_dashboard/services"
+)
+
+type CalibrationController struct {
+	Service *services.CalibrationService
+}
+
+// NewCalibrationController เป็น constructor สำหรับ CalibrationController
+func NewCalibrationController(s *services.CalibrationService) *CalibrationController {
+	return &CalibrationController{Service: s}
+}
+
+// ListCalibrations (ADMIN ONLY) ดึง calibration profile ทั้งหมดของอุปกรณ์
+func (cc *CalibrationController) ListCalibrations(c echo.Context) error {
+	profiles, err := cc.Service.ListProfiles(c.Param("dvid"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, profiles)
+}
+
+// CreateCalibration (ADMIN ONLY) เพิ่ม profile ใหม่ แล้วคำนวณค่าย้อนหลังใหม่ใน background
+func (cc *CalibrationController) CreateCalibration(c echo.Context) error {
+	dvid := c.Param("dvid")
+	var profile models.CalibrationProfile
+	if err := c.Bind(&profile); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	created, err := cc.Service.CreateProfile(dvid, profile)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	cc.Service.RecomputeHistoryAsync(dvid)
+	return c.JSON(http.StatusCreated, created)
+}
+
+// UpdateCalibration (ADMIN ONLY) แก้ไข profile แล้วคำนวณค่าย้อนหลังใหม่ใน background
+func (cc *CalibrationController) UpdateCalibration(c echo.Context) error {
+	dvid := c.Param("dvid")
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid calibration id"})
+	}
+
+	var input models.CalibrationProfile
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	updated, err := cc.Service.UpdateProfile(dvid, uint(id), input)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "calibration not found"})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	cc.Service.RecomputeHistoryAsync(dvid)
+	return c.JSON(http.StatusOK, updated)
+}
+
+// DeleteCalibration (ADMIN ONLY) ลบ profile แล้วคำนวณค่าย้อนหลังใหม่ใน background
+func (cc *CalibrationController) DeleteCalibration(c echo.Context) error {
+	dvid := c.Param("dvid")
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid calibration id"})
+	}
+
+	if err := cc.Service.DeleteProfile(dvid, uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "calibration not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	cc.Service.RecomputeHistoryAsync(dvid)
+	return c.JSON(http.StatusOK, map[string]string{"message": "Calibration deleted successfully"})
+}
+
+// RecomputeCalibrations (ADMIN ONLY) คำนวณค่า calibrated ย้อนหลังทั้งหมดของอุปกรณ์ใหม่ทันที
+func (cc *CalibrationController) RecomputeCalibrations(c echo.Context) error {
+	rows, err := cc.Service.RecomputeHistory(c.Param("dvid"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"message": "Calibration recomputed", "rows": rows})
+}
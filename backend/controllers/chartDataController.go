@@ -1,11 +1,15 @@
 package controllers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"yakkaw_dashboard/models"
 	"yakkaw_dashboard/services"
+	"yakkaw_dashboard/utils"
 
 	"github.com/labstack/echo/v4"
 )
@@ -28,12 +32,7 @@ func (ctl *ChartDataController) GetChartDataHandler(c echo.Context) error {
 		metric = "pm25"
 	}
 
-	chartData, err := services.GetChartData(rangeType, province, metric)
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
-	}
-
-	return c.JSON(http.StatusOK, chartData)
+	return respondChartData(c, rangeType, province, metric)
 }
 
 // GetTodayChartDataHandler ดึงข้อมูล chart ของวันนี้ (ตั้งแต่เที่ยงคืนถึงเวลาปัจจุบัน)
@@ -45,10 +44,64 @@ func (ctl *ChartDataController) GetTodayChartDataHandler(c echo.Context) error {
 		metric = "pm25"
 	}
 
-	chartData, err := services.GetChartData("Today", province, metric)
+	return respondChartData(c, "Today", province, metric)
+}
+
+// respondChartData: ?group=region คืนหนึ่ง dataset ต่อภาค (กรองด้วย ?region=) แทนรายจังหวัด
+// ?group=district คืนหนึ่ง dataset ต่ออำเภอ (กรองด้วย ?province= และ ?district=)
+// ?format=png|svg คืนเป็นภาพกราฟเส้น (ดู parseChartImageOptions)
+func respondChartData(c echo.Context, rangeType, province, metric string) error {
+	group := c.QueryParam("group")
+	fetch := func() (models.ChartData, error) {
+		switch group {
+		case "region":
+			return services.GetRegionChartData(rangeType, c.QueryParam("region"), metric, parseDataOptions(c))
+		case "district":
+			return services.GetDistrictChartData(rangeType, province, c.QueryParam("district"), metric, parseDataOptions(c))
+		}
+		return services.GetChartData(rangeType, province, metric, parseDataOptions(c))
+	}
+	subject := rangeType
+	switch group {
+	case "", "province":
+		if province != "" {
+			subject += " · " + province
+		}
+	case "region":
+		if region := c.QueryParam("region"); region != "" {
+			subject += " · " + region
+		}
+	case "district":
+		for _, area := range []string{c.QueryParam("district"), province} {
+			if area != "" {
+				subject += " · " + area
+			}
+		}
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "group must be province, region or district"})
+	}
+
+	if format := chartImageFormat(c); format != "" {
+		opts, err := parseChartImageOptions(c, format, metric, subject)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return respondChartImage(c, opts, func(opts services.ChartImageOptions) ([]byte, error) {
+			chartData, err := fetch()
+			if err != nil {
+				return nil, err
+			}
+			return services.RenderLineChartImage(chartData, opts)
+		})
+	}
+
+	chartData, err := fetch()
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
+	if format := exportFormat(c); format != "" {
+		return respondChartTable(c, format, "chart-data", exportColumn{"Hour", "ชั่วโมง"}, chartData)
+	}
 
 	return c.JSON(http.StatusOK, chartData)
 }
@@ -64,14 +117,31 @@ func (ctl *ChartDataController) GetHeatmapOneYearHandler(c echo.Context) error {
 		metric = "pm25"
 	}
 
-	chartData, err := services.GetHeatmapOneYearDaily(province, metric)
+	if format := chartImageFormat(c); format != "" {
+		opts, err := parseChartImageOptions(c, format, metric, province)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return respondChartImage(c, opts, func(opts services.ChartImageOptions) ([]byte, error) {
+			chartData, err := services.GetHeatmapOneYearDaily(province, metric, parseDataOptions(c))
+			if err != nil {
+				return nil, err
+			}
+			return services.RenderCalendarHeatmapImage(chartData, time.Now().In(time.FixedZone("Asia/Bangkok", 7*3600)), opts)
+		})
+	}
+
+	chartData, err := services.GetHeatmapOneYearDaily(province, metric, parseDataOptions(c))
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
+	if format := exportFormat(c); format != "" {
+		return respondChartTable(c, format, "heatmap-one-year", exportColumn{"Date", "วันที่"}, chartData)
+	}
 	return c.JSON(http.StatusOK, chartData)
 }
 
-// GetDailyRankingHandler returns daily ranking grouped by address/place/province
+// GetDailyRankingHandler returns daily ranking grouped by address/place/province/region/district
 func (ctl *ChartDataController) GetDailyRankingHandler(c echo.Context) error {
 	metric := c.QueryParam("metric")
 	if metric == "" {
@@ -101,10 +171,169 @@ func (ctl *ChartDataController) GetDailyRankingHandler(c echo.Context) error {
 		}
 	}
 
-	ranking, err := services.GetDailyRankingGrouped(dateStr, metric, group, limit)
+	ranking, err := services.GetDailyRankingGrouped(dateStr, metric, group, limit, parseDataOptions(c))
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
+	if format := exportFormat(c); format != "" {
+		columns := []exportColumn{
+			{"Date", "วันที่"}, {"Rank", "อันดับ"}, {"Name", "พื้นที่"}, {"Average", "ค่าเฉลี่ย"},
+			{"Readings", "จำนวนข้อมูล"}, {"Metric", "ตัวชี้วัด"}, {"Group", "จัดกลุ่มตาม"},
+		}
+		day, _ := time.Parse("2006-01-02", dateStr)
+		return respondTable(c, format, "daily-ranking", columns, func(write func(...interface{}) error) error {
+			for _, row := range ranking {
+				if err := write(utils.Date{Time: day}, row.Rank, row.Key, row.Avg, row.Count, row.Metric, row.Group); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	return c.JSON(http.StatusOK, ranking)
+}
+
+// GetRangeRankingHandler returns rankings over a date range (from/to) or rolling window (window=7d|30d)
+// together with the rank change compared to the previous window of the same length
+func (ctl *ChartDataController) GetRangeRankingHandler(c echo.Context) error {
+	metric := c.QueryParam("metric")
+	if metric == "" {
+		metric = "pm25"
+	}
+
+	group := c.QueryParam("group")
+	if group == "" {
+		group = "address"
+	}
+
+	from, to, err := parseRankingRange(c.QueryParam("from"), c.QueryParam("to"), c.QueryParam("window"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	var threshold float64
+	if ts := c.QueryParam("threshold"); ts != "" {
+		v, err := strconv.ParseFloat(ts, 64)
+		if err != nil || v <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid threshold"})
+		}
+		threshold = v
+	}
+
+	limit := 10
+	if ls := c.QueryParam("limit"); ls != "" {
+		if v, err := strconv.Atoi(ls); err == nil {
+			if v < 1 {
+				v = 1
+			}
+			if v > 100 {
+				v = 100
+			}
+			limit = v
+		}
+	}
+
+	ranking, err := services.GetRangeRanking(services.RangeRankingOptions{
+		From:        from,
+		To:          to,
+		Metric:      metric,
+		Group:       group,
+		By:          c.QueryParam("by"),
+		Order:       c.QueryParam("order"),
+		Threshold:   threshold,
+		Limit:       limit,
+		DataOptions: parseDataOptions(c),
+	})
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if format := exportFormat(c); format != "" {
+		columns := []exportColumn{
+			{"Rank", "อันดับ"}, {"Previous rank", "อันดับช่วงก่อนหน้า"}, {"Movement", "การเปลี่ยนแปลง"}, {"Name", "พื้นที่"},
+			{"Value", "ค่าที่ใช้จัดอันดับ"}, {"Average", "ค่าเฉลี่ย"}, {"Max", "ค่าสูงสุด"},
+			{"Exceedance days", "จำนวนวันเกินมาตรฐาน"}, {"Days", "จำนวนวัน"}, {"Readings", "จำนวนข้อมูล"},
+		}
+		return respondTable(c, format, "range-ranking", columns, func(write func(...interface{}) error) error {
+			for _, row := range ranking.Items {
+				var prevRank interface{}
+				if row.PrevRank != nil {
+					prevRank = *row.PrevRank
+				}
+				if err := write(row.Rank, prevRank, row.Movement, row.Key, row.Value, row.Avg, row.Max,
+					row.ExceedanceDays, row.Days, row.Count); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
 
 	return c.JSON(http.StatusOK, ranking)
 }
+
+// parseRankingRange แปลง from/to (YYYY-MM-DD, รวมวันสุดท้าย) หรือ window (เช่น 7d, 30d) เป็นช่วงเวลา [from, to)
+func parseRankingRange(fromStr, toStr, window string) (time.Time, time.Time, error) {
+	loc := time.FixedZone("Asia/Bangkok", 7*3600)
+
+	if fromStr != "" || toStr != "" {
+		if fromStr == "" || toStr == "" {
+			return time.Time{}, time.Time{}, fmt.Errorf("from and to are both required")
+		}
+		from, err := time.ParseInLocation("2006-01-02", fromStr, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid from (expect YYYY-MM-DD)")
+		}
+		to, err := time.ParseInLocation("2006-01-02", toStr, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid to (expect YYYY-MM-DD)")
+		}
+		if to.Before(from) {
+			return time.Time{}, time.Time{}, fmt.Errorf("to must not be before from")
+		}
+		if to.Sub(from) > 366*24*time.Hour {
+			return time.Time{}, time.Time{}, fmt.Errorf("range must not exceed 366 days")
+		}
+		return from, to.AddDate(0, 0, 1), nil
+	}
+
+	if window == "" {
+		window = "7d"
+	}
+	days, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(window), "d"))
+	if err != nil || days < 1 || days > 366 {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid window (expect e.g. 7d or 30d)")
+	}
+	to := time.Now().In(loc)
+	return to.AddDate(0, 0, -days), to, nil
+}
+
+// respondChartTable export ChartData เป็นตารางแบบกว้าง: คอลัมน์แรกคือ label ของแกนเวลา
+// ที่เหลือหนึ่งคอลัมน์ต่อ dataset (ชื่อคอลัมน์คือชื่อจังหวัด/ภาค/อำเภอ จึงไม่แปลตาม lang)
+func respondChartTable(c echo.Context, format, name string, axis exportColumn, chartData models.ChartData) error {
+	columns := []exportColumn{axis}
+	for _, ds := range chartData.Datasets {
+		columns = append(columns, exportColumn{ds.Label, ds.Label})
+	}
+	return respondTable(c, format, name, columns, func(write func(...interface{}) error) error {
+		for i, label := range chartData.Labels {
+			row := make([]interface{}, 0, len(columns))
+			if day, err := time.Parse("2006-01-02", label); err == nil {
+				row = append(row, utils.Date{Time: day})
+			} else {
+				row = append(row, label)
+			}
+			for _, ds := range chartData.Datasets {
+				var v interface{}
+				if i < len(ds.Data) {
+					v = ds.Data[i]
+				}
+				row = append(row, v)
+			}
+			if err := write(row...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
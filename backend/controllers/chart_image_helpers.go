@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"yakkaw_dashboard/services"
+)
+
+// chartImageMaxAge คือเวลาที่ให้ client/CDN cache ภาพกราฟ (วินาที ตามรอบ ingest 5 นาที)
+const chartImageMaxAge = 300
+
+// chartImageFormat คืน "png" หรือ "svg" เมื่อ client ขอภาพผ่าน ?format= (กรณีอื่นคืน "")
+func chartImageFormat(c echo.Context) string {
+	switch format := strings.ToLower(c.QueryParam("format")); format {
+	case "png", "svg":
+		return format
+	}
+	return ""
+}
+
+// parseChartImageOptions อ่าน ?width= &height= (200–2000) &title= &lang=th|en
+func parseChartImageOptions(c echo.Context, format, metric, subject string) (services.ChartImageOptions, error) {
+	opts := services.ChartImageOptions{
+		Format:  format,
+		Width:   services.ChartImageDefaultWidth,
+		Height:  services.ChartImageDefaultHeight,
+		Title:   strings.TrimSpace(c.QueryParam("title")),
+		Subject: subject,
+		Metric:  metric,
+		Lang:    "en",
+	}
+	if strings.EqualFold(c.QueryParam("lang"), "th") {
+		opts.Lang = "th"
+	}
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{{"width", &opts.Width}, {"height", &opts.Height}} {
+		raw := c.QueryParam(p.name)
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < services.ChartImageMinSize || v > services.ChartImageMaxSize {
+			return opts, fmt.Errorf("%s must be between %d and %d", p.name, services.ChartImageMinSize, services.ChartImageMaxSize)
+		}
+		*p.dst = v
+	}
+	return opts, nil
+}
+
+// respondChartImage ส่งภาพกราฟ โดย cache ตาม path + query ทั้งหมด (ล้างเมื่อ ingest รอบใหม่เสร็จ)
+// render ถูกเรียกเฉพาะเมื่อไม่มีใน cache และได้ opts ที่เติมช่วงสีจาก ColorRange แล้ว
+func respondChartImage(c echo.Context, opts services.ChartImageOptions, render func(opts services.ChartImageOptions) ([]byte, error)) error {
+	key := c.Request().URL.Path + "?" + c.QueryParams().Encode()
+	img, err := services.CachedChartImage(key, func() ([]byte, error) {
+		bands, err := services.GetAllColorRanges()
+		if err != nil {
+			return nil, err
+		}
+		opts.Bands = bands
+		return render(opts)
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrChartImageTooSmall) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	contentType := "image/png"
+	if opts.Format == "svg" {
+		contentType = "image/svg+xml; charset=utf-8"
+	}
+	c.Response().Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", chartImageMaxAge))
+	return c.Blob(http.StatusOK, contentType, img)
+}
@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"yakkaw_dashboard/services"
+)
+
+// GetDeviceAlerts (ADMIN ONLY) ดึง alert ของอุปกรณ์ที่ขาดการส่งข้อมูล กรองด้วย status=open|resolved ได้
+func GetDeviceAlerts(c echo.Context) error {
+	alerts, err := services.ListDeviceAlerts(c.QueryParam("status"), "")
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, alerts)
+}
+
+// GetDeviceAlertHistory (ADMIN ONLY) ดึงประวัติ alert ทั้งหมดของอุปกรณ์หนึ่งตัว
+func GetDeviceAlertHistory(c echo.Context) error {
+	alerts, err := services.ListDeviceAlerts(c.QueryParam("status"), c.Param("dvid"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, alerts)
+}
@@ -9,9 +9,28 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// adminDevice คือ Device ที่มี contact_email ซึ่ง route สาธารณะไม่ส่งออก ใช้กับ route ของ admin เท่านั้น
+type adminDevice struct {
+	models.Device
+	ContactEmail string `json:"contact_email"`
+}
+
+func newAdminDevice(device models.Device) adminDevice {
+	return adminDevice{Device: device, ContactEmail: device.ContactEmail}
+}
+
+func bindAdminDevice(c echo.Context) (models.Device, error) {
+	var body adminDevice
+	if err := c.Bind(&body); err != nil {
+		return models.Device{}, err
+	}
+	body.Device.ContactEmail = body.ContactEmail
+	return body.Device, nil
+}
+
 func CreateDevice(c echo.Context) error {
-	var device models.Device
-	if err := c.Bind(&device); err != nil {
+	device, err := bindAdminDevice(c)
+	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
@@ -20,7 +39,7 @@ func CreateDevice(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
-	return c.JSON(http.StatusOK, createdDevice)
+	return c.JSON(http.StatusOK, newAdminDevice(createdDevice))
 }
 
 func GetDevice(c echo.Context) error {
@@ -42,10 +61,57 @@ func GetAllDevices(c echo.Context) error {
 	return c.JSON(http.StatusOK, devices)
 }
 
+// GetAllDevicesAdmin คืนอุปกรณ์ทั้งหมดพร้อม contact_email สำหรับหน้า admin
+func GetAllDevicesAdmin(c echo.Context) error {
+	devices, err := services.GetAllDevices()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	views := make([]adminDevice, 0, len(devices))
+	for _, d := range devices {
+		views = append(views, newAdminDevice(d))
+	}
+	return c.JSON(http.StatusOK, views)
+}
+
+// GetDevicesHealth คืน last seen, reporting interval, completeness และสถานะของทุก DVID ใน sensor_data
+func GetDevicesHealth(c echo.Context) error {
+	health, err := services.GetDevicesHealth()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	if status := c.QueryParam("status"); status != "" {
+		filtered := []services.DeviceHealth{}
+		for _, h := range health {
+			if h.Status == status {
+				filtered = append(filtered, h)
+			}
+		}
+		health = filtered
+	}
+
+	return c.JSON(http.StatusOK, health)
+}
+
+// GetDeviceHealth คืนสถานะของอุปกรณ์เดียวพร้อม completeness รายวันและรายการ gap
+func GetDeviceHealth(c echo.Context) error {
+	health, err := services.GetDeviceHealth(c.Param("dvid"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if health == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Device not found"})
+	}
+
+	return c.JSON(http.StatusOK, health)
+}
+
 func UpdateDevice(c echo.Context) error {
 	dvid := c.Param("dvid")
-	var device models.Device
-	if err := c.Bind(&device); err != nil {
+	device, err := bindAdminDevice(c)
+	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
@@ -54,7 +120,7 @@ func UpdateDevice(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
-	return c.JSON(http.StatusOK, updatedDevice)
+	return c.JSON(http.StatusOK, newAdminDevice(updatedDevice))
 }
 
 func DeleteDevice(c echo.Context) error {
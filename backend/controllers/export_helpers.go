@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"yakkaw_dashboard/utils"
+)
+
+// XLSXContentType คือ media type ของไฟล์ Excel (Office Open XML)
+const XLSXContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// exportColumn คือหัวคอลัมน์ของไฟล์ export ภาษาอังกฤษ (ค่าเริ่มต้น) และภาษาไทย (?lang=th)
+type exportColumn struct {
+	EN string
+	TH string
+}
+
+// exportFormat คืน "csv" หรือ "xlsx" เมื่อ client ขอไฟล์ผ่าน ?format= (กรณีอื่นคืน "")
+func exportFormat(c echo.Context) string {
+	switch format := strings.ToLower(c.QueryParam("format")); format {
+	case "csv", "xlsx":
+		return format
+	}
+	return ""
+}
+
+// respondTable ส่งผลเป็นไฟล์ CSV/XLSX แบบ stream โดย fill เรียก write ทีละแถว
+// header ของ response ถูกส่งตอนเขียนแถวแรก error ก่อนหน้านั้นจึงยังตอบเป็น JSON ได้
+// ส่วน error หลังจากนั้นจะตัดไฟล์กลางทาง (ไม่ปิดไฟล์ให้สมบูรณ์) เพื่อไม่ให้ได้ไฟล์ที่ดูครบแต่ข้อมูลขาด
+func respondTable(c echo.Context, format, name string, columns []exportColumn, fill func(write func(values ...interface{}) error) error) error {
+	var table utils.TableWriter
+	start := func() error {
+		res := c.Response()
+		filename := fmt.Sprintf("%s-%s.%s", name, time.Now().In(time.FixedZone("Asia/Bangkok", 7*3600)).Format("20060102"), format)
+		res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+		var err error
+		if format == "xlsx" {
+			res.Header().Set(echo.HeaderContentType, XLSXContentType)
+			res.WriteHeader(http.StatusOK)
+			table, err = utils.NewXLSXTableWriter(res, name)
+		} else {
+			res.Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+			res.WriteHeader(http.StatusOK)
+			table, err = utils.NewCSVTableWriter(res)
+		}
+		if err != nil {
+			return err
+		}
+
+		thai := strings.EqualFold(c.QueryParam("lang"), "th")
+		header := make([]interface{}, len(columns))
+		for i, col := range columns {
+			header[i] = col.EN
+			if thai {
+				header[i] = col.TH
+			}
+		}
+		return table.WriteRow(header...)
+	}
+	write := func(values ...interface{}) error {
+		if table == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		return table.WriteRow(values...)
+	}
+
+	err := fill(write)
+	if err != nil && !c.Response().Committed {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if err == nil && table == nil {
+		// ไม่มีข้อมูลก็ยังส่งไฟล์ที่มีแต่หัวตาราง
+		err = start()
+	}
+	if err == nil {
+		err = table.Close()
+	}
+	if err != nil {
+		log.Printf("Error exporting %s as %s: %v", name, format, err)
+	}
+	return nil
+}
+
+// bangkokDate แปลง timestamp (มิลลิวินาที) ของ bucket รายวันที่ตัดตามเวลาไทยไว้แล้วเป็นวันที่
+func bangkokDate(ms int64) utils.Date {
+	return utils.Date{Time: time.UnixMilli(ms).UTC()}
+}
+
+// bangkokTime แปลง timestamp (มิลลิวินาที) ของ sensor_data เป็นเวลาไทย
+func bangkokTime(ms int64) time.Time {
+	return time.UnixMilli(ms).In(time.FixedZone("Asia/Bangkok", 7*3600))
+}
@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"yakkaw_dashboard/services"
+)
+
+type ForecastController struct{}
+
+func NewForecastController() *ForecastController {
+	return &ForecastController{}
+}
+
+// GetForecastHandler คืนผลพยากรณ์ PM2.5 รายชั่วโมง 24–72 ชั่วโมงข้างหน้าของจังหวัดพร้อมคะแนน backtest
+func (ctl *ForecastController) GetForecastHandler(c echo.Context) error {
+	province := strings.TrimSpace(c.QueryParam("province"))
+	if province == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "province is required"})
+	}
+
+	hours := 72
+	if hs := c.QueryParam("hours"); hs != "" {
+		v, err := strconv.Atoi(hs)
+		if err != nil || v < 1 || v > 72 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "hours must be between 1 and 72"})
+		}
+		hours = v
+	}
+
+	data, err := services.GetLatestForecast(province, hours)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "no forecast available for this province"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, data)
+}
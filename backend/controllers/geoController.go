@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"yakkaw_dashboard/services"
+)
+
+const (
+	maxNearestLimit = 50
+	maxRadiusKm     = 500
+)
+
+// parseLatLon อ่าน ?lat= &lon= (จำเป็น)
+func parseLatLon(c echo.Context) (float64, float64, error) {
+	lat, errLat := strconv.ParseFloat(c.QueryParam("lat"), 64)
+	lon, errLon := strconv.ParseFloat(c.QueryParam("lon"), 64)
+	if errLat != nil || errLon != nil || !services.ValidCoordinate(lat, lon) {
+		return 0, 0, errors.New("lat and lon are required and must be valid coordinates")
+	}
+	return lat, lon, nil
+}
+
+// parsePositiveFloat อ่าน query parameter ที่เป็นตัวเลขบวก (ไม่ระบุ = fallback)
+func parsePositiveFloat(c echo.Context, name string, fallback, max float64) (float64, error) {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return fallback, nil
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil || v <= 0 || v > max {
+		return 0, errors.New(name + " must be a positive number no greater than " + strconv.FormatFloat(max, 'f', -1, 64))
+	}
+	return v, nil
+}
+
+// parsePositiveInt อ่าน query parameter ที่เป็นจำนวนเต็มตั้งแต่ 1 ถึง max (ไม่ระบุ = fallback)
+func parsePositiveInt(c echo.Context, name string, fallback, max int) (int, error) {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return fallback, nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil || v < 1 || v > max {
+		return 0, errors.New(name + " must be an integer between 1 and " + strconv.Itoa(max))
+	}
+	return v, nil
+}
+
+func parseMaxAge(c echo.Context) (time.Duration, error) {
+	raw := c.QueryParam("max_age")
+	if raw == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return 0, errors.New("max_age must be a positive duration such as 30m or 2h")
+	}
+	return d, nil
+}
+
+// GetNearestStations คืน N สถานีที่ใกล้พิกัดที่สุดพร้อมระยะทาง
+// ?lat= &lon= &limit=5 &max_distance_km= &max_age=
+func GetNearestStations(c echo.Context) error {
+	lat, lon, err := parseLatLon(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	limit, err := parsePositiveInt(c, "limit", 5, maxNearestLimit)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	maxDistance, err := parsePositiveFloat(c, "max_distance_km", 0, maxRadiusKm)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	maxAge, err := parseMaxAge(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	stations, err := services.GetNearestStations(lat, lon, limit, maxDistance, maxAge)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if wantsGeoJSON(c) {
+		return respondGeoJSON(c, services.StationDistancesGeoJSON(stations))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"latitude":  lat,
+		"longitude": lon,
+		"data":      stations,
+	})
+}
+
+// GetStationsWithin คืนสถานีภายในรัศมี (?lat= &lon= &radius_km=) หรือภายในกรอบ (?bbox=minLon,minLat,maxLon,maxLat)
+func GetStationsWithin(c echo.Context) error {
+	maxAge, err := parseMaxAge(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if c.QueryParam("bbox") != "" {
+		filter, err := parseStationFilter(c)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		stations, _, err := services.GetLatestStations(filter)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		if wantsGeoJSON(c) {
+			return respondGeoJSON(c, services.StationsGeoJSON(stations))
+		}
+		return c.JSON(http.StatusOK, map[string]interface{}{"bbox": filter.BBox, "count": len(stations), "data": stations})
+	}
+
+	lat, lon, err := parseLatLon(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "either bbox or lat, lon and radius_km are required"})
+	}
+	radius, err := parsePositiveFloat(c, "radius_km", 10, maxRadiusKm)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	stations, err := services.GetStationsWithinRadius(lat, lon, radius, maxAge)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if wantsGeoJSON(c) {
+		return respondGeoJSON(c, services.StationDistancesGeoJSON(stations))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"latitude":  lat,
+		"longitude": lon,
+		"radius_km": radius,
+		"count":     len(stations),
+		"data":      stations,
+	})
+}
+
+// GetAirQualityAtPoint ประมาณคุณภาพอากาศ ณ พิกัดด้วย inverse-distance weighting
+// ?lat= &lon= &power=2 &max_distance_km=50 &max_stations=8
+func GetAirQualityAtPoint(c echo.Context) error {
+	lat, lon, err := parseLatLon(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	power, err := parsePositiveFloat(c, "power", 0, 5)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	maxDistance, err := parsePositiveFloat(c, "max_distance_km", 0, maxRadiusKm)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	maxStations, err := parsePositiveInt(c, "max_stations", 0, maxNearestLimit)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	estimate, err := services.EstimateAtPoint(lat, lon, services.IDWOptions{
+		Power:         power,
+		MaxDistanceKm: maxDistance,
+		MaxStations:   maxStations,
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrNoNearbyStations) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, estimate)
+}
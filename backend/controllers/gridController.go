@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"yakkaw_dashboard/services"
+)
+
+// GetPM25Grid คืน grid PM2.5 ที่ interpolate ด้วย IDW
+// ?bbox=minLon,minLat,maxLon,maxLat &resolution=0.05 &source=current|average &hours=24 &power=2 &max_distance_km=50
+// &format=json|geojson|png (geojson = เส้น contour ตาม ColorRange, png = ภาพสีตาม ColorRange; &scale=1..8)
+func GetPM25Grid(c echo.Context) error {
+	if method := c.QueryParam("method"); method != "" && method != "idw" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "only method=idw is supported"})
+	}
+
+	opts, err := parseGridOptions(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	grid, err := services.BuildPM25Grid(opts)
+	if err != nil {
+		if errors.Is(err, services.ErrNoNearbyStations) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	format := strings.ToLower(c.QueryParam("format"))
+	if format == "" && wantsGeoJSON(c) {
+		format = "geojson"
+	}
+	switch format {
+	case "", "json":
+		return c.JSON(http.StatusOK, grid)
+	case "geojson", "png":
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "format must be json, geojson or png"})
+	}
+
+	bands, err := services.GetAllColorRanges()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if format == "geojson" {
+		return respondGeoJSON(c, grid.ContoursGeoJSON(bands))
+	}
+
+	scale := 1
+	if raw := c.QueryParam("scale"); raw != "" {
+		if scale, err = strconv.Atoi(raw); err != nil || scale < 1 || scale > 8 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "scale must be between 1 and 8"})
+		}
+	}
+	img, err := grid.PNG(bands, scale)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.Blob(http.StatusOK, "image/png", img)
+}
+
+func parseGridOptions(c echo.Context) (services.GridOptions, error) {
+	opts := services.GridOptions{Source: strings.ToLower(c.QueryParam("source"))}
+
+	if c.QueryParam("bbox") != "" {
+		filter, err := parseStationFilter(c)
+		if err != nil {
+			return opts, err
+		}
+		opts.BBox = filter.BBox
+	}
+
+	var err error
+	if opts.Resolution, err = parsePositiveFloat(c, "resolution", 0, 1); err != nil {
+		return opts, err
+	}
+	if opts.Power, err = parsePositiveFloat(c, "power", 0, 5); err != nil {
+		return opts, err
+	}
+	if opts.MaxDistanceKm, err = parsePositiveFloat(c, "max_distance_km", 0, maxRadiusKm); err != nil {
+		return opts, err
+	}
+	if opts.Hours, err = parsePositiveInt(c, "hours", 0, 24*7); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"yakkaw_dashboard/services"
+)
+
+// LocateCoordinates คืนจังหวัด/อำเภอ/ภาคของพิกัด (?lat= &lon=) จากชุดขอบเขตการปกครองแบบ offline
+func LocateCoordinates(c echo.Context) error {
+	lat, lon, err := parseLatLon(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	loc, ok := services.LocateByCoordinates(lat, lon)
+	if !ok {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "coordinates are outside the known administrative boundaries"})
+	}
+	return c.JSON(http.StatusOK, loc)
+}
+
+// RebuildStationLocations โหลดชุดขอบเขตใหม่แล้วคำนวณ province/district/region ของอุปกรณ์และข้อมูลย้อนหลังทั้งหมด
+func RebuildStationLocations(c echo.Context) error {
+	if err := services.ReloadAdminBoundaries(); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	updated, err := services.RebuildStationLocations(true)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"updated_rows": updated})
+}
@@ -0,0 +1,24 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"yakkaw_dashboard/config"
+	"yakkaw_dashboard/services"
+	"yakkaw_dashboard/utils"
+)
+
+// GetMetrics ส่ง metric ของระบบและค่าล่าสุดของแต่ละสถานีในรูปแบบ Prometheus
+// ถ้าตั้ง METRICS_TOKEN ต้องส่ง Authorization: Bearer <token>
+func GetMetrics(c echo.Context) error {
+	if token := config.MetricsToken(); token != "" {
+		got := c.Request().Header.Get(echo.HeaderAuthorization)
+		if subtle.ConstantTimeCompare([]byte(got), []byte("Bearer "+token)) != 1 {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid metrics token"})
+		}
+	}
+	return c.Blob(http.StatusOK, utils.MetricsContentType, services.GatherMetrics())
+}
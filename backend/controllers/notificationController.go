@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"yakkaw_dashboard/database"
 	"yakkaw_dashboard/models"
+	"yakkaw_dashboard/services"
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
 	"fmt"
@@ -22,11 +23,7 @@ func CreateNotification(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
 	}
 
-	if notification.Icon == "" {
-		notification.Icon = "default-icon-url" 
-	}
-
-	if err := database.DB.Create(&notification).Error; err != nil {
+	if err := services.PublishNotification(&notification); err != nil {
 		c.Logger().Error(err) 
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save notification"})
 	}
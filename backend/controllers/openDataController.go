@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"yakkaw_dashboard/services"
+)
+
+// SensorThingsBasePath คือ path ของ service root ของ SensorThings API
+const SensorThingsBasePath = "/api/sta/v1.1"
+
+// GetSensorThings ตอบทุก path ใต้ /api/sta/v1.1 เช่น /Things, /Datastreams(12)/Observations
+// query option: $top $skip $count $expand และ $filter/$orderby ตาม phenomenonTime บน Observations
+func GetSensorThings(c echo.Context) error {
+	resourcePath, err := url.PathUnescape(c.Param("*"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid path"})
+	}
+	base := c.Scheme() + "://" + c.Request().Host + SensorThingsBasePath
+	result, err := services.SensorThings(base, resourcePath, c.QueryParams())
+	if err != nil {
+		return respondOpenDataError(c, err)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// GetOpenAQParameters คืน parameter ที่มีให้บริการ
+func GetOpenAQParameters(c echo.Context) error {
+	params := services.OpenAQParameters()
+	return c.JSON(http.StatusOK, services.NewOpenAQResponse(params, 1, len(params), int64(len(params))))
+}
+
+// GetOpenAQLocations คืนรายการ location ?province= &page= &limit= (ค่าเริ่มต้น 100 สูงสุด 1000)
+func GetOpenAQLocations(c echo.Context) error {
+	page, limit, err := parseOpenAQPage(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	locations, found, err := services.ListOpenAQLocations(c.QueryParam("province"), page, limit)
+	if err != nil {
+		return respondOpenDataError(c, err)
+	}
+	return c.JSON(http.StatusOK, services.NewOpenAQResponse(locations, page, limit, found))
+}
+
+// GetOpenAQLocation คืน location เดียว
+func GetOpenAQLocation(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	location, err := services.GetOpenAQLocation(uint(id))
+	if err != nil {
+		return respondOpenDataError(c, err)
+	}
+	return c.JSON(http.StatusOK, services.NewOpenAQResponse([]services.OpenAQLocation{location}, 1, 1, 1))
+}
+
+// GetOpenAQLocationSensors คืน sensor ทั้งหมดของ location
+func GetOpenAQLocationSensors(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	location, err := services.GetOpenAQLocation(uint(id))
+	if err != nil {
+		return respondOpenDataError(c, err)
+	}
+	n := len(location.Sensors)
+	return c.JSON(http.StatusOK, services.NewOpenAQResponse(location.Sensors, 1, n, int64(n)))
+}
+
+// GetOpenAQLocationLatest คืนค่าล่าสุดของทุก sensor ของ location
+func GetOpenAQLocationLatest(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	latest, err := services.GetOpenAQLocationLatest(uint(id))
+	if err != nil {
+		return respondOpenDataError(c, err)
+	}
+	return c.JSON(http.StatusOK, services.NewOpenAQResponse(latest, 1, len(latest), int64(len(latest))))
+}
+
+// GetOpenAQSensor คืน sensor เดียวพร้อมค่าล่าสุด
+func GetOpenAQSensor(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	sensor, err := services.GetOpenAQSensor(id)
+	if err != nil {
+		return respondOpenDataError(c, err)
+	}
+	return c.JSON(http.StatusOK, services.NewOpenAQResponse([]services.OpenAQSensor{sensor}, 1, 1, 1))
+}
+
+// GetOpenAQMeasurements คืนค่าที่วัดของ sensor ?datetime_from=&datetime_to= (YYYY-MM-DD หรือ RFC3339,
+// ค่าเริ่มต้น 7 วันล่าสุด) &page= &limit=
+func GetOpenAQMeasurements(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	page, limit, err := parseOpenAQPage(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	// OFFSET ลึกต้องอ่านแถวที่ข้ามทั้งหมด ให้แคบช่วงเวลาลงแทน
+	if page-1 > services.OpenDataMaxOffset/limit {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("page is too deep (more than %d measurements skipped), narrow datetime_from/datetime_to instead", services.OpenDataMaxOffset),
+		})
+	}
+	from, to, err := parseReadingRange(c.QueryParam("datetime_from"), c.QueryParam("datetime_to"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	measurements, found, err := services.ListOpenAQMeasurements(id, from, to, page, limit)
+	if err != nil {
+		return respondOpenDataError(c, err)
+	}
+	return c.JSON(http.StatusOK, services.NewOpenAQResponse(measurements, page, limit, found))
+}
+
+func parseOpenAQPage(c echo.Context) (int, int, error) {
+	page, limit := 1, services.OpenAQDefaultLimit
+	if raw := c.QueryParam("page"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 1 {
+			return 0, 0, fmt.Errorf("page must be a positive integer")
+		}
+		page = v
+	}
+	if raw := c.QueryParam("limit"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 1 || v > services.OpenAQMaxLimit {
+			return 0, 0, fmt.Errorf("limit must be between 1 and %d", services.OpenAQMaxLimit)
+		}
+		limit = v
+	}
+	return page, limit, nil
+}
+
+func respondOpenDataError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, services.ErrOpenDataNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrSTAInvalidRequest):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,175 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"yakkaw_dashboard/models"
+	"yakkaw_dashboard/services"
+)
+
+type ProvinceController struct {
+	Service *services.ProvinceService
+}
+
+// NewProvinceController เป็น constructor สำหรับ ProvinceController
+func NewProvinceController(s *services.ProvinceService) *ProvinceController {
+	return &ProvinceController{Service: s}
+}
+
+type aliasInput struct {
+	Alias string `json:"alias"`
+}
+
+type provinceRegionInput struct {
+	RegionID *uint `json:"region_id"`
+}
+
+// ListProvinces คืนจังหวัดทั้งหมดพร้อมภาคและชื่อเรียกอื่น
+func (pc *ProvinceController) ListProvinces(c echo.Context) error {
+	provinces, err := pc.Service.ListProvinces()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, provinces)
+}
+
+// ListRegions คืนภาคทั้งหมด
+func (pc *ProvinceController) ListRegions(c echo.Context) error {
+	regions, err := pc.Service.ListRegions()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, regions)
+}
+
+// CreateAlias (ADMIN ONLY) เพิ่มชื่อเรียกอื่นให้จังหวัด แล้ว resolve province ของข้อมูลเดิมใหม่ใน background
+func (pc *ProvinceController) CreateAlias(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid province id"})
+	}
+	var input aliasInput
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	alias, err := pc.Service.CreateAlias(uint(id), input.Alias)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "province not found"})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, alias)
+}
+
+// UpdateAlias (ADMIN ONLY) แก้ไขชื่อเรียกอื่น
+func (pc *ProvinceController) UpdateAlias(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid alias id"})
+	}
+	var input aliasInput
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	alias, err := pc.Service.UpdateAlias(uint(id), input.Alias)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "alias not found"})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, alias)
+}
+
+// DeleteAlias (ADMIN ONLY) ลบชื่อเรียกอื่น
+func (pc *ProvinceController) DeleteAlias(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid alias id"})
+	}
+	if err := pc.Service.DeleteAlias(uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "alias not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Alias deleted successfully"})
+}
+
+// CreateRegion (ADMIN ONLY) เพิ่มภาค/กลุ่มพื้นที่ใหม่ (เช่นกลุ่มข้ามพรมแดน)
+func (pc *ProvinceController) CreateRegion(c echo.Context) error {
+	var input models.Region
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	region, err := pc.Service.CreateRegion(input)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, region)
+}
+
+// UpdateRegion (ADMIN ONLY) แก้ไขชื่อภาค
+func (pc *ProvinceController) UpdateRegion(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid region id"})
+	}
+	var input models.Region
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	region, err := pc.Service.UpdateRegion(uint(id), input)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "region not found"})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, region)
+}
+
+// DeleteRegion (ADMIN ONLY) ลบภาค จังหวัดในภาคนั้นจะไม่มีภาค
+func (pc *ProvinceController) DeleteRegion(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid region id"})
+	}
+	if err := pc.Service.DeleteRegion(uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "region not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Region deleted successfully"})
+}
+
+// SetProvinceRegion (ADMIN ONLY) ย้ายจังหวัดไปอยู่ในภาคอื่น ({"region_id": null} = ไม่มีภาค)
+func (pc *ProvinceController) SetProvinceRegion(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid province id"})
+	}
+	var input provinceRegionInput
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	province, err := pc.Service.SetProvinceRegion(uint(id), input.RegionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "province not found"})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, province)
+}
@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"yakkaw_dashboard/models"
+	"yakkaw_dashboard/services"
+)
+
+type PushController struct {
+	Service *services.PushService
+}
+
+// NewPushController เป็น constructor สำหรับ PushController
+func NewPushController(s *services.PushService) *PushController {
+	return &PushController{Service: s}
+}
+
+// pushSubscriptionRequest ตรงกับผลของ PushSubscription.toJSON() ใน browser
+type pushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+// GetVAPIDPublicKey คืน applicationServerKey สำหรับ pushManager.subscribe
+func (pc *PushController) GetVAPIDPublicKey(c echo.Context) error {
+	key, err := pc.Service.PublicKey()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"public_key": key})
+}
+
+// RegisterPushSubscription บันทึก push subscription ของ browser
+func (pc *PushController) RegisterPushSubscription(c echo.Context) error {
+	var req pushSubscriptionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	sub, err := pc.Service.RegisterSubscription(models.PushSubscription{
+		Endpoint:  req.Endpoint,
+		P256dh:    req.Keys.P256dh,
+		Auth:      req.Keys.Auth,
+		UserAgent: c.Request().UserAgent(),
+	})
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, sub)
+}
+
+// DeletePushSubscription ลบ push subscription ตาม endpoint
+func (pc *PushController) DeletePushSubscription(c echo.Context) error {
+	var req pushSubscriptionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := pc.Service.DeleteSubscription(req.Endpoint); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "push subscription not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Push subscription deleted successfully"})
+}
+
+// RotateVAPIDKeys (ADMIN ONLY) สร้าง VAPID key ใหม่และล้าง subscription เดิมทั้งหมด
+func (pc *PushController) RotateVAPIDKeys(c echo.Context) error {
+	key, err := pc.Service.RotateVAPIDKeys()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"public_key": key})
+}
@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"yakkaw_dashboard/services"
+)
+
+// parseDataOptions อ่าน query parameter ที่ใช้ร่วมกันของ endpoint แบบ aggregate
+// include_flagged=true จะรวมข้อมูลที่ถูก flag ว่าผิดปกติ (ค่าเริ่มต้นคือตัดออก)
+// raw=true จะใช้ค่าดิบจากเซนเซอร์แทนค่าที่ calibrate แล้ว
+func parseDataOptions(c echo.Context) services.DataOptions {
+	includeFlagged, _ := strconv.ParseBool(c.QueryParam("include_flagged"))
+	raw, _ := strconv.ParseBool(c.QueryParam("raw"))
+	return services.DataOptions{IncludeFlagged: includeFlagged, Raw: raw}
+}
+
+// GeoJSONContentType คือ media type ของ GeoJSON (RFC 7946)
+const GeoJSONContentType = "application/geo+json"
+
+// wantsGeoJSON ตรวจว่า client ขอผลแบบ GeoJSON ผ่าน ?format=geojson หรือ header Accept
+func wantsGeoJSON(c echo.Context) bool {
+	if strings.EqualFold(c.QueryParam("format"), "geojson") {
+		return true
+	}
+	return strings.Contains(c.Request().Header.Get(echo.HeaderAccept), GeoJSONContentType)
+}
+
+// respondGeoJSON ส่ง FeatureCollection พร้อม Content-Type application/geo+json
+func respondGeoJSON(c echo.Context, fc services.GeoJSONFeatureCollection) error {
+	body, err := json.Marshal(fc)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.Blob(http.StatusOK, GeoJSONContentType, body)
+}
@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"yakkaw_dashboard/services"
+)
+
+// NDJSONContentType คือ media type ของ newline-delimited JSON
+const NDJSONContentType = "application/x-ndjson"
+
+// GetRawReadings คืนข้อมูลดิบจาก sensor_data แบบแบ่งหน้าด้วย cursor
+// filter: ?dvid=a,b &province= &from=&to= (YYYY-MM-DD รวมวันสุดท้าย หรือ RFC3339, ค่าเริ่มต้น 7 วันล่าสุด)
+// &metrics=pm25,aqi &limit= (ค่าเริ่มต้น 1000 สูงสุด 10000) &cursor= (จาก next_cursor ของหน้าก่อน)
+// ?format=ndjson (หรือ Accept: application/x-ndjson) จะ stream ทุกแถวในครั้งเดียว ส่วน csv/xlsx ได้ไฟล์
+func GetRawReadings(c echo.Context) error {
+	q, err := parseRawReadingQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if strings.EqualFold(c.QueryParam("format"), "ndjson") ||
+		strings.Contains(c.Request().Header.Get(echo.HeaderAccept), NDJSONContentType) {
+		return streamRawReadingsNDJSON(c, q)
+	}
+	if format := exportFormat(c); format != "" {
+		return respondRawReadingsTable(c, format, q)
+	}
+
+	readings, next, err := services.ListRawReadings(q)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	response := map[string]interface{}{
+		"data":     readings,
+		"has_more": next != nil,
+	}
+	if next != nil {
+		response["next_cursor"] = next.Encode()
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+func parseRawReadingQuery(c echo.Context) (services.RawReadingQuery, error) {
+	q := services.RawReadingQuery{
+		Province:    strings.TrimSpace(c.QueryParam("province")),
+		DataOptions: parseDataOptions(c),
+	}
+	for _, dvid := range strings.Split(c.QueryParam("dvid"), ",") {
+		if dvid = strings.TrimSpace(dvid); dvid != "" {
+			q.DVIDs = append(q.DVIDs, dvid)
+		}
+	}
+
+	var err error
+	if q.Metrics, err = services.ParseRawReadingMetrics(c.QueryParam("metrics")); err != nil {
+		return q, err
+	}
+	if q.From, q.To, err = parseReadingRange(c.QueryParam("from"), c.QueryParam("to")); err != nil {
+		return q, err
+	}
+
+	if ls := c.QueryParam("limit"); ls != "" {
+		v, err := strconv.Atoi(ls)
+		if err != nil || v < 1 {
+			return q, fmt.Errorf("invalid limit")
+		}
+		q.Limit = v
+	}
+	if cursor := c.QueryParam("cursor"); cursor != "" {
+		if q.After, err = services.DecodeRawReadingCursor(cursor); err != nil {
+			return q, err
+		}
+	}
+	return q, nil
+}
+
+// parseReadingRange: from/to เป็น YYYY-MM-DD (เวลาไทย, to รวมทั้งวัน) หรือ RFC3339 (to ไม่รวม)
+func parseReadingRange(fromStr, toStr string) (time.Time, time.Time, error) {
+	loc := time.FixedZone("Asia/Bangkok", 7*3600)
+	parse := func(s string, end bool) (time.Time, error) {
+		if t, err := time.ParseInLocation("2006-01-02", s, loc); err == nil {
+			if end {
+				t = t.AddDate(0, 0, 1)
+			}
+			return t, nil
+		}
+		return time.Parse(time.RFC3339, s)
+	}
+
+	to := time.Now()
+	if toStr != "" {
+		t, err := parse(toStr, true)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid to (expect YYYY-MM-DD or RFC3339)")
+		}
+		to = t
+	}
+	from := to.AddDate(0, 0, -7)
+	if fromStr != "" {
+		t, err := parse(fromStr, false)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid from (expect YYYY-MM-DD or RFC3339)")
+		}
+		from = t
+	}
+	if !to.After(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid range: from must be before to")
+	}
+	return from, to, nil
+}
+
+// streamRawReadingsNDJSON ส่งหนึ่งบรรทัดต่อหนึ่งแถว ไม่จำกัดจำนวนเว้นแต่ส่ง ?limit= มา
+func streamRawReadingsNDJSON(c echo.Context, q services.RawReadingQuery) error {
+	res := c.Response()
+	start := func() {
+		if !res.Committed {
+			res.Header().Set(echo.HeaderContentType, NDJSONContentType)
+			res.Header().Set("X-Accel-Buffering", "no")
+			res.WriteHeader(http.StatusOK)
+		}
+	}
+
+	enc := json.NewEncoder(res)
+	ctx := c.Request().Context()
+	err := services.StreamRawReadings(q, func(r services.RawReading) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		start()
+		return enc.Encode(r)
+	}, func() {
+		if res.Committed {
+			res.Flush()
+		}
+	})
+	if err != nil && !res.Committed {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if err != nil && ctx.Err() == nil {
+		log.Printf("Error streaming raw readings: %v", err)
+	}
+	start()
+	return nil
+}
+
+func respondRawReadingsTable(c echo.Context, format string, q services.RawReadingQuery) error {
+	columns := []exportColumn{
+		{"Time", "เวลา"}, {"Device ID", "รหัสอุปกรณ์"}, {"Place", "สถานที่"}, {"Address", "ที่อยู่"},
+		{"Province", "จังหวัด"}, {"District", "อำเภอ"}, {"Region", "ภาค"},
+		{"Latitude", "ละติจูด"}, {"Longitude", "ลองจิจูด"}, {"Quality flags", "สถานะคุณภาพข้อมูล"},
+	}
+	for _, m := range q.Metrics {
+		columns = append(columns, exportColumn{m, m})
+	}
+	return respondTable(c, format, "readings", columns, func(write func(...interface{}) error) error {
+		return services.StreamRawReadings(q, func(r services.RawReading) error {
+			row := []interface{}{bangkokTime(r.Timestamp), r.DVID, r.Place, r.Address, r.Province, r.District, r.Region,
+				r.Latitude, r.Longitude, r.QualityFlags}
+			for _, m := range q.Metrics {
+				if v, ok := r.Metrics[m]; ok {
+					row = append(row, v)
+				} else {
+					row = append(row, nil)
+				}
+			}
+			return write(row...)
+		}, nil)
+	})
+}
@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"yakkaw_dashboard/services"
+)
+
+type ReportController struct {
+	Service *services.ReportService
+}
+
+// NewReportController เป็น constructor สำหรับ ReportController
+func NewReportController(s *services.ReportService) *ReportController {
+	return &ReportController{Service: s}
+}
+
+// ListReports (ADMIN ONLY) รายการรายงาน PDF ที่สร้างแล้ว กรองด้วย ?province= และ ?month=YYYY-MM
+func (rc *ReportController) ListReports(c echo.Context) error {
+	month := c.QueryParam("month")
+	if month != "" {
+		if _, err := services.ParseReportMonth(month); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+	}
+	reports, err := rc.Service.List(c.QueryParam("province"), month)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, reports)
+}
+
+// GenerateReport (ADMIN ONLY) สร้างรายงานของจังหวัดและเดือนทันที (สร้างทับของเดิมถ้ามี)
+func (rc *ReportController) GenerateReport(c echo.Context) error {
+	var input struct {
+		Province string `json:"province"`
+		Month    string `json:"month"`
+	}
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	report, err := rc.Service.Generate(input.Province, input.Month, services.ReportTriggerManual)
+	if err != nil {
+		if errors.Is(err, services.ErrNoReportData) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, report)
+}
+
+// DownloadReport (ADMIN ONLY) ดาวน์โหลดไฟล์ PDF ของรายงาน
+func (rc *ReportController) DownloadReport(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid report id"})
+	}
+	report, path, err := rc.Service.Get(uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "report not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if _, err := os.Stat(path); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "report file not found"})
+	}
+	return c.Attachment(path, report.FileName)
+}
+
+// DeleteReport (ADMIN ONLY) ลบรายงานและไฟล์ PDF
+func (rc *ReportController) DeleteReport(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid report id"})
+	}
+	if err := rc.Service.Delete(uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "report not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Report deleted successfully"})
+}
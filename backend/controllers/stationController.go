@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"yakkaw_dashboard/services"
+)
+
+// GetLatestStations คืนค่าล่าสุดของทุกสถานีที่ active จาก cache
+// filter: ?province= &bbox=minLon,minLat,maxLon,maxLat &max_age=2h &status=online|stale|offline
+func GetLatestStations(c echo.Context) error {
+	filter, err := parseStationFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	stations, refreshedAt, err := services.GetLatestStations(filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if wantsGeoJSON(c) {
+		return respondGeoJSON(c, services.StationsGeoJSON(stations))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"refreshed_at": refreshedAt,
+		"count":        len(stations),
+		"data":         stations,
+	})
+}
+
+func parseStationFilter(c echo.Context) (services.StationFilter, error) {
+	filter := services.StationFilter{Province: c.QueryParam("province")}
+
+	if raw := c.QueryParam("bbox"); raw != "" {
+		parts := strings.Split(raw, ",")
+		if len(parts) != 4 {
+			return filter, errors.New("bbox must be minLon,minLat,maxLon,maxLat")
+		}
+		var bbox [4]float64
+		for i, p := range parts {
+			v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+			if err != nil {
+				return filter, errors.New("bbox must contain numbers")
+			}
+			bbox[i] = v
+		}
+		if bbox[0] > bbox[2] || bbox[1] > bbox[3] {
+			return filter, errors.New("bbox min must not exceed max")
+		}
+		filter.BBox = &bbox
+	}
+
+	if raw := c.QueryParam("max_age"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d <= 0 {
+			return filter, errors.New("max_age must be a positive duration such as 30m or 2h")
+		}
+		filter.MaxAge = d
+	}
+
+	switch status := c.QueryParam("status"); status {
+	case "", "online", "stale", "offline":
+		filter.Status = status
+	default:
+		return filter, errors.New("status must be online, stale or offline")
+	}
+	return filter, nil
+}
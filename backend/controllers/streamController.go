@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"yakkaw_dashboard/services"
+)
+
+const (
+	streamHeartbeatInterval = 15 * time.Second
+	streamRetryMillis       = 5000
+)
+
+// StreamReadings ส่งข้อมูลที่เพิ่ง ingest แบบ Server-Sent Events
+// filter: ?province= &place= &dvid= &metric=pm25,aqi (ค่าเริ่มต้นคือทุก metric)
+// ต่อจากเดิมได้ด้วย header Last-Event-ID (หรือ ?last_event_id=) ภายใน backlog ล่าสุด
+func StreamReadings(c echo.Context) error {
+	metrics, err := parseStreamMetrics(c.QueryParam("metric"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	lastID := c.Request().Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = c.QueryParam("last_event_id")
+	}
+	var lastEventID uint64
+	if lastID != "" {
+		if lastEventID, err = strconv.ParseUint(lastID, 10, 64); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid Last-Event-ID"})
+		}
+	}
+
+	filter := services.NewReadingFilter(c.QueryParam("province"), c.QueryParam("place"), c.QueryParam("dvid"))
+	sub, replay := services.Readings.Subscribe(filter, lastEventID)
+	defer sub.Close()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("Connection", "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(res, "retry: %d\n\n", streamRetryMillis); err != nil {
+		return nil
+	}
+	for _, ev := range replay {
+		if err := writeReadingEvent(res, ev, metrics); err != nil {
+			return nil
+		}
+	}
+	res.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+	ctx := c.Request().Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": ping\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		case ev, ok := <-sub.C:
+			if !ok {
+				// client รับไม่ทันจนถูกตัด ให้ reconnect แล้วต่อจาก Last-Event-ID
+				if sub.Dropped() {
+					fmt.Fprint(res, "event: overflow\ndata: {}\n\n")
+					res.Flush()
+				}
+				return nil
+			}
+			if err := writeReadingEvent(res, ev, metrics); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
+
+func parseStreamMetrics(raw string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return services.StreamMetrics, nil
+	}
+	var metrics []string
+	for _, m := range strings.Split(raw, ",") {
+		m = strings.ToLower(strings.TrimSpace(m))
+		known := false
+		for _, s := range services.StreamMetrics {
+			if m == s {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unsupported metric %q (use %s)", m, strings.Join(services.StreamMetrics, ", "))
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, nil
+}
+
+func writeReadingEvent(res *echo.Response, ev services.ReadingEvent, metrics []string) error {
+	selected := make(map[string]float64, len(metrics))
+	for _, m := range metrics {
+		selected[m] = ev.Metrics[m]
+	}
+	ev.Metrics = selected
+
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(res, "id: %d\nevent: reading\ndata: %s\n\n", ev.ID, data)
+	return err
+}
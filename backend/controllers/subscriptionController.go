@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"yakkaw_dashboard/database"
+	"yakkaw_dashboard/models"
+	"yakkaw_dashboard/services"
+)
+
+type SubscriptionController struct {
+	Service *services.SubscriptionService
+}
+
+// NewSubscriptionController เป็น constructor สำหรับ SubscriptionController
+func NewSubscriptionController(s *services.SubscriptionService) *SubscriptionController {
+	return &SubscriptionController{Service: s}
+}
+
+// currentUserID หา ID ของผู้ใช้ที่ login อยู่จาก username ที่ JWTMiddleware ใส่ไว้ใน context
+func currentUserID(c echo.Context) (uint, bool) {
+	username, _ := c.Get("username").(string)
+	if username == "" {
+		return 0, false
+	}
+	var user models.User
+	if err := database.DB.Where("username = ?", username).First(&user).Error; err != nil {
+		return 0, false
+	}
+	return user.ID, true
+}
+
+// ListSubscriptions (USER) ดึง subscription ทั้งหมดของผู้ใช้
+func (sc *SubscriptionController) ListSubscriptions(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	subs, err := sc.Service.ListSubscriptions(userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, subs)
+}
+
+// CreateSubscription (USER) สมัครรับแจ้งเตือนของจังหวัด/สถานที่
+func (sc *SubscriptionController) CreateSubscription(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	var sub models.AlertSubscription
+	if err := c.Bind(&sub); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	created, err := sc.Service.CreateSubscription(userID, sub)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, created)
+}
+
+// UpdateSubscription (USER) แก้ไข subscription ของตัวเอง
+func (sc *SubscriptionController) UpdateSubscription(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid subscription id"})
+	}
+
+	input := models.AlertSubscription{Active: true}
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	updated, err := sc.Service.UpdateSubscription(userID, uint(id), input)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "subscription not found"})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, updated)
+}
+
+// DeleteSubscription (USER) ลบ subscription ของตัวเอง
+func (sc *SubscriptionController) DeleteSubscription(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid subscription id"})
+	}
+
+	if err := sc.Service.DeleteSubscription(userID, uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "subscription not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Subscription deleted successfully"})
+}
+
+// ListDeliveries (USER) ดูประวัติการส่งแจ้งเตือนล่าสุดของผู้ใช้ (?limit=, สูงสุด 500)
+func (sc *SubscriptionController) ListDeliveries(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	limit := 100
+	if raw := c.QueryParam("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > 500 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "limit must be between 1 and 500"})
+		}
+		limit = n
+	}
+
+	deliveries, err := sc.Service.ListDeliveries(userID, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, deliveries)
+}
+
+// ConfirmEmail (PUBLIC) ยืนยันอีเมลของ subscription จากลิงก์ที่ส่งไปยังอีเมลนั้น
+func (sc *SubscriptionController) ConfirmEmail(c echo.Context) error {
+	if err := sc.Service.ConfirmEmail(c.QueryParam("token")); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "confirmation link is invalid or already used"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Your email has been confirmed"})
+}
+
+// Unsubscribe (PUBLIC) ยกเลิกการแจ้งเตือนจากลิงก์ในอีเมล/webhook
+func (sc *SubscriptionController) Unsubscribe(c echo.Context) error {
+	if err := sc.Service.Unsubscribe(c.QueryParam("token")); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "subscription not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "You have been unsubscribed"})
+}
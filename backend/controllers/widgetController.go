@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"yakkaw_dashboard/services"
+)
+
+// widgetMaxAge คือเวลาที่ให้ browser/CDN cache badge และ widget (วินาที)
+const widgetMaxAge = 60
+
+// GetStationBadge คืน badge SVG ของสถานี ?theme=light|dark &lang=en|th
+func GetStationBadge(c echo.Context) error {
+	return respondWidget(c, "badge", "station")
+}
+
+// GetProvinceBadge คืน badge SVG ค่าเฉลี่ยของจังหวัด ?theme=light|dark &lang=en|th
+func GetProvinceBadge(c echo.Context) error {
+	return respondWidget(c, "badge", "province")
+}
+
+// GetStationWidget คืนหน้า HTML สำหรับฝังด้วย iframe ?theme=light|dark &lang=en|th
+func GetStationWidget(c echo.Context) error {
+	return respondWidget(c, "widget", "station")
+}
+
+// GetProvinceWidget คืนหน้า HTML ค่าเฉลี่ยของจังหวัดสำหรับฝังด้วย iframe
+func GetProvinceWidget(c echo.Context) error {
+	return respondWidget(c, "widget", "province")
+}
+
+func respondWidget(c echo.Context, output, kind string) error {
+	opts := services.WidgetOptions{Theme: strings.ToLower(c.QueryParam("theme")), Lang: strings.ToLower(c.QueryParam("lang"))}
+	switch opts.Theme {
+	case "":
+		opts.Theme = "light"
+	case "light", "dark":
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "theme must be light or dark"})
+	}
+	if opts.Lang != "th" {
+		opts.Lang = "en"
+	}
+
+	id, err := url.PathUnescape(c.Param(kind))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid " + kind})
+	}
+	var status services.WidgetStatus
+	if kind == "province" {
+		status, err = services.GetProvinceWidgetStatus(id)
+	} else {
+		status, err = services.GetStationWidgetStatus(id)
+	}
+	code := http.StatusOK
+	if errors.Is(err, services.ErrWidgetNotFound) {
+		// ยังวาด badge/widget สีเทาให้หน้าเว็บที่ฝังไว้ ไม่ให้เป็นภาพแตก
+		code = http.StatusNotFound
+		status = services.WidgetStatus{Kind: kind, ID: id, Name: id, Status: "offline"}
+	} else if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	var body []byte
+	contentType := "image/svg+xml; charset=utf-8"
+	if output == "badge" {
+		body = services.RenderWidgetBadge(status, opts)
+	} else {
+		contentType = echo.MIMETextHTMLCharsetUTF8
+		if body, err = services.RenderWidgetHTML(status, opts); err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+	}
+
+	h := fnv.New64a()
+	h.Write(body)
+	etag := fmt.Sprintf(`"%x"`, h.Sum64())
+	header := c.Response().Header()
+	header.Set("Cache-Control", fmt.Sprintf("public, max-age=%d", widgetMaxAge))
+	header.Set("ETag", etag)
+	if !status.ObservedAt.IsZero() {
+		header.Set("Last-Modified", status.ObservedAt.UTC().Format(http.TimeFormat))
+	}
+	if code == http.StatusOK && c.Request().Header.Get("If-None-Match") == etag {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return c.Blob(code, contentType, body)
+}
@@ -48,6 +48,20 @@ func Init() {
 		&models.ColorRange{},
 		&models.SupportContact{},
 		&models.SupportFAQ{},
+		&models.ForecastRun{},
+		&models.Forecast{},
+		&models.CalibrationProfile{},
+		&models.DeviceAlert{},
+		&models.AlertRule{},
+		&models.AlertRuleState{},
+		&models.AlertSubscription{},
+		&models.AlertDelivery{},
+		&models.PushSubscription{},
+		&models.VAPIDKey{},
+		&models.Region{},
+		&models.Province{},
+		&models.ProvinceAlias{},
+		&models.Report{},
 	)
 	ensureIndexes(DB)
 
@@ -61,6 +75,16 @@ func ensureIndexes(db *gorm.DB) {
 	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_sensor_data_address ON sensor_data (address)").Error; err != nil {
 		log.Printf("failed to create idx_sensor_data_address: %v", err)
 	}
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_sensor_data_dvid_timestamp ON sensor_data (dvid, timestamp DESC)").Error; err != nil {
+		log.Printf("failed to create idx_sensor_data_dvid_timestamp: %v", err)
+	}
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_sensor_data_province ON sensor_data (province)").Error; err != nil {
+		log.Printf("failed to create idx_sensor_data_province: %v", err)
+	}
+	// keyset pagination ของ raw readings API (ORDER BY timestamp, id)
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_sensor_data_timestamp_id ON sensor_data (timestamp, id)").Error; err != nil {
+		log.Printf("failed to create idx_sensor_data_timestamp_id: %v", err)
+	}
 }
 
 func resolveDSN() (string, error) {
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"log"
 	"os"
 	"time"
 
+	"yakkaw_dashboard/config"
 	"yakkaw_dashboard/database"
 	"yakkaw_dashboard/routes"
 	"yakkaw_dashboard/services"
@@ -34,6 +36,18 @@ func main() {
 	// Set up routes
 	routes.Init(e)
 
+	// Load province/region reference data (seeded on first run) used to resolve station provinces.
+	if err := services.LoadProvinceReference(); err != nil {
+		log.Printf("Error loading province reference data: %v", err)
+	}
+
+	// Fill province/district/region for devices and readings stored before they were tracked.
+	go func() {
+		if _, err := services.RebuildStationLocations(false); err != nil {
+			log.Printf("Error backfilling station locations: %v", err)
+		}
+	}()
+
 	// Start a goroutine for the data pipeline to fetch and store API data periodically.
 	go func() {
 		// รับค่า API_URL จาก environment variable หรือใช้ fallback ถ้าไม่มีค่า
@@ -47,6 +61,36 @@ func main() {
 		}
 	}()
 
+	// Refit the PM2.5 forecast models every hour using the stored hourly history.
+	go func() {
+		for {
+			if err := services.RunForecasts(); err != nil {
+				log.Printf("Error running forecasts: %v", err)
+			}
+			time.Sleep(1 * time.Hour)
+		}
+	}()
+
+	// Open/resolve alerts for devices that stop (or resume) reporting.
+	go func() {
+		for {
+			if err := services.CheckDeviceAlerts(); err != nil {
+				log.Printf("Error checking device alerts: %v", err)
+			}
+			time.Sleep(config.DeviceAlertCheckInterval())
+		}
+	}()
+
+	// Generate last month's PDF report for every province that does not have one yet.
+	go func() {
+		for {
+			if err := services.RunScheduledReports(); err != nil {
+				log.Printf("Error generating scheduled reports: %v", err)
+			}
+			time.Sleep(config.ReportCheckInterval())
+		}
+	}()
+
 	// Start the server
 	e.Logger.Fatal(e.Start(":8080"))
 }
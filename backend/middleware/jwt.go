@@ -31,6 +31,7 @@ func JWTMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 		// Log the claims and attach to context
 		c.Logger().Infof("Token is valid. User: %s, Role: %s", claims.Username, claims.Role)
 		c.Set("userRole", claims.Role)
+		c.Set("username", claims.Username)
 
 		return next(c)
 	}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"yakkaw_dashboard/utils"
+)
+
+var (
+	httpRequests = utils.Metrics.CounterVec("http_requests_total",
+		"HTTP requests by method, Echo route and status code.", "method", "route", "status")
+	httpRequestDuration = utils.Metrics.HistogramVec("http_request_duration_seconds",
+		"HTTP request latency in seconds by method and Echo route.", utils.DefaultDurationBuckets, "method", "route")
+)
+
+// MetricsMiddleware records request counts and latencies for /metrics. It is labelled with the
+// route pattern (c.Path(), e.g. /devices/:dvid) rather than the raw URL to keep label values bounded.
+func MetricsMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+		err := next(c)
+
+		route := c.Path()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := c.Response().Status
+		if err != nil && !c.Response().Committed {
+			// the error handler has not written the response yet
+			status = http.StatusInternalServerError
+			var he *echo.HTTPError
+			if errors.As(err, &he) {
+				status = he.Code
+			}
+		}
+		method := c.Request().Method
+		httpRequests.Inc(method, route, strconv.Itoa(status))
+		httpRequestDuration.Observe(time.Since(start).Seconds(), method, route)
+		return err
+	}
+}
@@ -31,6 +31,13 @@ type SensorData struct {
 	Pres         int     `gorm:"column:pres" json:"pres"`
 	Color        string  `gorm:"column:color;size:5" json:"color"`
 	Trend        string  `gorm:"column:trend;size:5" json:"trend"`
+	QualityFlags int     `gorm:"column:quality_flags;not null;default:0" json:"quality_flags"`
+	PM25Calibrated *float64 `gorm:"column:pm25_calibrated" json:"pm25_calibrated"`
+	PM10Calibrated *float64 `gorm:"column:pm10_calibrated" json:"pm10_calibrated"`
+	Province     string  `gorm:"column:province;size:100" json:"province"`
+	District     string  `gorm:"column:district;size:100" json:"district"`
+	Subdistrict  string  `gorm:"column:subdistrict;size:100" json:"subdistrict"`
+	Region       string  `gorm:"column:region;size:100" json:"region"`
 }
 
 
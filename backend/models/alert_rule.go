@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AlertRule defines when the system should publish a notification automatically.
+// The rule is evaluated after each ingestion for every entity in its scope
+// (a single province/place/device, or all of them when ScopeValue is empty).
+type AlertRule struct {
+	gorm.Model
+	Name          string  `gorm:"type:varchar(255);not null" json:"name"`
+	Metric        string  `gorm:"type:varchar(20);not null" json:"metric"`
+	ScopeType     string  `gorm:"type:varchar(20);not null" json:"scope_type"` // province | place | device
+	ScopeValue    string  `gorm:"type:varchar(255)" json:"scope_value"`
+	Threshold     float64 `json:"threshold"`
+	WindowMinutes int     `json:"window_minutes"`
+	Aggregation   string  `gorm:"type:varchar(10)" json:"aggregation"` // avg | max
+	Hysteresis    float64 `json:"hysteresis"`
+	QuietStart    string  `gorm:"type:varchar(5)" json:"quiet_start,omitempty"` // HH:MM (Asia/Bangkok)
+	QuietEnd      string  `gorm:"type:varchar(5)" json:"quiet_end,omitempty"`
+	Category      string  `gorm:"type:varchar(100)" json:"category"`
+	Icon          string  `gorm:"type:varchar(255)" json:"icon,omitempty"`
+	ImprovedIcon  string  `gorm:"type:varchar(255)" json:"improved_icon,omitempty"`
+	Enabled       bool    `json:"enabled"`
+}
+
+// AlertRuleState remembers the last notified band of a rule for one scope key,
+// so notifications are only sent when the band changes.
+type AlertRuleState struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	RuleID         uint       `gorm:"uniqueIndex:idx_alert_rule_state_key" json:"rule_id"`
+	ScopeKey       string     `gorm:"type:varchar(255);uniqueIndex:idx_alert_rule_state_key" json:"scope_key"`
+	Active         bool       `json:"active"`
+	BandIndex      int        `json:"band_index"`
+	LastValue      float64    `json:"last_value"`
+	LastNotifiedAt *time.Time `json:"last_notified_at,omitempty"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
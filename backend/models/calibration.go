@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CalibrationProfile keeps correction factors of a low-cost sensor against a reference monitor.
+// calibrated = c0 + c1·raw + c2·raw² + ... + humidity_coef·RH, applied within [valid_from, valid_to).
+type CalibrationProfile struct {
+	gorm.Model
+	DVID         string     `gorm:"column:dv_id;type:varchar(255);index;not null" json:"dvid"`
+	Metric       string     `gorm:"type:varchar(20);not null" json:"metric"`
+	Method       string     `gorm:"type:varchar(20);not null" json:"method"`
+	Coefficients []float64  `gorm:"serializer:json;type:text" json:"coefficients"`
+	HumidityCoef float64    `json:"humidity_coef"`
+	ValidFrom    time.Time  `gorm:"type:timestamptz;not null" json:"valid_from"`
+	ValidTo      *time.Time `gorm:"type:timestamptz" json:"valid_to,omitempty"`
+	Note         string     `gorm:"type:text" json:"note,omitempty"`
+}
@@ -16,5 +16,13 @@ type Device struct {
 	Models       string    `gorm:"type:varchar(255);not null" json:"models"`
 	ContactName  string    `gorm:"type:varchar(255);not null" json:"contact_name"`
 	ContactPhone string    `gorm:"type:varchar(255);not null" json:"contact_phone"`
+	ContactEmail string    `gorm:"type:varchar(255)" json:"-"` // admin เท่านั้น ผ่าน controllers.adminDevice
 	DeployDate   time.Time `gorm:"type:timestamp;not null" json:"deploy_date"`
+
+	// ตำแหน่งทางปกครองที่คำนวณจากพิกัด (หรือจาก address เมื่อพิกัดไม่อยู่ในขอบเขตใด)
+	Province       string `gorm:"type:varchar(100);index" json:"province"`
+	District       string `gorm:"type:varchar(100)" json:"district"`
+	Subdistrict    string `gorm:"type:varchar(100)" json:"subdistrict"`
+	Region         string `gorm:"type:varchar(100)" json:"region"`
+	LocationSource string `gorm:"type:varchar(20)" json:"location_source"` // boundary | approximate | address
 }
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DeviceAlert records a period in which a device stopped reporting data.
+// It stays "open" until readings resume, then it is marked "resolved".
+type DeviceAlert struct {
+	gorm.Model
+	DVID         string     `gorm:"column:dv_id;type:varchar(255);index;not null" json:"dvid"`
+	Status       string     `gorm:"type:varchar(20);index;not null" json:"status"`
+	Level        string     `gorm:"type:varchar(20)" json:"level"`
+	Message      string     `gorm:"type:text" json:"message"`
+	LastSeen     time.Time  `json:"last_seen"`
+	StartedAt    time.Time  `json:"started_at"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+	ContactName  string     `gorm:"type:varchar(255)" json:"contact_name"`
+	ContactPhone string     `gorm:"type:varchar(255)" json:"contact_phone"`
+	NotifiedAt   *time.Time `json:"notified_at,omitempty"`
+	NotifyError  string     `gorm:"type:text" json:"notify_error,omitempty"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// ForecastRun keeps one model fit per province together with its backtest accuracy.
+type ForecastRun struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Province     string    `gorm:"type:varchar(100);index" json:"province"`
+	Metric       string    `gorm:"type:varchar(20)" json:"metric"`
+	Model        string    `gorm:"type:varchar(50)" json:"model"`
+	GeneratedAt  time.Time `gorm:"index" json:"generated_at"`
+	TrainedFrom  time.Time `json:"trained_from"`
+	TrainedTo    time.Time `json:"trained_to"`
+	Points       int       `json:"points"`
+	Alpha        float64   `json:"alpha"`
+	Beta         float64   `json:"beta"`
+	Gamma        float64   `json:"gamma"`
+	BacktestMAE  float64   `json:"backtest_mae"`
+	BacktestRMSE float64   `json:"backtest_rmse"`
+	BaselineMAE  float64   `json:"baseline_mae"`
+	SkillScore   float64   `json:"skill_score"`
+}
+
+// Forecast is a single hourly prediction with its prediction intervals.
+type Forecast struct {
+	ID          uint      `gorm:"primaryKey" json:"-"`
+	RunID       uint      `gorm:"index" json:"-"`
+	Province    string    `gorm:"type:varchar(100);index" json:"-"`
+	TargetTime  time.Time `gorm:"index" json:"target_time"`
+	HorizonHour int       `json:"horizon_hour"`
+	Value       float64   `json:"value"`
+	Lower80     float64   `json:"lower_80"`
+	Upper80     float64   `json:"upper_80"`
+	Lower95     float64   `json:"lower_95"`
+	Upper95     float64   `json:"upper_95"`
+}
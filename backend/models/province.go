@@ -0,0 +1,27 @@
+package models
+
+import "gorm.io/gorm"
+
+// Region groups provinces for reporting (e.g. ภาคเหนือ, or a cross-border group such as Laos).
+type Region struct {
+	gorm.Model
+	Name   string `gorm:"type:varchar(100);uniqueIndex;not null" json:"name"`
+	NameEN string `gorm:"type:varchar(100)" json:"name_en"`
+}
+
+// Province is the canonical name every station is grouped under.
+type Province struct {
+	gorm.Model
+	Name     string          `gorm:"type:varchar(100);uniqueIndex;not null" json:"name"`
+	Country  string          `gorm:"type:varchar(2);not null;default:TH" json:"country"`
+	RegionID *uint           `gorm:"index" json:"region_id"`
+	Region   *Region         `json:"region,omitempty"`
+	Aliases  []ProvinceAlias `json:"aliases,omitempty"`
+}
+
+// ProvinceAlias is an alternative spelling (English, abbreviation, Lao script, ...) resolved to a province.
+type ProvinceAlias struct {
+	gorm.Model
+	ProvinceID uint   `gorm:"index;not null" json:"province_id"`
+	Alias      string `gorm:"type:varchar(100);not null" json:"alias"`
+}
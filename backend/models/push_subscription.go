@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PushSubscription is a browser Web Push subscription registered by the dashboard
+// (the PushSubscription JSON of the Push API).
+type PushSubscription struct {
+	gorm.Model
+	Endpoint      string     `gorm:"type:text;uniqueIndex;not null" json:"endpoint"`
+	P256dh        string     `gorm:"type:varchar(255);not null" json:"p256dh"`
+	Auth          string     `gorm:"type:varchar(255);not null" json:"auth"`
+	UserAgent     string     `gorm:"type:varchar(512)" json:"user_agent,omitempty"`
+	FailureCount  int        `json:"failure_count"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+}
+
+// VAPIDKey stores the generated application server key pair used to sign Web Push requests
+// when VAPID_PUBLIC_KEY / VAPID_PRIVATE_KEY are not configured.
+type VAPIDKey struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	PublicKey  string    `gorm:"type:varchar(255);not null" json:"public_key"`
+	PrivateKey string    `gorm:"type:varchar(255);not null" json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "gorm.io/gorm"
+
+// Report is a generated monthly air-quality PDF for one province.
+// The file itself lives in config.ReportsDir(); regenerating replaces it.
+type Report struct {
+	gorm.Model
+	Province string `gorm:"type:varchar(100);uniqueIndex:idx_report_province_month;not null" json:"province"`
+	Month    string `gorm:"type:varchar(7);uniqueIndex:idx_report_province_month;not null" json:"month"` // YYYY-MM
+	FileName string `gorm:"type:varchar(255);not null" json:"file_name"`
+	Size     int64  `json:"size"`
+	Trigger  string `gorm:"type:varchar(20)" json:"trigger"` // schedule | manual
+}
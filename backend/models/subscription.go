@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AlertSubscription lets a registered user be alerted when a metric in a province
+// or place reaches their threshold. Delivery is by email or a signed webhook.
+// Email subscriptions only receive alerts after the address is confirmed through
+// the link mailed to it.
+type AlertSubscription struct {
+	gorm.Model
+	UserID           uint       `gorm:"index;not null" json:"user_id"`
+	ScopeType        string     `gorm:"type:varchar(20);not null" json:"scope_type"` // province | place
+	ScopeValue       string     `gorm:"type:varchar(255);not null" json:"scope_value"`
+	Metric           string     `gorm:"type:varchar(20);not null" json:"metric"`
+	Threshold        float64    `json:"threshold"`
+	Channel          string     `gorm:"type:varchar(20);not null" json:"channel"` // email | webhook
+	Email            string     `gorm:"type:varchar(255)" json:"email,omitempty"`
+	EmailConfirmed   bool       `json:"email_confirmed"`
+	ConfirmToken     string     `gorm:"type:varchar(64);index" json:"-"`
+	WebhookURL       string     `gorm:"type:varchar(1024)" json:"webhook_url,omitempty"`
+	WebhookSecret    string     `gorm:"type:varchar(255)" json:"webhook_secret,omitempty"`
+	UnsubscribeToken string     `gorm:"type:varchar(64);uniqueIndex" json:"-"`
+	Active           bool       `json:"active"`
+	Triggered        bool       `json:"triggered"` // อยู่เหนือ threshold แล้ว รอให้ลดลงก่อนแจ้งครั้งถัดไป
+	LastValue        float64    `json:"last_value"`
+	LastNotifiedAt   *time.Time `json:"last_notified_at,omitempty"`
+}
+
+// AlertDelivery is the delivery log of one subscription alert.
+type AlertDelivery struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	SubscriptionID uint      `gorm:"index" json:"subscription_id"`
+	UserID         uint      `gorm:"index" json:"user_id"`
+	Channel        string    `gorm:"type:varchar(20)" json:"channel"`
+	Target         string    `gorm:"type:varchar(1024)" json:"target"`
+	Title          string    `json:"title"`
+	Value          float64   `json:"value"`
+	Status         string    `gorm:"type:varchar(20);index" json:"status"` // sent | failed | rate_limited
+	Error          string    `json:"error,omitempty"`
+	CreatedAt      time.Time `gorm:"index" json:"created_at"`
+}
@@ -10,6 +10,9 @@ import (
 )
 
 func Init(e *echo.Echo) {
+	// นับ request ทุก route สำหรับ /metrics
+	e.Use(middleware.MetricsMiddleware)
+	e.GET("/metrics", controllers.GetMetrics)
 
 	ctrl := new(controllers.ColorRangeController)
 
@@ -25,6 +28,8 @@ func Init(e *echo.Echo) {
 	//Devices
 	e.GET("/devices", controllers.GetAllDevices)
 	e.GET("/devices/:dvid", controllers.GetDevice)
+	e.GET("/api/v1/devices/health", controllers.GetDevicesHealth)
+	e.GET("/api/v1/devices/health/:dvid", controllers.GetDeviceHealth)
 
 	// 🔹 Public Authentication Routes
 	e.POST("/register", controllers.Register)
@@ -35,11 +40,23 @@ func Init(e *echo.Echo) {
 	categoryService := services.NewCategoryService(database.DB)
 	newsService := services.NewNewsService(database.DB)
 	supportService := services.NewSupportService(database.DB)
+	calibrationService := services.NewCalibrationService(database.DB)
+	alertRuleService := services.NewAlertRuleService(database.DB)
+	subscriptionService := services.NewSubscriptionService(database.DB)
+	pushService := services.NewPushService(database.DB)
+	provinceService := services.NewProvinceService(database.DB)
+	reportService := services.NewReportService(database.DB)
 
 	// 🔹 Create controllers by injecting the corresponding service
 	categoryController := controllers.NewCategoryController(categoryService)
 	newsController := controllers.NewNewsController(newsService)
 	supportController := controllers.NewSupportController(supportService)
+	calibrationController := controllers.NewCalibrationController(calibrationService)
+	alertRuleController := controllers.NewAlertRuleController(alertRuleService)
+	subscriptionController := controllers.NewSubscriptionController(subscriptionService)
+	pushController := controllers.NewPushController(pushService)
+	provinceController := controllers.NewProvinceController(provinceService)
+	reportController := controllers.NewReportController(reportService)
 
 	// 🔹 Public Routes for Categories and News (READ only)
 	e.GET("/categories", categoryController.GetCategories)
@@ -54,9 +71,45 @@ func Init(e *echo.Echo) {
 	adminGroup.Use(middleware.JWTMiddleware) // Protect all admin routes
 
 	adminGroup.POST("/qr/generate", controllers.GenerateQRLogin)
+	adminGroup.GET("/devices", controllers.GetAllDevicesAdmin)
 	adminGroup.POST("/devices", controllers.CreateDevice)
 	adminGroup.PUT("/devices/:dvid", controllers.UpdateDevice)
 	adminGroup.DELETE("/devices/:id", controllers.DeleteDevice)
+	adminGroup.POST("/locations/rebuild", controllers.RebuildStationLocations)
+	adminGroup.POST("/quality-flags/rebuild", controllers.BackfillQualityFlags)
+
+	// ✅ Admin-only: Province aliases (ใช้ resolve ชื่อจังหวัดจาก address) และสมาชิกของแต่ละภาค
+	adminGroup.POST("/provinces/:id/aliases", provinceController.CreateAlias)
+	adminGroup.PUT("/province-aliases/:id", provinceController.UpdateAlias)
+	adminGroup.DELETE("/province-aliases/:id", provinceController.DeleteAlias)
+	adminGroup.PUT("/provinces/:id/region", provinceController.SetProvinceRegion)
+	adminGroup.POST("/regions", provinceController.CreateRegion)
+	adminGroup.PUT("/regions/:id", provinceController.UpdateRegion)
+	adminGroup.DELETE("/regions/:id", provinceController.DeleteRegion)
+
+	// ✅ Admin-only: Device Calibration Profiles
+	adminGroup.GET("/devices/:dvid/calibrations", calibrationController.ListCalibrations)
+	adminGroup.POST("/devices/:dvid/calibrations", calibrationController.CreateCalibration)
+	adminGroup.PUT("/devices/:dvid/calibrations/:id", calibrationController.UpdateCalibration)
+	adminGroup.DELETE("/devices/:dvid/calibrations/:id", calibrationController.DeleteCalibration)
+	adminGroup.POST("/devices/:dvid/calibrations/recompute", calibrationController.RecomputeCalibrations)
+
+	// ✅ Admin-only: Offline / Stale Device Alerts
+	adminGroup.GET("/device-alerts", controllers.GetDeviceAlerts)
+	adminGroup.GET("/devices/:dvid/alerts", controllers.GetDeviceAlertHistory)
+
+	// ✅ Admin-only: Threshold Alert Rules (สร้าง notification อัตโนมัติหลัง ingest)
+	adminGroup.GET("/alert-rules", alertRuleController.ListAlertRules)
+	adminGroup.GET("/alert-rules/:id", alertRuleController.GetAlertRule)
+	adminGroup.POST("/alert-rules", alertRuleController.CreateAlertRule)
+	adminGroup.PUT("/alert-rules/:id", alertRuleController.UpdateAlertRule)
+	adminGroup.DELETE("/alert-rules/:id", alertRuleController.DeleteAlertRule)
+
+	// ✅ Admin-only: Monthly PDF Reports (สร้างอัตโนมัติทุกต้นเดือน หรือสั่งสร้างเอง)
+	adminGroup.GET("/reports", reportController.ListReports)
+	adminGroup.POST("/reports", reportController.GenerateReport)
+	adminGroup.GET("/reports/:id/download", reportController.DownloadReport)
+	adminGroup.DELETE("/reports/:id", reportController.DeleteReport)
 
 	adminGroup.POST("/colorranges", ctrl.Create)
 	adminGroup.PUT("/colorranges/:id", ctrl.Update)
@@ -101,6 +154,22 @@ func Init(e *echo.Echo) {
 
 	e.GET("/qr/consume", controllers.ConsumeQRLogin)
 
+	// 🔹 User Alert Subscriptions (ต้อง login, ยกเลิกผ่านลิงก์ได้โดยไม่ต้อง login)
+	subscriptionGroup := e.Group("/subscriptions")
+	subscriptionGroup.GET("/unsubscribe", subscriptionController.Unsubscribe)
+	subscriptionGroup.GET("/confirm", subscriptionController.ConfirmEmail)
+	subscriptionGroup.GET("", subscriptionController.ListSubscriptions, middleware.JWTMiddleware)
+	subscriptionGroup.POST("", subscriptionController.CreateSubscription, middleware.JWTMiddleware)
+	subscriptionGroup.PUT("/:id", subscriptionController.UpdateSubscription, middleware.JWTMiddleware)
+	subscriptionGroup.DELETE("/:id", subscriptionController.DeleteSubscription, middleware.JWTMiddleware)
+	subscriptionGroup.GET("/deliveries", subscriptionController.ListDeliveries, middleware.JWTMiddleware)
+
+	// 🔹 Web Push (browser ของ dashboard)
+	e.GET("/api/push/vapid-public-key", pushController.GetVAPIDPublicKey)
+	e.POST("/api/push/subscriptions", pushController.RegisterPushSubscription)
+	e.DELETE("/api/push/subscriptions", pushController.DeletePushSubscription)
+	adminGroup.POST("/push/vapid/rotate", pushController.RotateVAPIDKeys)
+
 	// 🔹 Air Quality Data Routes
 	airCtl := controllers.NewAirQualityController()
 	e.GET("/api/airquality/one_day", airCtl.GetOneDayDataHandler)
@@ -122,7 +191,38 @@ func Init(e *echo.Echo) {
 	e.GET("/chart/today", chartDataController.GetTodayChartDataHandler)
 	e.GET("/chart/heatmap/year", chartDataController.GetHeatmapOneYearHandler)
 	e.GET("/chart/ranking/daily", chartDataController.GetDailyRankingHandler)
+	e.GET("/chart/ranking/range", chartDataController.GetRangeRankingHandler)
 
 	// 🔹 Get Latest Air Quality
 	e.GET("/api/airquality/latest", controllers.GetLatestAirQuality)
+	e.GET("/api/v1/readings", controllers.GetRawReadings)
+	e.GET("/api/stream/readings", controllers.StreamReadings)
+	e.GET("/api/v1/stations/latest", controllers.GetLatestStations)
+	e.GET("/api/v1/stations/nearest", controllers.GetNearestStations)
+	e.GET("/api/v1/stations/within", controllers.GetStationsWithin)
+	e.GET("/api/v1/badge/station/:station", controllers.GetStationBadge)
+	e.GET("/api/v1/badge/province/:province", controllers.GetProvinceBadge)
+	e.GET("/api/v1/widget/station/:station", controllers.GetStationWidget)
+	e.GET("/api/v1/widget/province/:province", controllers.GetProvinceWidget)
+
+	// มาตรฐานเปิดสำหรับ aggregator ภายนอก (อ่านอย่างเดียว)
+	e.GET(controllers.SensorThingsBasePath, controllers.GetSensorThings)
+	e.GET(controllers.SensorThingsBasePath+"/*", controllers.GetSensorThings)
+	e.GET("/api/openaq/v3/parameters", controllers.GetOpenAQParameters)
+	e.GET("/api/openaq/v3/locations", controllers.GetOpenAQLocations)
+	e.GET("/api/openaq/v3/locations/:id", controllers.GetOpenAQLocation)
+	e.GET("/api/openaq/v3/locations/:id/sensors", controllers.GetOpenAQLocationSensors)
+	e.GET("/api/openaq/v3/locations/:id/latest", controllers.GetOpenAQLocationLatest)
+	e.GET("/api/openaq/v3/sensors/:id", controllers.GetOpenAQSensor)
+	e.GET("/api/openaq/v3/sensors/:id/measurements", controllers.GetOpenAQMeasurements)
+
+	e.GET("/api/v1/airquality/point", controllers.GetAirQualityAtPoint)
+	e.GET("/api/v1/grid", controllers.GetPM25Grid)
+	e.GET("/api/v1/locate", controllers.LocateCoordinates)
+	e.GET("/api/v1/provinces", provinceController.ListProvinces)
+	e.GET("/api/v1/regions", provinceController.ListRegions)
+
+	// 🔹 PM2.5 Forecast
+	forecastController := controllers.NewForecastController()
+	e.GET("/api/v1/forecast", forecastController.GetForecastHandler)
 }
@@ -0,0 +1,272 @@
+package services
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strings"
+	"sync"
+
+	"yakkaw_dashboard/config"
+)
+
+// ชุดขอบเขตการปกครอง (จังหวัด/อำเภอของไทย และแขวงของลาว) ที่ฝังมากับ binary
+// แทนที่ได้ด้วย ADMIN_BOUNDARIES_FILE โดยแต่ละ feature ใช้ properties:
+//
+//	level    = "province" | "district" (ไม่ระบุ: มี province = district, ไม่มี = province)
+//	name     = ชื่อจังหวัด/อำเภอ
+//	province = จังหวัดที่อำเภอนั้นสังกัด (เฉพาะ district)
+//	country  = "TH" | "LA"
+//	region   = ภาค (ไม่ระบุ = ใช้ภาคของจังหวัดจากตาราง provinces)
+//
+// FeatureCollection ที่มี "approximate": true (เช่นชุดที่ฝังมา ซึ่ง cmd/boundaries สร้างจากที่ตั้งอำเภอ/ศาลากลาง
+// ไม่ใช่เส้นเขตจริง) ให้ผลเป็น Source = approximate และ ResolveLocation จะใช้จังหวัดจาก address เมื่อขัดกัน
+//
+//go:embed data/admin_boundaries.geojson
+var bundledAdminBoundaries []byte
+
+// AdminLocation คือจังหวัด/อำเภอ/ตำบล/ภาคของพิกัดหนึ่ง (ชุดขอบเขตไม่มีระดับตำบล Subdistrict จึงมาจาก address เสมอ)
+type AdminLocation struct {
+	Province    string `json:"province"`
+	District    string `json:"district,omitempty"`
+	Subdistrict string `json:"subdistrict,omitempty"`
+	Region      string `json:"region,omitempty"`
+	Country     string `json:"country,omitempty"`
+	Source      string `json:"source"` // boundary | approximate | address | "" (หาไม่ได้)
+}
+
+// boundaryPolygon: ring แรกคือขอบนอก ring ถัดไปคือรู (พิกัด [lon, lat])
+type boundaryPolygon [][][2]float64
+
+type adminBoundary struct {
+	level    string
+	name     string
+	province string
+	country  string
+	region   string
+	approx   bool
+	bbox     [4]float64
+	polygons []boundaryPolygon
+}
+
+var adminBoundaries = struct {
+	sync.RWMutex
+	loaded    bool
+	provinces []adminBoundary
+	districts []adminBoundary
+}{}
+
+// loadAdminBoundaries อ่านชุดขอบเขตจากไฟล์ที่ตั้งค่าไว้ หรือชุดที่ฝังมา
+func loadAdminBoundaries() error {
+	data := bundledAdminBoundaries
+	if path := config.AdminBoundariesFile(); path != "" {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", path, err)
+		}
+		data = raw
+	}
+
+	provinces, districts, err := parseAdminBoundaries(data)
+	if err != nil {
+		return err
+	}
+	adminBoundaries.Lock()
+	adminBoundaries.provinces, adminBoundaries.districts, adminBoundaries.loaded = provinces, districts, true
+	adminBoundaries.Unlock()
+	resetLocationCache()
+	log.Printf("Loaded %d province and %d district boundaries", len(provinces), len(districts))
+	return nil
+}
+
+// ReloadAdminBoundaries อ่านชุดขอบเขตใหม่ (เช่นหลังเปลี่ยนไฟล์ ADMIN_BOUNDARIES_FILE)
+func ReloadAdminBoundaries() error {
+	return loadAdminBoundaries()
+}
+
+func ensureAdminBoundaries() {
+	adminBoundaries.RLock()
+	loaded := adminBoundaries.loaded
+	adminBoundaries.RUnlock()
+	if loaded {
+		return
+	}
+	if err := loadAdminBoundaries(); err != nil {
+		log.Printf("Error loading admin boundaries (falling back to address parsing): %v", err)
+		adminBoundaries.Lock()
+		adminBoundaries.loaded = true
+		adminBoundaries.Unlock()
+	}
+}
+
+func parseAdminBoundaries(data []byte) ([]adminBoundary, []adminBoundary, error) {
+	var fc struct {
+		Approximate bool `json:"approximate"`
+		Features    []struct {
+			Geometry struct {
+				Type        string          `json:"type"`
+				Coordinates json.RawMessage `json:"coordinates"`
+			} `json:"geometry"`
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal(data, &fc); err != nil {
+		return nil, nil, fmt.Errorf("invalid boundary GeoJSON: %w", err)
+	}
+
+	var provinces, districts []adminBoundary
+	for i, f := range fc.Features {
+		prop := func(key string) string {
+			if v, ok := f.Properties[key].(string); ok {
+				return strings.TrimSpace(v)
+			}
+			return ""
+		}
+		b := adminBoundary{
+			level:    prop("level"),
+			name:     prop("name"),
+			province: prop("province"),
+			country:  strings.ToUpper(prop("country")),
+			region:   prop("region"),
+			approx:   fc.Approximate,
+		}
+		if b.name == "" {
+			return nil, nil, fmt.Errorf("feature %d has no name", i)
+		}
+		if b.level == "" {
+			b.level = "province"
+			if b.province != "" {
+				b.level = "district"
+			}
+		}
+
+		switch f.Geometry.Type {
+		case "Polygon":
+			var poly boundaryPolygon
+			if err := json.Unmarshal(f.Geometry.Coordinates, &poly); err != nil {
+				return nil, nil, fmt.Errorf("feature %d (%s): %w", i, b.name, err)
+			}
+			b.polygons = []boundaryPolygon{poly}
+		case "MultiPolygon":
+			if err := json.Unmarshal(f.Geometry.Coordinates, &b.polygons); err != nil {
+				return nil, nil, fmt.Errorf("feature %d (%s): %w", i, b.name, err)
+			}
+		default:
+			return nil, nil, fmt.Errorf("feature %d (%s): unsupported geometry %q", i, b.name, f.Geometry.Type)
+		}
+		b.bbox = polygonsBBox(b.polygons)
+
+		switch b.level {
+		case "province":
+			provinces = append(provinces, b)
+		case "district":
+			districts = append(districts, b)
+		default:
+			return nil, nil, fmt.Errorf("feature %d (%s): unknown level %q", i, b.name, b.level)
+		}
+	}
+	return provinces, districts, nil
+}
+
+func polygonsBBox(polygons []boundaryPolygon) [4]float64 {
+	bbox := [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	for _, poly := range polygons {
+		if len(poly) == 0 {
+			continue
+		}
+		for _, pt := range poly[0] {
+			bbox[0] = math.Min(bbox[0], pt[0])
+			bbox[1] = math.Min(bbox[1], pt[1])
+			bbox[2] = math.Max(bbox[2], pt[0])
+			bbox[3] = math.Max(bbox[3], pt[1])
+		}
+	}
+	return bbox
+}
+
+func (b adminBoundary) contains(lat, lon float64) bool {
+	if lon < b.bbox[0] || lon > b.bbox[2] || lat < b.bbox[1] || lat > b.bbox[3] {
+		return false
+	}
+	for _, poly := range b.polygons {
+		if len(poly) == 0 || !ringContains(poly[0], lat, lon) {
+			continue
+		}
+		inHole := false
+		for _, hole := range poly[1:] {
+			if ringContains(hole, lat, lon) {
+				inHole = true
+				break
+			}
+		}
+		if !inHole {
+			return true
+		}
+	}
+	return false
+}
+
+// ringContains: ray casting ไปทางตะวันออก
+func ringContains(ring [][2]float64, lat, lon float64) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+		if (yi > lat) != (yj > lat) && lon < (xj-xi)*(lat-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// LocateByCoordinates หาจังหวัด/อำเภอที่ครอบพิกัด จากชุดขอบเขตเท่านั้น
+func LocateByCoordinates(lat, lon float64) (AdminLocation, bool) {
+	ensureAdminBoundaries()
+	if !ValidCoordinate(lat, lon) || (lat == 0 && lon == 0) {
+		return AdminLocation{}, false
+	}
+
+	adminBoundaries.RLock()
+	defer adminBoundaries.RUnlock()
+
+	var loc AdminLocation
+	approx := false
+	for _, d := range adminBoundaries.districts {
+		if d.contains(lat, lon) {
+			loc = AdminLocation{Province: d.province, District: d.name, Country: d.country, Region: d.region}
+			approx = d.approx
+			break
+		}
+	}
+	if loc.Province == "" {
+		for _, p := range adminBoundaries.provinces {
+			if p.contains(lat, lon) {
+				loc.Province, loc.Country = p.name, p.country
+				if loc.Region == "" {
+					loc.Region = p.region
+				}
+				approx = p.approx
+				break
+			}
+		}
+	}
+	if loc.Province == "" {
+		return AdminLocation{}, false
+	}
+
+	if loc.Country != "LA" {
+		loc.Province = normalizeProvince(loc.Province)
+	}
+	// ภาคที่กำหนดในตาราง provinces มาก่อน region ใน GeoJSON
+	if region := regionForProvince(loc.Province, loc.Country); region != "" {
+		loc.Region = region
+	}
+	loc.Source = "boundary"
+	if approx {
+		loc.Source = "approximate"
+	}
+	return loc, true
+}
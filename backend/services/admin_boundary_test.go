@@ -0,0 +1,119 @@
+package services
+
+import "testing"
+
+const testBoundaries = `{"type":"FeatureCollection","features":[
+ {"type":"Feature","properties":{"name":"จ.เชียงราย","country":"TH"},"geometry":{"type":"Polygon","coordinates":[
+   [[99.0,19.0],[101.0,19.0],[101.0,21.0],[99.0,21.0],[99.0,19.0]],
+   [[100.4,20.4],[100.6,20.4],[100.6,20.6],[100.4,20.6],[100.4,20.4]]]}},
+ {"type":"Feature","properties":{"name":"แม่สรวย","province":"เชียงราย","country":"TH"},"geometry":{"type":"Polygon","coordinates":[
+   [[99.0,19.0],[100.0,19.0],[100.0,20.0],[99.0,20.0],[99.0,19.0]]]}},
+ {"type":"Feature","properties":{"name":"Bokeo","country":"la"},"geometry":{"type":"MultiPolygon","coordinates":[
+   [[[100.4,20.4],[100.6,20.4],[100.6,20.6],[100.4,20.6],[100.4,20.4]]]]}}
+]}`
+
+func TestLocateByCoordinates(t *testing.T) {
+	provinces, districts, err := parseAdminBoundaries([]byte(testBoundaries))
+	if err != nil {
+		t.Fatal(err)
+	}
+	adminBoundaries.Lock()
+	adminBoundaries.provinces, adminBoundaries.districts, adminBoundaries.loaded = provinces, districts, true
+	adminBoundaries.Unlock()
+	resetLocationCache()
+	defer func() {
+		adminBoundaries.Lock()
+		adminBoundaries.provinces, adminBoundaries.districts, adminBoundaries.loaded = nil, nil, false
+		adminBoundaries.Unlock()
+		resetLocationCache()
+	}()
+
+	cases := []struct {
+		lat, lon float64
+		want     AdminLocation
+	}{
+		{19.5, 99.5, AdminLocation{Province: "เชียงราย", District: "แม่สรวย", Region: regionNorth, Country: "TH", Source: "boundary"}},
+		{20.0, 100.8, AdminLocation{Province: "เชียงราย", Region: regionNorth, Country: "TH", Source: "boundary"}},
+		// อยู่ในรูของเชียงราย ซึ่งเป็นพื้นที่ของแขวงบ่อแก้ว
+		{20.5, 100.5, AdminLocation{Province: "Bokeo", Region: regionLaos, Country: "LA", Source: "boundary"}},
+	}
+	for _, tc := range cases {
+		if got, ok := LocateByCoordinates(tc.lat, tc.lon); !ok || got != tc.want {
+			t.Errorf("LocateByCoordinates(%v, %v) = %+v, %v; want %+v", tc.lat, tc.lon, got, ok, tc.want)
+		}
+	}
+
+	if _, ok := LocateByCoordinates(13.75, 100.5); ok {
+		t.Fatal("Bangkok is outside the test boundaries")
+	}
+	// นอกขอบเขต → ใช้ address แทน
+	got := ResolveLocation(13.75, 100.5, "แขวงพญาไท เขตพญาไท กรุงเทพมหานคร")
+	if got.Province != "กรุงเทพมหานคร" || got.District != "พญาไท" || got.Subdistrict != "พญาไท" ||
+		got.Region != regionCentral || got.Source != "address" {
+		t.Fatalf("address fallback = %+v", got)
+	}
+	// ในขอบเขต → ตำบลมาจาก address เมื่อจังหวัด/อำเภอตรงกัน
+	got = ResolveLocation(19.5, 99.5, "ต.วาวี อ.แม่สรวย จ.เชียงราย")
+	if got.District != "แม่สรวย" || got.Subdistrict != "วาวี" || got.Source != "boundary" {
+		t.Fatalf("boundary with address = %+v", got)
+	}
+	got = ResolveLocation(19.5, 99.5, "ต.ศรีภูมิ อ.เมืองเชียงใหม่ จ.เชียงใหม่")
+	if got.District != "แม่สรวย" || got.Subdistrict != "" {
+		t.Fatalf("mismatched address should be ignored: %+v", got)
+	}
+}
+
+func TestParseAdminBoundariesRejectsUnknownGeometry(t *testing.T) {
+	_, _, err := parseAdminBoundaries([]byte(`{"features":[{"properties":{"name":"x"},"geometry":{"type":"Point","coordinates":[1,2]}}]}`))
+	if err == nil {
+		t.Fatal("expected error for Point geometry")
+	}
+}
+
+func TestBundledAdminBoundaries(t *testing.T) {
+	provinces, districts, err := parseAdminBoundaries(bundledAdminBoundaries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	adminBoundaries.Lock()
+	adminBoundaries.provinces, adminBoundaries.districts, adminBoundaries.loaded = provinces, districts, true
+	adminBoundaries.Unlock()
+	resetLocationCache()
+	defer func() {
+		adminBoundaries.Lock()
+		adminBoundaries.provinces, adminBoundaries.districts, adminBoundaries.loaded = nil, nil, false
+		adminBoundaries.Unlock()
+		resetLocationCache()
+	}()
+
+	cases := []struct {
+		lat, lon float64
+		want     AdminLocation
+	}{
+		{18.79, 98.98, AdminLocation{Province: "เชียงใหม่", District: "เมืองเชียงใหม่", Region: regionNorth, Country: "TH", Source: "approximate"}},
+		{19.91, 99.84, AdminLocation{Province: "เชียงราย", District: "เมืองเชียงราย", Region: regionNorth, Country: "TH", Source: "approximate"}},
+		{13.75, 100.50, AdminLocation{Province: "กรุงเทพมหานคร", Region: regionCentral, Country: "TH", Source: "approximate"}},
+		{7.01, 100.47, AdminLocation{Province: "สงขลา", Region: regionSouth, Country: "TH", Source: "approximate"}},
+		{17.97, 102.61, AdminLocation{Province: "Vientiane Capital", Region: regionLaos, Country: "LA", Source: "approximate"}},
+		{20.28, 100.42, AdminLocation{Province: "Bokeo", Region: regionLaos, Country: "LA", Source: "approximate"}},
+	}
+	for _, tc := range cases {
+		if got, ok := LocateByCoordinates(tc.lat, tc.lon); !ok || got != tc.want {
+			t.Errorf("LocateByCoordinates(%v, %v) = %+v, %v; want %+v", tc.lat, tc.lon, got, ok, tc.want)
+		}
+	}
+	// พื้นที่โดยประมาณ: จังหวัดใน address ที่ต่างออกไปชนะ อำเภอใน address ชนะเมื่อจังหวัดตรงกัน
+	got := ResolveLocation(18.79, 98.98, "ต.ในเมือง อ.เมืองลำพูน จ.ลำพูน")
+	if got.Province != "ลำพูน" || got.District != "เมืองลำพูน" || got.Source != "address" {
+		t.Errorf("address province should win over an approximate area: %+v", got)
+	}
+	got = ResolveLocation(18.79, 98.98, "ต.ศรีภูมิ อ.สารภี จ.เชียงใหม่")
+	if got.Province != "เชียงใหม่" || got.District != "สารภี" || got.Subdistrict != "ศรีภูมิ" || got.Source != "approximate" {
+		t.Errorf("address district should win inside the same province: %+v", got)
+	}
+
+	// ทะเลอันดามันอยู่นอกทุกขอบเขต
+	if got, ok := LocateByCoordinates(8.0, 97.5); ok {
+		t.Errorf("Andaman Sea resolved to %+v", got)
+	}
+}
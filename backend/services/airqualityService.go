@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"log"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
@@ -55,9 +56,13 @@ func filterSensorData(datas []models.SensorData) []models.SensorData {
 
 // FetchAndStoreData ดึงข้อมูลจาก API แล้วเก็บลง DB (ด้วย Raw SQL ผ่าน GORM)
 func FetchAndStoreData(apiURL string) {
+	run := newIngestRun()
+	defer run.finish()
+
 	resp, err := http.Get(apiURL)
 	if err != nil {
 		log.Printf("Error fetching API: %v", err)
+		run.fail("fetch")
 		return
 	}
 	defer resp.Body.Close()
@@ -65,31 +70,54 @@ func FetchAndStoreData(apiURL string) {
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		log.Printf("Error reading response body: %v", err)
+		run.fail("read")
 		return
 	}
 
 	var apiResp models.APIResponse
 	if err := json.Unmarshal(body, &apiResp); err != nil {
 		log.Printf("Error unmarshaling JSON: %v", err)
+		run.fail("decode")
 		return
 	}
 
+	received := len(apiResp.Response)
 	apiResp.Response = filterSensorData(apiResp.Response)
+	run.rows("received", received)
+	run.rows("invalid", received-len(apiResp.Response))
 
 	// วนลูป insert ข้อมูลลงในตาราง sensor_data
+	var inserted []ReadingEvent
 	for _, data := range apiResp.Response {
+		// ตรวจคุณภาพข้อมูลเทียบกับค่าล่าสุดของอุปกรณ์เดียวกันก่อนบันทึก
+		recent, err := loadRecentPM25(data.DVID, data.Timestamp)
+		if err != nil {
+			log.Printf("Error loading recent readings for device %s: %v", data.DVID, err)
+		}
+		data.QualityFlags = computeQualityFlags(data, recent)
+		if data.QualityFlags != 0 {
+			log.Printf("Flagged reading for device %s: %v", data.DVID, QualityFlagNames(data.QualityFlags))
+			run.rows("flagged", 1)
+		}
+		if err := calibrateReading(&data); err != nil {
+			log.Printf("Error calibrating reading for device %s: %v", data.DVID, err)
+		}
+		applyReadingLocation(&data)
+
 		// GORM: Exec() จะคืนค่าเป็น *gorm.DB
 		result := database.DB.Exec(`
 			INSERT INTO sensor_data (
 				dvid, deviceid, status, latitude, longitude, place, address, model,
 				deploydate, contactname, contactphone, note, ddate, dtime, timestamp,
 				av24h, av12h, av6h, av3h, av1h, pm25, pm10, pm100, aqi,
-				temperature, humidity, pres, color, trend
+				temperature, humidity, pres, color, trend, quality_flags,
+				pm25_calibrated, pm10_calibrated, province, district, subdistrict, region
 			) VALUES (
 				?, ?, ?, ?, ?, ?, ?, ?,
 				?, ?, ?, ?, ?, ?, ?,
 				?, ?, ?, ?, ?, ?, ?, ?, ?,
-				?, ?, ?, ?, ?
+				?, ?, ?, ?, ?, ?,
+				?, ?, ?, ?, ?, ?
 			)
 		`,
 			data.DVID, data.DeviceID, data.Status, data.Latitude, data.Longitude,
@@ -97,21 +125,43 @@ func FetchAndStoreData(apiURL string) {
 			data.ContactPhone, data.Note, data.DDate, data.DTime, data.Timestamp,
 			data.Av24h, data.Av12h, data.Av6h, data.Av3h, data.Av1h, data.PM25,
 			data.PM10, data.PM100, data.AQI, data.Temperature, data.Humidity,
-			data.Pres, data.Color, data.Trend,
+			data.Pres, data.Color, data.Trend, data.QualityFlags,
+			data.PM25Calibrated, data.PM10Calibrated, data.Province, data.District, data.Subdistrict, data.Region,
 		)
 
 		if result.Error != nil {
 			log.Printf("Error inserting data: %v", result.Error)
+			run.fail("insert")
+			run.rows("failed", 1)
+			continue
 		}
+		inserted = append(inserted, newReadingEvent(data))
+	}
+
+	// แต่ละ INSERT commit แล้ว จึง publish ให้ stream ได้ (ไม่ส่งแถวที่บันทึกไม่สำเร็จ)
+	run.rows("inserted", len(inserted))
+	Readings.Publish(inserted)
+	if err := RefreshStationSnapshots(); err != nil {
+		log.Printf("Error refreshing station snapshots: %v", err)
+		run.fail("snapshot")
+	}
+
+	if err := EvaluateAlertRules(); err != nil {
+		log.Printf("Error evaluating alert rules: %v", err)
+		run.fail("alert_rules")
+	}
+	if err := EvaluateSubscriptions(); err != nil {
+		log.Printf("Error evaluating alert subscriptions: %v", err)
+		run.fail("subscriptions")
 	}
 }
 
 // GetAirQuality24Hours ค่าเฉลี่ย 24 ชั่วโมง พร้อมระบุช่วงเวลาที่ใช้ดึงข้อมูล
-func GetAirQuality24Hours() (map[string]interface{}, error) {
+func GetAirQuality24Hours(opts DataOptions) (map[string]interface{}, error) {
 	query := `
-        SELECT address, AVG(pm25) AS avg_pm25, AVG(pm10) AS avg_pm10
+        SELECT address, AVG(` + opts.metricExpr("pm25") + `) AS avg_pm25, AVG(` + opts.metricExpr("pm10") + `) AS avg_pm10
         FROM sensor_data
-        WHERE to_timestamp(timestamp/1000) BETWEEN now() - interval '24 hours' AND now()
+        WHERE to_timestamp(timestamp/1000) BETWEEN now() - interval '24 hours' AND now()` + opts.qualityClause() + `
         GROUP BY address
     `
 	data, err := queryAirQuality(query)
@@ -132,11 +182,11 @@ func GetAirQuality24Hours() (map[string]interface{}, error) {
 }
 
 // GetAirQualityOneMonth ค่าเฉลี่ย 1 เดือน พร้อมระบุช่วงเวลาที่ใช้ดึงข้อมูล
-func GetAirQualityOneMonth() (map[string]interface{}, error) {
+func GetAirQualityOneMonth(opts DataOptions) (map[string]interface{}, error) {
 	query := `
-        SELECT address, AVG(pm25) AS avg_pm25, AVG(pm10) AS avg_pm10
+        SELECT address, AVG(` + opts.metricExpr("pm25") + `) AS avg_pm25, AVG(` + opts.metricExpr("pm10") + `) AS avg_pm10
         FROM sensor_data
-        WHERE to_timestamp(timestamp/1000) BETWEEN now() - interval '1 month' AND now()
+        WHERE to_timestamp(timestamp/1000) BETWEEN now() - interval '1 month' AND now()` + opts.qualityClause() + `
         GROUP BY address
     `
 	data, err := queryAirQuality(query)
@@ -158,11 +208,11 @@ func GetAirQualityOneMonth() (map[string]interface{}, error) {
 }
 
 // GetAirQualityThreeMonths ค่าเฉลี่ย 3 เดือน พร้อมระบุช่วงเวลาที่ใช้ดึงข้อมูล
-func GetAirQualityThreeMonths() (map[string]interface{}, error) {
+func GetAirQualityThreeMonths(opts DataOptions) (map[string]interface{}, error) {
 	query := `
-        SELECT address, AVG(pm25) AS avg_pm25, AVG(pm10) AS avg_pm10
+        SELECT address, AVG(` + opts.metricExpr("pm25") + `) AS avg_pm25, AVG(` + opts.metricExpr("pm10") + `) AS avg_pm10
         FROM sensor_data
-        WHERE to_timestamp(timestamp/1000) BETWEEN now() - interval '3 months' AND now()
+        WHERE to_timestamp(timestamp/1000) BETWEEN now() - interval '3 months' AND now()` + opts.qualityClause() + `
         GROUP BY address
     `
 	data, err := queryAirQuality(query)
@@ -183,11 +233,11 @@ func GetAirQualityThreeMonths() (map[string]interface{}, error) {
 }
 
 // GetAirQualityOneYear ค่าเฉลี่ย 1 ปี พร้อมระบุช่วงเวลาที่ใช้ดึงข้อมูล
-func GetAirQualityOneYear() (map[string]interface{}, error) {
+func GetAirQualityOneYear(opts DataOptions) (map[string]interface{}, error) {
 	query := `
-        SELECT address, AVG(pm25) AS avg_pm25, AVG(pm10) AS avg_pm10
+        SELECT address, AVG(` + opts.metricExpr("pm25") + `) AS avg_pm25, AVG(` + opts.metricExpr("pm10") + `) AS avg_pm10
         FROM sensor_data
-        WHERE to_timestamp(timestamp/1000) BETWEEN now() - interval '1 year' AND now()
+        WHERE to_timestamp(timestamp/1000) BETWEEN now() - interval '1 year' AND now()` + opts.qualityClause() + `
         GROUP BY address
     `
 	data, err := queryAirQuality(query)
@@ -208,11 +258,11 @@ func GetAirQualityOneYear() (map[string]interface{}, error) {
 }
 
 // GetAirQualityOneWeek ค่าเฉลี่ย 1 สัปดาห์ พร้อมระบุช่วงเวลาที่ใช้ดึงข้อมูล
-func GetAirQualityOneWeek() (map[string]interface{}, error) {
+func GetAirQualityOneWeek(opts DataOptions) (map[string]interface{}, error) {
 	query := `
-        SELECT address, AVG(pm25) AS avg_pm25, AVG(pm10) AS avg_pm10
+        SELECT address, AVG(` + opts.metricExpr("pm25") + `) AS avg_pm25, AVG(` + opts.metricExpr("pm10") + `) AS avg_pm10
         FROM sensor_data
-        WHERE to_timestamp(timestamp/1000) BETWEEN now() - interval '7 days' AND now()
+        WHERE to_timestamp(timestamp/1000) BETWEEN now() - interval '7 days' AND now()` + opts.qualityClause() + `
         GROUP BY address
     `
 	data, err := queryAirQuality(query)
@@ -263,73 +313,127 @@ func queryAirQuality(query string) ([]map[string]interface{}, error) {
 }
 
 // GetProvinceAveragePM25 คำนวณค่าเฉลี่ย PM2.5 ของแต่ละจังหวัด
-func GetProvinceAveragePM25() ([]map[string]interface{}, error) {
-	query := `
-        WITH province_data AS (
-            SELECT 
-                split_part(address, ' ', array_length(string_to_array(address, ' '), 1)) as province,
-                pm25
-            FROM sensor_data
-            WHERE to_timestamp(timestamp/1000) BETWEEN now() - interval '24 hours' AND now()
-        )
-        SELECT 
-            province,
-            ROUND(AVG(pm25)::numeric, 2) as avg_pm25,
-            COUNT(*) as station_count
-        FROM province_data
-        WHERE province != ''
-        GROUP BY province
-        ORDER BY avg_pm25 DESC
-    `
+func GetProvinceAveragePM25(opts DataOptions) ([]map[string]interface{}, error) {
+	return getAreaAveragePM25("province", opts)
+}
 
-	rows, err := database.DB.Raw(query).Rows()
-	if err != nil {
+// GetRegionAveragePM25 คำนวณค่าเฉลี่ย PM2.5 ของแต่ละภาค (ผลมี key "region" แทน "province")
+func GetRegionAveragePM25(opts DataOptions) ([]map[string]interface{}, error) {
+	return getAreaAveragePM25("region", opts)
+}
+
+// getAreaAveragePM25: col ต้องเป็น "province" หรือ "region" เท่านั้น
+func getAreaAveragePM25(col string, opts DataOptions) ([]map[string]interface{}, error) {
+	var rows []areaAverageRow
+	if err := database.DB.Raw(areaAverageQuery(opts)).Scan(&rows).Error; err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return groupAreaAverages(rows, col), nil
+}
 
-	results := []map[string]interface{}{}
+// areaAverageQuery รวมข้อมูล 24 ชั่วโมงล่าสุดราย (province, region) แล้วให้ groupAreaAverages รวมต่อใน Go
+// เพื่อให้แถวที่ยังไม่มี region ใช้ภาคของจังหวัดแบบเดียวกับกราฟ
+func areaAverageQuery(opts DataOptions) string {
+	return `
+        SELECT 
+            COALESCE(province, '') AS province,
+            COALESCE(region, '') AS region,
+            SUM(` + opts.metricExpr("pm25") + `) AS pm25_sum,
+            COUNT(` + opts.metricExpr("pm25") + `) AS pm25_count,
+            COUNT(*) AS row_count
+        FROM sensor_data
+        WHERE to_timestamp(timestamp/1000) BETWEEN now() - interval '24 hours' AND now()` + opts.qualityClause() + `
+        GROUP BY 1, 2
+    `
+}
 
-	for rows.Next() {
-		var province string
-		var avgPM25 float64
-		var stationCount int
+type areaAverageRow struct {
+	Province  string
+	Region    string
+	PM25Sum   float64 `gorm:"column:pm25_sum"`
+	PM25Count int     `gorm:"column:pm25_count"`
+	RowCount  int
+}
 
-		if err := rows.Scan(&province, &avgPM25, &stationCount); err != nil {
-			return nil, err
+// groupAreaAverages รวมแถวตาม col (province | region) เรียงค่าเฉลี่ยมากไปน้อย
+func groupAreaAverages(rows []areaAverageRow, col string) []map[string]interface{} {
+	type areaAgg struct {
+		sum          float64
+		valued, rows int
+	}
+	aggs := make(map[string]*areaAgg)
+	for _, row := range rows {
+		area := row.Province
+		if col == "region" {
+			area = row.Region
+			if area == "" {
+				area = regionForProvince(row.Province, "")
+			}
 		}
-
-		data := map[string]interface{}{
-			"province":      province,
-			"avg_pm25":      avgPM25,
-			"station_count": stationCount,
+		if area == "" {
+			continue
 		}
-		results = append(results, data)
+		agg := aggs[area]
+		if agg == nil {
+			agg = &areaAgg{}
+			aggs[area] = agg
+		}
+		agg.sum += row.PM25Sum
+		agg.valued += row.PM25Count
+		agg.rows += row.RowCount
 	}
 
-	return results, nil
+	results := make([]map[string]interface{}, 0, len(aggs))
+	for area, agg := range aggs {
+		avg := 0.0
+		if agg.valued > 0 {
+			avg = roundToTwoDecimals(agg.sum / float64(agg.valued))
+		}
+		results = append(results, map[string]interface{}{
+			col:             area,
+			"avg_pm25":      avg,
+			"station_count": agg.rows,
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		ai, aj := results[i]["avg_pm25"].(float64), results[j]["avg_pm25"].(float64)
+		if ai != aj {
+			return ai > aj
+		}
+		return results[i][col].(string) < results[j][col].(string)
+	})
+	return results
 }
 
-// GetSensorData7Days ดึงข้อมูล sensor_data ย้อนหลัง 7 วัน
-func GetSensorData7Days() ([]models.SensorData, error) {
-	var sensorData []models.SensorData
-
-	query := `
+const sensorData7DaysQuery = `
 		SELECT *
 		FROM sensor_data
 		WHERE to_timestamp(timestamp/1000) BETWEEN now() - interval '7 days' AND now()
 		ORDER BY timestamp DESC
 	`
 
-	if err := database.DB.Raw(query).Scan(&sensorData).Error; err != nil {
-		return nil, err
+// EachSensorData7Days ส่งข้อมูล sensor_data ย้อนหลัง 7 วันให้ fn ทีละแถว โดยไม่โหลดทั้งหมดเข้าหน่วยความจำ
+func EachSensorData7Days(fn func(models.SensorData) error) error {
+	rows, err := database.DB.Raw(sensorData7DaysQuery).Rows()
+	if err != nil {
+		return err
 	}
+	defer rows.Close()
 
-	return sensorData, nil
+	for rows.Next() {
+		var data models.SensorData
+		if err := database.DB.ScanRows(rows, &data); err != nil {
+			return err
+		}
+		if err := fn(data); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
 }
 
 // GetAirQualityOneYearSeriesByPlace : ข้อมูลรายวัน 1 ปี สำหรับ heatmap (filter ด้วย place)
-func GetAirQualityOneYearSeriesByPlace(place string) (map[string]interface{}, error) {
+func GetAirQualityOneYearSeriesByPlace(place string, opts DataOptions) (map[string]interface{}, error) {
 	place = strings.TrimSpace(place)
 	if place == "" {
 		return nil, fmt.Errorf("place is required")
@@ -342,10 +446,10 @@ func GetAirQualityOneYearSeriesByPlace(place string) (map[string]interface{}, er
 		WITH t AS (
 			SELECT 
 				(to_timestamp(timestamp/1000) AT TIME ZONE 'Asia/Bangkok') AS ts,
-				NULLIF(pm25,0) AS pm25,
-				NULLIF(pm10,0) AS pm10
+				NULLIF(` + opts.metricExpr("pm25") + `,0) AS pm25,
+				NULLIF(` + opts.metricExpr("pm10") + `,0) AS pm10
 		FROM sensor_data
-		WHERE place ILIKE ? AND to_timestamp(timestamp/1000) BETWEEN ? AND ?
+		WHERE place ILIKE ? AND to_timestamp(timestamp/1000) BETWEEN ? AND ?` + opts.qualityClause() + `
 	)
 	SELECT 
 			date_trunc('day', ts) AS bucket,
@@ -395,8 +499,8 @@ func GetAirQualityOneYearSeriesByPlace(place string) (map[string]interface{}, er
 
 }
 
-// GetAirQualityOneYearSeriesByProvince: daily buckets for last 1 year filtered by province (address ILIKE)
-func GetAirQualityOneYearSeriesByProvince(province string) (map[string]interface{}, error) {
+// GetAirQualityOneYearSeriesByProvince: daily buckets for last 1 year filtered by the resolved province column
+func GetAirQualityOneYearSeriesByProvince(province string, opts DataOptions) (map[string]interface{}, error) {
 	if province == "" {
 		return nil, fmt.Errorf("province is required")
 	}
@@ -408,10 +512,10 @@ func GetAirQualityOneYearSeriesByProvince(province string) (map[string]interface
         WITH t AS (
             SELECT 
                 (to_timestamp(timestamp/1000) AT TIME ZONE 'Asia/Bangkok') AS ts,
-                NULLIF(pm25,0) AS pm25,
-                NULLIF(pm10,0) AS pm10
+                NULLIF(` + opts.metricExpr("pm25") + `,0) AS pm25,
+                NULLIF(` + opts.metricExpr("pm10") + `,0) AS pm10
             FROM sensor_data
-            WHERE address ILIKE ? AND to_timestamp(timestamp/1000) BETWEEN ? AND ?
+            WHERE province = ? AND to_timestamp(timestamp/1000) BETWEEN ? AND ?` + opts.qualityClause() + `
         )
         SELECT 
             date_trunc('day', ts) AS bucket,
@@ -423,7 +527,7 @@ func GetAirQualityOneYearSeriesByProvince(province string) (map[string]interface
         ORDER BY bucket ASC;
     `
 
-	rows, err := database.DB.Raw(query, "%"+province+"%", from, now).Rows()
+	rows, err := database.DB.Raw(query, normalizeProvince(province), from, now).Rows()
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAreaAverageQuery(t *testing.T) {
+	query := areaAverageQuery(DataOptions{})
+	for _, want := range []string{"COALESCE(region, '') AS region", "GROUP BY 1, 2", "quality_flags = 0"} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query missing %q:\n%s", want, query)
+		}
+	}
+}
+
+func TestGroupAreaAverages(t *testing.T) {
+	rows := []areaAverageRow{
+		{Province: "เชียงใหม่", Region: regionNorth, PM25Sum: 400, PM25Count: 10, RowCount: 10},
+		// ยังไม่มี region ใช้ภาคของจังหวัด
+		{Province: "ลำพูน", PM25Sum: 100, PM25Count: 10, RowCount: 12},
+		{Province: "Bokeo", Region: regionLaos, PM25Sum: 300, PM25Count: 5, RowCount: 5},
+		{Province: "Laos", PM25Sum: 300, PM25Count: 5, RowCount: 5},
+		{PM25Sum: 999, PM25Count: 1, RowCount: 1},
+	}
+
+	regions := groupAreaAverages(rows, "region")
+	want := []map[string]interface{}{
+		{"region": regionLaos, "avg_pm25": 60.0, "station_count": 10},
+		{"region": regionNorth, "avg_pm25": 25.0, "station_count": 22},
+	}
+	if !reflect.DeepEqual(regions, want) {
+		t.Fatalf("regions = %v, want %v", regions, want)
+	}
+
+	provinces := groupAreaAverages(rows, "province")
+	var names []string
+	for _, row := range provinces {
+		names = append(names, row["province"].(string))
+	}
+	if strings.Join(names, ",") != "Bokeo,Laos,เชียงใหม่,ลำพูน" {
+		t.Fatalf("province order = %v", names)
+	}
+}
@@ -0,0 +1,367 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"yakkaw_dashboard/database"
+	"yakkaw_dashboard/models"
+
+	"gorm.io/gorm"
+)
+
+const defaultAlertCategory = "Air Quality"
+
+type AlertRuleService struct {
+	DB *gorm.DB
+}
+
+// NewAlertRuleService creates a new AlertRuleService instance
+func NewAlertRuleService(db *gorm.DB) *AlertRuleService {
+	return &AlertRuleService{DB: db}
+}
+
+// ListRules returns all alert rules
+func (s *AlertRuleService) ListRules() ([]models.AlertRule, error) {
+	var rules []models.AlertRule
+	if err := s.DB.Order("id ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// GetRule fetches a single alert rule by ID
+func (s *AlertRuleService) GetRule(id uint) (models.AlertRule, error) {
+	var rule models.AlertRule
+	if err := s.DB.First(&rule, id).Error; err != nil {
+		return models.AlertRule{}, err
+	}
+	return rule, nil
+}
+
+// CreateRule validates and stores a new alert rule
+func (s *AlertRuleService) CreateRule(rule models.AlertRule) (models.AlertRule, error) {
+	if err := validateAlertRule(&rule); err != nil {
+		return models.AlertRule{}, err
+	}
+	if err := s.DB.Create(&rule).Error; err != nil {
+		return models.AlertRule{}, err
+	}
+	return rule, nil
+}
+
+// UpdateRule replaces an alert rule and resets its state so it is evaluated from scratch
+func (s *AlertRuleService) UpdateRule(id uint, input models.AlertRule) (models.AlertRule, error) {
+	var rule models.AlertRule
+	if err := s.DB.First(&rule, id).Error; err != nil {
+		return models.AlertRule{}, err
+	}
+
+	input.Model = rule.Model
+	if err := validateAlertRule(&input); err != nil {
+		return models.AlertRule{}, err
+	}
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&input).Error; err != nil {
+			return err
+		}
+		return tx.Where("rule_id = ?", id).Delete(&models.AlertRuleState{}).Error
+	})
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	return input, nil
+}
+
+// DeleteRule removes an alert rule together with its state
+func (s *AlertRuleService) DeleteRule(id uint) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.AlertRule{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Where("rule_id = ?", id).Delete(&models.AlertRuleState{}).Error
+	})
+}
+
+func validateAlertRule(rule *models.AlertRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" {
+		return errors.New("name is required")
+	}
+
+	rule.Metric = strings.ToLower(strings.TrimSpace(rule.Metric))
+	if rule.Metric == "" {
+		rule.Metric = "pm25"
+	}
+	if _, _, err := rankingColumns(rule.Metric, "address"); err != nil {
+		return err
+	}
+
+	rule.ScopeType = strings.ToLower(strings.TrimSpace(rule.ScopeType))
+	switch rule.ScopeType {
+	case "province", "place", "device":
+	default:
+		return errors.New("scope_type must be province, place or device")
+	}
+	rule.ScopeValue = strings.TrimSpace(rule.ScopeValue)
+
+	rule.Aggregation = strings.ToLower(strings.TrimSpace(rule.Aggregation))
+	switch rule.Aggregation {
+	case "":
+		rule.Aggregation = "avg"
+	case "avg", "max":
+	default:
+		return errors.New("aggregation must be avg or max")
+	}
+
+	if rule.WindowMinutes <= 0 {
+		rule.WindowMinutes = 60
+	}
+	if rule.WindowMinutes > 7*24*60 {
+		return errors.New("window_minutes must not exceed 7 days")
+	}
+	if rule.Hysteresis < 0 {
+		return errors.New("hysteresis must not be negative")
+	}
+
+	if (rule.QuietStart == "") != (rule.QuietEnd == "") {
+		return errors.New("quiet_start and quiet_end must be set together")
+	}
+	for _, v := range []string{rule.QuietStart, rule.QuietEnd} {
+		if v == "" {
+			continue
+		}
+		if _, err := time.Parse("15:04", v); err != nil {
+			return errors.New("quiet hours must be in HH:MM format")
+		}
+	}
+
+	if strings.TrimSpace(rule.Category) == "" {
+		rule.Category = defaultAlertCategory
+	}
+	return nil
+}
+
+// EvaluateAlertRules ประเมินทุก rule ที่เปิดใช้งานอยู่ (เรียกหลัง ingest แต่ละรอบ)
+func EvaluateAlertRules() error {
+	var rules []models.AlertRule
+	if err := database.DB.Where("enabled = ?", true).Find(&rules).Error; err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	bands, err := GetAllColorRanges()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, rule := range rules {
+		if inQuietHours(rule, now) {
+			continue
+		}
+		if err := evaluateAlertRule(rule, bands, now); err != nil {
+			log.Printf("Error evaluating alert rule %d (%s): %v", rule.ID, rule.Name, err)
+		}
+	}
+	return nil
+}
+
+func evaluateAlertRule(rule models.AlertRule, bands []models.ColorRange, now time.Time) error {
+	values, err := aggregateRuleScope(rule, now)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range values {
+		var state models.AlertRuleState
+		err := database.DB.Where("rule_id = ? AND scope_key = ?", rule.ID, key).First(&state).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			state = models.AlertRuleState{RuleID: rule.ID, ScopeKey: key, BandIndex: -1}
+		} else if err != nil {
+			return err
+		}
+
+		if notification := nextAlertNotification(rule, &state, bands, key, value); notification != nil {
+			if err := PublishNotification(notification); err != nil {
+				return err
+			}
+			notifiedAt := now
+			state.LastNotifiedAt = &notifiedAt
+		}
+
+		state.LastValue = value
+		if err := database.DB.Save(&state).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nextAlertNotification เลื่อน state ตามค่าใหม่และคืน notification เมื่อเข้าสู่ band ที่แย่ลง
+// หรือเมื่อดีขึ้นพ้นระยะ hysteresis แล้ว (คืน nil เมื่อไม่ต้องแจ้ง)
+func nextAlertNotification(rule models.AlertRule, state *models.AlertRuleState, bands []models.ColorRange, key string, value float64) *models.Notification {
+	band := colorBandIndex(bands, value)
+	metric := strings.ToUpper(rule.Metric)
+
+	if value >= rule.Threshold && band > state.BandIndex {
+		state.Active = true
+		state.BandIndex = band
+		return &models.Notification{
+			Title:    fmt.Sprintf("%s: %s alert in %s", rule.Name, metric, key),
+			Message:  fmt.Sprintf("%s in %s reached %.1f (%s over %d min)%s", metric, key, value, rule.Aggregation, rule.WindowMinutes, bandSuffix(bands, band)),
+			Category: rule.Category,
+			Icon:     rule.Icon,
+		}
+	}
+
+	if !state.Active {
+		return nil
+	}
+
+	// ต้องลดลงต่ำกว่าขอบล่างของ band เดิมเกินค่า hysteresis จึงถือว่าดีขึ้นจริง
+	improved := colorBandIndex(bands, value+rule.Hysteresis)
+	switch {
+	case value+rule.Hysteresis < rule.Threshold:
+		state.Active = false
+		state.BandIndex = -1
+		return &models.Notification{
+			Title:    fmt.Sprintf("%s: %s back to normal in %s", rule.Name, metric, key),
+			Message:  fmt.Sprintf("%s in %s dropped to %.1f, below the alert threshold of %.1f", metric, key, value, rule.Threshold),
+			Category: rule.Category,
+			Icon:     rule.ImprovedIcon,
+		}
+	case improved < state.BandIndex:
+		state.BandIndex = improved
+		return &models.Notification{
+			Title:    fmt.Sprintf("%s: %s improving in %s", rule.Name, metric, key),
+			Message:  fmt.Sprintf("%s in %s improved to %.1f%s", metric, key, value, bandSuffix(bands, improved)),
+			Category: rule.Category,
+			Icon:     rule.ImprovedIcon,
+		}
+	}
+	return nil
+}
+
+// colorBandIndex คืนตำแหน่งของ ColorRange (เรียงตาม Min) ที่ครอบคลุมค่า ค่าที่เกินทุกช่วงจะอยู่ใน band สุดท้าย
+func colorBandIndex(bands []models.ColorRange, value float64) int {
+	index := 0
+	for i, band := range bands {
+		if value >= float64(band.Min) {
+			index = i
+		}
+	}
+	return index
+}
+
+func bandSuffix(bands []models.ColorRange, index int) string {
+	if index < 0 || index >= len(bands) {
+		return ""
+	}
+	return fmt.Sprintf(" [band %d–%d, %s]", bands[index].Min, bands[index].Max, bands[index].Color)
+}
+
+// aggregateRuleScope คำนวณค่า avg/max ในช่วง window ของทุก key ใน scope ของ rule
+func aggregateRuleScope(rule models.AlertRule, now time.Time) (map[string]float64, error) {
+	metricCol, _, err := rankingColumns(rule.Metric, "address")
+	if err != nil {
+		return nil, err
+	}
+	expr := DataOptions{}.metricExpr(metricCol)
+
+	// province ใช้ค่าที่ resolve ไว้ตอน ingest (ชุดเดียวกับ ranking/chart)
+	keyCol := map[string]string{"province": "province", "place": "place", "device": "dvid"}[rule.ScopeType]
+	args := []interface{}{now.Add(-time.Duration(rule.WindowMinutes) * time.Minute).UnixMilli()}
+	filter := ""
+	if rule.ScopeValue != "" {
+		value := rule.ScopeValue
+		if rule.ScopeType == "province" {
+			value = normalizeProvince(value)
+		}
+		filter = " AND " + keyCol + " = ?"
+		args = append(args, value)
+	}
+
+	type aggRow struct {
+		Key    string
+		SumVal float64
+		Cnt    int
+		MaxVal float64
+	}
+	var rows []aggRow
+	if err := database.DB.Raw(`
+		SELECT `+keyCol+` AS key,
+		       SUM(`+expr+`) AS sum_val,
+		       COUNT(`+expr+`) AS cnt,
+		       MAX(`+expr+`) AS max_val
+		FROM sensor_data
+		WHERE timestamp >= ?`+DataOptions{}.qualityClause()+filter+`
+		GROUP BY `+keyCol, args...).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	type agg struct {
+		sum   float64
+		count int
+		max   float64
+	}
+	grouped := make(map[string]*agg)
+	for _, row := range rows {
+		key := row.Key
+		if key == "" || row.Cnt == 0 {
+			continue
+		}
+		g := grouped[key]
+		if g == nil {
+			g = &agg{max: row.MaxVal}
+			grouped[key] = g
+		}
+		g.sum += row.SumVal
+		g.count += row.Cnt
+		if row.MaxVal > g.max {
+			g.max = row.MaxVal
+		}
+	}
+
+	values := make(map[string]float64, len(grouped))
+	for key, g := range grouped {
+		if rule.Aggregation == "max" {
+			values[key] = roundToTwoDecimals(g.max)
+		} else {
+			values[key] = roundToTwoDecimals(g.sum / float64(g.count))
+		}
+	}
+	return values, nil
+}
+
+// inQuietHours ตรวจว่าเวลาปัจจุบัน (Asia/Bangkok) อยู่ในช่วงงดแจ้งเตือนของ rule หรือไม่ (รองรับช่วงข้ามเที่ยงคืน)
+// ระหว่าง quiet hours จะไม่ประเมิน rule เลย การเปลี่ยน band จึงถูกแจ้งหลังหมดช่วงหากยังเป็นอยู่
+func inQuietHours(rule models.AlertRule, now time.Time) bool {
+	if rule.QuietStart == "" || rule.QuietEnd == "" {
+		return false
+	}
+	start, err1 := time.Parse("15:04", rule.QuietStart)
+	end, err2 := time.Parse("15:04", rule.QuietEnd)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+
+	local := now.In(time.FixedZone("Asia/Bangkok", 7*3600))
+	minute := local.Hour()*60 + local.Minute()
+	startMin := start.Hour()*60 + start.Minute()
+	endMin := end.Hour()*60 + end.Minute()
+	if startMin <= endMin {
+		return minute >= startMin && minute < endMin
+	}
+	return minute >= startMin || minute < endMin
+}
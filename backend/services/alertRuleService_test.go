@@ -0,0 +1,54 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"yakkaw_dashboard/models"
+)
+
+func TestNextAlertNotification(t *testing.T) {
+	bands := []models.ColorRange{{Min: 0, Max: 25}, {Min: 26, Max: 50}, {Min: 51, Max: 100}, {Min: 101, Max: 500}}
+	rule := models.AlertRule{Name: "PM2.5", Metric: "pm25", Threshold: 26, Hysteresis: 5, WindowMinutes: 60, Aggregation: "avg"}
+	state := models.AlertRuleState{BandIndex: -1}
+
+	steps := []struct {
+		value  float64
+		notify bool
+		band   int
+		active bool
+	}{
+		{20, false, -1, false},
+		{30, true, 1, true},  // เข้า band ที่แย่กว่าเกณฑ์
+		{60, true, 2, true},  // แย่ลงอีก
+		{55, false, 2, true}, // ยังอยู่ใน band เดิม
+		{48, false, 2, true}, // ลดลงแต่ยังไม่พ้น hysteresis
+		{44, true, 1, true},  // ดีขึ้นพ้น hysteresis
+		{24, false, 1, true}, // ต่ำกว่าเกณฑ์แต่ยังไม่พ้น hysteresis
+		{18, true, -1, false},
+	}
+
+	for i, step := range steps {
+		n := nextAlertNotification(rule, &state, bands, "Chiang Mai", step.value)
+		if (n != nil) != step.notify || state.BandIndex != step.band || state.Active != step.active {
+			t.Fatalf("step %d (%.0f): notify=%v band=%d active=%v", i, step.value, n != nil, state.BandIndex, state.Active)
+		}
+	}
+}
+
+func TestInQuietHours(t *testing.T) {
+	rule := models.AlertRule{QuietStart: "22:00", QuietEnd: "06:00"}
+	at := func(hour int) time.Time {
+		return time.Date(2024, 1, 1, hour, 30, 0, 0, time.FixedZone("Asia/Bangkok", 7*3600))
+	}
+
+	if !inQuietHours(rule, at(23)) || !inQuietHours(rule, at(5)) {
+		t.Fatal("expected night hours to be quiet")
+	}
+	if inQuietHours(rule, at(12)) {
+		t.Fatal("expected noon not to be quiet")
+	}
+	if inQuietHours(models.AlertRule{}, at(23)) {
+		t.Fatal("rule without quiet hours should never be quiet")
+	}
+}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"yakkaw_dashboard/database"
+	"yakkaw_dashboard/models"
+
+	"gorm.io/gorm"
+)
+
+// calibratedColumns คือ metric ที่รองรับการ calibrate และ column ที่เก็บค่าหลัง calibrate
+var calibratedColumns = map[string]string{
+	"pm25": "pm25_calibrated",
+	"pm10": "pm10_calibrated",
+}
+
+type CalibrationService struct {
+	DB *gorm.DB
+}
+
+// NewCalibrationService creates a new CalibrationService instance
+func NewCalibrationService(db *gorm.DB) *CalibrationService {
+	return &CalibrationService{DB: db}
+}
+
+// ListProfiles returns calibration profiles of a device ordered by validity
+func (s *CalibrationService) ListProfiles(dvid string) ([]models.CalibrationProfile, error) {
+	var profiles []models.CalibrationProfile
+	if err := s.DB.Where("dv_id = ?", dvid).Order("metric ASC").Order("valid_from ASC").Find(&profiles).Error; err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// CreateProfile validates and stores a new calibration profile for the device
+func (s *CalibrationService) CreateProfile(dvid string, profile models.CalibrationProfile) (models.CalibrationProfile, error) {
+	profile.DVID = dvid
+	if err := validateCalibrationProfile(&profile); err != nil {
+		return models.CalibrationProfile{}, err
+	}
+	if err := s.DB.Create(&profile).Error; err != nil {
+		return models.CalibrationProfile{}, err
+	}
+	return profile, nil
+}
+
+// UpdateProfile replaces coefficients/validity of an existing profile of the device
+func (s *CalibrationService) UpdateProfile(dvid string, id uint, input models.CalibrationProfile) (models.CalibrationProfile, error) {
+	var profile models.CalibrationProfile
+	if err := s.DB.Where("dv_id = ?", dvid).First(&profile, id).Error; err != nil {
+		return models.CalibrationProfile{}, err
+	}
+
+	profile.Metric = input.Metric
+	profile.Method = input.Method
+	profile.Coefficients = input.Coefficients
+	profile.HumidityCoef = input.HumidityCoef
+	profile.ValidFrom = input.ValidFrom
+	profile.ValidTo = input.ValidTo
+	profile.Note = input.Note
+	if err := validateCalibrationProfile(&profile); err != nil {
+		return models.CalibrationProfile{}, err
+	}
+
+	if err := s.DB.Save(&profile).Error; err != nil {
+		return models.CalibrationProfile{}, err
+	}
+	return profile, nil
+}
+
+// DeleteProfile removes a profile of the device
+func (s *CalibrationService) DeleteProfile(dvid string, id uint) error {
+	result := s.DB.Where("dv_id = ?", dvid).Delete(&models.CalibrationProfile{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RecomputeHistory คำนวณค่า calibrated ของ sensor_data ทั้งหมดของอุปกรณ์ใหม่ตาม profile ปัจจุบัน
+// ช่วงที่ไม่มี profile ครอบคลุมจะถูกตั้งเป็น NULL (ใช้ค่า raw)
+func (s *CalibrationService) RecomputeHistory(dvid string) (int64, error) {
+	profiles, err := s.ListProfiles(dvid)
+	if err != nil {
+		return 0, err
+	}
+
+	var affected int64
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`UPDATE sensor_data SET pm25_calibrated = NULL, pm10_calibrated = NULL WHERE dvid = ?`, dvid).Error; err != nil {
+			return err
+		}
+
+		// profile ที่เริ่มทีหลังจะทับช่วงที่ซ้อนกันของ profile ก่อนหน้า เหมือนตอน ingest
+		for _, profile := range profiles {
+			expr, args := calibrationSQLExpr(profile)
+			query := `UPDATE sensor_data SET ` + calibratedColumns[profile.Metric] + ` = ` + expr + `
+				WHERE dvid = ? AND timestamp >= ?`
+			args = append(args, dvid, profile.ValidFrom.UnixMilli())
+			if profile.ValidTo != nil {
+				query += ` AND timestamp < ?`
+				args = append(args, profile.ValidTo.UnixMilli())
+			}
+			result := tx.Exec(query, args...)
+			if result.Error != nil {
+				return result.Error
+			}
+			affected += result.RowsAffected
+		}
+		return nil
+	})
+	return affected, err
+}
+
+// RecomputeHistoryAsync runs RecomputeHistory in the background after a profile change
+func (s *CalibrationService) RecomputeHistoryAsync(dvid string) {
+	go func() {
+		rows, err := s.RecomputeHistory(dvid)
+		if err != nil {
+			log.Printf("Error recomputing calibration for device %s: %v", dvid, err)
+			return
+		}
+		log.Printf("Recomputed calibration for device %s (%d rows)", dvid, rows)
+	}()
+}
+
+func validateCalibrationProfile(profile *models.CalibrationProfile) error {
+	profile.Metric = strings.ToLower(strings.TrimSpace(profile.Metric))
+	if _, ok := calibratedColumns[profile.Metric]; !ok {
+		return errors.New("metric must be pm25 or pm10")
+	}
+
+	profile.Method = strings.ToLower(strings.TrimSpace(profile.Method))
+	switch profile.Method {
+	case "", "linear":
+		profile.Method = "linear"
+		if len(profile.Coefficients) != 2 {
+			return errors.New("linear calibration needs exactly 2 coefficients [intercept, slope]")
+		}
+	case "polynomial":
+		if len(profile.Coefficients) < 1 || len(profile.Coefficients) > 4 {
+			return errors.New("polynomial calibration needs 1 to 4 coefficients")
+		}
+	default:
+		return errors.New("method must be linear or polynomial")
+	}
+
+	for _, c := range append(append([]float64(nil), profile.Coefficients...), profile.HumidityCoef) {
+		if math.IsNaN(c) || math.IsInf(c, 0) {
+			return errors.New("coefficients must be finite numbers")
+		}
+	}
+
+	if profile.ValidFrom.IsZero() {
+		return errors.New("valid_from is required")
+	}
+	if profile.ValidTo != nil && !profile.ValidTo.After(profile.ValidFrom) {
+		return errors.New("valid_to must be after valid_from")
+	}
+	return nil
+}
+
+// calibrationSQLExpr สร้าง expression SQL ของสมการ calibrate (ใช้ bind parameter ทั้งหมด)
+// ทุก bind ต้อง cast เป็น double precision ไม่เช่นนั้น Postgres อนุมานเป็น bigint ตาม column ข้างเคียง
+// และตัดทศนิยมของสัมประสิทธิ์ทิ้ง ผลปัดเป็นทศนิยม 2 ตำแหน่งเหมือน applyCalibration
+func calibrationSQLExpr(profile models.CalibrationProfile) (string, []interface{}) {
+	var terms []string
+	var args []interface{}
+	for i, c := range profile.Coefficients {
+		switch i {
+		case 0:
+			terms = append(terms, "?::double precision")
+		case 1:
+			terms = append(terms, "?::double precision * "+profile.Metric)
+		default:
+			terms = append(terms, fmt.Sprintf("?::double precision * power(%s, %d)", profile.Metric, i))
+		}
+		args = append(args, c)
+	}
+	if profile.HumidityCoef != 0 {
+		terms = append(terms, "?::double precision * humidity")
+		args = append(args, profile.HumidityCoef)
+	}
+	return "ROUND(GREATEST(0, " + strings.Join(terms, " + ") + ")::numeric, 2)", args
+}
+
+// applyCalibration คำนวณสมการ calibrate ของ profile กับค่าดิบหนึ่งค่า
+func applyCalibration(profile models.CalibrationProfile, raw, humidity float64) float64 {
+	value := 0.0
+	pow := 1.0
+	for _, c := range profile.Coefficients {
+		value += c * pow
+		pow *= raw
+	}
+	value += profile.HumidityCoef * humidity
+	return roundToTwoDecimals(math.Max(0, value))
+}
+
+// calibrateReading เติมค่า pm25_calibrated/pm10_calibrated ตาม profile ที่มีผล ณ เวลาที่วัด
+func calibrateReading(data *models.SensorData) error {
+	var profiles []models.CalibrationProfile
+	readingTime := time.UnixMilli(data.Timestamp)
+	if err := database.DB.
+		Where("dv_id = ? AND valid_from <= ? AND (valid_to IS NULL OR valid_to > ?)", data.DVID, readingTime, readingTime).
+		Order("valid_from ASC").
+		Find(&profiles).Error; err != nil {
+		return err
+	}
+
+	for _, profile := range profiles {
+		switch profile.Metric {
+		case "pm25":
+			v := applyCalibration(profile, float64(data.PM25), float64(data.Humidity))
+			data.PM25Calibrated = &v
+		case "pm10":
+			v := applyCalibration(profile, float64(data.PM10), float64(data.Humidity))
+			data.PM10Calibrated = &v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"yakkaw_dashboard/models"
+)
+
+func TestApplyCalibration(t *testing.T) {
+	cases := []struct {
+		name          string
+		profile       models.CalibrationProfile
+		raw, humidity float64
+		want          float64
+	}{
+		{"linear", models.CalibrationProfile{Coefficients: []float64{1.5, 0.52}}, 40, 0, 22.3},
+		{"polynomial", models.CalibrationProfile{Coefficients: []float64{1, 0.5, 0.01}}, 10, 0, 7},
+		{"humidity term", models.CalibrationProfile{Coefficients: []float64{0, 1}, HumidityCoef: -0.1}, 30, 80, 22},
+		{"rounded to two decimals", models.CalibrationProfile{Coefficients: []float64{0, 0.333}}, 10, 0, 3.33},
+		{"clamped at zero", models.CalibrationProfile{Coefficients: []float64{-5, 0.5}}, 4, 0, 0},
+	}
+	for _, tc := range cases {
+		if got := applyCalibration(tc.profile, tc.raw, tc.humidity); got != tc.want {
+			t.Errorf("%s: applyCalibration = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestCalibrationSQLExpr(t *testing.T) {
+	cases := []struct {
+		profile  models.CalibrationProfile
+		wantExpr string
+		wantArgs []interface{}
+	}{
+		{
+			models.CalibrationProfile{Metric: "pm25", Coefficients: []float64{1.5, 0.52}},
+			"ROUND(GREATEST(0, ?::double precision + ?::double precision * pm25)::numeric, 2)",
+			[]interface{}{1.5, 0.52},
+		},
+		{
+			models.CalibrationProfile{Metric: "pm10", Coefficients: []float64{1, 0.5, 0.01}, HumidityCoef: -0.1},
+			"ROUND(GREATEST(0, ?::double precision + ?::double precision * pm10 + ?::double precision * power(pm10, 2) + ?::double precision * humidity)::numeric, 2)",
+			[]interface{}{1.0, 0.5, 0.01, -0.1},
+		},
+	}
+	for _, tc := range cases {
+		expr, args := calibrationSQLExpr(tc.profile)
+		if expr != tc.wantExpr || !reflect.DeepEqual(args, tc.wantArgs) {
+			t.Errorf("calibrationSQLExpr(%v) = %q %v, want %q %v", tc.profile.Coefficients, expr, args, tc.wantExpr, tc.wantArgs)
+		}
+	}
+}
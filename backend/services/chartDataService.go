@@ -6,6 +6,7 @@ import (
 	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"yakkaw_dashboard/database"
 	"yakkaw_dashboard/models"
@@ -13,64 +14,38 @@ import (
 
 // GetChartData ดึงข้อมูลและ aggregate ค่า pm25 ตามช่วงเวลาที่ระบุ
 // หาก query parameter "province" ถูกส่งมา จะทำการ filter โดยใช้ชื่อจังหวัดที่ trim แล้วเปรียบเทียบแบบเท่ากัน
-// แต่ถ้าไม่ส่ง จะดึงข้อมูลของทุกจังหวัดโดย extract จังหวัดจาก address (โดยใช้ split_part)
-func GetChartData(rangeType string, province string, metric string) (models.ChartData, error) {
+// แต่ถ้าไม่ส่ง จะดึงข้อมูลของทุกจังหวัดโดยใช้ province ที่ resolve ไว้ตอน ingest
+func GetChartData(rangeType string, province string, metric string, opts DataOptions) (models.ChartData, error) {
+	return getGroupedChartData(rangeType, "province", province, "", metric, opts)
+}
+
+// GetRegionChartData เหมือน GetChartData แต่หนึ่ง dataset ต่อหนึ่งภาค (region ว่าง = ทุกภาค)
+func GetRegionChartData(rangeType string, region string, metric string, opts DataOptions) (models.ChartData, error) {
+	return getGroupedChartData(rangeType, "region", region, "", metric, opts)
+}
+
+// GetDistrictChartData คืนหนึ่ง dataset ต่ออำเภอ (label "อำเภอ, จังหวัด") กรองด้วยจังหวัดและ/หรืออำเภอได้
+func GetDistrictChartData(rangeType string, province string, district string, metric string, opts DataOptions) (models.ChartData, error) {
+	return getGroupedChartData(rangeType, "district", province, district, metric, opts)
+}
+
+func getGroupedChartData(rangeType, group, area, district, metric string, opts DataOptions) (models.ChartData, error) {
 	var chartData models.ChartData
 	startTimeMs, endTimeMs := getTimeRange(rangeType)
-	provinceFilter := normalizeProvince(province)
 
 	metricCol := selectMetricColumn(metric)
 
-	query, args := buildHourlyQuery(rangeType, provinceFilter, province, startTimeMs, endTimeMs, metricCol)
-
-	type resultRow struct {
-		Address   string
-		TimeLabel time.Time
-		AvgPM25   float64
-	}
-	var results []resultRow
+	provinceFilter, regionFilter, filterClause, filterArgs := chartFilter(group, area, district)
+	query, args := buildHourlyQuery(rangeType, filterClause, filterArgs, startTimeMs, endTimeMs, metricCol, opts)
 
+	var results []hourlyAreaRow
 	if err := database.DB.Raw(query, args...).Scan(&results).Error; err != nil {
 		return chartData, err
 	}
 
 	hourLabels := buildHourLabels()
 	chartData.Labels = hourLabels
-
-	type valueAgg struct {
-		sum   float64
-		count int
-	}
-
-	provinceAgg := make(map[string]map[int]*valueAgg)
-	for _, row := range results {
-		provinceName := deriveProvince(row.Address)
-		if provinceName == "" {
-			continue
-		}
-		bucket := row.TimeLabel.Local().Hour()
-
-		if _, ok := provinceAgg[provinceName]; !ok {
-			provinceAgg[provinceName] = make(map[int]*valueAgg)
-		}
-		agg := provinceAgg[provinceName][bucket]
-		if agg == nil {
-			agg = &valueAgg{}
-			provinceAgg[provinceName][bucket] = agg
-		}
-		agg.sum += row.AvgPM25
-		agg.count++
-	}
-
-	provinceValues := make(map[string]map[int]float64)
-	for prov, buckets := range provinceAgg {
-		provinceValues[prov] = make(map[int]float64)
-		for key, agg := range buckets {
-			if agg != nil && agg.count > 0 {
-				provinceValues[prov][key] = roundToTwoDecimals(agg.sum / float64(agg.count))
-			}
-		}
-	}
+	provinceValues := groupHourlyRows(results, group)
 
 	if provinceFilter != "" {
 		dataSlice, found := selectProvinceData(provinceValues, provinceFilter)
@@ -78,7 +53,13 @@ func GetChartData(rangeType string, province string, metric string) (models.Char
 			dataSlice = make([]float64, len(hourLabels))
 		}
 		chartData.Datasets = []models.DatasetChart{
-			{Label: province, Data: dataSlice},
+			{Label: area, Data: dataSlice},
+		}
+		return chartData, nil
+	}
+	if regionFilter != "" {
+		chartData.Datasets = []models.DatasetChart{
+			{Label: regionFilter, Data: buildHourlyData(provinceValues[regionFilter])},
 		}
 		return chartData, nil
 	}
@@ -106,20 +87,24 @@ func roundToTwoDecimals(value float64) float64 {
 	return math.Round(value*100) / 100
 }
 
-func buildHourlyQuery(rangeType, normalizedProvince, rawProvince string, startMs, endMs int64, metricCol string) (string, []interface{}) {
-	filters := buildAddressFilters(rawProvince, normalizedProvince)
+func buildHourlyQuery(rangeType, areaClause string, areaArgs []interface{}, startMs, endMs int64, metricCol string, opts DataOptions) (string, []interface{}) {
 	var args []interface{}
 	args = append(args, startMs, endMs)
+	args = append(args, areaArgs...)
 
-	filterClause := buildFilterClause(filters, &args)
+	filterClause := areaClause + opts.qualityClause()
+	metricExpr := opts.metricExpr(metricCol)
 
 	if rangeType == "Today" {
 		return `
 			WITH hourly_data AS (
 				SELECT 
 					address,
+					province,
+					district,
+					region,
 					date_trunc('hour', to_timestamp(timestamp/1000)) as time_label,
-					` + metricCol + `,
+					` + metricExpr + ` as metric_val,
 					ROW_NUMBER() OVER (
 						PARTITION BY address, date_trunc('hour', to_timestamp(timestamp/1000))
 						ORDER BY timestamp DESC
@@ -129,8 +114,11 @@ func buildHourlyQuery(rangeType, normalizedProvince, rawProvince string, startMs
 			)
 			SELECT 
 				address,
+				province,
+				district,
+				region,
 				time_label,
-				` + metricCol + ` as avg_pm25
+				metric_val as avg_pm25
 			FROM hourly_data
 			WHERE rn = 1
 			ORDER BY address, time_label
@@ -138,26 +126,108 @@ func buildHourlyQuery(rangeType, normalizedProvince, rawProvince string, startMs
 	}
 
 	return `
-		SELECT address,
+		SELECT address, province, district, region,
 		       date_trunc('hour', to_timestamp(timestamp/1000)) as time_label,
-		       AVG(` + metricCol + `) as avg_pm25
+		       AVG(` + metricExpr + `) as avg_pm25
 		FROM sensor_data
 		WHERE timestamp BETWEEN ? AND ?` + filterClause + `
-		GROUP BY address, time_label
+		GROUP BY address, province, district, region, time_label
 		ORDER BY address, time_label
 	`, args
 }
 
-func buildFilterClause(filters []string, args *[]interface{}) string {
-	if len(filters) == 0 {
+// chartFilter คืนเงื่อนไข SQL ของ getGroupedChartData ตาม group (province | region | district)
+// ภาคกรองด้วย region ที่เก็บไว้ หรือ province ของภาคนั้นเมื่อแถวยังไม่มี region (เหมือนตอนจัดกลุ่ม)
+func chartFilter(group, area, district string) (provinceFilter, regionFilter, clause string, args []interface{}) {
+	switch group {
+	case "region":
+		if area != "" {
+			regionFilter = canonicalRegion(area)
+			clause, args = " AND region = ?", []interface{}{regionFilter}
+			if provinces := provincesInRegion(regionFilter); len(provinces) > 0 {
+				clause = " AND (region = ? OR (COALESCE(region, '') = '' AND province IN ?))"
+				args = append(args, provinces)
+			}
+		}
+	case "district":
+		if area != "" {
+			clause, args = " AND province = ?", []interface{}{normalizeProvince(area)}
+		}
+		if district = strings.TrimSpace(district); district != "" {
+			clause += " AND district = ?"
+			args = append(args, district)
+		}
+	default:
+		if provinceFilter = normalizeProvince(area); provinceFilter != "" {
+			clause, args = " AND province = ?", []interface{}{provinceFilter}
+		}
+	}
+	return provinceFilter, regionFilter, clause, args
+}
+
+type hourlyAreaRow struct {
+	Address   string
+	Province  string
+	District  string
+	Region    string
+	TimeLabel time.Time
+	AvgPM25   float64
+}
+
+// hourlyGroupKey คืนชื่อกลุ่มของแถว ("" = ไม่นับ) แถวที่ยังไม่มี region ใช้ภาคของจังหวัดแทน
+func hourlyGroupKey(row hourlyAreaRow, group string) string {
+	switch group {
+	case "region":
+		if row.Region != "" {
+			return row.Region
+		}
+		return regionForProvince(storedProvince(row.Province, row.Address), "")
+	case "district":
+		if row.District != "" && row.Province != "" {
+			return row.District + ", " + row.Province
+		}
 		return ""
 	}
-	var parts []string
-	for _, f := range filters {
-		parts = append(parts, "address ILIKE ?")
-		*args = append(*args, f)
+	return storedProvince(row.Province, row.Address)
+}
+
+// groupHourlyRows เฉลี่ยค่ารายชั่วโมงของทุกที่อยู่ในกลุ่มเดียวกัน: กลุ่ม → ชั่วโมง (0–23) → ค่าเฉลี่ย
+func groupHourlyRows(rows []hourlyAreaRow, group string) map[string]map[int]float64 {
+	type valueAgg struct {
+		sum   float64
+		count int
+	}
+
+	groupAgg := make(map[string]map[int]*valueAgg)
+	for _, row := range rows {
+		key := hourlyGroupKey(row, group)
+		if key == "" {
+			continue
+		}
+		bucket := row.TimeLabel.Local().Hour()
+
+		if _, ok := groupAgg[key]; !ok {
+			groupAgg[key] = make(map[int]*valueAgg)
+		}
+		agg := groupAgg[key][bucket]
+		if agg == nil {
+			agg = &valueAgg{}
+			groupAgg[key][bucket] = agg
+		}
+		agg.sum += row.AvgPM25
+		agg.count++
+	}
+
+	values := make(map[string]map[int]float64)
+	for key, buckets := range groupAgg {
+		values[key] = make(map[int]float64)
+		for hour, agg := range buckets {
+			if agg.count > 0 {
+				values[key][hour] = roundToTwoDecimals(agg.sum / float64(agg.count))
+			}
+		}
 	}
-	return " AND (" + strings.Join(parts, " OR ") + ")"
+	return values
 }
 
 func buildHourLabels() []string {
@@ -253,6 +323,89 @@ func selectProvinceData(provinceMap map[string]map[int]float64, target string) (
 	return nil, false
 }
 
+// AddressParts คือส่วนประกอบของที่อยู่แบบไทย (กรุงเทพฯ: แขวง = Subdistrict, เขต = District)
+type AddressParts struct {
+	Subdistrict string `json:"subdistrict,omitempty"`
+	District    string `json:"district,omitempty"`
+	Province    string `json:"province,omitempty"`
+}
+
+// คำนำหน้าที่ตามด้วยชื่อตำบล/อำเภอ (ติดกันหรือเว้นวรรคก็ได้)
+// แขวง/เขต ใช้เฉพาะกรุงเทพฯ ที่อื่น "แขวง" คือจังหวัดของลาว และ "เขต" มักเป็น "เขตอุตสาหกรรม" ฯลฯ
+var (
+	subdistrictPrefixes        = []string{"ตำบล", "ต.", "tambon"}
+	districtPrefixes           = []string{"กิ่งอำเภอ", "อำเภอ", "อ.", "amphoe"}
+	bangkokSubdistrictPrefixes = append([]string{"แขวง", "khwaeng"}, subdistrictPrefixes...)
+	bangkokDistrictPrefixes    = append([]string{"เขต", "khet"}, districtPrefixes...)
+)
+
+// parseThaiAddress แยกตำบล/อำเภอ/จังหวัดจาก address เช่น "ต.วาวี อ.แม่สรวย จ.เชียงราย"
+func parseThaiAddress(address string) AddressParts {
+	parts := AddressParts{Province: deriveProvince(address)}
+	subPrefixes, distPrefixes := subdistrictPrefixes, districtPrefixes
+	if parts.Province == "กรุงเทพมหานคร" {
+		subPrefixes, distPrefixes = bangkokSubdistrictPrefixes, bangkokDistrictPrefixes
+	}
+	segments := strings.FieldsFunc(address, func(r rune) bool {
+		return r == ',' || r == ';' || r == '|'
+	})
+	for _, segment := range segments {
+		tokens := strings.Fields(segment)
+		if parts.Subdistrict == "" {
+			parts.Subdistrict = addressComponent(tokens, subPrefixes)
+		}
+		if parts.District == "" {
+			parts.District = addressComponent(tokens, distPrefixes)
+		}
+	}
+
+	// "อ.เมือง" มีทุกจังหวัด ใช้ชื่อเต็มแบบทางการ เช่น "เมืองเชียงราย"
+	if parts.District == "เมือง" && parts.Province != "" && parts.Province != "กรุงเทพมหานคร" {
+		parts.District += parts.Province
+	}
+	return parts
+}
+
+// addressComponent หาชื่อหลังคำนำหน้าใน segment หนึ่ง ชื่อภาษาไทยจบที่ช่องว่าง
+// ส่วนชื่อภาษาอังกฤษ (เช่น "Amphoe Mae Suai") ยาวถึงคำนำหน้าถัดไปหรือท้าย segment
+func addressComponent(tokens []string, prefixes []string) string {
+	for i, token := range tokens {
+		lower := strings.ToLower(token)
+		for _, prefix := range prefixes {
+			if !strings.HasPrefix(lower, prefix) {
+				continue
+			}
+			name := token[len(prefix):]
+			if name == "" && i+1 < len(tokens) {
+				if prefix[0] < utf8.RuneSelf {
+					name = strings.Join(tokensUntilPrefix(tokens[i+1:]), " ")
+				} else {
+					name = tokens[i+1]
+				}
+			}
+			name = strings.Trim(name, " .()")
+			if name != "" && (name == "เมือง" || !isGenericProvinceWord(name)) {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+func tokensUntilPrefix(tokens []string) []string {
+	for i, token := range tokens {
+		lower := strings.ToLower(token)
+		for _, prefixes := range [][]string{bangkokSubdistrictPrefixes, bangkokDistrictPrefixes} {
+			for _, prefix := range prefixes {
+				if prefix[0] < utf8.RuneSelf && lower == prefix {
+					return tokens[:i]
+				}
+			}
+		}
+	}
+	return tokens
+}
+
 func deriveProvince(address string) string {
 	addr := strings.TrimSpace(address)
 	if addr == "" {
@@ -332,7 +485,7 @@ func selectMetricColumn(metric string) string {
 }
 
 // GetHeatmapOneYearDaily returns daily averages for the past year for a given province and metric.
-func GetHeatmapOneYearDaily(province string, metric string) (models.ChartData, error) {
+func GetHeatmapOneYearDaily(province string, metric string, opts DataOptions) (models.ChartData, error) {
 	var chartData models.ChartData
 	if province == "" {
 		return chartData, nil
@@ -343,10 +496,10 @@ func GetHeatmapOneYearDaily(province string, metric string) (models.ChartData, e
 	baseQuery := `
         SELECT 
             date_trunc('day', (to_timestamp(timestamp/1000) AT TIME ZONE 'Asia/Bangkok')) as time_label,
-            AVG(` + col + `) as avg_val
+            AVG(` + opts.metricExpr(col) + `) as avg_val
         FROM sensor_data
         WHERE (to_timestamp(timestamp/1000) AT TIME ZONE 'Asia/Bangkok') BETWEEN (now() AT TIME ZONE 'Asia/Bangkok') - interval '1 year' AND (now() AT TIME ZONE 'Asia/Bangkok')
-          AND (address ILIKE ? OR place ILIKE ?)
+          AND province = ?` + opts.qualityClause() + `
         GROUP BY time_label
         ORDER BY time_label ASC
     `
@@ -357,7 +510,7 @@ func GetHeatmapOneYearDaily(province string, metric string) (models.ChartData, e
 	}
 	var results []resultRow
 
-	if err := database.DB.Raw(baseQuery, "%"+province+"%", "%"+province+"%").Scan(&results).Error; err != nil {
+	if err := database.DB.Raw(baseQuery, normalizeProvince(province)).Scan(&results).Error; err != nil {
 		return chartData, err
 	}
 
@@ -386,7 +539,7 @@ func canonicalizeProvince(name string) string {
 	lower := strings.ToLower(trimmed)
 	lowerNoSpace := strings.ReplaceAll(lower, " ", "")
 
-	for _, entry := range provinceAliasData {
+	for _, entry := range provinceEntries() {
 		canonicalLower := strings.ToLower(entry.canonical)
 		canonicalNoSpace := strings.ReplaceAll(canonicalLower, " ", "")
 		if lower == canonicalLower || lowerNoSpace == canonicalNoSpace {
@@ -417,135 +570,3 @@ func isGenericProvinceWord(word string) bool {
 		return false
 	}
 }
-
-func buildAddressFilters(rawProvince, normalizedProvince string) []string {
-	candidates := make(map[string]string)
-	addCandidate := func(s string) {
-		trimmed := strings.TrimSpace(s)
-		if trimmed == "" {
-			return
-		}
-		key := strings.ToLower(trimmed)
-		if _, exists := candidates[key]; !exists {
-			candidates[key] = trimmed
-		}
-	}
-
-	if rawProvince != "" {
-		addCandidate(rawProvince)
-	}
-	if normalizedProvince != "" {
-		addCandidate(normalizedProvince)
-	}
-
-	canonical := canonicalizeProvince(normalizedProvince)
-	if canonical != "" {
-		addCandidate(canonical)
-		for _, alias := range getProvinceAliases(canonical) {
-			addCandidate(alias)
-		}
-	}
-
-	var filters []string
-	for _, val := range candidates {
-		filters = append(filters, "%"+val+"%")
-	}
-	sort.Strings(filters)
-	return filters
-}
-
-func getProvinceAliases(canonical string) []string {
-	if canonical == "" {
-		return nil
-	}
-	for _, entry := range provinceAliasData {
-		if strings.EqualFold(entry.canonical, canonical) {
-			return entry.aliases
-		}
-	}
-	return nil
-}
-
-var provinceAliasData = []struct {
-	canonical string
-	aliases   []string
-}{
-	{canonical: "กรุงเทพมหานคร", aliases: []string{"bangkok", "bangkok province", "bangkok metropolis", "bangkok metropolitan", "krung thep", "krungthep", "bkk"}},
-	{canonical: "กระบี่", aliases: []string{"krabi"}},
-	{canonical: "กาญจนบุรี", aliases: []string{"kanchanaburi"}},
-	{canonical: "กาฬสินธุ์", aliases: []string{"kalasin"}},
-	{canonical: "กำแพงเพชร", aliases: []string{"kamphaeng phet", "kamphaengphet"}},
-	{canonical: "ขอนแก่น", aliases: []string{"khon kaen", "khonkaen"}},
-	{canonical: "จันทบุรี", aliases: []string{"chanthaburi"}},
-	{canonical: "ฉะเชิงเทรา", aliases: []string{"chachoengsao"}},
-	{canonical: "ชลบุรี", aliases: []string{"chon buri", "chonburi"}},
-	{canonical: "ชัยนาท", aliases: []string{"chai nat", "chainat"}},
-	{canonical: "ชัยภูมิ", aliases: []string{"chaiyaphum"}},
-	{canonical: "ชุมพร", aliases: []string{"chumphon"}},
-	{canonical: "เชียงราย", aliases: []string{"chiang rai", "chiangrai"}},
-	{canonical: "เชียงใหม่", aliases: []string{"chiang mai", "chiangmai"}},
-	{canonical: "ตรัง", aliases: []string{"trang"}},
-	{canonical: "ตราด", aliases: []string{"trat"}},
-	{canonical: "ตาก", aliases: []string{"tak"}},
-	{canonical: "นครนายก", aliases: []string{"nakhon nayok", "nakhonnayok"}},
-	{canonical: "นครปฐม", aliases: []string{"nakhon pathom", "nakhonpathom"}},
-	{canonical: "นครพนม", aliases: []string{"nakhon phanom", "nakhonphanom"}},
-	{canonical: "นครราชสีมา", aliases: []string{"nakhon ratchasima", "nakhonratchasima", "korat"}},
-	{canonical: "นครศรีธรรมราช", aliases: []string{"nakhon si thammarat", "nakhonsithammarat"}},
-	{canonical: "นครสวรรค์", aliases: []string{"nakhon sawan", "nakhonsawan"}},
-	{canonical: "นนทบุรี", aliases: []string{"nonthaburi"}},
-	{canonical: "นราธิวาส", aliases: []string{"narathiwat"}},
-	{canonical: "น่าน", aliases: []string{"nan"}},
-	{canonical: "บึงกาฬ", aliases: []string{"bueng kan", "buengkan"}},
-	{canonical: "บุรีรัมย์", aliases: []string{"buri ram", "buriram"}},
-	{canonical: "ปทุมธานี", aliases: []string{"pathum thani", "pathumthani"}},
-	{canonical: "ประจวบคีรีขันธ์", aliases: []string{"prachuap khiri khan", "prachuapkhirikhan"}},
-	{canonical: "ปราจีนบุรี", aliases: []string{"prachin buri", "prachinburi"}},
-	{canonical: "ปัตตานี", aliases: []string{"pattani"}},
-	{canonical: "พระนครศรีอยุธยา", aliases: []string{"phra nakhon si ayutthaya", "phrana khonsiayutthaya", "ayutthaya", "phra nakhon si ayutaya"}},
-	{canonical: "พะเยา", aliases: []string{"phayao"}},
-	{canonical: "พังงา", aliases: []string{"phang nga", "phangnga"}},
-	{canonical: "พัทลุง", aliases: []string{"phatthalung"}},
-	{canonical: "พิจิตร", aliases: []string{"phichit"}},
-	{canonical: "พิษณุโลก", aliases: []string{"phitsanulok"}},
-	{canonical: "เพชรบุรี", aliases: []string{"phetchaburi"}},
-	{canonical: "เพชรบูรณ์", aliases: []string{"phetchabun"}},
-	{canonical: "แพร่", aliases: []string{"phrae"}},
-	{canonical: "ภูเก็ต", aliases: []string{"phuket"}},
-	{canonical: "มหาสารคาม", aliases: []string{"maha sarakham", "mahasarakham"}},
-	{canonical: "มุกดาหาร", aliases: []string{"mukdahan"}},
-	{canonical: "แม่ฮ่องสอน", aliases: []string{"mae hong son", "maehongson"}},
-	{canonical: "ยะลา", aliases: []string{"yala"}},
-	{canonical: "ยโสธร", aliases: []string{"yasothon"}},
-	{canonical: "ร้อยเอ็ด", aliases: []string{"roi et", "roiet"}},
-	{canonical: "ระนอง", aliases: []string{"ranong"}},
-	{canonical: "ระยอง", aliases: []string{"rayong"}},
-	{canonical: "ราชบุรี", aliases: []string{"ratchaburi"}},
-	{canonical: "ลพบุรี", aliases: []string{"lop buri", "lopburi"}},
-	{canonical: "ลำปาง", aliases: []string{"lampang"}},
-	{canonical: "ลำพูน", aliases: []string{"lamphun"}},
-	{canonical: "เลย", aliases: []string{"loei"}},
-	{canonical: "ศรีสะเกษ", aliases: []string{"sisaket", "si sa ket", "si saket"}},
-	{canonical: "สกลนคร", aliases: []string{"sakon nakhon", "sakonnakhon"}},
-	{canonical: "สงขลา", aliases: []string{"songkhla"}},
-	{canonical: "สตูล", aliases: []string{"satun"}},
-	{canonical: "สมุทรปราการ", aliases: []string{"samut prakan", "samutprakan"}},
-	{canonical: "สมุทรสงคราม", aliases: []string{"samut songkhram", "samutsongkhram"}},
-	{canonical: "สมุทรสาคร", aliases: []string{"samut sakhon", "samutsakhon"}},
-	{canonical: "สระบุรี", aliases: []string{"saraburi"}},
-	{canonical: "สระแก้ว", aliases: []string{"sa kaeo", "sakaeo"}},
-	{canonical: "สิงห์บุรี", aliases: []string{"sing buri", "singburi"}},
-	{canonical: "สุโขทัย", aliases: []string{"sukhothai"}},
-	{canonical: "สุพรรณบุรี", aliases: []string{"suphan buri", "suphanburi"}},
-	{canonical: "สุราษฎร์ธานี", aliases: []string{"surat thani", "suratthani"}},
-	{canonical: "สุรินทร์", aliases: []string{"surin"}},
-	{canonical: "หนองคาย", aliases: []string{"nong khai", "nongkhai"}},
-	{canonical: "หนองบัวลำภู", aliases: []string{"nong bua lam phu", "nongbualamphu"}},
-	{canonical: "อ่างทอง", aliases: []string{"ang thong", "angthong"}},
-	{canonical: "อำนาจเจริญ", aliases: []string{"amnat charoen", "amnacharoen", "amnat charern"}},
-	{canonical: "อุดรธานี", aliases: []string{"udon thani", "udonthani"}},
-	{canonical: "อุทัยธานี", aliases: []string{"uthai thani", "uthaithani"}},
-	{canonical: "อุตรดิตถ์", aliases: []string{"uttaradit"}},
-	{canonical: "อุบลราชธานี", aliases: []string{"ubon ratchathani", "ubonratchathani"}},
-	{canonical: "Laos", aliases: []string{"laos", "lao", "lao pdr", "ລາວ"}},
-}
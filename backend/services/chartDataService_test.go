@@ -1,6 +1,10 @@
 package services
 
-import "testing"
+import (
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestDeriveProvinceSampleAddresses(t *testing.T) {
 	cases := map[string]string{
@@ -23,3 +27,81 @@ func TestDeriveProvinceSampleAddresses(t *testing.T) {
 	}
 }
 
+func TestParseThaiAddress(t *testing.T) {
+	cases := map[string]AddressParts{
+		"ต.วาวี อ.แม่สรวย จ.เชียงราย":                         {Subdistrict: "วาวี", District: "แม่สรวย", Province: "เชียงราย"},
+		"ตำบล ตะคร้อ อำเภอ ไพศาลี จังหวัดนครสวรรค์":           {Subdistrict: "ตะคร้อ", District: "ไพศาลี", Province: "นครสวรรค์"},
+		"ต.เวียง อ.เมือง จ.เชียงราย":                          {Subdistrict: "เวียง", District: "เมืองเชียงราย", Province: "เชียงราย"},
+		"แขวงป้อมปราบ เขตป้อมปราบศัตรูพ่าย กรุงเทพมหานคร ": {Subdistrict: "ป้อมปราบ", District: "ป้อมปราบศัตรูพ่าย", Province: "กรุงเทพมหานคร"},
+		"Tambon Wawi, Amphoe Mae Suai, Chiang Rai":           {Subdistrict: "Wawi", District: "Mae Suai", Province: "เชียงราย"},
+		"Somewhere, Bangkok City":                            {Province: "กรุงเทพมหานคร"},
+		// แขวง/เขต เป็นแขวง/เขตของกรุงเทพฯ เท่านั้น
+		"บ้านห้วยทราย เมืองห้วยทราย แขวงบ่อแก้ว Laos":      {Province: "Laos"},
+		"เขตอุตสาหกรรมภาคเหนือ ต.บ้านกลาง อ.เมือง จ.ลำพูน": {Subdistrict: "บ้านกลาง", District: "เมืองลำพูน", Province: "ลำพูน"},
+	}
+
+	for input, expected := range cases {
+		if got := parseThaiAddress(input); got != expected {
+			t.Errorf("parseThaiAddress(%q) = %+v, want %+v", input, got, expected)
+		}
+	}
+}
+
+func TestChartFilter(t *testing.T) {
+	province, region, clause, args := chartFilter("region", "North", "")
+	if province != "" || region != regionNorth {
+		t.Fatalf("filters = %q, %q", province, region)
+	}
+	if clause != " AND (region = ? OR (COALESCE(region, '') = '' AND province IN ?))" {
+		t.Fatalf("clause = %q", clause)
+	}
+	provinces, _ := args[1].([]string)
+	if len(args) != 2 || args[0] != regionNorth || !strings.Contains(strings.Join(provinces, ","), "ลำพูน") {
+		t.Fatalf("args = %v", args)
+	}
+
+	if _, region, _, args := chartFilter("region", "Laos", ""); region != regionLaos || len(args) != 2 {
+		t.Fatalf("laos filter = %q %v", region, args)
+	}
+	if _, _, clause, args := chartFilter("region", "ภาคที่ไม่มี", ""); clause != " AND region = ?" || len(args) != 1 {
+		t.Fatalf("unknown region = %q %v", clause, args)
+	}
+	if province, _, clause, args := chartFilter("province", "Chiang Mai", ""); province != "เชียงใหม่" || clause != " AND province = ?" || args[0] != "เชียงใหม่" {
+		t.Fatalf("province filter = %q %q %v", province, clause, args)
+	}
+	if _, _, clause, args := chartFilter("district", "lamphun", " สารภี "); clause != " AND province = ? AND district = ?" || args[0] != "ลำพูน" || args[1] != "สารภี" {
+		t.Fatalf("district filter = %q %v", clause, args)
+	}
+	if _, _, clause, _ := chartFilter("region", "", ""); clause != "" {
+		t.Fatalf("empty region clause = %q", clause)
+	}
+}
+
+func TestGroupHourlyRows(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2024, 3, 1, hour, 0, 0, 0, time.Local) }
+	rows := []hourlyAreaRow{
+		{Address: "ต.สุเทพ อ.เมือง จ.เชียงใหม่", Province: "เชียงใหม่", District: "เมืองเชียงใหม่", Region: regionNorth, TimeLabel: at(8), AvgPM25: 40},
+		{Address: "ต.ยางเนิ้ง อ.สารภี จ.เชียงใหม่", Province: "เชียงใหม่", District: "สารภี", Region: regionNorth, TimeLabel: at(8), AvgPM25: 30},
+		// ข้อมูลเก่าที่ยังไม่มี region ใช้ภาคของจังหวัด
+		{Address: "ต.ในเมือง อ.เมือง จ.ลำพูน", Province: "ลำพูน", TimeLabel: at(8), AvgPM25: 20},
+		// ข้ามพรมแดน: region จาก boundary หรือ province "Laos" จาก address
+		{Address: "Houayxay, Bokeo", Province: "Bokeo", Region: regionLaos, TimeLabel: at(9), AvgPM25: 60},
+		{Address: "Ban Houayxay, Laos", TimeLabel: at(9), AvgPM25: 40},
+		{Address: "unknown", TimeLabel: at(9), AvgPM25: 99},
+	}
+
+	regions := groupHourlyRows(rows, "region")
+	if len(regions) != 2 || regions[regionNorth][8] != 30 || regions[regionLaos][9] != 50 {
+		t.Fatalf("region groups = %v", regions)
+	}
+
+	provinces := groupHourlyRows(rows, "province")
+	if provinces["เชียงใหม่"][8] != 35 || provinces["ลำพูน"][8] != 20 || provinces["Bokeo"][9] != 60 || provinces["Laos"][9] != 40 {
+		t.Fatalf("province groups = %v", provinces)
+	}
+
+	districts := groupHourlyRows(rows, "district")
+	if len(districts) != 2 || districts["สารภี, เชียงใหม่"][8] != 30 || districts["เมืองเชียงใหม่, เชียงใหม่"][8] != 40 {
+		t.Fatalf("district groups = %v", districts)
+	}
+}
@@ -0,0 +1,642 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"log"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"yakkaw_dashboard/config"
+	"yakkaw_dashboard/models"
+	"yakkaw_dashboard/utils"
+)
+
+const (
+	ChartImageDefaultWidth  = 800
+	ChartImageDefaultHeight = 400
+	ChartImageMinSize       = 200
+	ChartImageMaxSize       = 2000
+	chartImageMaxCached     = 128 // จำนวนภาพที่ cache ได้ต่อรอบ ingest
+	chartImageMaxLegend     = 3   // จำนวนแถวสูงสุดของ legend (ที่เกินแสดงเป็น "+N")
+)
+
+// ChartImageOptions ควบคุมการวาดกราฟเป็นภาพ ค่าศูนย์ = ใช้ค่าเริ่มต้น
+type ChartImageOptions struct {
+	Format  string // png | svg
+	Width   int
+	Height  int
+	Title   string // ว่าง = สร้างจาก metric และ Subject
+	Subject string // เช่นจังหวัดหรือช่วงเวลา ใช้ประกอบชื่อกราฟ
+	Metric  string
+	Lang    string              // en | th (ป้ายแกน เดือน legend)
+	Bands   []models.ColorRange // ช่วงสีของ PM2.5 (ใช้เมื่อ metric เป็น pm25)
+}
+
+// chartSeriesColors คือสีของเส้นกราฟแต่ละ dataset (วนซ้ำเมื่อเกิน)
+var chartSeriesColors = []color.NRGBA{
+	{31, 119, 180, 255}, {214, 39, 40, 255}, {44, 160, 44, 255}, {255, 127, 14, 255},
+	{148, 103, 189, 255}, {140, 86, 75, 255}, {227, 119, 194, 255}, {127, 127, 127, 255},
+	{188, 189, 34, 255}, {23, 190, 207, 255},
+}
+
+var (
+	chartTextColor  = color.NRGBA{33, 33, 33, 255}
+	chartMutedColor = color.NRGBA{110, 110, 110, 255}
+	chartGridColor  = color.NRGBA{0, 0, 0, 28}
+	chartEmptyCell  = color.NRGBA{235, 235, 235, 255}
+)
+
+// ErrChartImageTooSmall คืนเมื่อขนาดภาพที่ขอเล็กเกินกว่าจะวาดกราฟได้
+var ErrChartImageTooSmall = errors.New("image too small for this chart")
+
+var chartMonthsTH = []string{"ม.ค.", "ก.พ.", "มี.ค.", "เม.ย.", "พ.ค.", "มิ.ย.", "ก.ค.", "ส.ค.", "ก.ย.", "ต.ค.", "พ.ย.", "ธ.ค."}
+
+// chartImageCache เก็บภาพตาม query และถูกล้างทุกครั้งที่ ingest เสร็จ (ผ่าน RefreshStationSnapshots)
+var chartImageCache = struct {
+	sync.Mutex
+	images map[string][]byte
+}{images: make(map[string][]byte)}
+
+func invalidateChartImageCache() {
+	chartImageCache.Lock()
+	chartImageCache.images = make(map[string][]byte)
+	chartImageCache.Unlock()
+}
+
+// CachedChartImage คืนภาพจาก cache ตาม key หรือเรียก build แล้วเก็บผลไว้
+func CachedChartImage(key string, build func() ([]byte, error)) ([]byte, error) {
+	chartImageCache.Lock()
+	img, ok := chartImageCache.images[key]
+	chartImageCache.Unlock()
+	if ok {
+		return img, nil
+	}
+
+	img, err := build()
+	if err != nil {
+		return nil, err
+	}
+	chartImageCache.Lock()
+	if len(chartImageCache.images) >= chartImageMaxCached {
+		chartImageCache.images = make(map[string][]byte)
+	}
+	chartImageCache.images[key] = img
+	chartImageCache.Unlock()
+	return img, nil
+}
+
+var chartFonts struct {
+	once  sync.Once
+	fonts *utils.FontSet
+}
+
+// loadChartFonts โหลดฟอนต์ตาม CHART_FONT_FILES ครั้งเดียว (ไม่มีฟอนต์ = PNG จะไม่มีข้อความ)
+func loadChartFonts() *utils.FontSet {
+	chartFonts.once.Do(func() {
+		fonts := &utils.FontSet{}
+		for _, path := range config.ChartFontFiles() {
+			data, err := os.ReadFile(path)
+			if err == nil {
+				err = fonts.Add(data)
+			}
+			if err != nil {
+				log.Printf("chart font %s skipped: %v", path, err)
+			}
+		}
+		if fonts.Empty() {
+			log.Printf("warning: no chart font found; PNG charts will be drawn without text (set CHART_FONT_FILES)")
+		}
+		chartFonts.fonts = fonts
+	})
+	return chartFonts.fonts
+}
+
+// chartSurface คือปลายทางการวาดที่ใช้ layout เดียวกันได้ทั้ง PNG และ SVG
+type chartSurface interface {
+	rect(x, y, w, h float64, fill color.NRGBA)
+	polyline(points [][2]float64, width float64, stroke color.NRGBA)
+	dashedLine(x1, y1, x2, y2, width float64, stroke color.NRGBA)
+	circle(x, y, r float64, fill color.NRGBA)
+	// text วาดข้อความโดย baseline อยู่ที่ y, anchor = start | middle | end
+	text(x, y, size float64, anchor string, bold bool, fill color.NRGBA, s string)
+	textWidth(s string, size float64) float64
+	encode() ([]byte, error)
+}
+
+func newChartSurface(opts ChartImageOptions) chartSurface {
+	fonts := loadChartFonts()
+	if opts.Format == "svg" {
+		return newSVGSurface(opts.Width, opts.Height, fonts)
+	}
+	return &pngSurface{canvas: utils.NewCanvas(opts.Width, opts.Height, color.NRGBA{255, 255, 255, 255}, fonts)}
+}
+
+type pngSurface struct {
+	canvas *utils.Canvas
+}
+
+func (p *pngSurface) rect(x, y, w, h float64, fill color.NRGBA) { p.canvas.FillRect(x, y, w, h, fill) }
+func (p *pngSurface) polyline(points [][2]float64, width float64, stroke color.NRGBA) {
+	p.canvas.Polyline(points, width, stroke)
+}
+func (p *pngSurface) dashedLine(x1, y1, x2, y2, width float64, stroke color.NRGBA) {
+	p.canvas.DashedLine(x1, y1, x2, y2, width, 4*width, stroke)
+}
+func (p *pngSurface) circle(x, y, r float64, fill color.NRGBA) { p.canvas.FillCircle(x, y, r, fill) }
+func (p *pngSurface) textWidth(s string, size float64) float64 {
+	return p.canvas.TextWidth(s, size)
+}
+func (p *pngSurface) encode() ([]byte, error) { return p.canvas.PNG() }
+
+func (p *pngSurface) text(x, y, size float64, anchor string, bold bool, fill color.NRGBA, s string) {
+	x = anchorX(x, p.textWidth(s, size), anchor)
+	p.canvas.Text(x, y, size, fill, s)
+	if bold {
+		// ฟอนต์มีน้ำหนักเดียว วาดซ้ำเหลื่อมเล็กน้อยให้ดูหนา
+		p.canvas.Text(x+math.Max(0.5, size/30), y, size, fill, s)
+	}
+}
+
+func anchorX(x, width float64, anchor string) float64 {
+	switch anchor {
+	case "middle":
+		return x - width/2
+	case "end":
+		return x - width
+	}
+	return x
+}
+
+// svgSurface เขียน element ของ SVG ข้อความใช้ฟอนต์ของผู้ดู (มีฟอนต์ไทยสำรองใน font-family)
+type svgSurface struct {
+	b     strings.Builder
+	fonts *utils.FontSet
+}
+
+func newSVGSurface(w, h int, fonts *utils.FontSet) *svgSurface {
+	s := &svgSurface{fonts: fonts}
+	fmt.Fprintf(&s.b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="'DejaVu Sans', 'Noto Sans Thai', Sarabun, Tahoma, sans-serif">`, w, h, w, h)
+	fmt.Fprintf(&s.b, `<rect width="%d" height="%d" fill="#ffffff"/>`, w, h)
+	return s
+}
+
+func svgColor(c color.NRGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func svgOpacity(attr string, c color.NRGBA) string {
+	if c.A == 255 {
+		return ""
+	}
+	return fmt.Sprintf(` %s="%.3g"`, attr, float64(c.A)/255)
+}
+
+func (s *svgSurface) rect(x, y, w, h float64, fill color.NRGBA) {
+	fmt.Fprintf(&s.b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"%s/>`, x, y, w, h, svgColor(fill), svgOpacity("fill-opacity", fill))
+}
+
+func (s *svgSurface) polyline(points [][2]float64, width float64, stroke color.NRGBA) {
+	parts := make([]string, len(points))
+	for i, p := range points {
+		parts[i] = fmt.Sprintf("%.1f,%.1f", p[0], p[1])
+	}
+	fmt.Fprintf(&s.b, `<polyline points="%s" fill="none" stroke="%s"%s stroke-width="%.1f" stroke-linejoin="round" stroke-linecap="round"/>`,
+		strings.Join(parts, " "), svgColor(stroke), svgOpacity("stroke-opacity", stroke), width)
+}
+
+func (s *svgSurface) dashedLine(x1, y1, x2, y2, width float64, stroke color.NRGBA) {
+	fmt.Fprintf(&s.b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s"%s stroke-width="%.1f" stroke-dasharray="%.1f"/>`,
+		x1, y1, x2, y2, svgColor(stroke), svgOpacity("stroke-opacity", stroke), width, 4*width)
+}
+
+func (s *svgSurface) circle(x, y, r float64, fill color.NRGBA) {
+	fmt.Fprintf(&s.b, `<circle cx="%.1f" cy="%.1f" r="%.1f" fill="%s"%s/>`, x, y, r, svgColor(fill), svgOpacity("fill-opacity", fill))
+}
+
+func (s *svgSurface) text(x, y, size float64, anchor string, bold bool, fill color.NRGBA, text string) {
+	if text == "" {
+		return
+	}
+	weight := ""
+	if bold {
+		weight = ` font-weight="bold"`
+	}
+	fmt.Fprintf(&s.b, `<text x="%.1f" y="%.1f" font-size="%.1f" text-anchor="%s"%s fill="%s">%s</text>`,
+		x, y, size, anchor, weight, svgColor(fill), utils.XMLEscape(text))
+}
+
+func (s *svgSurface) textWidth(text string, size float64) float64 {
+	return s.fonts.TextWidth(text, size)
+}
+
+func (s *svgSurface) encode() ([]byte, error) {
+	s.b.WriteString("</svg>")
+	return []byte(s.b.String()), nil
+}
+
+// chartMetricLabel คืนชื่อ metric และหน่วยสำหรับแสดงบนกราฟ
+func chartMetricLabel(metric, lang string) (string, string) {
+	thai := lang == "th"
+	switch selectMetricColumn(metric) {
+	case "pm10":
+		return "PM10", "µg/m³"
+	case "pm100":
+		return "PM100", "µg/m³"
+	case "aqi":
+		return "AQI", ""
+	case "temperature":
+		if thai {
+			return "อุณหภูมิ", "°C"
+		}
+		return "Temperature", "°C"
+	case "humidity":
+		if thai {
+			return "ความชื้น", "%"
+		}
+		return "Humidity", "%"
+	case "pres":
+		if thai {
+			return "ความกดอากาศ", "hPa"
+		}
+		return "Pressure", "hPa"
+	}
+	return "PM2.5", "µg/m³"
+}
+
+// chartLayout คือขนาดตัวอักษรและระยะขอบที่ปรับตามขนาดภาพ
+type chartLayout struct {
+	s                        chartSurface
+	opts                     ChartImageOptions
+	k                        float64 // อัตราส่วนเทียบกับภาพ 800×400
+	pad                      float64
+	titleSize, bodySize, sml float64
+}
+
+func newChartLayout(opts ChartImageOptions) *chartLayout {
+	if opts.Width == 0 {
+		opts.Width = ChartImageDefaultWidth
+	}
+	if opts.Height == 0 {
+		opts.Height = ChartImageDefaultHeight
+	}
+	if opts.Format != "svg" {
+		opts.Format = "png"
+	}
+	k := math.Max(0.6, math.Min(2.5, math.Min(float64(opts.Width)/ChartImageDefaultWidth, float64(opts.Height)/ChartImageDefaultHeight)))
+	return &chartLayout{
+		s: newChartSurface(opts), opts: opts, k: k, pad: 16 * k,
+		titleSize: 16 * k, bodySize: 11 * k, sml: 9.5 * k,
+	}
+}
+
+func (l *chartLayout) usesBands() bool {
+	return len(l.opts.Bands) > 0 && selectMetricColumn(l.opts.Metric) == "pm25"
+}
+
+func (l *chartLayout) bandColor(v float64) color.NRGBA {
+	return parseHexColor(l.opts.Bands[colorBandIndex(l.opts.Bands, v)].Color, 255)
+}
+
+// drawHeader วาดชื่อกราฟและคำอธิบาย คืนตำแหน่ง y ด้านล่างของส่วนหัว
+func (l *chartLayout) drawHeader(subtitle string) float64 {
+	label, _ := chartMetricLabel(l.opts.Metric, l.opts.Lang)
+	title := l.opts.Title
+	if title == "" {
+		title = label
+		if l.opts.Subject != "" {
+			title += " · " + l.opts.Subject
+		}
+	}
+	y := l.pad + l.titleSize
+	l.s.text(l.pad, y, l.titleSize, "start", true, chartTextColor, l.fit(title, float64(l.opts.Width)-2*l.pad, l.titleSize))
+	y += l.bodySize + 6*l.k
+	l.s.text(l.pad, y, l.bodySize, "start", false, chartMutedColor, subtitle)
+	return y + 10*l.k
+}
+
+// fit ตัดข้อความให้ไม่เกินความกว้าง
+func (l *chartLayout) fit(s string, width, size float64) string {
+	if l.s.textWidth(s, size) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && l.s.textWidth(string(runes)+"…", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+type chartLegendItem struct {
+	label string
+	color color.NRGBA
+	line  bool // true = เส้น, false = ช่องสี
+}
+
+// layoutLegend จัด legend เป็นแถว (ไม่เกิน chartImageMaxLegend แถว) คืนตำแหน่งของแต่ละรายการ
+func (l *chartLayout) layoutLegend(items []chartLegendItem, width float64) ([][]chartLegendItem, int) {
+	swatch := 14 * l.k
+	var rows [][]chartLegendItem
+	var row []chartLegendItem
+	x := 0.0
+	for i, item := range items {
+		w := swatch + 4*l.k + l.s.textWidth(item.label, l.sml) + 12*l.k
+		if x+w > width && len(row) > 0 {
+			rows = append(rows, row)
+			row, x = nil, 0
+			if len(rows) == chartImageMaxLegend {
+				return rows, len(items) - i
+			}
+		}
+		row = append(row, item)
+		x += w
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	return rows, 0
+}
+
+func (l *chartLayout) drawLegend(rows [][]chartLegendItem, hidden int, top float64) {
+	swatch := 14 * l.k
+	lineH := l.sml + 8*l.k
+	for r, row := range rows {
+		x := l.pad
+		y := top + float64(r)*lineH + l.sml
+		for _, item := range row {
+			if item.line {
+				l.s.polyline([][2]float64{{x, y - l.sml/3}, {x + swatch, y - l.sml/3}}, 2.5*l.k, item.color)
+			} else {
+				l.s.rect(x, y-l.sml, swatch, l.sml+2*l.k, item.color)
+			}
+			x += swatch + 4*l.k
+			l.s.text(x, y, l.sml, "start", false, chartTextColor, item.label)
+			x += l.s.textWidth(item.label, l.sml) + 12*l.k
+		}
+		if r == len(rows)-1 && hidden > 0 {
+			more := fmt.Sprintf("+%d", hidden)
+			if l.opts.Lang == "th" {
+				more = fmt.Sprintf("และอีก %d รายการ", hidden)
+			}
+			l.s.text(x, y, l.sml, "start", false, chartMutedColor, more)
+		}
+	}
+}
+
+func (l *chartLayout) bandLegendItems() []chartLegendItem {
+	var items []chartLegendItem
+	for i, b := range l.opts.Bands {
+		label := fmt.Sprintf("%d–%d", b.Min, b.Max)
+		if i == len(l.opts.Bands)-1 {
+			label = fmt.Sprintf("≥ %d", b.Min)
+		}
+		items = append(items, chartLegendItem{label: label, color: parseHexColor(b.Color, 255)})
+	}
+	return items
+}
+
+func (l *chartLayout) noData(top float64) {
+	msg := "No data"
+	if l.opts.Lang == "th" {
+		msg = "ไม่มีข้อมูล"
+	}
+	l.s.text(float64(l.opts.Width)/2, (top+float64(l.opts.Height))/2, l.titleSize, "middle", false, chartMutedColor, msg)
+}
+
+// RenderLineChartImage วาด ChartData (เช่นจาก GetChartData) เป็นกราฟเส้น หนึ่งเส้นต่อ dataset
+// ค่า 0 ถือว่าไม่มีข้อมูล (เส้นจะขาดช่วง) พื้นหลังแบ่งสีตาม ColorRange เมื่อเป็น PM2.5
+func RenderLineChartImage(data models.ChartData, opts ChartImageOptions) ([]byte, error) {
+	l := newChartLayout(opts)
+	s := l.s
+	w, h := float64(l.opts.Width), float64(l.opts.Height)
+
+	_, unit := chartMetricLabel(l.opts.Metric, l.opts.Lang)
+	subtitle := "Average per time bucket"
+	if l.opts.Lang == "th" {
+		subtitle = "ค่าเฉลี่ยในแต่ละช่วงเวลา"
+	}
+	if unit != "" {
+		subtitle += " (" + unit + ")"
+	}
+	top := l.drawHeader(subtitle)
+
+	maxVal := 0.0
+	for _, ds := range data.Datasets {
+		for _, v := range ds.Data {
+			if !math.IsNaN(v) {
+				maxVal = math.Max(maxVal, v)
+			}
+		}
+	}
+	if len(data.Labels) == 0 || maxVal <= 0 {
+		l.noData(top)
+		return s.encode()
+	}
+
+	var legend []chartLegendItem
+	for i, ds := range data.Datasets {
+		legend = append(legend, chartLegendItem{label: ds.Label, color: chartSeriesColors[i%len(chartSeriesColors)], line: true})
+	}
+	if len(data.Datasets) == 1 && data.Datasets[0].Label == "" {
+		legend = nil
+	}
+	legendRows, hidden := l.layoutLegend(legend, w-2*l.pad)
+	legendH := float64(len(legendRows)) * (l.sml + 8*l.k)
+
+	step := niceStep(maxVal, 5)
+	yMax := math.Ceil(maxVal/step) * step
+	yLabelW := s.textWidth(formatReportValue(yMax), l.sml)
+	left := l.pad + yLabelW + 6*l.k
+	right := w - l.pad
+	bottom := h - l.pad - legendH - l.sml - 8*l.k
+	plotH := bottom - top
+	if plotH < 20 || right-left < 20 {
+		return nil, ErrChartImageTooSmall
+	}
+	yOf := func(v float64) float64 { return bottom - v/yMax*plotH }
+
+	if l.usesBands() {
+		for i, b := range l.opts.Bands {
+			lo, hi := float64(b.Min), math.Min(float64(b.Max), yMax)
+			if i == len(l.opts.Bands)-1 {
+				hi = yMax
+			}
+			if lo >= yMax || hi <= lo {
+				continue
+			}
+			s.rect(left, yOf(hi), right-left, yOf(lo)-yOf(hi), parseHexColor(b.Color, 45))
+		}
+	}
+	for v := 0.0; v <= yMax+step/2; v += step {
+		y := yOf(v)
+		s.polyline([][2]float64{{left, y}, {right, y}}, 1, chartGridColor)
+		s.text(left-4*l.k, y+l.sml/3, l.sml, "end", false, chartMutedColor, formatReportValue(v))
+	}
+
+	n := len(data.Labels)
+	xOf := func(i int) float64 {
+		if n == 1 {
+			return (left + right) / 2
+		}
+		return left + (right-left)*float64(i)/float64(n-1)
+	}
+	labelW := 0.0
+	for _, label := range data.Labels {
+		labelW = math.Max(labelW, s.textWidth(label, l.sml))
+	}
+	every := 1
+	if n > 1 {
+		every = int(math.Ceil((labelW + 8*l.k) / ((right - left) / float64(n-1))))
+	}
+	for i, label := range data.Labels {
+		if i%every == 0 {
+			s.text(xOf(i), bottom+l.sml+4*l.k, l.sml, "middle", false, chartMutedColor, label)
+		}
+	}
+
+	for i, ds := range data.Datasets {
+		col := chartSeriesColors[i%len(chartSeriesColors)]
+		var segment [][2]float64
+		flush := func() {
+			if len(segment) == 1 {
+				s.circle(segment[0][0], segment[0][1], 2.5*l.k, col)
+			} else if len(segment) > 1 {
+				s.polyline(segment, 2*l.k, col)
+			}
+			segment = nil
+		}
+		for j := 0; j < n && j < len(ds.Data); j++ {
+			v := ds.Data[j]
+			if v <= 0 || math.IsNaN(v) {
+				flush()
+				continue
+			}
+			segment = append(segment, [2]float64{xOf(j), yOf(v)})
+		}
+		flush()
+
+		// กราฟเส้นเดียวแสดงจุดตามสีระดับคุณภาพอากาศ
+		if len(data.Datasets) == 1 && l.usesBands() {
+			for j := 0; j < n && j < len(ds.Data); j++ {
+				if v := ds.Data[j]; v > 0 {
+					s.circle(xOf(j), yOf(v), 3.5*l.k, l.bandColor(v))
+				}
+			}
+		}
+	}
+
+	l.drawLegend(legendRows, hidden, h-l.pad-legendH)
+	return s.encode()
+}
+
+// RenderCalendarHeatmapImage วาดค่าเฉลี่ยรายวัน (label YYYY-MM-DD เช่นจาก GetHeatmapOneYearDaily)
+// เป็นปฏิทินแบบหนึ่งคอลัมน์ต่อสัปดาห์ ย้อนหลัง 53 สัปดาห์จากวันล่าสุด (end)
+func RenderCalendarHeatmapImage(data models.ChartData, end time.Time, opts ChartImageOptions) ([]byte, error) {
+	l := newChartLayout(opts)
+	s := l.s
+	w, h := float64(l.opts.Width), float64(l.opts.Height)
+	thai := l.opts.Lang == "th"
+
+	_, unit := chartMetricLabel(l.opts.Metric, l.opts.Lang)
+	subtitle := "Daily average, last 12 months"
+	if thai {
+		subtitle = "ค่าเฉลี่ยรายวัน 12 เดือนล่าสุด"
+	}
+	if unit != "" {
+		subtitle += " (" + unit + ")"
+	}
+	top := l.drawHeader(subtitle)
+
+	values := make(map[string]float64)
+	maxVal := 0.0
+	if len(data.Datasets) > 0 {
+		for i, label := range data.Labels {
+			if i < len(data.Datasets[0].Data) {
+				v := data.Datasets[0].Data[i]
+				values[label] = v
+				maxVal = math.Max(maxVal, v)
+			}
+		}
+	}
+
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	first := end.AddDate(0, 0, -364)
+	first = first.AddDate(0, 0, -((int(first.Weekday()) + 6) % 7)) // เริ่มวันจันทร์
+	weeks := int(end.Sub(first).Hours()/24)/7 + 1
+
+	var legend []chartLegendItem
+	noData := "No data"
+	if thai {
+		noData = "ไม่มีข้อมูล"
+	}
+	legend = append(legend, chartLegendItem{label: noData, color: chartEmptyCell})
+	if l.usesBands() {
+		legend = append(legend, l.bandLegendItems()...)
+	} else {
+		legend = append(legend,
+			chartLegendItem{label: "0", color: heatColor(0)},
+			chartLegendItem{label: formatReportValue(maxVal), color: heatColor(1)})
+	}
+	legendRows, hidden := l.layoutLegend(legend, w-2*l.pad)
+	legendH := float64(len(legendRows)) * (l.sml + 8*l.k)
+
+	dayNames := []string{"Mon", "", "Wed", "", "Fri", "", ""}
+	if thai {
+		dayNames = []string{"จ.", "", "พ.", "", "ศ.", "", ""}
+	}
+	labelW := 0.0
+	for _, d := range dayNames {
+		labelW = math.Max(labelW, s.textWidth(d, l.sml))
+	}
+	left := l.pad + labelW + 6*l.k
+	gridTop := top + l.sml + 6*l.k
+	cell := math.Min((w-l.pad-left)/float64(weeks), (h-l.pad-legendH-8*l.k-gridTop)/7)
+	if cell < 3 {
+		return nil, ErrChartImageTooSmall
+	}
+	gap := math.Max(1, cell*0.12)
+
+	for i, d := range dayNames {
+		s.text(left-4*l.k, gridTop+float64(i)*cell+cell/2+l.sml/3, l.sml, "end", false, chartMutedColor, d)
+	}
+	lastMonthX := -1e9
+	for day := first; !day.After(end); day = day.AddDate(0, 0, 1) {
+		offset := int(day.Sub(first).Hours() / 24)
+		col, row := offset/7, offset%7
+		x, y := left+float64(col)*cell, gridTop+float64(row)*cell
+
+		if day.Day() == 1 && x-lastMonthX > s.textWidth("MMMM", l.sml) {
+			label := day.Format("Jan")
+			if thai {
+				label = chartMonthsTH[day.Month()-1]
+			}
+			s.text(x, gridTop-4*l.k, l.sml, "start", false, chartMutedColor, label)
+			lastMonthX = x
+		}
+
+		fill := chartEmptyCell
+		if v, ok := values[day.Format("2006-01-02")]; ok && !math.IsNaN(v) {
+			switch {
+			case l.usesBands():
+				fill = l.bandColor(v)
+			case maxVal > 0:
+				fill = heatColor(v / maxVal)
+			}
+		}
+		s.rect(x, y, cell-gap, cell-gap, fill)
+	}
+
+	l.drawLegend(legendRows, hidden, gridTop+7*cell+8*l.k)
+	return s.encode()
+}
+
+// heatColor ไล่สีฟ้าอ่อนถึงน้ำเงินเข้มตามสัดส่วน t (0..1) สำหรับ metric ที่ไม่มี ColorRange
+func heatColor(t float64) color.NRGBA {
+	t = math.Max(0, math.Min(1, t))
+	lerp := func(a, b float64) uint8 { return uint8(math.Round(a + (b-a)*t)) }
+	return color.NRGBA{lerp(222, 8), lerp(235, 69), lerp(247, 148), 255}
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"yakkaw_dashboard/models"
+)
+
+var testChartBands = []models.ColorRange{
+	{Min: 0, Max: 25, Color: "#00e400"},
+	{Min: 25, Max: 75, Color: "#ffff00"},
+	{Min: 75, Max: 500, Color: "#ff0000"},
+}
+
+func TestRenderLineChartImage(t *testing.T) {
+	data := models.ChartData{
+		Labels: buildHourLabels(),
+		Datasets: []models.DatasetChart{
+			{Label: "เชียงราย", Data: buildHourlyData(map[int]float64{0: 20, 1: 35, 2: 80, 5: 40})},
+			{Label: "A & <B>", Data: buildHourlyData(map[int]float64{3: 10})},
+		},
+	}
+
+	img, err := RenderLineChartImage(data, ChartImageOptions{Format: "png", Width: 400, Height: 300, Metric: "pm25", Bands: testChartBands})
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := png.Decode(bytes.NewReader(img))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := decoded.Bounds(); b.Dx() != 400 || b.Dy() != 300 {
+		t.Fatalf("PNG size = %v", b)
+	}
+
+	svg, err := RenderLineChartImage(data, ChartImageOptions{Format: "svg", Metric: "pm25", Subject: "24 Hour", Bands: testChartBands})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(svg)
+	for _, want := range []string{`<svg xmlns="http://www.w3.org/2000/svg" width="800" height="400"`, "<polyline", "A &amp; &lt;B&gt;", "PM2.5 · 24 Hour", "#ff0000", "</svg>"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("SVG missing %q", want)
+		}
+	}
+	// ชั่วโมง 3–4 เป็น 0 (ไม่มีข้อมูล) เส้นของเชียงรายจึงขาด: 00–02 เป็นเส้น, 05 เป็นจุดเดี่ยว
+	if n := strings.Count(out, `stroke="#1f77b4"`); n != 2 { // เส้น + legend
+		t.Errorf("first series drawn as %d polylines, want 2", n)
+	}
+	if !strings.Contains(out, `r="2.5" fill="#1f77b4"`) {
+		t.Error("isolated reading should be drawn as a dot")
+	}
+
+	if _, err := RenderLineChartImage(data, ChartImageOptions{Format: "png", Width: 200, Height: 200, Bands: testChartBands}); err != nil {
+		t.Errorf("smallest size should still render: %v", err)
+	}
+	empty, err := RenderLineChartImage(models.ChartData{}, ChartImageOptions{Format: "svg", Lang: "th"})
+	if err != nil || !strings.Contains(string(empty), "ไม่มีข้อมูล") {
+		t.Errorf("empty chart = %s, %v", empty, err)
+	}
+}
+
+func TestRenderCalendarHeatmapImage(t *testing.T) {
+	end := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC) // วันอาทิตย์
+	data := models.ChartData{
+		Labels:   []string{"2024-03-30", "2024-03-31", "2023-04-03"},
+		Datasets: []models.DatasetChart{{Data: []float64{10, 90, 30}}},
+	}
+
+	svg, err := RenderCalendarHeatmapImage(data, end, ChartImageOptions{Format: "svg", Metric: "pm25", Lang: "th", Bands: testChartBands})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(svg)
+	// 53 สัปดาห์ × 7 วัน เริ่มวันจันทร์ 3 เม.ย. 2023
+	if n := strings.Count(out, "<rect x="); n < 53*7 {
+		t.Errorf("calendar has %d cells, want at least %d", n, 53*7)
+	}
+	for _, want := range []string{"#00e400", "#ff0000", "#ffff00", "เม.ย.", "≥ 75"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("SVG missing %q", want)
+		}
+	}
+
+	if _, err := RenderCalendarHeatmapImage(data, end, ChartImageOptions{Format: "png", Metric: "humidity"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RenderCalendarHeatmapImage(data, end, ChartImageOptions{Format: "png", Width: 200, Height: 200, Bands: testChartBands}); !errors.Is(err, ErrChartImageTooSmall) && err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCachedChartImage(t *testing.T) {
+	invalidateChartImageCache()
+	calls := 0
+	build := func() ([]byte, error) {
+		calls++
+		return []byte("img"), nil
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := CachedChartImage("/chart?format=png", build); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("build called %d times, want 1", calls)
+	}
+	if _, err := CachedChartImage("/chart?format=png", func() ([]byte, error) { return nil, errors.New("boom") }); err != nil {
+		t.Fatal("cached entry should be returned without calling build")
+	}
+	invalidateChartImageCache()
+	if _, err := CachedChartImage("/chart?format=png", build); err != nil || calls != 2 {
+		t.Fatalf("after invalidation calls = %d, err = %v", calls, err)
+	}
+}
@@ -12,9 +12,10 @@ func CreateColorRange(ColorRange models.ColorRange) (models.ColorRange, error) {
 	return ColorRange, nil
 }
 
+// GetAllColorRanges คืนช่วงสีทั้งหมดเรียงตาม Min (colorBandIndex และผู้เรียกทุกที่ใช้ลำดับนี้)
 func GetAllColorRanges() ([]models.ColorRange, error) {
 	var colorRanges []models.ColorRange
-	if err := database.DB.Find(&colorRanges).Error; err != nil {
+	if err := database.DB.Order("min").Find(&colorRanges).Error; err != nil {
 		return nil, err
 	}
 	return colorRanges, nil
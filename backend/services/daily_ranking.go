@@ -10,7 +10,7 @@ import (
 )
 
 type DailyRankRow struct {
-	Key    string  `json:"key"` // address | place | province
+	Key    string  `json:"key"` // address | place | province | region | "อำเภอ, จังหวัด" (group=district)
 	Avg    float64 `json:"avg"`
 	Rank   int     `json:"rank"`
 	Count  int     `json:"count"`
@@ -19,33 +19,12 @@ type DailyRankRow struct {
 	Group  string  `json:"group"`
 }
 
-// GetDailyRankingGrouped จัดอันดับเฉลี่ยรายวันโดย group: address | place | province
+// GetDailyRankingGrouped จัดอันดับเฉลี่ยรายวันโดย group: address | place | province | region | district
 // dateStr: YYYY-MM-DD (ใช้ TZ Asia/Bangkok)
-func GetDailyRankingGrouped(dateStr, metric, group string, limit int) ([]DailyRankRow, error) {
-	metricCol, ok := map[string]string{
-		"pm25":        "pm25",
-		"pm10":        "pm10",
-		"pm100":       "pm100",
-		"aqi":         "aqi",
-		"temp":        "temperature",
-		"temperature": "temperature",
-		"humidity":    "humidity",
-	}[metric]
-	if !ok {
-		return nil, fmt.Errorf("invalid metric")
-	}
-
-	groupCol, ok := map[string]string{
-		"address":  "address",
-		"place":    "place",
-		"province": "province",
-	}[group]
-	if !ok {
-		return nil, fmt.Errorf("invalid group")
-	}
-
-	if groupCol == "province" {
-		groupCol = "regexp_replace(trim(regexp_replace(address, '^\\s+|\\s+$', '', 'g')), '^.*\\s+', '')"
+func GetDailyRankingGrouped(dateStr, metric, group string, limit int, opts DataOptions) ([]DailyRankRow, error) {
+	metricCol, groupCol, err := rankingColumns(metric, group)
+	if err != nil {
+		return nil, err
 	}
 
 	loc, _ := time.LoadLocation("Asia/Bangkok")
@@ -66,7 +45,7 @@ func GetDailyRankingGrouped(dateStr, metric, group string, limit int) ([]DailyRa
             WHERE (to_timestamp(timestamp/1000) AT TIME ZONE 'Asia/Bangkok') >= ?
               AND (to_timestamp(timestamp/1000) AT TIME ZONE 'Asia/Bangkok') <  ?
               AND %s IS NOT NULL
-              AND %s <> ''
+              AND %s <> ''%s
             GROUP BY %s
         )
         SELECT key, avg_val, cnt,
@@ -74,7 +53,7 @@ func GetDailyRankingGrouped(dateStr, metric, group string, limit int) ([]DailyRa
         FROM d
         ORDER BY rk
         LIMIT ?;
-    `, groupCol, metricCol, groupCol, groupCol, groupCol)
+    `, groupCol, opts.metricExpr(metricCol), groupCol, groupCol, opts.qualityClause(), groupCol)
 
 	rows, err := database.DB.Raw(query, start, end, limit).Rows()
 	if err != nil {
@@ -110,3 +89,32 @@ func GetDailyRankingGrouped(dateStr, metric, group string, limit int) ([]DailyRa
 	}
 	return res, nil
 }
+
+// rankingColumns แปลง metric/group จาก query parameter เป็น column/expression ที่ใช้ใน SQL
+func rankingColumns(metric, group string) (string, string, error) {
+	metricCol, ok := map[string]string{
+		"pm25":        "pm25",
+		"pm10":        "pm10",
+		"pm100":       "pm100",
+		"aqi":         "aqi",
+		"temp":        "temperature",
+		"temperature": "temperature",
+		"humidity":    "humidity",
+	}[metric]
+	if !ok {
+		return "", "", fmt.Errorf("invalid metric")
+	}
+
+	groupCol, ok := map[string]string{
+		"address":  "address",
+		"place":    "place",
+		"province": "province",
+		"region":   "region",
+		// อำเภอชื่อซ้ำกันได้ข้ามจังหวัด (เช่น อำเภอเมือง...) จึงต่อชื่อจังหวัดไว้ด้วย
+		"district": "NULLIF(district, '') || ', ' || province",
+	}[group]
+	if !ok {
+		return "", "", fmt.Errorf("invalid group")
+	}
+	return metricCol, groupCol, nil
+}
@@ -0,0 +1,24 @@
+package services
+
+// DataOptions ควบคุมการเลือกข้อมูลของ query แบบ aggregate ที่ใช้ร่วมกันทุก endpoint
+type DataOptions struct {
+	IncludeFlagged bool // รวมข้อมูลที่ถูก flag ว่าผิดปกติด้วย
+	Raw            bool // ใช้ค่าดิบจากเซนเซอร์แทนค่าที่ calibrate แล้ว
+}
+
+// qualityClause คืนเงื่อนไข SQL (ต่อท้าย WHERE) สำหรับตัดข้อมูลที่ถูก flag ออก
+func (o DataOptions) qualityClause() string {
+	if o.IncludeFlagged {
+		return ""
+	}
+	return " AND quality_flags = 0"
+}
+
+// metricExpr คืน expression SQL ของ metric โดยใช้ค่า calibrate เมื่อมี และย้อนกลับไปใช้ค่าดิบเมื่อไม่มี
+func (o DataOptions) metricExpr(col string) string {
+	calibrated, ok := calibratedColumns[col]
+	if !ok || o.Raw {
+		return col
+	}
+	return "COALESCE(" + calibrated + ", " + col + ")"
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"yakkaw_dashboard/config"
+	"yakkaw_dashboard/database"
+	"yakkaw_dashboard/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DeviceAlertOpen     = "open"
+	DeviceAlertResolved = "resolved"
+)
+
+// CheckDeviceAlerts เปิด alert ให้อุปกรณ์ที่ไม่ส่งข้อมูลนานเกิน DEVICE_ALERT_AFTER
+// และปิด alert อัตโนมัติเมื่ออุปกรณ์กลับมาส่งข้อมูล
+// อุปกรณ์ที่ไม่อยู่ในทะเบียนและเงียบไปนานกว่า DEVICE_ALERT_LOOKBACK ถือว่าเลิกใช้แล้ว ไม่เปิด alert
+func CheckDeviceAlerts() error {
+	health, err := GetDevicesHealth()
+	if err != nil {
+		return err
+	}
+
+	var registered []string
+	if err := database.DB.Model(&models.Device{}).Pluck("dv_id", &registered).Error; err != nil {
+		return err
+	}
+	isRegistered := make(map[string]bool, len(registered))
+	for _, dvid := range registered {
+		isRegistered[dvid] = true
+	}
+
+	var open []models.DeviceAlert
+	if err := database.DB.Where("status = ?", DeviceAlertOpen).Find(&open).Error; err != nil {
+		return err
+	}
+	openByDVID := make(map[string]models.DeviceAlert, len(open))
+	for _, alert := range open {
+		openByDVID[alert.DVID] = alert
+	}
+
+	threshold := config.DeviceAlertAfter()
+	lookback := config.DeviceAlertLookback()
+	now := time.Now()
+	for _, h := range health {
+		age := now.Sub(h.LastSeen)
+		alert, hasOpen := openByDVID[h.DVID]
+
+		switch deviceAlertAction(age, threshold, lookback, hasOpen, isRegistered[h.DVID]) {
+		case DeviceAlertOpen:
+			if err := openDeviceAlert(h, age); err != nil {
+				log.Printf("Error opening device alert for %s: %v", h.DVID, err)
+			}
+		case DeviceAlertResolved:
+			if err := resolveDeviceAlert(alert, h); err != nil {
+				log.Printf("Error resolving device alert for %s: %v", h.DVID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// deviceAlertAction ตัดสินว่าอุปกรณ์ที่เงียบไป age ต้องเปิด alert (DeviceAlertOpen), ปิด alert (DeviceAlertResolved)
+// หรือไม่ต้องทำอะไร ("")
+func deviceAlertAction(age, threshold, lookback time.Duration, hasOpen, registered bool) string {
+	switch {
+	case age >= threshold && !hasOpen && (registered || age < lookback):
+		return DeviceAlertOpen
+	case age < threshold && hasOpen:
+		return DeviceAlertResolved
+	}
+	return ""
+}
+
+func openDeviceAlert(h DeviceHealth, age time.Duration) error {
+	contact := lookupDeviceContact(h.DVID)
+	alert := models.DeviceAlert{
+		DVID:         h.DVID,
+		Status:       DeviceAlertOpen,
+		Level:        h.Status,
+		Message:      fmt.Sprintf("Device %s (%s) has not reported for %s", h.DVID, h.Place, age.Truncate(time.Minute)),
+		LastSeen:     h.LastSeen,
+		StartedAt:    time.Now(),
+		ContactName:  contact.ContactName,
+		ContactPhone: contact.ContactPhone,
+	}
+	if err := database.DB.Create(&alert).Error; err != nil {
+		return err
+	}
+
+	notifyDeviceAlert(&alert, contact.ContactEmail, "device.offline", h)
+	return database.DB.Save(&alert).Error
+}
+
+func resolveDeviceAlert(alert models.DeviceAlert, h DeviceHealth) error {
+	now := time.Now()
+	alert.Status = DeviceAlertResolved
+	alert.ResolvedAt = &now
+	alert.LastSeen = h.LastSeen
+	alert.Message = fmt.Sprintf("Device %s (%s) resumed reporting after %s", h.DVID, h.Place, now.Sub(alert.StartedAt).Truncate(time.Minute))
+	if err := database.DB.Save(&alert).Error; err != nil {
+		return err
+	}
+
+	contact := lookupDeviceContact(h.DVID)
+	notifyDeviceAlert(&alert, contact.ContactEmail, "device.resumed", h)
+	return database.DB.Save(&alert).Error
+}
+
+// notifyDeviceAlert ส่ง webhook/อีเมล (ถ้าตั้งค่าไว้) และบันทึกผลการส่งลงใน alert
+func notifyDeviceAlert(alert *models.DeviceAlert, contactEmail, event string, h DeviceHealth) {
+	var errs []string
+	sent := false
+
+	if url, secret := config.DeviceAlertWebhook(); url != "" {
+		payload := map[string]interface{}{
+			"event":         event,
+			"alert_id":      alert.ID,
+			"dvid":          alert.DVID,
+			"place":         h.Place,
+			"address":       h.Address,
+			"status":        alert.Status,
+			"level":         alert.Level,
+			"message":       alert.Message,
+			"last_seen":     alert.LastSeen,
+			"contact_name":  alert.ContactName,
+			"contact_phone": alert.ContactPhone,
+		}
+		if err := postWebhook(url, secret, payload); err != nil {
+			errs = append(errs, "webhook: "+err.Error())
+		} else {
+			sent = true
+		}
+	}
+
+	var recipients []string
+	if contactEmail != "" {
+		recipients = append(recipients, contactEmail)
+	}
+	if admin := config.DeviceAlertEmail(); admin != "" {
+		recipients = append(recipients, admin)
+	}
+	if len(recipients) > 0 && config.SMTP().Enabled() {
+		body := fmt.Sprintf("เรียน %s\n\n%s\n\nสถานที่: %s\nที่อยู่: %s\nข้อมูลล่าสุด: %s\n",
+			alert.ContactName, alert.Message, h.Place, h.Address, alert.LastSeen.Format(time.RFC3339))
+		if err := sendEmail(recipients, "[Yakkaw] "+alert.Message, body); err != nil {
+			errs = append(errs, "email: "+err.Error())
+		} else {
+			sent = true
+		}
+	}
+
+	if sent {
+		now := time.Now()
+		alert.NotifiedAt = &now
+	}
+	alert.NotifyError = strings.Join(errs, "; ")
+}
+
+// lookupDeviceContact หา contact จาก device registry ก่อน แล้วค่อยใช้ข้อมูลจาก sensor_data ล่าสุด
+func lookupDeviceContact(dvid string) models.Device {
+	var device models.Device
+	err := database.DB.Where("dv_id = ?", dvid).First(&device).Error
+	if err == nil {
+		return device
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("Error loading device %s: %v", dvid, err)
+	}
+
+	database.DB.Raw(`
+		SELECT contactname AS contact_name, contactphone AS contact_phone
+		FROM sensor_data
+		WHERE dvid = ?
+		ORDER BY timestamp DESC
+		LIMIT 1
+	`, dvid).Scan(&device)
+	device.DVID = dvid
+	return device
+}
+
+// ListDeviceAlerts returns alerts (newest first), optionally filtered by status and/or DVID
+func ListDeviceAlerts(status, dvid string) ([]models.DeviceAlert, error) {
+	var alerts []models.DeviceAlert
+	query := database.DB.Order("started_at DESC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if dvid != "" {
+		query = query.Where("dv_id = ?", dvid)
+	}
+	if err := query.Find(&alerts).Error; err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
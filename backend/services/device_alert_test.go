@@ -0,0 +1,32 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeviceAlertAction(t *testing.T) {
+	threshold, lookback := 3*time.Hour, 7*24*time.Hour
+
+	cases := []struct {
+		name       string
+		age        time.Duration
+		hasOpen    bool
+		registered bool
+		want       string
+	}{
+		{"reporting", time.Hour, false, true, ""},
+		{"just past threshold", threshold, false, true, DeviceAlertOpen},
+		{"already open", 5 * time.Hour, true, true, ""},
+		{"resumed", time.Minute, true, true, DeviceAlertResolved},
+		{"unregistered within lookback", 2 * 24 * time.Hour, false, false, DeviceAlertOpen},
+		{"unregistered past lookback", lookback, false, false, ""},
+		{"registered past lookback", 30 * 24 * time.Hour, false, true, DeviceAlertOpen},
+		{"unregistered resumed", time.Minute, true, false, DeviceAlertResolved},
+	}
+	for _, tc := range cases {
+		if got := deviceAlertAction(tc.age, threshold, lookback, tc.hasOpen, tc.registered); got != tc.want {
+			t.Errorf("%s: deviceAlertAction = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"database/sql"
+	"math"
+	"sort"
+	"time"
+
+	"yakkaw_dashboard/config"
+	"yakkaw_dashboard/database"
+)
+
+const (
+	defaultReportingInterval = 5 * time.Minute
+	deviceHealthWindowDays   = 7
+	deviceGapFactor          = 3 // ช่วงที่ห่างเกิน interval × factor ถือเป็น gap
+	deviceMaxGaps            = 50
+)
+
+type DeviceHealth struct {
+	DVID                     string              `json:"dvid"`
+	Place                    string              `json:"place"`
+	Address                  string              `json:"address"`
+	LastSeen                 time.Time           `json:"last_seen"`
+	LastSeenAgeMinutes       float64             `json:"last_seen_age_minutes"`
+	ReportingIntervalMinutes float64             `json:"reporting_interval_minutes"`
+	Status                   string              `json:"status"` // online | stale | offline
+	Completeness24h          float64             `json:"completeness_24h"`
+	Completeness7d           float64             `json:"completeness_7d"`
+	Daily                    []DailyCompleteness `json:"daily,omitempty"`
+	Gaps                     []DataGap           `json:"gaps,omitempty"`
+}
+
+type DailyCompleteness struct {
+	Date         string  `json:"date"`
+	Received     int     `json:"received"`
+	Expected     int     `json:"expected"`
+	Completeness float64 `json:"completeness"`
+}
+
+type DataGap struct {
+	From            time.Time `json:"from"`
+	To              time.Time `json:"to"`
+	DurationMinutes float64   `json:"duration_minutes"`
+}
+
+// GetDevicesHealth สรุปสถานะของทุก DVID ที่เคยส่งข้อมูลเข้า sensor_data
+func GetDevicesHealth() ([]DeviceHealth, error) {
+	now := time.Now()
+	weekStart := now.AddDate(0, 0, -deviceHealthWindowDays)
+	dayStart := now.Add(-24 * time.Hour)
+
+	type lastSeenRow struct {
+		DVID      string
+		Timestamp int64
+		Place     string
+		Address   string
+	}
+	var lastSeen []lastSeenRow
+	if err := database.DB.Raw(`
+		SELECT DISTINCT ON (dvid) dvid, timestamp, place, address
+		FROM sensor_data
+		WHERE dvid IS NOT NULL AND dvid <> ''
+		ORDER BY dvid, timestamp DESC
+	`).Scan(&lastSeen).Error; err != nil {
+		return nil, err
+	}
+
+	type statsRow struct {
+		DVID       string
+		MedianDiff sql.NullFloat64
+		WeekCount  int
+		DayCount   int
+	}
+	var stats []statsRow
+	if err := database.DB.Raw(`
+		WITH t AS (
+			SELECT DISTINCT dvid, timestamp
+			FROM sensor_data
+			WHERE timestamp >= ?
+		), d AS (
+			SELECT dvid, timestamp,
+			       timestamp - LAG(timestamp) OVER (PARTITION BY dvid ORDER BY timestamp) AS diff
+			FROM t
+		)
+		SELECT dvid,
+		       percentile_cont(0.5) WITHIN GROUP (ORDER BY diff) AS median_diff,
+		       COUNT(*)                                         AS week_count,
+		       COUNT(*) FILTER (WHERE timestamp >= ?)           AS day_count
+		FROM d
+		GROUP BY dvid
+	`, weekStart.UnixMilli(), dayStart.UnixMilli()).Scan(&stats).Error; err != nil {
+		return nil, err
+	}
+
+	statsByDVID := make(map[string]statsRow, len(stats))
+	for _, s := range stats {
+		statsByDVID[s.DVID] = s
+	}
+
+	result := make([]DeviceHealth, 0, len(lastSeen))
+	for _, row := range lastSeen {
+		st := statsByDVID[row.DVID]
+		interval := reportingInterval(st.MedianDiff)
+		health := newDeviceHealth(row.DVID, row.Place, row.Address, time.UnixMilli(row.Timestamp), interval, now)
+		health.Completeness24h = completenessPercent(st.DayCount, 24*time.Hour, interval)
+		health.Completeness7d = completenessPercent(st.WeekCount, deviceHealthWindowDays*24*time.Hour, interval)
+		result = append(result, health)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].DVID < result[j].DVID })
+	return result, nil
+}
+
+// GetDeviceHealth คืนสถานะของอุปกรณ์เดียวพร้อม completeness รายวันและรายการ gap ใน 7 วันล่าสุด
+// คืน nil เมื่อไม่เคยพบ DVID นี้ใน sensor_data
+func GetDeviceHealth(dvid string) (*DeviceHealth, error) {
+	now := time.Now()
+	loc, _ := time.LoadLocation("Asia/Bangkok")
+	if loc == nil {
+		loc = time.FixedZone("Asia/Bangkok", 7*3600)
+	}
+	today := now.In(loc)
+	windowStart := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, -(deviceHealthWindowDays - 1))
+
+	var last struct {
+		Timestamp int64
+		Place     string
+		Address   string
+	}
+	if err := database.DB.Raw(`
+		SELECT timestamp, place, address
+		FROM sensor_data
+		WHERE dvid = ?
+		ORDER BY timestamp DESC
+		LIMIT 1
+	`, dvid).Scan(&last).Error; err != nil {
+		return nil, err
+	}
+	if last.Timestamp == 0 {
+		return nil, nil
+	}
+
+	var timestamps []int64
+	if err := database.DB.Raw(`
+		SELECT DISTINCT timestamp
+		FROM sensor_data
+		WHERE dvid = ? AND timestamp >= ?
+		ORDER BY timestamp ASC
+	`, dvid, windowStart.UnixMilli()).Scan(&timestamps).Error; err != nil {
+		return nil, err
+	}
+
+	interval := medianInterval(timestamps)
+	health := newDeviceHealth(dvid, last.Place, last.Address, time.UnixMilli(last.Timestamp), interval, now)
+
+	dayStart := now.Add(-24 * time.Hour).UnixMilli()
+	dayCount := 0
+	for _, ts := range timestamps {
+		if ts >= dayStart {
+			dayCount++
+		}
+	}
+	health.Completeness24h = completenessPercent(dayCount, 24*time.Hour, interval)
+	health.Completeness7d = completenessPercent(len(timestamps), now.Sub(windowStart), interval)
+
+	received := make(map[string]int)
+	for _, ts := range timestamps {
+		received[time.UnixMilli(ts).In(loc).Format("2006-01-02")]++
+	}
+	for day := windowStart; day.Before(now); day = day.AddDate(0, 0, 1) {
+		span := 24 * time.Hour
+		if end := day.AddDate(0, 0, 1); end.After(now) {
+			span = now.Sub(day)
+		}
+		key := day.Format("2006-01-02")
+		expected := int(span / interval)
+		health.Daily = append(health.Daily, DailyCompleteness{
+			Date:         key,
+			Received:     received[key],
+			Expected:     expected,
+			Completeness: completenessPercent(received[key], span, interval),
+		})
+	}
+
+	health.Gaps = findDataGaps(timestamps, windowStart, now, interval)
+	return &health, nil
+}
+
+func newDeviceHealth(dvid, place, address string, lastSeen time.Time, interval time.Duration, now time.Time) DeviceHealth {
+	age := now.Sub(lastSeen)
+	return DeviceHealth{
+		DVID:                     dvid,
+		Place:                    place,
+		Address:                  address,
+		LastSeen:                 lastSeen,
+		LastSeenAgeMinutes:       roundToTwoDecimals(age.Minutes()),
+		ReportingIntervalMinutes: roundToTwoDecimals(interval.Minutes()),
+		Status:                   deviceStatus(age),
+	}
+}
+
+// deviceStatus แปลงอายุของข้อมูลล่าสุดเป็นสถานะตาม DEVICE_STALE_AFTER / DEVICE_OFFLINE_AFTER
+func deviceStatus(age time.Duration) string {
+	switch {
+	case age >= config.DeviceOfflineAfter():
+		return "offline"
+	case age >= config.DeviceStaleAfter():
+		return "stale"
+	default:
+		return "online"
+	}
+}
+
+func reportingInterval(medianDiffMs sql.NullFloat64) time.Duration {
+	if !medianDiffMs.Valid || medianDiffMs.Float64 <= 0 {
+		return defaultReportingInterval
+	}
+	return time.Duration(medianDiffMs.Float64) * time.Millisecond
+}
+
+func medianInterval(timestamps []int64) time.Duration {
+	if len(timestamps) < 2 {
+		return defaultReportingInterval
+	}
+	diffs := make([]int, 0, len(timestamps)-1)
+	for i := 1; i < len(timestamps); i++ {
+		diffs = append(diffs, int(timestamps[i]-timestamps[i-1]))
+	}
+	return reportingInterval(sql.NullFloat64{Float64: medianInt(diffs), Valid: true})
+}
+
+func completenessPercent(received int, span, interval time.Duration) float64 {
+	expected := float64(span) / float64(interval)
+	if expected < 1 {
+		return 100
+	}
+	return roundToTwoDecimals(math.Min(100, float64(received)/expected*100))
+}
+
+// findDataGaps หาช่วงที่ไม่มีข้อมูลนานเกิน interval × deviceGapFactor (ใหม่สุดก่อน)
+func findDataGaps(timestamps []int64, windowStart, now time.Time, interval time.Duration) []DataGap {
+	threshold := interval * deviceGapFactor
+	var gaps []DataGap
+	addGap := func(from, to time.Time) {
+		if to.Sub(from) > threshold {
+			gaps = append(gaps, DataGap{From: from, To: to, DurationMinutes: roundToTwoDecimals(to.Sub(from).Minutes())})
+		}
+	}
+
+	prev := windowStart
+	for _, ts := range timestamps {
+		current := time.UnixMilli(ts)
+		addGap(prev, current)
+		prev = current
+	}
+	addGap(prev, now)
+
+	sort.Slice(gaps, func(i, j int) bool { return gaps[i].From.After(gaps[j].From) })
+	if len(gaps) > deviceMaxGaps {
+		gaps = gaps[:deviceMaxGaps]
+	}
+	return gaps
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMedianInterval(t *testing.T) {
+	cases := []struct {
+		name       string
+		timestamps []int64
+		want       time.Duration
+	}{
+		{"empty", nil, defaultReportingInterval},
+		{"single reading", []int64{1000}, defaultReportingInterval},
+		{"duplicate timestamps", []int64{1000, 1000, 1000}, defaultReportingInterval},
+		{"regular", []int64{0, 60000, 120000, 180000}, time.Minute},
+		{"one outage", []int64{0, 60000, 120000, 3720000, 3780000}, time.Minute},
+		{"even count", []int64{0, 60000, 180000}, 90 * time.Second},
+	}
+	for _, tc := range cases {
+		if got := medianInterval(tc.timestamps); got != tc.want {
+			t.Errorf("%s: medianInterval = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestCompletenessPercent(t *testing.T) {
+	cases := []struct {
+		name     string
+		received int
+		span     time.Duration
+		interval time.Duration
+		want     float64
+	}{
+		{"no readings", 0, 24 * time.Hour, 5 * time.Minute, 0},
+		{"full day", 288, 24 * time.Hour, 5 * time.Minute, 100},
+		{"half day", 144, 24 * time.Hour, 5 * time.Minute, 50},
+		{"capped", 400, 24 * time.Hour, 5 * time.Minute, 100},
+		{"span shorter than interval", 0, 2 * time.Minute, 5 * time.Minute, 100},
+		{"single reading", 1, 15 * time.Minute, 5 * time.Minute, 33.33},
+	}
+	for _, tc := range cases {
+		if got := completenessPercent(tc.received, tc.span, tc.interval); got != tc.want {
+			t.Errorf("%s: completenessPercent = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestFindDataGaps(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	now := start.Add(2 * time.Hour)
+	at := func(minutes int) int64 { return start.Add(time.Duration(minutes) * time.Minute).UnixMilli() }
+	span := func(from, to int) DataGap {
+		f, e := start.Add(time.Duration(from)*time.Minute), start.Add(time.Duration(to)*time.Minute)
+		return DataGap{From: f, To: e, DurationMinutes: float64(to - from)}
+	}
+	every := func(from, to int) []int64 {
+		var ts []int64
+		for m := from; m <= to; m += 5 {
+			ts = append(ts, at(m))
+		}
+		return ts
+	}
+
+	cases := []struct {
+		name       string
+		timestamps []int64
+		want       []DataGap
+	}{
+		{"empty", nil, []DataGap{span(0, 120)}},
+		{"single reading", []int64{at(60)}, []DataGap{span(60, 120), span(0, 60)}},
+		{"complete", every(0, 120), nil},
+		{"gap at window start", every(30, 120), []DataGap{span(0, 30)}},
+		{"gap at window end", every(0, 90), []DataGap{span(90, 120)}},
+		{"gap in the middle", append(every(0, 40), every(70, 120)...), []DataGap{span(40, 70)}},
+		{"at threshold is not a gap", append(every(0, 40), every(55, 120)...), nil},
+	}
+	for _, tc := range cases {
+		got := findDataGaps(tc.timestamps, start, now, 5*time.Minute)
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: findDataGaps = %+v, want %+v", tc.name, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if !got[i].From.Equal(tc.want[i].From) || !got[i].To.Equal(tc.want[i].To) || got[i].DurationMinutes != tc.want[i].DurationMinutes {
+				t.Errorf("%s: gap %d = %+v, want %+v", tc.name, i, got[i], tc.want[i])
+			}
+		}
+	}
+}
@@ -13,6 +13,7 @@ func CreateDevice(device models.Device) (models.Device, error) {
 	if device.DeployDate.IsZero() {
 		device.DeployDate = time.Now()
 	}
+	applyDeviceLocation(&device)
 
 	// สร้าง device ในฐานข้อมูล
 	if err := database.DB.Create(&device).Error; err != nil {
@@ -59,6 +60,8 @@ func UpdateDevice(dvid string, device models.Device) (models.Device, error) {
 	existingDevice.Models = device.Models
 	existingDevice.ContactName = device.ContactName
 	existingDevice.ContactPhone = device.ContactPhone
+	existingDevice.ContactEmail = device.ContactEmail
+	applyDeviceLocation(&existingDevice)
 	// ตั้งค่า deploy_date เป็นเวลาปัจจุบันถ้าไม่มีการตั้งค่า
 
 	if device.DeployDate.IsZero() {
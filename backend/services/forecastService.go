@@ -0,0 +1,256 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"yakkaw_dashboard/database"
+	"yakkaw_dashboard/models"
+)
+
+const (
+	forecastMetric        = "pm25"
+	forecastModelName     = "holt-winters-damped"
+	forecastPeriodHours   = 24
+	forecastLookbackDays  = 28
+	forecastHorizonHours  = 72
+	forecastBacktestHours = 24
+	forecastMinCoverage   = 0.5
+	forecastRetentionDays = 7
+)
+
+// forecastData: fit ด้วยค่าที่ calibrate แล้วและตัดข้อมูลที่ถูก flag ออกเสมอ
+var forecastData = DataOptions{}
+
+// RunForecasts fit โมเดลใหม่ให้ทุกจังหวัดจากข้อมูลรายชั่วโมงใน sensor_data แล้วบันทึกผลพร้อม backtest
+func RunForecasts() error {
+	end := time.Now().Truncate(time.Hour)
+	start := end.AddDate(0, 0, -forecastLookbackDays)
+
+	series, err := loadProvinceHourlySeries(start, end)
+	if err != nil {
+		return err
+	}
+
+	var provinces []string
+	for prov := range series {
+		provinces = append(provinces, prov)
+	}
+	sort.Strings(provinces)
+
+	for _, prov := range provinces {
+		if err := runProvinceForecast(prov, series[prov], start, end); err != nil {
+			log.Printf("forecast for %s skipped: %v", prov, err)
+		}
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -forecastRetentionDays)
+	var oldRunIDs []uint
+	if err := database.DB.Model(&models.ForecastRun{}).Where("generated_at < ?", cutoff).Pluck("id", &oldRunIDs).Error; err != nil {
+		return err
+	}
+	if len(oldRunIDs) > 0 {
+		if err := database.DB.Where("run_id IN ?", oldRunIDs).Delete(&models.Forecast{}).Error; err != nil {
+			return err
+		}
+		if err := database.DB.Delete(&models.ForecastRun{}, oldRunIDs).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runProvinceForecast(province string, raw []float64, start, end time.Time) error {
+	observed := 0
+	for _, v := range raw {
+		if !math.IsNaN(v) {
+			observed++
+		}
+	}
+	if float64(observed) < forecastMinCoverage*float64(len(raw)) || observed < 3*forecastPeriodHours {
+		return fmt.Errorf("not enough hourly data (%d of %d hours)", observed, len(raw))
+	}
+
+	// ตัดช่วงต้นที่ยังไม่มีข้อมูลออก เพื่อไม่ให้ค่าที่เติมเองครอบงำการ fit
+	first := 0
+	for first < len(raw) && math.IsNaN(raw[first]) {
+		first++
+	}
+	series := fillSeriesGaps(raw[first:])
+	trainedFrom := start.Add(time.Duration(first) * time.Hour)
+
+	backtestMAE, backtestRMSE, baselineMAE, skill := backtestForecast(series)
+
+	model, ok := fitHoltWinters(series, forecastPeriodHours)
+	if !ok {
+		return fmt.Errorf("series too short")
+	}
+
+	run := models.ForecastRun{
+		Province:     province,
+		Metric:       forecastMetric,
+		Model:        forecastModelName,
+		GeneratedAt:  time.Now(),
+		TrainedFrom:  trainedFrom,
+		TrainedTo:    end,
+		Points:       len(series),
+		Alpha:        model.alpha,
+		Beta:         model.beta,
+		Gamma:        model.gamma,
+		BacktestMAE:  backtestMAE,
+		BacktestRMSE: backtestRMSE,
+		BaselineMAE:  baselineMAE,
+		SkillScore:   skill,
+	}
+
+	tx := database.DB.Begin()
+	if err := tx.Create(&run).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	predictions := model.forecast(forecastHorizonHours)
+	rows := make([]models.Forecast, 0, len(predictions))
+	for i, p := range predictions {
+		rows = append(rows, models.Forecast{
+			RunID:       run.ID,
+			Province:    province,
+			TargetTime:  end.Add(time.Duration(i) * time.Hour),
+			HorizonHour: i + 1,
+			Value:       roundToTwoDecimals(p.value),
+			Lower80:     roundToTwoDecimals(p.lower80),
+			Upper80:     roundToTwoDecimals(p.upper80),
+			Lower95:     roundToTwoDecimals(p.lower95),
+			Upper95:     roundToTwoDecimals(p.upper95),
+		})
+	}
+	if err := tx.Create(&rows).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// backtestForecast กันข้อมูล 24 ชั่วโมงล่าสุดไว้ทดสอบ แล้วเทียบ MAE กับ seasonal naive
+// skill score = 1 - MAE(model)/MAE(naive) (> 0 แปลว่าดีกว่า baseline)
+func backtestForecast(series []float64) (mae, rmse, baselineMAE, skill float64) {
+	train := series[:len(series)-forecastBacktestHours]
+	actual := series[len(series)-forecastBacktestHours:]
+
+	model, ok := fitHoltWinters(train, forecastPeriodHours)
+	if !ok {
+		return 0, 0, 0, 0
+	}
+	predicted := make([]float64, 0, len(actual))
+	for _, p := range model.forecast(len(actual)) {
+		predicted = append(predicted, p.value)
+	}
+
+	mae = meanAbsoluteError(actual, predicted)
+	rmse = rootMeanSquaredError(actual, predicted)
+	baselineMAE = meanAbsoluteError(actual, seasonalNaive(train, forecastPeriodHours, len(actual)))
+	if baselineMAE > 0 {
+		skill = 1 - mae/baselineMAE
+	}
+	return roundToTwoDecimals(mae), roundToTwoDecimals(rmse), roundToTwoDecimals(baselineMAE), roundToTwoDecimals(skill)
+}
+
+// loadProvinceHourlySeries คืนค่าเฉลี่ยรายชั่วโมงของแต่ละจังหวัดในช่วง [start, end) ช่องที่ไม่มีข้อมูลเป็น NaN
+func loadProvinceHourlySeries(start, end time.Time) (map[string][]float64, error) {
+	query := `
+		SELECT address, province,
+		       date_trunc('hour', to_timestamp(timestamp/1000)) as time_label,
+		       AVG(NULLIF(` + forecastData.metricExpr(forecastMetric) + `,0)) as avg_val
+		FROM sensor_data
+		WHERE timestamp >= ? AND timestamp < ?` + forecastData.qualityClause() + `
+		GROUP BY address, province, time_label
+	`
+
+	type resultRow struct {
+		Address   string
+		Province  string
+		TimeLabel time.Time
+		AvgVal    *float64
+	}
+	var results []resultRow
+	if err := database.DB.Raw(query, start.UnixMilli(), end.UnixMilli()).Scan(&results).Error; err != nil {
+		return nil, err
+	}
+
+	hours := int(end.Sub(start) / time.Hour)
+	sums := make(map[string][]float64)
+	counts := make(map[string][]int)
+	for _, row := range results {
+		if row.AvgVal == nil {
+			continue
+		}
+		prov := storedProvince(row.Province, row.Address)
+		if prov == "" {
+			continue
+		}
+		idx := int(row.TimeLabel.Sub(start) / time.Hour)
+		if idx < 0 || idx >= hours {
+			continue
+		}
+		if _, ok := sums[prov]; !ok {
+			sums[prov] = make([]float64, hours)
+			counts[prov] = make([]int, hours)
+		}
+		sums[prov][idx] += *row.AvgVal
+		counts[prov][idx]++
+	}
+
+	series := make(map[string][]float64, len(sums))
+	for prov, s := range sums {
+		values := make([]float64, hours)
+		for i := range values {
+			if counts[prov][i] == 0 {
+				values[i] = math.NaN()
+			} else {
+				values[i] = s[i] / float64(counts[prov][i])
+			}
+		}
+		series[prov] = values
+	}
+	return series, nil
+}
+
+// GetLatestForecast คืนผลพยากรณ์ล่าสุดของจังหวัด (ไม่เกิน hours ชั่วโมงข้างหน้า)
+func GetLatestForecast(province string, hours int) (map[string]interface{}, error) {
+	canonical := normalizeProvince(province)
+	if canonical == "" {
+		return nil, fmt.Errorf("province is required")
+	}
+
+	var run models.ForecastRun
+	if err := database.DB.Where("province = ?", canonical).Order("generated_at DESC").First(&run).Error; err != nil {
+		return nil, err
+	}
+
+	var points []models.Forecast
+	if err := database.DB.Where("run_id = ? AND horizon_hour <= ?", run.ID, hours).
+		Order("horizon_hour ASC").Find(&points).Error; err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"province":     run.Province,
+		"metric":       run.Metric,
+		"model":        run.Model,
+		"generated_at": run.GeneratedAt,
+		"trained_from": run.TrainedFrom,
+		"trained_to":   run.TrainedTo,
+		"backtest": map[string]interface{}{
+			"horizon_hours": forecastBacktestHours,
+			"mae":           run.BacktestMAE,
+			"rmse":          run.BacktestRMSE,
+			"baseline":      "seasonal-naive",
+			"baseline_mae":  run.BaselineMAE,
+			"skill_score":   run.SkillScore,
+		},
+		"data": points,
+	}, nil
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"math"
+)
+
+// holtWinters เป็นโมเดล exponential smoothing แบบ additive seasonal + damped trend (ETS(A,Ad,A))
+// ใช้กับข้อมูลรายชั่วโมงที่มีฤดูกาลรายวัน (period = 24) ทำงานบน CPU ล้วนไม่พึ่งบริการภายนอก
+type holtWinters struct {
+	alpha, beta, gamma, phi float64
+	period                  int
+
+	level, trend float64
+	season       []float64
+	n            int     // จำนวนจุดที่ fit แล้ว
+	sigma        float64 // ส่วนเบี่ยงเบนมาตรฐานของ one-step residual
+}
+
+const forecastDampingPhi = 0.9
+
+var (
+	hwAlphaGrid = []float64{0.1, 0.2, 0.3, 0.5, 0.7, 0.9}
+	hwBetaGrid  = []float64{0.01, 0.05, 0.1, 0.2}
+	hwGammaGrid = []float64{0.05, 0.1, 0.2, 0.4}
+)
+
+// fitHoltWinters เลือก alpha/beta/gamma ด้วย grid search ที่ให้ one-step SSE ต่ำที่สุด
+// ต้องมีข้อมูลอย่างน้อย 2 รอบฤดูกาล
+func fitHoltWinters(series []float64, period int) (*holtWinters, bool) {
+	if period < 1 || len(series) < 2*period {
+		return nil, false
+	}
+
+	var best *holtWinters
+	bestSSE := math.Inf(1)
+	for _, a := range hwAlphaGrid {
+		for _, b := range hwBetaGrid {
+			for _, g := range hwGammaGrid {
+				model := &holtWinters{alpha: a, beta: b, gamma: g, phi: forecastDampingPhi, period: period}
+				sse := model.fit(series)
+				if sse < bestSSE {
+					bestSSE = sse
+					best = model
+				}
+			}
+		}
+	}
+	return best, best != nil
+}
+
+// fit ประมาณค่า level/trend/season ตามลำดับเวลาและคืนค่า sum of squared one-step errors
+func (m *holtWinters) fit(series []float64) float64 {
+	p := m.period
+	first := mean(series[:p])
+	second := mean(series[p : 2*p])
+
+	m.level = first
+	m.trend = (second - first) / float64(p)
+	m.season = make([]float64, p)
+	for i := 0; i < p; i++ {
+		m.season[i] = series[i] - first
+	}
+
+	var sse float64
+	var count int
+	for t := p; t < len(series); t++ {
+		y := series[t]
+		s := m.season[t%p]
+		yhat := m.level + m.phi*m.trend + s
+		e := y - yhat
+		sse += e * e
+		count++
+
+		prevLevel := m.level
+		m.level = m.alpha*(y-s) + (1-m.alpha)*(m.level+m.phi*m.trend)
+		m.trend = m.beta*(m.level-prevLevel) + (1-m.beta)*m.phi*m.trend
+		m.season[t%p] = m.gamma*(y-m.level) + (1-m.gamma)*s
+	}
+
+	m.n = len(series)
+	if count > 1 {
+		m.sigma = math.Sqrt(sse / float64(count-1))
+	}
+	return sse
+}
+
+type forecastPoint struct {
+	value            float64
+	lower80, upper80 float64
+	lower95, upper95 float64
+}
+
+// forecast ทำนายล่วงหน้า h ชั่วโมงพร้อม prediction interval 80% / 95%
+// ความแปรปรวนของ h-step ใช้สูตรประมาณของ ETS(A,Ad,A): σ²(1 + Σ c_j²), c_j = α(1 + βφ_j) + γ·[j mod m = 0]
+func (m *holtWinters) forecast(h int) []forecastPoint {
+	points := make([]forecastPoint, 0, h)
+	dampedSum := 0.0
+	phiPow := 1.0
+	variance := 0.0
+	for step := 1; step <= h; step++ {
+		phiPow *= m.phi
+		dampedSum += phiPow
+
+		value := m.level + dampedSum*m.trend + m.season[(m.n+step-1)%m.period]
+
+		if step > 1 {
+			j := step - 1
+			phiJ := m.phi * (1 - math.Pow(m.phi, float64(j))) / (1 - m.phi)
+			c := m.alpha * (1 + m.beta*phiJ)
+			if j%m.period == 0 {
+				c += m.gamma
+			}
+			variance += c * c
+		}
+		sd := m.sigma * math.Sqrt(1+variance)
+
+		points = append(points, forecastPoint{
+			value:   math.Max(0, value),
+			lower80: math.Max(0, value-1.2816*sd),
+			upper80: math.Max(0, value+1.2816*sd),
+			lower95: math.Max(0, value-1.96*sd),
+			upper95: math.Max(0, value+1.96*sd),
+		})
+	}
+	return points
+}
+
+// seasonalNaive ทำนายโดยใช้ค่าของรอบฤดูกาลล่าสุดซ้ำ ใช้เป็น baseline ในการ backtest
+func seasonalNaive(series []float64, period, h int) []float64 {
+	out := make([]float64, h)
+	n := len(series)
+	for i := 0; i < h; i++ {
+		out[i] = series[n-period+(i%period)]
+	}
+	return out
+}
+
+// fillSeriesGaps เติมช่องว่าง (NaN) ด้วย linear interpolation และใช้ค่าใกล้สุดสำหรับปลายทั้งสองด้าน
+func fillSeriesGaps(series []float64) []float64 {
+	out := make([]float64, len(series))
+	copy(out, series)
+
+	prev := -1
+	for i, v := range out {
+		if math.IsNaN(v) {
+			continue
+		}
+		if prev == -1 {
+			for j := 0; j < i; j++ {
+				out[j] = v
+			}
+		} else if i-prev > 1 {
+			step := (v - out[prev]) / float64(i-prev)
+			for j := prev + 1; j < i; j++ {
+				out[j] = out[prev] + step*float64(j-prev)
+			}
+		}
+		prev = i
+	}
+	if prev >= 0 {
+		for j := prev + 1; j < len(out); j++ {
+			out[j] = out[prev]
+		}
+	}
+	return out
+}
+
+func meanAbsoluteError(actual, predicted []float64) float64 {
+	var sum float64
+	for i := range actual {
+		sum += math.Abs(actual[i] - predicted[i])
+	}
+	return sum / float64(len(actual))
+}
+
+func rootMeanSquaredError(actual, predicted []float64) float64 {
+	var sum float64
+	for i := range actual {
+		d := actual[i] - predicted[i]
+		sum += d * d
+	}
+	return math.Sqrt(sum / float64(len(actual)))
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHoltWintersBeatsNaiveOnSeasonalSeries(t *testing.T) {
+	series := make([]float64, 24*10)
+	for i := range series {
+		series[i] = 40 + 15*math.Sin(2*math.Pi*float64(i%24)/24) + 0.05*float64(i)
+	}
+
+	mae, _, baseline, skill := backtestForecast(series)
+	if mae >= baseline {
+		t.Fatalf("expected model MAE %.2f to beat seasonal naive %.2f", mae, baseline)
+	}
+	if skill <= 0 {
+		t.Fatalf("expected positive skill score, got %.2f", skill)
+	}
+
+	model, ok := fitHoltWinters(series, 24)
+	if !ok {
+		t.Fatal("fit failed")
+	}
+	points := model.forecast(72)
+	if len(points) != 72 {
+		t.Fatalf("got %d points, want 72", len(points))
+	}
+	for i, p := range points {
+		if p.lower95 > p.lower80 || p.lower80 > p.value || p.value > p.upper80 || p.upper80 > p.upper95 {
+			t.Fatalf("interval not nested at h=%d: %+v", i+1, p)
+		}
+	}
+}
+
+func TestFillSeriesGaps(t *testing.T) {
+	nan := math.NaN()
+	got := fillSeriesGaps([]float64{nan, 10, nan, nan, 40, nan})
+	want := []float64{10, 10, 20, 30, 40, 40}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-9 {
+			t.Fatalf("fillSeriesGaps[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
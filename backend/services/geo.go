@@ -0,0 +1,207 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	earthRadiusKm = 6371.0
+
+	idwDefaultPower       = 2.0
+	idwDefaultMaxDistance = 50.0 // km
+	idwDefaultMaxStations = 8
+	idwExactMatchKm       = 0.05 // สถานีที่อยู่ใกล้กว่านี้ใช้ค่าของสถานีนั้นโดยตรง
+)
+
+// StationDistance คือสถานีพร้อมระยะทาง (km) และทิศ (องศาจากทิศเหนือ) จากจุดที่ค้นหา
+type StationDistance struct {
+	StationSnapshot
+	DistanceKm float64 `json:"distance_km"`
+	BearingDeg float64 `json:"bearing_deg"`
+}
+
+// IDWOptions: ค่าศูนย์ = ใช้ค่าเริ่มต้น
+type IDWOptions struct {
+	Power         float64
+	MaxDistanceKm float64
+	MaxStations   int
+}
+
+// IDWContribution คือสถานีที่ใช้ในการประมาณค่าและน้ำหนัก (รวมกันได้ 1)
+type IDWContribution struct {
+	DVID       string  `json:"dvid"`
+	Place      string  `json:"place"`
+	DistanceKm float64 `json:"distance_km"`
+	Weight     float64 `json:"weight"`
+	PM25       float64 `json:"pm25"`
+}
+
+// PointEstimate คือค่าคุณภาพอากาศที่ประมาณ ณ จุดใด ๆ ด้วย inverse-distance weighting
+type PointEstimate struct {
+	Latitude          float64           `json:"latitude"`
+	Longitude         float64           `json:"longitude"`
+	PM25              float64           `json:"pm25"`
+	PM10              float64           `json:"pm10"`
+	AQI               float64           `json:"aqi"`
+	Color             string            `json:"color"`
+	Method            string            `json:"method"` // idw | nearest-station
+	Power             float64           `json:"power"`
+	NearestDistanceKm float64           `json:"nearest_distance_km"`
+	Stations          []IDWContribution `json:"stations"`
+}
+
+// ErrNoNearbyStations: ไม่มีสถานีที่ยัง online ภายในระยะที่กำหนด
+var ErrNoNearbyStations = errors.New("no reporting stations within range")
+
+// haversineKm คืนระยะทางบนผิวโลกระหว่างสองพิกัด (km)
+func haversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	rlat1, rlat2 := lat1*math.Pi/180, lat2*math.Pi/180
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLon := (lon2 - lon1) * math.Pi / 180
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(rlat1)*math.Cos(rlat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// bearingDeg คืนทิศจากจุดแรกไปจุดที่สอง (0 = เหนือ, 90 = ตะวันออก)
+func bearingDeg(lat1, lon1, lat2, lon2 float64) float64 {
+	rlat1, rlat2 := lat1*math.Pi/180, lat2*math.Pi/180
+	dLon := (lon2 - lon1) * math.Pi / 180
+	y := math.Sin(dLon) * math.Cos(rlat2)
+	x := math.Cos(rlat1)*math.Sin(rlat2) - math.Sin(rlat1)*math.Cos(rlat2)*math.Cos(dLon)
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
+
+// ValidCoordinate ตรวจช่วงของ latitude/longitude
+func ValidCoordinate(lat, lon float64) bool {
+	return lat >= -90 && lat <= 90 && lon >= -180 && lon <= 180
+}
+
+// sortByDistance คำนวณระยะของทุกสถานีจากจุด (lat, lon) แล้วเรียงจากใกล้ไปไกล
+// สถานีที่ไม่มีพิกัด (0,0) จะถูกข้าม
+func sortByDistance(stations []StationSnapshot, lat, lon float64) []StationDistance {
+	result := make([]StationDistance, 0, len(stations))
+	for _, s := range stations {
+		if s.Latitude == 0 && s.Longitude == 0 {
+			continue
+		}
+		result = append(result, StationDistance{
+			StationSnapshot: s,
+			DistanceKm:      roundToTwoDecimals(haversineKm(lat, lon, s.Latitude, s.Longitude)),
+			BearingDeg:      math.Round(bearingDeg(lat, lon, s.Latitude, s.Longitude)),
+		})
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].DistanceKm < result[j].DistanceKm })
+	return result
+}
+
+// GetNearestStations คืน limit สถานีที่ใกล้จุดที่สุด (maxDistanceKm <= 0 = ไม่จำกัดระยะ)
+func GetNearestStations(lat, lon float64, limit int, maxDistanceKm float64, maxAge time.Duration) ([]StationDistance, error) {
+	stations, _, err := GetLatestStations(StationFilter{MaxAge: maxAge})
+	if err != nil {
+		return nil, err
+	}
+	sorted := sortByDistance(stations, lat, lon)
+	result := []StationDistance{}
+	for _, s := range sorted {
+		if len(result) >= limit || (maxDistanceKm > 0 && s.DistanceKm > maxDistanceKm) {
+			break
+		}
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+// GetStationsWithinRadius คืนทุกสถานีภายในรัศมี radiusKm เรียงจากใกล้ไปไกล
+func GetStationsWithinRadius(lat, lon, radiusKm float64, maxAge time.Duration) ([]StationDistance, error) {
+	return GetNearestStations(lat, lon, math.MaxInt, radiusKm, maxAge)
+}
+
+// EstimateAtPoint ประมาณค่าคุณภาพอากาศ ณ จุดจากสถานีที่ไม่ offline (snapshot มีแต่ค่าที่ไม่ถูก flag อยู่แล้ว)
+func EstimateAtPoint(lat, lon float64, opts IDWOptions) (*PointEstimate, error) {
+	stations, _, err := GetLatestStations(StationFilter{})
+	if err != nil {
+		return nil, err
+	}
+	usable := make([]StationSnapshot, 0, len(stations))
+	for _, s := range stations {
+		if s.Status != "offline" {
+			usable = append(usable, s)
+		}
+	}
+
+	estimate, err := inverseDistanceWeighting(sortByDistance(usable, lat, lon), opts)
+	if err != nil {
+		return nil, err
+	}
+	estimate.Latitude, estimate.Longitude = lat, lon
+
+	bands, err := GetAllColorRanges()
+	if err != nil {
+		return nil, err
+	}
+	if len(bands) > 0 {
+		estimate.Color = bands[colorBandIndex(bands, estimate.PM25)].Color
+	}
+	return estimate, nil
+}
+
+// inverseDistanceWeighting รับสถานีที่เรียงตามระยะแล้ว น้ำหนัก = 1/d^power
+func inverseDistanceWeighting(sorted []StationDistance, opts IDWOptions) (*PointEstimate, error) {
+	if opts.Power <= 0 {
+		opts.Power = idwDefaultPower
+	}
+	if opts.MaxDistanceKm <= 0 {
+		opts.MaxDistanceKm = idwDefaultMaxDistance
+	}
+	if opts.MaxStations <= 0 {
+		opts.MaxStations = idwDefaultMaxStations
+	}
+
+	var nearby []StationDistance
+	for _, s := range sorted {
+		if s.DistanceKm > opts.MaxDistanceKm || len(nearby) >= opts.MaxStations {
+			break
+		}
+		nearby = append(nearby, s)
+	}
+	if len(nearby) == 0 {
+		return nil, ErrNoNearbyStations
+	}
+
+	estimate := &PointEstimate{Method: "idw", Power: opts.Power, NearestDistanceKm: nearby[0].DistanceKm}
+	if nearby[0].DistanceKm <= idwExactMatchKm {
+		s := nearby[0]
+		estimate.Method = "nearest-station"
+		estimate.PM25, estimate.PM10, estimate.AQI = s.PM25, s.PM10, float64(s.AQI)
+		estimate.Stations = []IDWContribution{{DVID: s.DVID, Place: s.Place, DistanceKm: s.DistanceKm, Weight: 1, PM25: s.PM25}}
+		return estimate, nil
+	}
+
+	weights := make([]float64, len(nearby))
+	total := 0.0
+	for i, s := range nearby {
+		weights[i] = 1 / math.Pow(s.DistanceKm, opts.Power)
+		total += weights[i]
+	}
+	var pm25, pm10, aqi float64
+	for i, s := range nearby {
+		w := weights[i] / total
+		pm25 += w * s.PM25
+		pm10 += w * s.PM10
+		aqi += w * float64(s.AQI)
+		estimate.Stations = append(estimate.Stations, IDWContribution{
+			DVID:       s.DVID,
+			Place:      s.Place,
+			DistanceKm: s.DistanceKm,
+			Weight:     math.Round(w*10000) / 10000,
+			PM25:       s.PM25,
+		})
+	}
+	estimate.PM25 = roundToTwoDecimals(pm25)
+	estimate.PM10 = roundToTwoDecimals(pm10)
+	estimate.AQI = math.Round(aqi)
+	return estimate, nil
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHaversineKm(t *testing.T) {
+	// เชียงใหม่ → เชียงราย ประมาณ 160 km ตามแนวเส้นตรง
+	d := haversineKm(18.7883, 98.9853, 19.9105, 99.8406)
+	if d < 150 || d > 160 {
+		t.Fatalf("distance = %.1f km", d)
+	}
+	if got := bearingDeg(18.0, 99.0, 19.0, 99.0); math.Abs(got) > 1e-6 {
+		t.Fatalf("bearing due north = %v", got)
+	}
+}
+
+func TestInverseDistanceWeighting(t *testing.T) {
+	stations := []StationDistance{
+		{StationSnapshot: StationSnapshot{DVID: "A", PM25: 10, AQI: 20}, DistanceKm: 1},
+		{StationSnapshot: StationSnapshot{DVID: "B", PM25: 40, AQI: 80}, DistanceKm: 2},
+		{StationSnapshot: StationSnapshot{DVID: "far", PM25: 500}, DistanceKm: 80},
+	}
+
+	est, err := inverseDistanceWeighting(stations, IDWOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// น้ำหนัก 1 : 1/4 → (10*1 + 40*0.25) / 1.25 = 16
+	if est.PM25 != 16 || est.AQI != 32 || len(est.Stations) != 2 {
+		t.Fatalf("estimate = %+v", est)
+	}
+
+	exact, _ := inverseDistanceWeighting([]StationDistance{{StationSnapshot: StationSnapshot{DVID: "A", PM25: 12}, DistanceKm: 0.01}}, IDWOptions{})
+	if exact.Method != "nearest-station" || exact.PM25 != 12 {
+		t.Fatalf("exact = %+v", exact)
+	}
+
+	if _, err := inverseDistanceWeighting(stations[2:], IDWOptions{}); err != ErrNoNearbyStations {
+		t.Fatalf("expected ErrNoNearbyStations, got %v", err)
+	}
+}
+
+func TestStationsGeoJSON(t *testing.T) {
+	fc := StationsGeoJSON([]StationSnapshot{{DVID: "A", Latitude: 18.79, Longitude: 98.98, PM25: 42, Color: "#ff0"}})
+	if fc.Type != "FeatureCollection" || len(fc.Features) != 1 {
+		t.Fatalf("collection = %+v", fc)
+	}
+	f := fc.Features[0]
+	coords := f.Geometry.Coordinates.([]float64)
+	if f.Geometry.Type != "Point" || coords[0] != 98.98 || coords[1] != 18.79 {
+		t.Fatalf("geometry must be [lon, lat], got %v", coords)
+	}
+	if f.Properties["pm25"] != 42.0 || f.Properties["color"] != "#ff0" {
+		t.Fatalf("properties = %v", f.Properties)
+	}
+
+	if empty := StationsGeoJSON(nil); empty.Features == nil {
+		t.Fatal("features must encode as [] rather than null")
+	}
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"yakkaw_dashboard/models"
+)
+
+// GeoJSON types (RFC 7946) ใช้กับ map layer ของ dashboard
+type GeoJSONGeometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+type GeoJSONFeature struct {
+	Type       string                 `json:"type"`
+	ID         interface{}            `json:"id,omitempty"`
+	Geometry   *GeoJSONGeometry       `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type GeoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []GeoJSONFeature `json:"features"`
+}
+
+func newFeatureCollection() GeoJSONFeatureCollection {
+	return GeoJSONFeatureCollection{Type: "FeatureCollection", Features: []GeoJSONFeature{}}
+}
+
+// pointGeometry: GeoJSON เรียงพิกัดเป็น [longitude, latitude]
+func pointGeometry(lat, lon float64) *GeoJSONGeometry {
+	return &GeoJSONGeometry{Type: "Point", Coordinates: []float64{lon, lat}}
+}
+
+// StationsGeoJSON แปลง snapshot ของสถานีเป็น FeatureCollection ของ Point
+func StationsGeoJSON(stations []StationSnapshot) GeoJSONFeatureCollection {
+	fc := newFeatureCollection()
+	for _, s := range stations {
+		fc.Features = append(fc.Features, GeoJSONFeature{
+			Type:       "Feature",
+			ID:         s.DVID,
+			Geometry:   pointGeometry(s.Latitude, s.Longitude),
+			Properties: stationProperties(s),
+		})
+	}
+	return fc
+}
+
+// StationDistancesGeoJSON เหมือน StationsGeoJSON แต่เพิ่มระยะทางและทิศจากจุดที่ค้นหา
+func StationDistancesGeoJSON(stations []StationDistance) GeoJSONFeatureCollection {
+	fc := newFeatureCollection()
+	for _, s := range stations {
+		props := stationProperties(s.StationSnapshot)
+		props["distance_km"] = s.DistanceKm
+		props["bearing_deg"] = s.BearingDeg
+		fc.Features = append(fc.Features, GeoJSONFeature{
+			Type:       "Feature",
+			ID:         s.DVID,
+			Geometry:   pointGeometry(s.Latitude, s.Longitude),
+			Properties: props,
+		})
+	}
+	return fc
+}
+
+func stationProperties(s StationSnapshot) map[string]interface{} {
+	props := map[string]interface{}{
+		"dvid":        s.DVID,
+		"place":       s.Place,
+		"address":     s.Address,
+		"province":    s.Province,
+		"district":    s.District,
+		"subdistrict": s.Subdistrict,
+		"region":      s.Region,
+		"timestamp":   s.Timestamp,
+		"observed_at": s.ObservedAt,
+		"age_minutes": s.AgeMinutes,
+		"status":      s.Status,
+		"pm25":        s.PM25,
+		"pm10":        s.PM10,
+		"pm100":       s.PM100,
+		"aqi":         s.AQI,
+		"temperature": s.Temperature,
+		"humidity":    s.Humidity,
+		"pressure":    s.Pressure,
+		"color":       s.Color,
+		"color_min":   s.ColorMin,
+		"color_max":   s.ColorMax,
+		"trend":       s.Trend,
+	}
+	if s.PM25Change1h != nil {
+		props["pm25_change_1h"] = *s.PM25Change1h
+	}
+	return props
+}
+
+// ProvinceAveragesGeoJSON แปลงผลของ GetProvinceAveragePM25 เป็น FeatureCollection
+// geometry เป็นจุดกึ่งกลางของสถานีในจังหวัดนั้น (null เมื่อไม่พบพิกัดของสถานี)
+func ProvinceAveragesGeoJSON(rows []map[string]interface{}) (GeoJSONFeatureCollection, error) {
+	return areaAveragesGeoJSON(rows, "province")
+}
+
+// RegionAveragesGeoJSON แปลงผลของ GetRegionAveragePM25 เป็น FeatureCollection (จุดกึ่งกลางของสถานีในภาค)
+func RegionAveragesGeoJSON(rows []map[string]interface{}) (GeoJSONFeatureCollection, error) {
+	return areaAveragesGeoJSON(rows, "region")
+}
+
+func areaAveragesGeoJSON(rows []map[string]interface{}, key string) (GeoJSONFeatureCollection, error) {
+	stations, _, err := GetLatestStations(StationFilter{})
+	if err != nil {
+		return GeoJSONFeatureCollection{}, err
+	}
+	bands, err := GetAllColorRanges()
+	if err != nil {
+		return GeoJSONFeatureCollection{}, err
+	}
+
+	centroids := areaCentroids(stations, key)
+	fc := newFeatureCollection()
+	for _, row := range rows {
+		props := make(map[string]interface{}, len(row)+3)
+		for k, v := range row {
+			props[k] = v
+		}
+		area, _ := row[key].(string)
+		if avg, ok := row["avg_pm25"].(float64); ok {
+			addBandProperties(props, bands, avg)
+		}
+
+		feature := GeoJSONFeature{Type: "Feature", ID: area, Properties: props}
+		if key == "province" {
+			area = normalizeProvince(area)
+		}
+		if c, ok := centroids[area]; ok {
+			feature.Geometry = pointGeometry(c[0], c[1])
+		}
+		fc.Features = append(fc.Features, feature)
+	}
+	return fc, nil
+}
+
+func addBandProperties(props map[string]interface{}, bands []models.ColorRange, value float64) {
+	if len(bands) == 0 {
+		return
+	}
+	band := bands[colorBandIndex(bands, value)]
+	props["color"] = band.Color
+	props["color_min"] = band.Min
+	props["color_max"] = band.Max
+}
+
+// areaCentroids คืนค่าเฉลี่ย [lat, lon] ของสถานีในแต่ละจังหวัด (key = "province") หรือภาค (key = "region")
+func areaCentroids(stations []StationSnapshot, key string) map[string][2]float64 {
+	sums := make(map[string][3]float64)
+	for _, s := range stations {
+		area := s.Province
+		if key == "region" {
+			area = s.Region
+		}
+		if area == "" || (s.Latitude == 0 && s.Longitude == 0) {
+			continue
+		}
+		acc := sums[area]
+		acc[0] += s.Latitude
+		acc[1] += s.Longitude
+		acc[2]++
+		sums[area] = acc
+	}
+	centroids := make(map[string][2]float64, len(sums))
+	for prov, acc := range sums {
+		centroids[prov] = [2]float64{acc[0] / acc[2], acc[1] / acc[2]}
+	}
+	return centroids
+}
@@ -0,0 +1,391 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"yakkaw_dashboard/database"
+	"yakkaw_dashboard/models"
+)
+
+const (
+	gridDefaultResolution = 0.05 // องศา (~5.5 km)
+	gridMinResolution     = 0.005
+	gridMaxCells          = 250000
+	gridBBoxPadding       = 0.1 // องศา รอบสถานีเมื่อไม่ระบุ bbox
+	gridMaxAverageHours   = 24 * 7
+	gridMaxCached         = 64 // จำนวน grid (ต่าง option กัน) ที่ cache ได้ต่อรอบ ingest
+)
+
+// GridOptions: ค่าศูนย์ = ใช้ค่าเริ่มต้น
+type GridOptions struct {
+	BBox          *[4]float64 // minLon, minLat, maxLon, maxLat (ไม่ระบุ = ครอบคลุมทุกสถานี)
+	Resolution    float64     // ขนาด cell เป็นองศา
+	Source        string      // current | average
+	Hours         int         // ช่วงเฉลี่ยเมื่อ Source = average
+	Power         float64
+	MaxDistanceKm float64
+}
+
+// PM25Grid คือผล interpolation แบบ regular grid
+// Values[row][col]: row 0 = ใต้สุด (MinLat), col 0 = ตะวันตกสุด (MinLon) ค่า nil = ไม่มีสถานีในระยะ
+type PM25Grid struct {
+	BBox        [4]float64   `json:"bbox"`
+	Resolution  float64      `json:"resolution"`
+	Rows        int          `json:"rows"`
+	Cols        int          `json:"cols"`
+	Source      string       `json:"source"`
+	Hours       int          `json:"hours,omitempty"`
+	Method      string       `json:"method"`
+	Power       float64      `json:"power"`
+	Stations    int          `json:"stations"`
+	GeneratedAt time.Time    `json:"generated_at"`
+	Values      [][]*float64 `json:"values"`
+}
+
+// gridPoint คือค่าของสถานีที่ใช้ interpolate
+type gridPoint struct {
+	lat, lon, value float64
+}
+
+// gridCache เก็บผลตาม option และถูกล้างทุกครั้งที่ ingest เสร็จ (ผ่าน RefreshStationSnapshots)
+var gridCache = struct {
+	sync.Mutex
+	grids map[string]*PM25Grid
+}{grids: make(map[string]*PM25Grid)}
+
+func invalidateGridCache() {
+	gridCache.Lock()
+	gridCache.grids = make(map[string]*PM25Grid)
+	gridCache.Unlock()
+}
+
+// BuildPM25Grid สร้าง (หรือคืนจาก cache) grid PM2.5 ด้วย inverse-distance weighting
+func BuildPM25Grid(opts GridOptions) (*PM25Grid, error) {
+	if opts.Resolution == 0 {
+		opts.Resolution = gridDefaultResolution
+	}
+	if opts.Resolution < gridMinResolution {
+		return nil, fmt.Errorf("resolution must be at least %g degrees", gridMinResolution)
+	}
+	if opts.Power <= 0 {
+		opts.Power = idwDefaultPower
+	}
+	if opts.MaxDistanceKm <= 0 {
+		opts.MaxDistanceKm = idwDefaultMaxDistance
+	}
+	switch opts.Source {
+	case "", "current":
+		opts.Source, opts.Hours = "current", 0
+	case "average":
+		if opts.Hours <= 0 {
+			opts.Hours = 24
+		}
+		if opts.Hours > gridMaxAverageHours {
+			return nil, fmt.Errorf("hours must not exceed %d", gridMaxAverageHours)
+		}
+	default:
+		return nil, errors.New("source must be current or average")
+	}
+
+	key := fmt.Sprintf("%v|%g|%s|%d|%g|%g", opts.BBox, opts.Resolution, opts.Source, opts.Hours, opts.Power, opts.MaxDistanceKm)
+	gridCache.Lock()
+	cached, ok := gridCache.grids[key]
+	gridCache.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	points, err := loadGridPoints(opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(points) == 0 {
+		return nil, ErrNoNearbyStations
+	}
+
+	bbox := stationsBBox(points)
+	if opts.BBox != nil {
+		bbox = *opts.BBox
+	}
+	grid, err := interpolateGrid(points, bbox, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	gridCache.Lock()
+	if len(gridCache.grids) >= gridMaxCached {
+		gridCache.grids = make(map[string]*PM25Grid)
+	}
+	gridCache.grids[key] = grid
+	gridCache.Unlock()
+	return grid, nil
+}
+
+// loadGridPoints ใช้ค่าล่าสุดจาก station snapshot หรือค่าเฉลี่ยย้อนหลังจาก sensor_data (ตัดข้อมูลที่ถูก flag)
+func loadGridPoints(opts GridOptions) ([]gridPoint, error) {
+	var points []gridPoint
+	if opts.Source == "current" {
+		stations, _, err := GetLatestStations(StationFilter{})
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range stations {
+			if s.Status == "offline" || (s.Latitude == 0 && s.Longitude == 0) {
+				continue
+			}
+			points = append(points, gridPoint{lat: s.Latitude, lon: s.Longitude, value: s.PM25})
+		}
+		return points, nil
+	}
+
+	var rows []struct {
+		Latitude  float64
+		Longitude float64
+		AvgPM25   float64
+	}
+	if err := database.DB.Raw(`
+		SELECT AVG(latitude) AS latitude, AVG(longitude) AS longitude,
+		       AVG(`+DataOptions{}.metricExpr("pm25")+`) AS avg_pm25
+		FROM sensor_data
+		WHERE timestamp >= ? AND latitude <> 0 AND longitude <> 0`+DataOptions{}.qualityClause()+`
+		GROUP BY dvid
+	`, time.Now().Add(-time.Duration(opts.Hours)*time.Hour).UnixMilli()).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		points = append(points, gridPoint{lat: r.Latitude, lon: r.Longitude, value: r.AvgPM25})
+	}
+	return points, nil
+}
+
+func stationsBBox(points []gridPoint) [4]float64 {
+	bbox := [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	for _, p := range points {
+		bbox[0] = math.Min(bbox[0], p.lon)
+		bbox[1] = math.Min(bbox[1], p.lat)
+		bbox[2] = math.Max(bbox[2], p.lon)
+		bbox[3] = math.Max(bbox[3], p.lat)
+	}
+	return [4]float64{bbox[0] - gridBBoxPadding, bbox[1] - gridBBoxPadding, bbox[2] + gridBBoxPadding, bbox[3] + gridBBoxPadding}
+}
+
+// interpolateGrid คำนวณค่า IDW ที่จุดกึ่งกลางของทุก cell
+func interpolateGrid(points []gridPoint, bbox [4]float64, opts GridOptions) (*PM25Grid, error) {
+	cols := int(math.Ceil((bbox[2] - bbox[0]) / opts.Resolution))
+	rows := int(math.Ceil((bbox[3] - bbox[1]) / opts.Resolution))
+	if cols <= 0 || rows <= 0 {
+		return nil, errors.New("bbox is empty")
+	}
+	if cols*rows > gridMaxCells {
+		return nil, fmt.Errorf("grid too large (%d cells); use a coarser resolution or a smaller bbox", cols*rows)
+	}
+
+	values := make([][]*float64, rows)
+	for r := 0; r < rows; r++ {
+		values[r] = make([]*float64, cols)
+		lat := bbox[1] + (float64(r)+0.5)*opts.Resolution
+		for c := 0; c < cols; c++ {
+			lon := bbox[0] + (float64(c)+0.5)*opts.Resolution
+			if v, ok := idwAt(points, lat, lon, opts); ok {
+				values[r][c] = &v
+			}
+		}
+	}
+
+	return &PM25Grid{
+		BBox:        bbox,
+		Resolution:  opts.Resolution,
+		Rows:        rows,
+		Cols:        cols,
+		Source:      opts.Source,
+		Hours:       opts.Hours,
+		Method:      "idw",
+		Power:       opts.Power,
+		Stations:    len(points),
+		GeneratedAt: time.Now(),
+		Values:      values,
+	}, nil
+}
+
+func idwAt(points []gridPoint, lat, lon float64, opts GridOptions) (float64, bool) {
+	var sum, total float64
+	for _, p := range points {
+		d := haversineKm(lat, lon, p.lat, p.lon)
+		if d > opts.MaxDistanceKm {
+			continue
+		}
+		if d <= idwExactMatchKm {
+			return roundToTwoDecimals(p.value), true
+		}
+		w := 1 / math.Pow(d, opts.Power)
+		sum += w * p.value
+		total += w
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return roundToTwoDecimals(sum / total), true
+}
+
+// ContoursGeoJSON สร้างเส้น contour (marching squares) ที่ขอบล่างของแต่ละ ColorRange
+// หนึ่ง Feature (MultiLineString) ต่อหนึ่งระดับ bands ต้องเรียงตาม Min เหมือนที่ GetAllColorRanges คืน
+func (g *PM25Grid) ContoursGeoJSON(bands []models.ColorRange) GeoJSONFeatureCollection {
+	fc := newFeatureCollection()
+	for i := 1; i < len(bands); i++ {
+		level := float64(bands[i].Min)
+		segments := g.contourSegments(level)
+		if len(segments) == 0 {
+			continue
+		}
+		fc.Features = append(fc.Features, GeoJSONFeature{
+			Type:     "Feature",
+			ID:       bands[i].Min,
+			Geometry: &GeoJSONGeometry{Type: "MultiLineString", Coordinates: segments},
+			Properties: map[string]interface{}{
+				"level": level,
+				"color": bands[i].Color,
+				"min":   bands[i].Min,
+				"max":   bands[i].Max,
+			},
+		})
+	}
+	return fc
+}
+
+// contourSegments คืนเส้นตรงแต่ละช่วงของ isoline ที่ค่า level (พิกัด [lon, lat])
+func (g *PM25Grid) contourSegments(level float64) [][][2]float64 {
+	var segments [][][2]float64
+	for r := 0; r+1 < g.Rows; r++ {
+		for c := 0; c+1 < g.Cols; c++ {
+			bl, br, tr, tl := g.Values[r][c], g.Values[r][c+1], g.Values[r+1][c+1], g.Values[r+1][c]
+			if bl == nil || br == nil || tr == nil || tl == nil {
+				continue
+			}
+			x0 := g.BBox[0] + (float64(c)+0.5)*g.Resolution
+			y0 := g.BBox[1] + (float64(r)+0.5)*g.Resolution
+			for _, seg := range marchingSquare(*bl, *br, *tr, *tl, level) {
+				segments = append(segments, [][2]float64{
+					{roundCoord(x0 + seg[0][0]*g.Resolution), roundCoord(y0 + seg[0][1]*g.Resolution)},
+					{roundCoord(x0 + seg[1][0]*g.Resolution), roundCoord(y0 + seg[1][1]*g.Resolution)},
+				})
+			}
+		}
+	}
+	return segments
+}
+
+// marchingSquare คืน segment ภายใน cell หนึ่งช่อง ในพิกัด 0..1 (x ไปทางตะวันออก, y ไปทางเหนือ)
+func marchingSquare(bl, br, tr, tl, level float64) [][2][2]float64 {
+	interp := func(a, b float64) float64 {
+		if a == b {
+			return 0.5
+		}
+		return (level - a) / (b - a)
+	}
+	bottom := [2]float64{interp(bl, br), 0}
+	right := [2]float64{1, interp(br, tr)}
+	top := [2]float64{interp(tl, tr), 1}
+	left := [2]float64{0, interp(bl, tl)}
+
+	idx := 0
+	if bl >= level {
+		idx |= 1
+	}
+	if br >= level {
+		idx |= 2
+	}
+	if tr >= level {
+		idx |= 4
+	}
+	if tl >= level {
+		idx |= 8
+	}
+
+	switch idx {
+	case 0, 15:
+		return nil
+	case 1, 14:
+		return [][2][2]float64{{left, bottom}}
+	case 2, 13:
+		return [][2][2]float64{{bottom, right}}
+	case 3, 12:
+		return [][2][2]float64{{left, right}}
+	case 4, 11:
+		return [][2][2]float64{{top, right}}
+	case 6, 9:
+		return [][2][2]float64{{bottom, top}}
+	case 7, 8:
+		return [][2][2]float64{{left, top}}
+	case 5, 10:
+		// saddle: ใช้ค่าเฉลี่ยของ cell ตัดสินว่าจะเชื่อมด้านไหน
+		center := (bl + br + tr + tl) / 4
+		if (center >= level) == (idx == 5) {
+			return [][2][2]float64{{left, top}, {bottom, right}}
+		}
+		return [][2][2]float64{{left, bottom}, {top, right}}
+	}
+	return nil
+}
+
+func roundCoord(v float64) float64 {
+	return math.Round(v*1e5) / 1e5
+}
+
+// PNG วาด grid เป็นภาพ (1 pixel ต่อ cell × scale) ด้วยสีของ ColorRange ช่องที่ไม่มีค่าจะโปร่งใส
+// แถวบนสุดของภาพคือทิศเหนือ bands ต้องเรียงตาม Min
+func (g *PM25Grid) PNG(bands []models.ColorRange, scale int) ([]byte, error) {
+	if scale < 1 {
+		scale = 1
+	}
+	palette := make([]color.NRGBA, len(bands))
+	for i, b := range bands {
+		palette[i] = parseHexColor(b.Color, 200)
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, g.Cols*scale, g.Rows*scale))
+	for r := 0; r < g.Rows; r++ {
+		for c := 0; c < g.Cols; c++ {
+			v := g.Values[r][c]
+			if v == nil || len(palette) == 0 {
+				continue
+			}
+			px := palette[colorBandIndex(bands, *v)]
+			y0 := (g.Rows - 1 - r) * scale
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetNRGBA(c*scale+dx, y0+dy, px)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// parseHexColor อ่านสีแบบ #RRGGBB หรือ #RGB (ค่าที่อ่านไม่ได้จะเป็นสีเทา)
+func parseHexColor(s string, alpha uint8) color.NRGBA {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	if len(s) == 6 {
+		if v, err := strconv.ParseUint(s, 16, 32); err == nil {
+			return color.NRGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: alpha}
+		}
+	}
+	return color.NRGBA{R: 128, G: 128, B: 128, A: alpha}
+}
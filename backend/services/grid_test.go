@@ -0,0 +1,87 @@
+package services
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	"yakkaw_dashboard/models"
+)
+
+func gridOf(rows [][]float64) *PM25Grid {
+	values := make([][]*float64, len(rows))
+	for r, row := range rows {
+		values[r] = make([]*float64, len(row))
+		for c := range row {
+			values[r][c] = &row[c]
+		}
+	}
+	return &PM25Grid{BBox: [4]float64{98, 18, 98 + float64(len(rows[0])), 18 + float64(len(rows))}, Resolution: 1, Rows: len(rows), Cols: len(rows[0]), Values: values}
+}
+
+func TestMarchingSquare(t *testing.T) {
+	// มุมซ้ายล่างเท่านั้นที่เกินระดับ → เส้นตัดด้านซ้ายและด้านล่างที่จุดกึ่งกลาง
+	segs := marchingSquare(20, 0, 0, 0, 10)
+	if len(segs) != 1 || segs[0][0] != [2]float64{0, 0.5} || segs[0][1] != [2]float64{0.5, 0} {
+		t.Fatalf("segments = %v", segs)
+	}
+	if segs := marchingSquare(5, 5, 5, 5, 10); segs != nil {
+		t.Fatalf("flat cell below level must have no segments, got %v", segs)
+	}
+	if segs := marchingSquare(20, 0, 20, 0, 10); len(segs) != 2 {
+		t.Fatalf("saddle must produce two segments, got %v", segs)
+	}
+}
+
+func TestContoursGeoJSON(t *testing.T) {
+	g := gridOf([][]float64{
+		{10, 10, 10},
+		{10, 60, 10},
+		{10, 10, 10},
+	})
+	bands := []models.ColorRange{{Min: 0, Max: 25, Color: "#00ff00"}, {Min: 50, Max: 100, Color: "#ff0000"}, {Min: 100, Max: 500, Color: "#800080"}}
+
+	fc := g.ContoursGeoJSON(bands)
+	if len(fc.Features) != 1 {
+		t.Fatalf("expected one contour (level 50), got %d", len(fc.Features))
+	}
+	f := fc.Features[0]
+	segments := f.Geometry.Coordinates.([][][2]float64)
+	if f.Geometry.Type != "MultiLineString" || len(segments) != 4 || f.Properties["color"] != "#ff0000" {
+		t.Fatalf("contour = %+v", f)
+	}
+}
+
+func TestGridPNG(t *testing.T) {
+	g := gridOf([][]float64{{10, 80}})
+	g.Values[0][0] = nil
+	out, err := g.PNG([]models.ColorRange{{Min: 0, Color: "#00ff00"}, {Min: 50, Color: "#ff0000"}}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 4 || b.Dy() != 2 {
+		t.Fatalf("bounds = %v", b)
+	}
+	if _, _, _, a := img.At(0, 0).RGBA(); a != 0 {
+		t.Fatal("cell without value must be transparent")
+	}
+	if r, g, _, _ := img.At(3, 1).RGBA(); r == 0 || g != 0 {
+		t.Fatal("cell at 80 must use the red band")
+	}
+}
+
+func TestParseHexColor(t *testing.T) {
+	if c := parseHexColor("#ff8000", 255); c.R != 255 || c.G != 128 || c.B != 0 {
+		t.Fatalf("color = %+v", c)
+	}
+	if c := parseHexColor("#0f0", 10); c.G != 255 || c.A != 10 {
+		t.Fatalf("short color = %+v", c)
+	}
+	if c := parseHexColor("green", 255); c.R != 128 {
+		t.Fatalf("invalid color should fall back to grey, got %+v", c)
+	}
+}
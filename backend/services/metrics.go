@@ -0,0 +1,151 @@
+package services
+
+import (
+	"log"
+	"runtime"
+	"time"
+
+	"yakkaw_dashboard/database"
+	"yakkaw_dashboard/utils"
+)
+
+// metric ของรอบ ingest (FetchAndStoreData) นับสะสมตั้งแต่ process เริ่ม
+var (
+	ingestRuns     = utils.Metrics.CounterVec("yakkaw_ingest_runs_total", "Ingestion runs by result (success or error).", "result")
+	ingestDuration = utils.Metrics.HistogramVec("yakkaw_ingest_duration_seconds", "Duration of ingestion runs in seconds.",
+		[]float64{1, 2.5, 5, 10, 20, 30, 60, 120, 300})
+	ingestRows = utils.Metrics.CounterVec("yakkaw_ingest_rows_total",
+		"Rows seen by ingestion: received from the upstream API, invalid (skipped), inserted, failed to insert, flagged by quality checks.", "outcome")
+	ingestErrors = utils.Metrics.CounterVec("yakkaw_ingest_errors_total",
+		"Ingestion errors by stage (fetch, read, decode, insert, snapshot, alert_rules, subscriptions).", "stage")
+	ingestLastRun = utils.Metrics.GaugeVec("yakkaw_ingest_last_run_timestamp_seconds", "Unix time of the last ingestion run by result.", "result")
+)
+
+var processStart = time.Now()
+
+// ingestRun เก็บผลของ ingest หนึ่งรอบ และบันทึก metric ตอน finish
+type ingestRun struct {
+	start  time.Time
+	failed bool
+}
+
+func newIngestRun() *ingestRun {
+	return &ingestRun{start: time.Now()}
+}
+
+// fail นับ error ของขั้นตอน stage และทำให้รอบนี้ถูกนับเป็น error
+func (r *ingestRun) fail(stage string) {
+	r.failed = true
+	ingestErrors.Inc(stage)
+}
+
+func (r *ingestRun) rows(outcome string, n int) {
+	ingestRows.Add(float64(n), outcome)
+}
+
+func (r *ingestRun) finish() {
+	result := "success"
+	if r.failed {
+		result = "error"
+	}
+	ingestRuns.Inc(result)
+	ingestDuration.Observe(time.Since(r.start).Seconds())
+	ingestLastRun.Set(float64(time.Now().Unix()), result)
+}
+
+// GatherMetrics สร้างเอกสาร Prometheus: metric ที่นับสะสม (HTTP, ingest) ตามด้วยค่าที่อ่านตอน scrape
+// (connection pool, runtime และค่าล่าสุดของแต่ละสถานีจาก station snapshot cache ไม่ query ฐานข้อมูลเพิ่ม)
+func GatherMetrics() []byte {
+	w := utils.NewMetricsWriter()
+	utils.Metrics.WriteTo(w)
+	writeDBPoolMetrics(w)
+	writeRuntimeMetrics(w)
+	writeStationMetrics(w)
+	return w.Bytes()
+}
+
+func writeDBPoolMetrics(w *utils.MetricsWriter) {
+	if database.DB == nil {
+		return
+	}
+	sqlDB, err := database.DB.DB()
+	if err != nil {
+		return
+	}
+	stats := sqlDB.Stats()
+	for _, m := range []struct {
+		name, typ, help string
+		value           float64
+	}{
+		{"db_pool_max_open_connections", "gauge", "Maximum number of open connections to the database (0 = unlimited).", float64(stats.MaxOpenConnections)},
+		{"db_pool_open_connections", "gauge", "Established connections, both in use and idle.", float64(stats.OpenConnections)},
+		{"db_pool_in_use_connections", "gauge", "Connections currently in use.", float64(stats.InUse)},
+		{"db_pool_idle_connections", "gauge", "Idle connections.", float64(stats.Idle)},
+		{"db_pool_wait_count_total", "counter", "Total number of connections waited for.", float64(stats.WaitCount)},
+		{"db_pool_wait_duration_seconds_total", "counter", "Total time blocked waiting for a new connection.", stats.WaitDuration.Seconds()},
+		{"db_pool_max_idle_closed_total", "counter", "Connections closed due to SetMaxIdleConns.", float64(stats.MaxIdleClosed)},
+		{"db_pool_max_idle_time_closed_total", "counter", "Connections closed due to SetConnMaxIdleTime.", float64(stats.MaxIdleTimeClosed)},
+		{"db_pool_max_lifetime_closed_total", "counter", "Connections closed due to SetConnMaxLifetime.", float64(stats.MaxLifetimeClosed)},
+	} {
+		w.Header(m.name, m.typ, m.help)
+		w.Sample(m.name, m.value)
+	}
+}
+
+func writeRuntimeMetrics(w *utils.MetricsWriter) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	w.Header("go_goroutines", "gauge", "Number of goroutines that currently exist.")
+	w.Sample("go_goroutines", float64(runtime.NumGoroutine()))
+	w.Header("go_memstats_heap_alloc_bytes", "gauge", "Bytes of allocated heap objects.")
+	w.Sample("go_memstats_heap_alloc_bytes", float64(mem.HeapAlloc))
+	w.Header("go_memstats_sys_bytes", "gauge", "Bytes of memory obtained from the OS.")
+	w.Sample("go_memstats_sys_bytes", float64(mem.Sys))
+	w.Header("process_start_time_seconds", "gauge", "Start time of the process since unix epoch in seconds.")
+	w.Sample("process_start_time_seconds", float64(processStart.Unix()))
+}
+
+// stationGauges คือค่าล่าสุดต่อสถานีที่ส่งออก (PM ใช้ค่าที่ calibrate แล้วเหมือน endpoint อื่น)
+var stationGauges = []struct {
+	name, help string
+	value      func(StationSnapshot) float64
+}{
+	{"yakkaw_station_pm25", "Latest PM2.5 in µg/m³ (calibrated when available).", func(s StationSnapshot) float64 { return s.PM25 }},
+	{"yakkaw_station_pm10", "Latest PM10 in µg/m³ (calibrated when available).", func(s StationSnapshot) float64 { return s.PM10 }},
+	{"yakkaw_station_aqi", "Latest AQI reported by the station.", func(s StationSnapshot) float64 { return float64(s.AQI) }},
+	{"yakkaw_station_temperature_celsius", "Latest temperature in °C.", func(s StationSnapshot) float64 { return float64(s.Temperature) }},
+	{"yakkaw_station_humidity_percent", "Latest relative humidity in %.", func(s StationSnapshot) float64 { return float64(s.Humidity) }},
+}
+
+// writeStationMetrics: ค่าวัดส่งเฉพาะสถานีที่ไม่ offline เพื่อไม่ให้ alert ค้างจากค่าเก่า
+// ส่วนเวลาที่วัดล่าสุดและสถานะส่งทุกสถานีที่ active เพื่อใช้ alert เมื่อสถานีหยุดส่งข้อมูล
+func writeStationMetrics(w *utils.MetricsWriter) {
+	stations, _, err := GetLatestStations(StationFilter{})
+	if err != nil {
+		log.Printf("Error loading stations for metrics: %v", err)
+		return
+	}
+
+	for _, g := range stationGauges {
+		w.Header(g.name, "gauge", g.help)
+		for _, s := range stations {
+			if s.Status != "offline" {
+				w.Sample(g.name, g.value(s), "dvid", s.DVID, "province", s.Province)
+			}
+		}
+	}
+
+	w.Header("yakkaw_station_last_observation_timestamp_seconds", "gauge", "Unix time of the station's latest reading.")
+	for _, s := range stations {
+		w.Sample("yakkaw_station_last_observation_timestamp_seconds", float64(s.ObservedAt.Unix()), "dvid", s.DVID, "province", s.Province)
+	}
+
+	counts := map[string]int{"online": 0, "stale": 0, "offline": 0}
+	for _, s := range stations {
+		counts[s.Status]++
+	}
+	w.Header("yakkaw_stations", "gauge", "Active stations by reporting status (online, stale, offline).")
+	for _, status := range []string{"online", "stale", "offline"} {
+		w.Sample("yakkaw_stations", float64(counts[status]), "status", status)
+	}
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"yakkaw_dashboard/utils"
+)
+
+func TestWriteStationMetrics(t *testing.T) {
+	stationCache.Lock()
+	saved, savedAt := stationCache.stations, stationCache.refreshedAt
+	now := time.Now()
+	stationCache.stations = []StationSnapshot{
+		{DVID: "a1", Province: "เชียงราย", PM25: 41.5, PM10: 60, AQI: 101, Temperature: 31, Humidity: 55, ObservedAt: now.Add(-5 * time.Minute)},
+		{DVID: "b2", Province: "เชียงใหม่", PM25: 300, ObservedAt: now.Add(-48 * time.Hour)},
+	}
+	stationCache.refreshedAt = now
+	stationCache.Unlock()
+	defer func() {
+		stationCache.Lock()
+		stationCache.stations, stationCache.refreshedAt = saved, savedAt
+		stationCache.Unlock()
+	}()
+
+	w := utils.NewMetricsWriter()
+	writeStationMetrics(w)
+	out := string(w.Bytes())
+	for _, want := range []string{
+		"# TYPE yakkaw_station_pm25 gauge\n",
+		`yakkaw_station_pm25{dvid="a1",province="เชียงราย"} 41.5`,
+		`yakkaw_station_aqi{dvid="a1",province="เชียงราย"} 101`,
+		`yakkaw_station_humidity_percent{dvid="a1",province="เชียงราย"} 55`,
+		`yakkaw_station_last_observation_timestamp_seconds{dvid="b2",province="เชียงใหม่"}`,
+		`yakkaw_stations{status="online"} 1`,
+		`yakkaw_stations{status="offline"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics missing %q:\n%s", want, out)
+		}
+	}
+	// ค่าวัดของสถานีที่ offline ไม่ถูกส่ง เพื่อไม่ให้ alert จากค่าเก่า
+	if strings.Contains(out, `yakkaw_station_pm25{dvid="b2"`) {
+		t.Errorf("offline station should not export readings:\n%s", out)
+	}
+}
+
+func TestIngestRunMetrics(t *testing.T) {
+	run := newIngestRun()
+	run.rows("received", 3)
+	run.fail("insert")
+	run.finish()
+
+	w := utils.NewMetricsWriter()
+	utils.Metrics.WriteTo(w)
+	out := string(w.Bytes())
+	for _, want := range []string{
+		`yakkaw_ingest_runs_total{result="error"} `,
+		`yakkaw_ingest_errors_total{stage="insert"} `,
+		`yakkaw_ingest_rows_total{outcome="received"} `,
+		`yakkaw_ingest_duration_seconds_count `,
+		`yakkaw_ingest_last_run_timestamp_seconds{result="error"} `,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package services
+
+import (
+	"yakkaw_dashboard/database"
+	"yakkaw_dashboard/models"
+)
+
+const defaultNotificationIcon = "default-icon-url"
+
+// PublishNotification บันทึก notification ใหม่ (ใช้ร่วมกันระหว่าง admin และระบบ alert อัตโนมัติ)
+// แล้วส่ง Web Push ไปยัง browser ที่ subscribe ไว้ใน background
+func PublishNotification(notification *models.Notification) error {
+	if notification.Icon == "" {
+		notification.Icon = defaultNotificationIcon
+	}
+	if err := database.DB.Create(notification).Error; err != nil {
+		return err
+	}
+	go SendPushNotification(*notification)
+	return nil
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"yakkaw_dashboard/config"
+)
+
+// WebhookSignatureHeader เป็น header ที่ใช้ส่ง HMAC-SHA256 ของ "<timestamp>.<body>" เมื่อมี secret
+const WebhookSignatureHeader = "X-Yakkaw-Signature"
+
+// webhookClient ใช้กับ webhook ที่ผู้ดูแลตั้งค่าเอง (DEVICE_ALERT_WEBHOOK_URL) ซึ่งอาจอยู่ในเครือข่ายภายในได้
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// publicWebhookClient ใช้กับ URL ที่ผู้ใช้กำหนด: ต่อได้เฉพาะ IP สาธารณะ โดยตรวจ IP ที่ resolve แล้วตอน dial
+// (กัน DNS rebinding และ redirect ไปยัง localhost/เครือข่ายภายใน) และไม่ผ่าน proxy
+var publicWebhookClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext:         (&net.Dialer{Timeout: 5 * time.Second, Control: publicDialControl}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+		MaxIdleConns:        10,
+		IdleConnTimeout:     90 * time.Second,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if req.URL.Scheme != "https" {
+			return errors.New("webhook redirected to a non-https URL")
+		}
+		if len(via) >= 5 {
+			return errors.New("webhook redirected too many times")
+		}
+		return nil
+	},
+}
+
+// nonPublicNets คือช่วงที่ไม่ใช่อินเทอร์เน็ตสาธารณะนอกเหนือจากที่ net.IP ตรวจให้ (loopback, private, link-local)
+var nonPublicNets = func() []*net.IPNet {
+	var nets []*net.IPNet
+	for _, cidr := range []string{"0.0.0.0/8", "100.64.0.0/10", "192.0.0.0/24", "198.18.0.0/15", "240.0.0.0/4"} {
+		_, n, _ := net.ParseCIDR(cidr)
+		nets = append(nets, n)
+	}
+	return nets
+}()
+
+// isPublicIP รายงานว่า ip เป็นที่อยู่บนอินเทอร์เน็ตสาธารณะ (ไม่ใช่ loopback, private, link-local เช่น 169.254.169.254)
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	for _, n := range nonPublicNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// publicDialControl ถูกเรียกหลัง resolve DNS แล้วก่อนเชื่อมต่อจริง address จึงเป็น IP ที่จะต่อไป
+func publicDialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("refusing to connect to non-public address %s", host)
+	}
+	return nil
+}
+
+// lookupWebhookHost resolve host ของ webhook ตอนตรวจ URL (เปลี่ยนได้ใน test)
+var lookupWebhookHost = func(host string) ([]net.IP, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return net.DefaultResolver.LookupIP(ctx, "ip", host)
+}
+
+// validatePublicWebhookURL ตรวจ URL ที่ผู้ใช้กำหนด: ต้องเป็น https และ host ต้องเป็น IP สาธารณะทั้งหมด
+func validatePublicWebhookURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" || u.User != nil {
+		return nil, errors.New("webhook_url must be an absolute https URL")
+	}
+	ips := []net.IP{net.ParseIP(u.Hostname())}
+	if ips[0] == nil {
+		if ips, err = lookupWebhookHost(u.Hostname()); err != nil || len(ips) == 0 {
+			return nil, errors.New("webhook_url host cannot be resolved")
+		}
+	}
+	for _, ip := range ips {
+		if !isPublicIP(ip) {
+			return nil, errors.New("webhook_url must point to a public internet address")
+		}
+	}
+	return u, nil
+}
+
+// sendEmail ส่งอีเมล text/plain (UTF-8) ผ่าน SMTP ที่ตั้งค่าไว้
+func sendEmail(to []string, subject, body string) error {
+	settings := config.SMTP()
+	if !settings.Enabled() {
+		return errors.New("smtp is not configured")
+	}
+	if len(to) == 0 {
+		return errors.New("no recipients")
+	}
+
+	var msg strings.Builder
+	msg.WriteString("From: " + settings.From + "\r\n")
+	msg.WriteString("To: " + strings.Join(to, ", ") + "\r\n")
+	msg.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	msg.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	var auth smtp.Auth
+	if settings.Username != "" {
+		auth = smtp.PlainAuth("", settings.Username, settings.Password, settings.Host)
+	}
+	return smtp.SendMail(settings.Host+":"+settings.Port, auth, settings.From, to, []byte(msg.String()))
+}
+
+// postWebhook ส่ง payload เป็น JSON ไปยัง url และเซ็นด้วย HMAC-SHA256 เมื่อมี secret
+func postWebhook(url, secret string, payload interface{}) error {
+	return sendWebhook(webhookClient, url, secret, payload)
+}
+
+// postPublicWebhook เหมือน postWebhook แต่สำหรับ URL ที่ผู้ใช้กำหนด (ต่อได้เฉพาะ https และ IP สาธารณะ)
+func postPublicWebhook(rawURL, secret string, payload interface{}) error {
+	if _, err := validatePublicWebhookURL(rawURL); err != nil {
+		return err
+	}
+	return sendWebhook(publicWebhookClient, rawURL, secret, payload)
+}
+
+func sendWebhook(client *http.Client, url, secret string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set("X-Yakkaw-Timestamp", ts)
+		req.Header.Set(WebhookSignatureHeader, "sha256="+signWebhook(secret, ts, body))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func signWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,282 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"yakkaw_dashboard/database"
+	"yakkaw_dashboard/models"
+)
+
+// ErrOpenDataNotFound คืนเมื่อ id ที่ขอผ่าน OpenAQ/SensorThings ไม่มีอยู่
+var ErrOpenDataNotFound = errors.New("entity not found")
+
+// OpenDataMaxOffset จำกัด OFFSET ของ $skip และ page ของ OpenAQ เพราะ OFFSET ลึกต้องอ่านและเรียงแถวที่ข้ามทั้งหมด
+// หน้าถัดไปของ Observations ใช้ $skiptoken (keyset) จาก @iot.nextLink แทน
+const OpenDataMaxOffset = 10000
+
+// openDataParameter คือ metric ที่เปิดให้ aggregator ภายนอกผ่าน OpenAQ และ SensorThings
+// Key เป็นหลักสุดท้ายของ id sensor/datastream/observation จึงต้องอยู่ระหว่าง 1–9 และห้ามเปลี่ยน
+type openDataParameter struct {
+	Key            int
+	OpenAQID       int    // id ของ parameter ใน OpenAQ
+	Name           string // ชื่อ parameter แบบ OpenAQ
+	DisplayName    string
+	Units          string
+	Column         string // column ใน sensor_data
+	Definition     string // URI ของปริมาณที่วัด (ObservedProperty)
+	UnitName       string
+	UnitSymbol     string
+	UnitDefinition string
+}
+
+var openDataParameters = []openDataParameter{
+	{
+		Key: 1, OpenAQID: 2, Name: "pm25", DisplayName: "PM2.5", Units: "µg/m³", Column: "pm25",
+		Definition: "http://dd.eionet.europa.eu/vocabulary/aq/pollutant/6001",
+		UnitName:   "microgram per cubic meter", UnitSymbol: "µg/m³", UnitDefinition: "http://qudt.org/vocab/unit/MicroGM-PER-M3",
+	},
+	{
+		Key: 2, OpenAQID: 1, Name: "pm10", DisplayName: "PM10", Units: "µg/m³", Column: "pm10",
+		Definition: "http://dd.eionet.europa.eu/vocabulary/aq/pollutant/5",
+		UnitName:   "microgram per cubic meter", UnitSymbol: "µg/m³", UnitDefinition: "http://qudt.org/vocab/unit/MicroGM-PER-M3",
+	},
+	{
+		Key: 3, OpenAQID: 100, Name: "temperature", DisplayName: "Temperature", Units: "c", Column: "temperature",
+		Definition: "http://qudt.org/vocab/quantitykind/Temperature",
+		UnitName:   "degree Celsius", UnitSymbol: "°C", UnitDefinition: "http://qudt.org/vocab/unit/DEG_C",
+	},
+	{
+		Key: 4, OpenAQID: 98, Name: "relativehumidity", DisplayName: "Relative humidity", Units: "%", Column: "humidity",
+		Definition: "http://qudt.org/vocab/quantitykind/RelativeHumidity",
+		UnitName:   "percent", UnitSymbol: "%", UnitDefinition: "http://qudt.org/vocab/unit/PERCENT",
+	},
+}
+
+func openDataParameterByKey(key int) (openDataParameter, bool) {
+	for _, p := range openDataParameters {
+		if p.Key == key {
+			return p, true
+		}
+	}
+	return openDataParameter{}, false
+}
+
+// openDataSeriesID คือ id ของ sensor (OpenAQ) และ datastream (SensorThings): device id × 10 + parameter key
+func openDataSeriesID(deviceID uint, key int) uint64 {
+	return uint64(deviceID)*10 + uint64(key)
+}
+
+// splitOpenDataID แยก id ที่ประกอบด้วย openDataSeriesID (หรือ id ของแถว × 10 + key) กลับเป็นสองส่วน
+func splitOpenDataID(id uint64) (uint, openDataParameter, bool) {
+	p, ok := openDataParameterByKey(int(id % 10))
+	if !ok || id < 10 {
+		return 0, openDataParameter{}, false
+	}
+	return uint(id / 10), p, true
+}
+
+// openDataStation คืออุปกรณ์จากทะเบียน devices พร้อมค่าล่าสุดจาก station snapshot (nil = ยังไม่เคยส่งข้อมูล)
+type openDataStation struct {
+	models.Device
+	Country string
+	Latest  *StationSnapshot
+}
+
+// Name คือชื่อสถานที่ตั้ง หรือ dvid เมื่อไม่ได้กรอก place
+func (s openDataStation) Name() string {
+	if strings.TrimSpace(s.Place) != "" {
+		return s.Place
+	}
+	return s.DVID
+}
+
+// latestValue คืนค่าล่าสุดของ parameter จาก snapshot (PM ใช้ค่าที่ calibrate แล้วเหมือน endpoint อื่น)
+func (s openDataStation) latestValue(p openDataParameter) (float64, time.Time, bool) {
+	if s.Latest == nil {
+		return 0, time.Time{}, false
+	}
+	var v float64
+	switch p.Column {
+	case "pm25":
+		v = s.Latest.PM25
+	case "pm10":
+		v = s.Latest.PM10
+	case "temperature":
+		v = float64(s.Latest.Temperature)
+	case "humidity":
+		v = float64(s.Latest.Humidity)
+	default:
+		return 0, time.Time{}, false
+	}
+	return v, s.Latest.ObservedAt, true
+}
+
+// loadOpenDataStations โหลดอุปกรณ์ในทะเบียน (ไม่รวมที่ถูกลบ) เรียงตาม id กรองจังหวัดได้
+func loadOpenDataStations(province string) ([]openDataStation, error) {
+	query := database.DB.Order("id ASC")
+	if province = strings.TrimSpace(province); province != "" {
+		query = query.Where("province = ?", normalizeProvince(province))
+	}
+	var devices []models.Device
+	if err := query.Find(&devices).Error; err != nil {
+		return nil, err
+	}
+
+	snapshots, _, err := GetLatestStations(StationFilter{})
+	if err != nil {
+		return nil, err
+	}
+	byDVID := make(map[string]StationSnapshot, len(snapshots))
+	for _, s := range snapshots {
+		byDVID[s.DVID] = s
+	}
+
+	stations := make([]openDataStation, 0, len(devices))
+	for _, d := range devices {
+		st := openDataStation{Device: d, Country: provinceCountry(d.Province)}
+		if snap, ok := byDVID[d.DVID]; ok {
+			st.Latest = &snap
+		}
+		stations = append(stations, st)
+	}
+	return stations, nil
+}
+
+func loadOpenDataStation(id uint) (openDataStation, error) {
+	stations, err := loadOpenDataStations("")
+	if err != nil {
+		return openDataStation{}, err
+	}
+	for _, s := range stations {
+		if s.ID == id {
+			return s, nil
+		}
+	}
+	return openDataStation{}, ErrOpenDataNotFound
+}
+
+// openDataTimeCond คือเงื่อนไขเวลาของ observation เช่น {">=", t}
+type openDataTimeCond struct {
+	Op   string // = > >= < <=
+	Time time.Time
+}
+
+// openDataObservationQuery: ค่าศูนย์ = ไม่กรอง, ค่าเริ่มต้นเรียงจากใหม่ไปเก่า
+type openDataObservationQuery struct {
+	DeviceID  uint
+	Key       int
+	RowID     uint
+	Conds     []openDataTimeCond
+	Ascending bool
+	Limit     int
+	Offset    int
+	After     *RawReadingCursor // observation สุดท้ายของหน้าก่อน (ID = id ของ observation)
+}
+
+// openDataObservation คือค่าของ parameter หนึ่งตัวจาก sensor_data หนึ่งแถว
+type openDataObservation struct {
+	RowID     uint
+	DeviceID  uint
+	DVID      string
+	Param     openDataParameter
+	Timestamp int64
+	Value     float64
+	Latitude  float64
+	Longitude float64
+}
+
+// ID คือ id ของแถวใน sensor_data × 10 + parameter key
+func (o openDataObservation) ID() uint64 {
+	return uint64(o.RowID)*10 + uint64(o.Param.Key)
+}
+
+// openDataObservationsSQL แตกแต่ละแถวของ sensor_data เป็นหนึ่ง observation ต่อ parameter ด้วย LATERAL VALUES
+// เฉพาะอุปกรณ์ที่อยู่ในทะเบียน และตัดข้อมูลที่ถูก flag ออกเหมือน endpoint อื่น
+func openDataObservationsSQL(q openDataObservationQuery, count bool) (string, []interface{}) {
+	var values []string
+	for _, p := range openDataParameters {
+		if q.Key == 0 || q.Key == p.Key {
+			values = append(values, fmt.Sprintf("(%d, (%s)::double precision)", p.Key, DataOptions{}.metricExpr(p.Column)))
+		}
+	}
+
+	var sb strings.Builder
+	if count {
+		sb.WriteString("SELECT COUNT(*)")
+	} else {
+		sb.WriteString("SELECT s.id, d.id, s.dvid, s.timestamp, s.latitude, s.longitude, m.k, m.v")
+	}
+	sb.WriteString(`
+		FROM sensor_data s
+		JOIN devices d ON d.dv_id = s.dvid AND d.deleted_at IS NULL
+		CROSS JOIN LATERAL (VALUES ` + strings.Join(values, ", ") + `) AS m(k, v)
+		WHERE m.v IS NOT NULL`)
+	sb.WriteString(DataOptions{}.qualityClause())
+
+	var args []interface{}
+	if q.DeviceID > 0 {
+		sb.WriteString(" AND d.id = ?")
+		args = append(args, q.DeviceID)
+	}
+	if q.RowID > 0 {
+		sb.WriteString(" AND s.id = ?")
+		args = append(args, q.RowID)
+	}
+	for _, c := range q.Conds {
+		sb.WriteString(" AND s.timestamp " + c.Op + " ?")
+		args = append(args, c.Time.UnixMilli())
+	}
+	if count {
+		return sb.String(), args
+	}
+
+	if c := q.After; c != nil {
+		// m.k เรียงจากน้อยไปมากทั้งสองทิศทาง จึงเทียบ (timestamp, id) ก่อนแล้วจึง k ภายในแถวเดียวกัน
+		cmp := "<"
+		if q.Ascending {
+			cmp = ">"
+		}
+		rowID, key := c.ID/10, int(c.ID%10)
+		sb.WriteString(" AND ((s.timestamp, s.id) " + cmp + " (?, ?) OR (s.timestamp = ? AND s.id = ? AND m.k > ?))")
+		args = append(args, c.Timestamp, rowID, c.Timestamp, rowID, key)
+	}
+	if q.Ascending {
+		sb.WriteString(" ORDER BY s.timestamp, s.id, m.k")
+	} else {
+		sb.WriteString(" ORDER BY s.timestamp DESC, s.id DESC, m.k")
+	}
+	sb.WriteString(" LIMIT ? OFFSET ?")
+	args = append(args, q.Limit, q.Offset)
+	return sb.String(), args
+}
+
+func listOpenDataObservations(q openDataObservationQuery) ([]openDataObservation, error) {
+	query, args := openDataObservationsSQL(q, false)
+	rows, err := database.DB.Raw(query, args...).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	observations := make([]openDataObservation, 0, q.Limit)
+	for rows.Next() {
+		var o openDataObservation
+		var key int
+		if err := rows.Scan(&o.RowID, &o.DeviceID, &o.DVID, &o.Timestamp, &o.Latitude, &o.Longitude, &key, &o.Value); err != nil {
+			return nil, err
+		}
+		o.Param, _ = openDataParameterByKey(key)
+		observations = append(observations, o)
+	}
+	return observations, rows.Err()
+}
+
+func countOpenDataObservations(q openDataObservationQuery) (int64, error) {
+	query, args := openDataObservationsSQL(q, true)
+	var n int64
+	err := database.DB.Raw(query, args...).Scan(&n).Error
+	return n, err
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"yakkaw_dashboard/models"
+)
+
+func testOpenDataStations() []openDataStation {
+	observed := time.Date(2024, 3, 5, 2, 30, 0, 0, time.UTC)
+	a := openDataStation{Device: models.Device{
+		DVID: "a1", Place: "โรงเรียน", Address: "เชียงราย", Latitude: 19.9, Longitude: 99.8,
+		Models: "PMS7003", ContactPhone: "0812345678", Province: "เชียงราย", DeployDate: observed,
+	}, Country: "TH", Latest: &StationSnapshot{DVID: "a1", PM25: 41.256, Temperature: 31, ObservedAt: observed, Status: "online"}}
+	a.ID = 3
+	b := openDataStation{Device: models.Device{DVID: "b2", Latitude: 18.8, Longitude: 98.9, Province: "เชียงใหม่"}, Country: "TH"}
+	b.ID = 7
+	return []openDataStation{a, b}
+}
+
+func TestOpenDataIDs(t *testing.T) {
+	id := openDataSeriesID(42, 3)
+	deviceID, p, ok := splitOpenDataID(id)
+	if id != 423 || !ok || deviceID != 42 || p.Name != "temperature" {
+		t.Fatalf("split(%d) = %d %v %v", id, deviceID, p.Name, ok)
+	}
+	for _, bad := range []uint64{0, 5, 420, 429} {
+		if _, _, ok := splitOpenDataID(bad); ok {
+			t.Errorf("splitOpenDataID(%d) should fail", bad)
+		}
+	}
+}
+
+func TestOpenDataObservationsSQL(t *testing.T) {
+	from := time.UnixMilli(1000)
+	query, args := openDataObservationsSQL(openDataObservationQuery{
+		DeviceID: 3, Key: 1, Conds: []openDataTimeCond{{Op: ">=", Time: from}}, Limit: 10, Offset: 20,
+	}, false)
+	for _, part := range []string{
+		"JOIN devices d ON d.dv_id = s.dvid AND d.deleted_at IS NULL",
+		"(VALUES (1, (COALESCE(pm25_calibrated, pm25))::double precision)) AS m(k, v)",
+		"quality_flags = 0",
+		"d.id = ?",
+		"s.timestamp >= ?",
+		"ORDER BY s.timestamp DESC, s.id DESC, m.k LIMIT ? OFFSET ?",
+	} {
+		if !strings.Contains(query, part) {
+			t.Errorf("query missing %q:\n%s", part, query)
+		}
+	}
+	if want := []interface{}{uint(3), int64(1000), 10, 20}; !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+
+	// keyset: observation id 4563 = แถว 456 parameter 3
+	after := &RawReadingCursor{Timestamp: 2000, ID: 4563}
+	query, args = openDataObservationsSQL(openDataObservationQuery{After: after, Limit: 5}, false)
+	if !strings.Contains(query, "AND ((s.timestamp, s.id) < (?, ?) OR (s.timestamp = ? AND s.id = ? AND m.k > ?)) ORDER BY s.timestamp DESC") {
+		t.Errorf("keyset query:\n%s", query)
+	}
+	if want := []interface{}{int64(2000), uint(456), int64(2000), uint(456), 3, 5, 0}; !reflect.DeepEqual(args, want) {
+		t.Errorf("keyset args = %v, want %v", args, want)
+	}
+	if query, _ = openDataObservationsSQL(openDataObservationQuery{After: after, Ascending: true}, false); !strings.Contains(query, "(s.timestamp, s.id) > (?, ?)") {
+		t.Errorf("ascending keyset query:\n%s", query)
+	}
+
+	count, _ := openDataObservationsSQL(openDataObservationQuery{After: after}, true)
+	if !strings.HasPrefix(count, "SELECT COUNT(*)") || strings.Contains(count, "LIMIT") || strings.Contains(count, "m.k >") || !strings.Contains(count, "(4, (humidity)") {
+		t.Errorf("count query:\n%s", count)
+	}
+}
+
+func TestParseSTAPath(t *testing.T) {
+	got, err := parseSTAPath("/Datastreams(31)/Observations")
+	want := []staSegment{{Name: "Datastreams", ID: 31, HasID: true}, {Name: "Observations"}}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, %v", got, err)
+	}
+	if got, err := parseSTAPath("Things('5')"); err != nil || got[0].ID != 5 {
+		t.Fatalf("quoted id: %+v, %v", got, err)
+	}
+	for _, bad := range []string{"Widgets", "Things(x)", "Things/Locations", "Things(1)/Sensor", "Things(1)/Locations(2)", "Things(1)/Datastreams/Observations"} {
+		if _, err := parseSTAPath(bad); !errors.Is(err, ErrSTAInvalidRequest) {
+			t.Errorf("parseSTAPath(%q) = %v, want invalid request", bad, err)
+		}
+	}
+}
+
+func TestParseSTAQuery(t *testing.T) {
+	q, err := parseSTAQuery(url.Values{
+		"$top":     {"5000"},
+		"$skip":    {"10"},
+		"$count":   {"true"},
+		"$expand":  {"Locations,Datastreams($top=2)"},
+		"$orderby": {"phenomenonTime asc"},
+		"$filter":  {"phenomenonTime ge 2024-01-01T00:00:00Z and phenomenonTime lt '2024-01-02T00:00:00+07:00'"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Top != STAMaxTop || q.Skip != 10 || !q.Count || !q.OrderBy || !q.Ascending {
+		t.Fatalf("query = %+v", q)
+	}
+	if want := []staExpand{{Name: "Locations", Top: -1}, {Name: "Datastreams", Top: 2}}; !reflect.DeepEqual(q.Expand, want) {
+		t.Fatalf("expand = %+v", q.Expand)
+	}
+	if len(q.Conds) != 2 || q.Conds[0].Op != ">=" || q.Conds[1].Op != "<" || q.Conds[1].Time.UTC().Hour() != 17 {
+		t.Fatalf("conds = %+v", q.Conds)
+	}
+
+	token := RawReadingCursor{Timestamp: 2000, ID: 4563}.Encode()
+	if q, err := parseSTAQuery(url.Values{"$skiptoken": {token}}); err != nil || q.SkipToken == nil || q.SkipToken.ID != 4563 {
+		t.Fatalf("$skiptoken = %+v, %v", q.SkipToken, err)
+	}
+
+	for _, bad := range []url.Values{
+		{"$top": {"-1"}},
+		{"$skip": {"10001"}},
+		{"$skiptoken": {"nope"}},
+		{"$skip": {"5"}, "$skiptoken": {token}},
+		{"$count": {"yes"}},
+		{"$expand": {"Datastreams($select=name)"}},
+		{"$expand": {"Datastreams/Observations"}},
+		{"$orderby": {"name desc"}},
+		{"$filter": {"result gt 50"}},
+		{"$filter": {"phenomenonTime gt yesterday"}},
+	} {
+		if _, err := parseSTAQuery(bad); !errors.Is(err, ErrSTAInvalidRequest) {
+			t.Errorf("parseSTAQuery(%v) = %v, want invalid request", bad, err)
+		}
+	}
+}
+
+func TestSTAResolverThings(t *testing.T) {
+	r := &staResolver{base: "http://x/api/sta/v1.1", stations: testOpenDataStations(), loaded: true}
+	q, _ := parseSTAQuery(url.Values{"$top": {"1"}, "$count": {"true"}, "$expand": {"Datastreams($top=1),Locations"}})
+	resp, err := r.collection("Things", staFilter{}, q, "Things", url.Values{"$top": {"1"}, "$count": {"true"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp["@iot.count"] != int64(2) {
+		t.Errorf("count = %v", resp["@iot.count"])
+	}
+	if next, _ := resp["@iot.nextLink"].(string); !strings.HasPrefix(next, "http://x/api/sta/v1.1/Things?") || !strings.Contains(next, "%24skip=1") {
+		t.Errorf("nextLink = %q", next)
+	}
+
+	thing := resp["value"].([]map[string]interface{})[0]
+	if thing["@iot.id"] != uint64(3) || thing["@iot.selfLink"] != "http://x/api/sta/v1.1/Things(3)" || thing["name"] != "โรงเรียน" {
+		t.Errorf("thing = %v", thing)
+	}
+	if thing["Datastreams@iot.navigationLink"] != "http://x/api/sta/v1.1/Things(3)/Datastreams" {
+		t.Errorf("navigation link = %v", thing["Datastreams@iot.navigationLink"])
+	}
+	if props := thing["properties"].(map[string]interface{}); props["dvid"] != "a1" || props["contact_phone"] != nil {
+		t.Errorf("properties = %v", props)
+	}
+	datastreams := thing["Datastreams"].([]map[string]interface{})
+	if len(datastreams) != 1 || datastreams[0]["@iot.id"] != uint64(31) {
+		t.Fatalf("expanded datastreams = %v", datastreams)
+	}
+	if uom := datastreams[0]["unitOfMeasurement"].(map[string]string); uom["symbol"] != "µg/m³" {
+		t.Errorf("unitOfMeasurement = %v", uom)
+	}
+	location := thing["Locations"].([]map[string]interface{})[0]
+	if point := location["location"].(map[string]interface{}); !reflect.DeepEqual(point["coordinates"], []float64{99.8, 19.9}) {
+		t.Errorf("location = %v", point)
+	}
+
+	// Datastreams(72)/Thing → Things(7), ObservedProperties(2)/Datastreams → PM10 ของทุกอุปกรณ์
+	ds, err := r.entity("Datastreams", 72)
+	if err != nil {
+		t.Fatal(err)
+	}
+	things, _, _, _ := r.list("Things", ds.filter.project("Things"), staQuery{Top: 1})
+	if len(things) != 1 || things[0].body["@iot.id"] != uint64(7) || things[0].body["name"] != "b2" {
+		t.Errorf("Datastreams(72)/Thing = %v", things)
+	}
+	pm10, total, _, _ := r.list("Datastreams", staFilter{Key: 2}, staQuery{Top: 10})
+	if total != 2 || pm10[1].body["@iot.id"] != uint64(72) {
+		t.Errorf("PM10 datastreams = %v", pm10)
+	}
+	if _, err := r.entity("Things", 99); !errors.Is(err, ErrOpenDataNotFound) {
+		t.Errorf("Things(99) = %v", err)
+	}
+	if _, err := r.collection("Things", staFilter{}, staQuery{Top: 1, OrderBy: true}, "Things", nil); !errors.Is(err, ErrSTAInvalidRequest) {
+		t.Errorf("$orderby on Things = %v", err)
+	}
+	if _, err := r.collection("Things", staFilter{}, staQuery{Top: 1, SkipToken: &RawReadingCursor{}}, "Things", nil); !errors.Is(err, ErrSTAInvalidRequest) {
+		t.Errorf("$skiptoken on Things = %v", err)
+	}
+}
+
+func TestOpenAQLocation(t *testing.T) {
+	loc := openAQLocation(testOpenDataStations()[0])
+	if loc.ID != 3 || loc.Country.Name != "Thailand" || loc.Timezone != "Asia/Bangkok" || len(loc.Instruments) != 1 {
+		t.Fatalf("location = %+v", loc)
+	}
+	if loc.DatetimeLast == nil || loc.DatetimeLast.UTC != "2024-03-05T02:30:00Z" || loc.DatetimeLast.Local != "2024-03-05T09:30:00+07:00" {
+		t.Fatalf("datetimeLast = %+v", loc.DatetimeLast)
+	}
+	pm25 := loc.Sensors[0]
+	if pm25.ID != 31 || pm25.Parameter.ID != 2 || pm25.Latest == nil || pm25.Latest.Value != 41.26 || pm25.Latest.LocationsID != 3 {
+		t.Fatalf("pm25 sensor = %+v", pm25)
+	}
+	if empty := openAQLocation(testOpenDataStations()[1]); empty.DatetimeLast != nil || empty.Sensors[0].Latest != nil || len(empty.Instruments) != 0 {
+		t.Fatalf("station without readings = %+v", empty)
+	}
+}
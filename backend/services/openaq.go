@@ -0,0 +1,277 @@
+package services
+
+import (
+	"strings"
+	"time"
+)
+
+// API แบบ OpenAQ v3 (อ่านอย่างเดียว): location = อุปกรณ์ในทะเบียน, sensor = อุปกรณ์ × parameter
+// id ของ sensor ตรงกับ id ของ Datastream ใน SensorThings
+const (
+	OpenAQDefaultLimit = 100
+	OpenAQMaxLimit     = 1000
+
+	openAQProviderName = "Yakkaw"
+)
+
+var openAQCountryNames = map[string]string{"TH": "Thailand", "LA": "Lao People's Democratic Republic"}
+
+type OpenAQMeta struct {
+	Name    string `json:"name"`
+	Website string `json:"website"`
+	Page    int    `json:"page"`
+	Limit   int    `json:"limit"`
+	Found   int64  `json:"found"`
+}
+
+// OpenAQResponse คือรูปแบบ {meta, results} ของทุก endpoint
+type OpenAQResponse struct {
+	Meta    OpenAQMeta  `json:"meta"`
+	Results interface{} `json:"results"`
+}
+
+// NewOpenAQResponse ห่อผลลัพธ์พร้อม meta ของหน้าปัจจุบัน
+func NewOpenAQResponse(results interface{}, page, limit int, found int64) OpenAQResponse {
+	return OpenAQResponse{
+		Meta:    OpenAQMeta{Name: "openaq-api", Website: "/", Page: page, Limit: limit, Found: found},
+		Results: results,
+	}
+}
+
+type OpenAQDatetime struct {
+	UTC   string `json:"utc"`
+	Local string `json:"local"`
+}
+
+func newOpenAQDatetime(t time.Time) *OpenAQDatetime {
+	if t.IsZero() {
+		return nil
+	}
+	return &OpenAQDatetime{
+		UTC:   t.UTC().Format("2006-01-02T15:04:05Z"),
+		Local: t.In(time.FixedZone("Asia/Bangkok", 7*3600)).Format(time.RFC3339),
+	}
+}
+
+type OpenAQParameter struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Units       string `json:"units"`
+	DisplayName string `json:"displayName"`
+}
+
+type OpenAQCoordinates struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type OpenAQNamed struct {
+	Name string `json:"name"`
+}
+
+type OpenAQCountry struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+type OpenAQLatest struct {
+	Datetime    OpenAQDatetime    `json:"datetime"`
+	Value       float64           `json:"value"`
+	Coordinates OpenAQCoordinates `json:"coordinates"`
+	SensorsID   uint64            `json:"sensorsId"`
+	LocationsID uint              `json:"locationsId"`
+}
+
+type OpenAQSensor struct {
+	ID           uint64          `json:"id"`
+	Name         string          `json:"name"`
+	Parameter    OpenAQParameter `json:"parameter"`
+	DatetimeLast *OpenAQDatetime `json:"datetimeLast"`
+	Latest       *OpenAQLatest   `json:"latest"`
+}
+
+type OpenAQLocation struct {
+	ID            uint              `json:"id"`
+	Name          string            `json:"name"`
+	Locality      string            `json:"locality"`
+	Timezone      string            `json:"timezone"`
+	Country       OpenAQCountry     `json:"country"`
+	Owner         OpenAQNamed       `json:"owner"`
+	Provider      OpenAQNamed       `json:"provider"`
+	IsMobile      bool              `json:"isMobile"`
+	IsMonitor     bool              `json:"isMonitor"` // false = เซนเซอร์ราคาประหยัด ไม่ใช่สถานีอ้างอิง
+	Instruments   []OpenAQNamed     `json:"instruments"`
+	Sensors       []OpenAQSensor    `json:"sensors"`
+	Coordinates   OpenAQCoordinates `json:"coordinates"`
+	Bounds        [4]float64        `json:"bounds"`
+	DatetimeFirst *OpenAQDatetime   `json:"datetimeFirst"`
+	DatetimeLast  *OpenAQDatetime   `json:"datetimeLast"`
+}
+
+type OpenAQPeriod struct {
+	Label        string         `json:"label"`
+	DatetimeFrom OpenAQDatetime `json:"datetimeFrom"`
+	DatetimeTo   OpenAQDatetime `json:"datetimeTo"`
+}
+
+type OpenAQMeasurement struct {
+	Value       float64           `json:"value"`
+	Parameter   OpenAQParameter   `json:"parameter"`
+	Period      OpenAQPeriod      `json:"period"`
+	Coordinates OpenAQCoordinates `json:"coordinates"`
+}
+
+func openAQParameter(p openDataParameter) OpenAQParameter {
+	return OpenAQParameter{ID: p.OpenAQID, Name: p.Name, Units: p.Units, DisplayName: p.DisplayName}
+}
+
+// OpenAQParameters คืน parameter ทั้งหมดที่เปิดให้บริการ
+func OpenAQParameters() []OpenAQParameter {
+	params := make([]OpenAQParameter, 0, len(openDataParameters))
+	for _, p := range openDataParameters {
+		params = append(params, openAQParameter(p))
+	}
+	return params
+}
+
+func openAQSensor(st openDataStation, p openDataParameter) OpenAQSensor {
+	sensor := OpenAQSensor{
+		ID:        openDataSeriesID(st.ID, p.Key),
+		Name:      p.Name + " " + p.Units,
+		Parameter: openAQParameter(p),
+	}
+	if v, observedAt, ok := st.latestValue(p); ok && !observedAt.IsZero() {
+		sensor.DatetimeLast = newOpenAQDatetime(observedAt)
+		sensor.Latest = &OpenAQLatest{
+			Datetime:    *sensor.DatetimeLast,
+			Value:       roundToTwoDecimals(v),
+			Coordinates: OpenAQCoordinates{Latitude: st.Latitude, Longitude: st.Longitude},
+			SensorsID:   sensor.ID,
+			LocationsID: st.ID,
+		}
+	}
+	return sensor
+}
+
+func openAQLocation(st openDataStation) OpenAQLocation {
+	loc := OpenAQLocation{
+		ID:          st.ID,
+		Name:        st.Name(),
+		Locality:    st.Province,
+		Timezone:    "Asia/Bangkok",
+		Country:     OpenAQCountry{Code: st.Country, Name: openAQCountryNames[st.Country]},
+		Owner:       OpenAQNamed{Name: openAQProviderName},
+		Provider:    OpenAQNamed{Name: openAQProviderName},
+		Instruments: []OpenAQNamed{},
+		Coordinates: OpenAQCoordinates{Latitude: st.Latitude, Longitude: st.Longitude},
+		Bounds:      [4]float64{st.Longitude, st.Latitude, st.Longitude, st.Latitude},
+	}
+	if st.District != "" {
+		loc.Locality = st.District + ", " + st.Province
+	}
+	if model := strings.TrimSpace(st.Models); model != "" {
+		loc.Instruments = append(loc.Instruments, OpenAQNamed{Name: model})
+	}
+	if !st.DeployDate.IsZero() {
+		loc.DatetimeFirst = newOpenAQDatetime(st.DeployDate)
+	}
+	if st.Latest != nil {
+		loc.DatetimeLast = newOpenAQDatetime(st.Latest.ObservedAt)
+	}
+	for _, p := range openDataParameters {
+		loc.Sensors = append(loc.Sensors, openAQSensor(st, p))
+	}
+	return loc
+}
+
+// ListOpenAQLocations คืน location หนึ่งหน้า (page เริ่มที่ 1) และจำนวนทั้งหมด กรองจังหวัดได้
+func ListOpenAQLocations(province string, page, limit int) ([]OpenAQLocation, int64, error) {
+	stations, err := loadOpenDataStations(province)
+	if err != nil {
+		return nil, 0, err
+	}
+	start := min((page-1)*limit, len(stations))
+	end := min(start+limit, len(stations))
+	locations := make([]OpenAQLocation, 0, end-start)
+	for _, st := range stations[start:end] {
+		locations = append(locations, openAQLocation(st))
+	}
+	return locations, int64(len(stations)), nil
+}
+
+// GetOpenAQLocation คืน location ตาม id (ErrOpenDataNotFound เมื่อไม่พบ)
+func GetOpenAQLocation(id uint) (OpenAQLocation, error) {
+	st, err := loadOpenDataStation(id)
+	if err != nil {
+		return OpenAQLocation{}, err
+	}
+	return openAQLocation(st), nil
+}
+
+// GetOpenAQLocationLatest คืนค่าล่าสุดของทุก sensor ของ location
+func GetOpenAQLocationLatest(id uint) ([]OpenAQLatest, error) {
+	loc, err := GetOpenAQLocation(id)
+	if err != nil {
+		return nil, err
+	}
+	latest := []OpenAQLatest{}
+	for _, s := range loc.Sensors {
+		if s.Latest != nil {
+			latest = append(latest, *s.Latest)
+		}
+	}
+	return latest, nil
+}
+
+// GetOpenAQSensor คืน sensor ตาม id (device id × 10 + parameter key)
+func GetOpenAQSensor(id uint64) (OpenAQSensor, error) {
+	deviceID, p, ok := splitOpenDataID(id)
+	if !ok {
+		return OpenAQSensor{}, ErrOpenDataNotFound
+	}
+	st, err := loadOpenDataStation(deviceID)
+	if err != nil {
+		return OpenAQSensor{}, err
+	}
+	return openAQSensor(st, p), nil
+}
+
+// ListOpenAQMeasurements คืนค่าที่วัดของ sensor ในช่วง [from, to) เรียงตามเวลา หนึ่งหน้าพร้อมจำนวนทั้งหมด
+func ListOpenAQMeasurements(sensorID uint64, from, to time.Time, page, limit int) ([]OpenAQMeasurement, int64, error) {
+	deviceID, p, ok := splitOpenDataID(sensorID)
+	if !ok {
+		return nil, 0, ErrOpenDataNotFound
+	}
+	if _, err := loadOpenDataStation(deviceID); err != nil {
+		return nil, 0, err
+	}
+
+	q := openDataObservationQuery{
+		DeviceID:  deviceID,
+		Key:       p.Key,
+		Conds:     []openDataTimeCond{{Op: ">=", Time: from}, {Op: "<", Time: to}},
+		Ascending: true,
+		Limit:     limit,
+		Offset:    (page - 1) * limit,
+	}
+	found, err := countOpenDataObservations(q)
+	if err != nil {
+		return nil, 0, err
+	}
+	observations, err := listOpenDataObservations(q)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	measurements := make([]OpenAQMeasurement, 0, len(observations))
+	for _, o := range observations {
+		at := *newOpenAQDatetime(time.UnixMilli(o.Timestamp))
+		measurements = append(measurements, OpenAQMeasurement{
+			Value:       roundToTwoDecimals(o.Value),
+			Parameter:   openAQParameter(o.Param),
+			Period:      OpenAQPeriod{Label: "raw", DatetimeFrom: at, DatetimeTo: at},
+			Coordinates: OpenAQCoordinates{Latitude: o.Latitude, Longitude: o.Longitude},
+		})
+	}
+	return measurements, found, nil
+}
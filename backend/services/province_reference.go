@@ -0,0 +1,556 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"yakkaw_dashboard/database"
+	"yakkaw_dashboard/models"
+
+	"gorm.io/gorm"
+)
+
+// provinceEntry คือจังหวัดหนึ่งพร้อมชื่อเรียกอื่นและภาค ที่ใช้ resolve ชื่อจังหวัดทั้งใน Go และตอนเขียน province ลง sensor_data
+type provinceEntry struct {
+	canonical string
+	aliases   []string
+	region    string
+	country   string
+}
+
+// provinceRegistry โหลดจากตาราง provinces/province_aliases/regions
+// ก่อนโหลด (หรือเมื่อไม่มีฐานข้อมูล เช่นใน test) ใช้ข้อมูลตั้งต้นที่ compile มา
+var provinceRegistry = struct {
+	sync.RWMutex
+	entries []provinceEntry
+	regions []models.Region
+}{entries: builtinProvinceEntries(), regions: builtinRegions()}
+
+func provinceEntries() []provinceEntry {
+	provinceRegistry.RLock()
+	defer provinceRegistry.RUnlock()
+	return provinceRegistry.entries
+}
+
+func builtinRegions() []models.Region {
+	regions := make([]models.Region, 0, len(regionSeed))
+	for _, r := range regionSeed {
+		regions = append(regions, models.Region{Name: r.name, NameEN: r.nameEN})
+	}
+	return regions
+}
+
+// canonicalRegion แปลงชื่อภาค (ไทยหรืออังกฤษ ไม่สนตัวพิมพ์) เป็นชื่อที่เก็บใน sensor_data.region
+func canonicalRegion(name string) string {
+	name = strings.TrimSpace(name)
+	provinceRegistry.RLock()
+	defer provinceRegistry.RUnlock()
+	for _, r := range provinceRegistry.regions {
+		if strings.EqualFold(r.Name, name) || strings.EqualFold(r.NameEN, name) {
+			return r.Name
+		}
+	}
+	return name
+}
+
+func builtinProvinceEntries() []provinceEntry {
+	entries := make([]provinceEntry, 0, len(provinceAliasData))
+	for _, p := range provinceAliasData {
+		entry := provinceEntry{canonical: p.canonical, aliases: p.aliases, region: provinceRegions[p.canonical], country: "TH"}
+		if p.canonical == "Laos" {
+			entry.region, entry.country = regionLaos, "LA"
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// regionForProvince คืนภาคของจังหวัด (แขวงของลาวที่ไม่อยู่ในตารางรวมเป็นกลุ่ม Laos)
+func regionForProvince(province, country string) string {
+	for _, entry := range provinceEntries() {
+		if entry.canonical == province {
+			return entry.region
+		}
+	}
+	if country == "LA" {
+		return regionLaos
+	}
+	return ""
+}
+
+// provincesInRegion คืนจังหวัดทั้งหมดที่อยู่ในภาค (เรียงตามชื่อ)
+func provincesInRegion(region string) []string {
+	var provinces []string
+	for _, entry := range provinceEntries() {
+		if region != "" && entry.region == region {
+			provinces = append(provinces, entry.canonical)
+		}
+	}
+	sort.Strings(provinces)
+	return provinces
+}
+
+// provinceCountry คืนรหัสประเทศ ISO ของจังหวัด (ค่าเริ่มต้น TH เมื่อไม่รู้จัก)
+func provinceCountry(province string) string {
+	for _, entry := range provinceEntries() {
+		if entry.canonical == province && entry.country != "" {
+			return entry.country
+		}
+	}
+	return "TH"
+}
+
+// provinceNameEN คืนชื่อภาษาอังกฤษของจังหวัดจาก alias ตัวแรกที่เป็นอักษรละติน (เช่น "chiang rai" → "Chiang Rai")
+// คืนค่าว่างเมื่อไม่มี alias ภาษาอังกฤษ
+func provinceNameEN(province string) string {
+	if isASCII(province) {
+		return province
+	}
+	for _, entry := range provinceEntries() {
+		if entry.canonical != province {
+			continue
+		}
+		for _, alias := range entry.aliases {
+			if alias != "" && isASCII(alias) {
+				words := strings.Fields(alias)
+				for i, w := range words {
+					words[i] = strings.ToUpper(w[:1]) + w[1:]
+				}
+				return strings.Join(words, " ")
+			}
+		}
+	}
+	return ""
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// LoadProvinceReference seed ตารางอ้างอิงจากข้อมูลตั้งต้นเมื่อยังว่าง แล้วโหลดเข้า registry
+func LoadProvinceReference() error {
+	if err := seedProvinceReference(database.DB); err != nil {
+		return err
+	}
+	return reloadProvinceRegistry(database.DB)
+}
+
+func seedProvinceReference(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&models.Province{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		regionIDs := make(map[string]uint)
+		for _, r := range regionSeed {
+			region := models.Region{Name: r.name, NameEN: r.nameEN}
+			if err := tx.Create(&region).Error; err != nil {
+				return err
+			}
+			regionIDs[r.name] = region.ID
+		}
+		for _, entry := range builtinProvinceEntries() {
+			province := models.Province{Name: entry.canonical, Country: entry.country}
+			if id, ok := regionIDs[entry.region]; ok {
+				province.RegionID = &id
+			}
+			for _, alias := range entry.aliases {
+				province.Aliases = append(province.Aliases, models.ProvinceAlias{Alias: alias})
+			}
+			if err := tx.Create(&province).Error; err != nil {
+				return err
+			}
+		}
+		log.Printf("Seeded %d provinces and %d regions", len(provinceAliasData), len(regionSeed))
+		return nil
+	})
+}
+
+func reloadProvinceRegistry(db *gorm.DB) error {
+	var provinces []models.Province
+	if err := db.Preload("Region").Preload("Aliases").Order("name ASC").Find(&provinces).Error; err != nil {
+		return err
+	}
+	var regions []models.Region
+	if err := db.Order("id ASC").Find(&regions).Error; err != nil {
+		return err
+	}
+
+	entries := make([]provinceEntry, 0, len(provinces))
+	for _, p := range provinces {
+		entry := provinceEntry{canonical: p.Name, country: p.Country}
+		if p.Region != nil {
+			entry.region = p.Region.Name
+		}
+		for _, a := range p.Aliases {
+			entry.aliases = append(entry.aliases, a.Alias)
+		}
+		entries = append(entries, entry)
+	}
+
+	provinceRegistry.Lock()
+	provinceRegistry.entries, provinceRegistry.regions = entries, regions
+	provinceRegistry.Unlock()
+	resetLocationCache()
+	return nil
+}
+
+var errDuplicateAlias = errors.New("alias already belongs to a province")
+
+type ProvinceService struct {
+	DB *gorm.DB
+}
+
+// NewProvinceService creates a new ProvinceService instance
+func NewProvinceService(db *gorm.DB) *ProvinceService {
+	return &ProvinceService{DB: db}
+}
+
+// ListProvinces returns every province with its region and aliases
+func (s *ProvinceService) ListProvinces() ([]models.Province, error) {
+	var provinces []models.Province
+	if err := s.DB.Preload("Region").Preload("Aliases").Order("name ASC").Find(&provinces).Error; err != nil {
+		return nil, err
+	}
+	return provinces, nil
+}
+
+// ListRegions returns all regions
+func (s *ProvinceService) ListRegions() ([]models.Region, error) {
+	var regions []models.Region
+	if err := s.DB.Order("id ASC").Find(&regions).Error; err != nil {
+		return nil, err
+	}
+	return regions, nil
+}
+
+// CreateAlias adds an alternative name to a province
+func (s *ProvinceService) CreateAlias(provinceID uint, alias string) (models.ProvinceAlias, error) {
+	if err := s.DB.First(&models.Province{}, provinceID).Error; err != nil {
+		return models.ProvinceAlias{}, err
+	}
+	record := models.ProvinceAlias{ProvinceID: provinceID}
+	if err := s.validateAlias(&record, alias); err != nil {
+		return models.ProvinceAlias{}, err
+	}
+	if err := s.DB.Create(&record).Error; err != nil {
+		return models.ProvinceAlias{}, err
+	}
+	s.afterReferenceChange()
+	return record, nil
+}
+
+// UpdateAlias renames an alias
+func (s *ProvinceService) UpdateAlias(id uint, alias string) (models.ProvinceAlias, error) {
+	var record models.ProvinceAlias
+	if err := s.DB.First(&record, id).Error; err != nil {
+		return models.ProvinceAlias{}, err
+	}
+	if err := s.validateAlias(&record, alias); err != nil {
+		return models.ProvinceAlias{}, err
+	}
+	if err := s.DB.Save(&record).Error; err != nil {
+		return models.ProvinceAlias{}, err
+	}
+	s.afterReferenceChange()
+	return record, nil
+}
+
+// DeleteAlias removes an alias
+func (s *ProvinceService) DeleteAlias(id uint) error {
+	result := s.DB.Delete(&models.ProvinceAlias{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	s.afterReferenceChange()
+	return nil
+}
+
+// CreateRegion adds a region that provinces can be assigned to
+func (s *ProvinceService) CreateRegion(input models.Region) (models.Region, error) {
+	region := models.Region{}
+	if err := validateRegion(&region, input); err != nil {
+		return models.Region{}, err
+	}
+	if err := s.DB.Create(&region).Error; err != nil {
+		return models.Region{}, err
+	}
+	s.afterReferenceChange()
+	return region, nil
+}
+
+// UpdateRegion renames a region
+func (s *ProvinceService) UpdateRegion(id uint, input models.Region) (models.Region, error) {
+	var region models.Region
+	if err := s.DB.First(&region, id).Error; err != nil {
+		return models.Region{}, err
+	}
+	if err := validateRegion(&region, input); err != nil {
+		return models.Region{}, err
+	}
+	if err := s.DB.Save(&region).Error; err != nil {
+		return models.Region{}, err
+	}
+	s.afterReferenceChange()
+	return region, nil
+}
+
+// DeleteRegion removes a region; its provinces become unassigned
+func (s *ProvinceService) DeleteRegion(id uint) error {
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Province{}).Where("region_id = ?", id).Update("region_id", nil).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.Region{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.afterReferenceChange()
+	return nil
+}
+
+// SetProvinceRegion moves a province into a region (nil = no region)
+func (s *ProvinceService) SetProvinceRegion(provinceID uint, regionID *uint) (models.Province, error) {
+	var province models.Province
+	if err := s.DB.First(&province, provinceID).Error; err != nil {
+		return models.Province{}, err
+	}
+	if regionID != nil {
+		if err := s.DB.First(&models.Region{}, *regionID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return models.Province{}, errors.New("region does not exist")
+			}
+			return models.Province{}, err
+		}
+	}
+	if err := s.DB.Model(&province).Update("region_id", regionID).Error; err != nil {
+		return models.Province{}, err
+	}
+	if err := s.DB.Preload("Region").Preload("Aliases").First(&province, provinceID).Error; err != nil {
+		return models.Province{}, err
+	}
+	s.afterReferenceChange()
+	return province, nil
+}
+
+func validateRegion(region *models.Region, input models.Region) error {
+	region.Name = strings.TrimSpace(input.Name)
+	region.NameEN = strings.TrimSpace(input.NameEN)
+	if region.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func (s *ProvinceService) validateAlias(record *models.ProvinceAlias, alias string) error {
+	alias = strings.TrimSpace(alias)
+	if alias == "" {
+		return errors.New("alias is required")
+	}
+	var existing int64
+	if err := s.DB.Model(&models.ProvinceAlias{}).
+		Where("LOWER(alias) = LOWER(?) AND id <> ?", alias, record.ID).Count(&existing).Error; err != nil {
+		return err
+	}
+	if existing > 0 {
+		return errDuplicateAlias
+	}
+	record.Alias = alias
+	return nil
+}
+
+// afterReferenceChange โหลด registry ใหม่ แล้ว resolve province/region ของข้อมูลเดิมใหม่ใน background
+func (s *ProvinceService) afterReferenceChange() {
+	if err := reloadProvinceRegistry(s.DB); err != nil {
+		log.Printf("Error reloading province registry: %v", err)
+		return
+	}
+	queueLocationRebuild()
+}
+
+// locationRebuilds มีที่ว่างช่องเดียว: แก้ alias/ภาคหลายครั้งระหว่างที่ rebuild รอบก่อนยังทำงานอยู่
+// จะรวมเป็น rebuild รอบถัดไปรอบเดียว และมี worker ตัวเดียวจึงไม่มี rebuild ซ้อนกัน
+var (
+	locationRebuilds    = make(chan struct{}, 1)
+	locationRebuildOnce sync.Once
+)
+
+func queueLocationRebuild() {
+	locationRebuildOnce.Do(func() { go runLocationRebuilds() })
+	select {
+	case locationRebuilds <- struct{}{}:
+	default:
+		// มีรอบที่รออยู่แล้ว ซึ่งจะอ่าน registry ล่าสุดตอนเริ่ม
+	}
+}
+
+func runLocationRebuilds() {
+	for range locationRebuilds {
+		rows, err := RebuildStationLocations(true)
+		if err != nil {
+			log.Printf("Error re-resolving station locations: %v", err)
+			continue
+		}
+		log.Printf("Re-resolved station locations after reference data change (%d rows)", rows)
+	}
+}
+
+var regionSeed = []struct {
+	name   string
+	nameEN string
+}{
+	{regionNorth, "North"},
+	{regionNortheast, "Northeast"},
+	{regionCentral, "Central"},
+	{regionEast, "East"},
+	{regionWest, "West"},
+	{regionSouth, "South"},
+	{regionLaos, "Laos"},
+}
+
+const (
+	regionNorth     = "ภาคเหนือ"
+	regionNortheast = "ภาคตะวันออกเฉียงเหนือ"
+	regionCentral   = "ภาคกลาง"
+	regionEast      = "ภาคตะวันออก"
+	regionWest      = "ภาคตะวันตก"
+	regionSouth     = "ภาคใต้"
+	regionLaos      = "Laos"
+)
+
+// provinceRegions คือภาคตั้งต้นของแต่ละจังหวัด ตามระบบ 6 ภาคทางภูมิศาสตร์
+var provinceRegions = map[string]string{
+	"เชียงราย": regionNorth, "เชียงใหม่": regionNorth, "น่าน": regionNorth, "พะเยา": regionNorth, "แพร่": regionNorth,
+	"แม่ฮ่องสอน": regionNorth, "ลำปาง": regionNorth, "ลำพูน": regionNorth, "อุตรดิตถ์": regionNorth,
+
+	"กาฬสินธุ์": regionNortheast, "ขอนแก่น": regionNortheast, "ชัยภูมิ": regionNortheast, "นครพนม": regionNortheast,
+	"นครราชสีมา": regionNortheast, "บึงกาฬ": regionNortheast, "บุรีรัมย์": regionNortheast, "มหาสารคาม": regionNortheast,
+	"มุกดาหาร": regionNortheast, "ยโสธร": regionNortheast, "ร้อยเอ็ด": regionNortheast, "เลย": regionNortheast,
+	"ศรีสะเกษ": regionNortheast, "สกลนคร": regionNortheast, "สุรินทร์": regionNortheast, "หนองคาย": regionNortheast,
+	"หนองบัวลำภู": regionNortheast, "อำนาจเจริญ": regionNortheast, "อุดรธานี": regionNortheast, "อุบลราชธานี": regionNortheast,
+
+	"กรุงเทพมหานคร": regionCentral, "กำแพงเพชร": regionCentral, "ชัยนาท": regionCentral, "นครนายก": regionCentral,
+	"นครปฐม": regionCentral, "นครสวรรค์": regionCentral, "นนทบุรี": regionCentral, "ปทุมธานี": regionCentral,
+	"พระนครศรีอยุธยา": regionCentral, "พิจิตร": regionCentral, "พิษณุโลก": regionCentral, "เพชรบูรณ์": regionCentral,
+	"ลพบุรี": regionCentral, "สมุทรปราการ": regionCentral, "สมุทรสงคราม": regionCentral, "สมุทรสาคร": regionCentral,
+	"สิงห์บุรี": regionCentral, "สุโขทัย": regionCentral, "สุพรรณบุรี": regionCentral, "สระบุรี": regionCentral,
+	"อ่างทอง": regionCentral, "อุทัยธานี": regionCentral,
+
+	"จันทบุรี": regionEast, "ฉะเชิงเทรา": regionEast, "ชลบุรี": regionEast, "ตราด": regionEast,
+	"ปราจีนบุรี": regionEast, "ระยอง": regionEast, "สระแก้ว": regionEast,
+
+	"กาญจนบุรี": regionWest, "ตาก": regionWest, "ประจวบคีรีขันธ์": regionWest, "เพชรบุรี": regionWest, "ราชบุรี": regionWest,
+
+	"กระบี่": regionSouth, "ชุมพร": regionSouth, "ตรัง": regionSouth, "นครศรีธรรมราช": regionSouth,
+	"นราธิวาส": regionSouth, "ปัตตานี": regionSouth, "พังงา": regionSouth, "พัทลุง": regionSouth,
+	"ภูเก็ต": regionSouth, "ระนอง": regionSouth, "สตูล": regionSouth, "สงขลา": regionSouth,
+	"สุราษฎร์ธานี": regionSouth, "ยะลา": regionSouth,
+}
+
+// provinceAliasData คือข้อมูลตั้งต้นของตาราง provinces/province_aliases
+var provinceAliasData = []struct {
+	canonical string
+	aliases   []string
+}{
+	{canonical: "กรุงเทพมหานคร", aliases: []string{"bangkok", "bangkok province", "bangkok metropolis", "bangkok metropolitan", "krung thep", "krungthep", "bkk"}},
+	{canonical: "กระบี่", aliases: []string{"krabi"}},
+	{canonical: "กาญจนบุรี", aliases: []string{"kanchanaburi"}},
+	{canonical: "กาฬสินธุ์", aliases: []string{"kalasin"}},
+	{canonical: "กำแพงเพชร", aliases: []string{"kamphaeng phet", "kamphaengphet"}},
+	{canonical: "ขอนแก่น", aliases: []string{"khon kaen", "khonkaen"}},
+	{canonical: "จันทบุรี", aliases: []string{"chanthaburi"}},
+	{canonical: "ฉะเชิงเทรา", aliases: []string{"chachoengsao"}},
+	{canonical: "ชลบุรี", aliases: []string{"chon buri", "chonburi"}},
+	{canonical: "ชัยนาท", aliases: []string{"chai nat", "chainat"}},
+	{canonical: "ชัยภูมิ", aliases: []string{"chaiyaphum"}},
+	{canonical: "ชุมพร", aliases: []string{"chumphon"}},
+	{canonical: "เชียงราย", aliases: []string{"chiang rai", "chiangrai"}},
+	{canonical: "เชียงใหม่", aliases: []string{"chiang mai", "chiangmai"}},
+	{canonical: "ตรัง", aliases: []string{"trang"}},
+	{canonical: "ตราด", aliases: []string{"trat"}},
+	{canonical: "ตาก", aliases: []string{"tak"}},
+	{canonical: "นครนายก", aliases: []string{"nakhon nayok", "nakhonnayok"}},
+	{canonical: "นครปฐม", aliases: []string{"nakhon pathom", "nakhonpathom"}},
+	{canonical: "นครพนม", aliases: []string{"nakhon phanom", "nakhonphanom"}},
+	{canonical: "นครราชสีมา", aliases: []string{"nakhon ratchasima", "nakhonratchasima", "korat"}},
+	{canonical: "นครศรีธรรมราช", aliases: []string{"nakhon si thammarat", "nakhonsithammarat"}},
+	{canonical: "นครสวรรค์", aliases: []string{"nakhon sawan", "nakhonsawan"}},
+	{canonical: "นนทบุรี", aliases: []string{"nonthaburi"}},
+	{canonical: "นราธิวาส", aliases: []string{"narathiwat"}},
+	{canonical: "น่าน", aliases: []string{"nan"}},
+	{canonical: "บึงกาฬ", aliases: []string{"bueng kan", "buengkan"}},
+	{canonical: "บุรีรัมย์", aliases: []string{"buri ram", "buriram"}},
+	{canonical: "ปทุมธานี", aliases: []string{"pathum thani", "pathumthani"}},
+	{canonical: "ประจวบคีรีขันธ์", aliases: []string{"prachuap khiri khan", "prachuapkhirikhan"}},
+	{canonical: "ปราจีนบุรี", aliases: []string{"prachin buri", "prachinburi"}},
+	{canonical: "ปัตตานี", aliases: []string{"pattani"}},
+	{canonical: "พระนครศรีอยุธยา", aliases: []string{"phra nakhon si ayutthaya", "phrana khonsiayutthaya", "ayutthaya", "phra nakhon si ayutaya"}},
+	{canonical: "พะเยา", aliases: []string{"phayao"}},
+	{canonical: "พังงา", aliases: []string{"phang nga", "phangnga"}},
+	{canonical: "พัทลุง", aliases: []string{"phatthalung"}},
+	{canonical: "พิจิตร", aliases: []string{"phichit"}},
+	{canonical: "พิษณุโลก", aliases: []string{"phitsanulok"}},
+	{canonical: "เพชรบุรี", aliases: []string{"phetchaburi"}},
+	{canonical: "เพชรบูรณ์", aliases: []string{"phetchabun"}},
+	{canonical: "แพร่", aliases: []string{"phrae"}},
+	{canonical: "ภูเก็ต", aliases: []string{"phuket"}},
+	{canonical: "มหาสารคาม", aliases: []string{"maha sarakham", "mahasarakham"}},
+	{canonical: "มุกดาหาร", aliases: []string{"mukdahan"}},
+	{canonical: "แม่ฮ่องสอน", aliases: []string{"mae hong son", "maehongson"}},
+	{canonical: "ยะลา", aliases: []string{"yala"}},
+	{canonical: "ยโสธร", aliases: []string{"yasothon"}},
+	{canonical: "ร้อยเอ็ด", aliases: []string{"roi et", "roiet"}},
+	{canonical: "ระนอง", aliases: []string{"ranong"}},
+	{canonical: "ระยอง", aliases: []string{"rayong"}},
+	{canonical: "ราชบุรี", aliases: []string{"ratchaburi"}},
+	{canonical: "ลพบุรี", aliases: []string{"lop buri", "lopburi"}},
+	{canonical: "ลำปาง", aliases: []string{"lampang"}},
+	{canonical: "ลำพูน", aliases: []string{"lamphun"}},
+	{canonical: "เลย", aliases: []string{"loei"}},
+	{canonical: "ศรีสะเกษ", aliases: []string{"sisaket", "si sa ket", "si saket"}},
+	{canonical: "สกลนคร", aliases: []string{"sakon nakhon", "sakonnakhon"}},
+	{canonical: "สงขลา", aliases: []string{"songkhla"}},
+	{canonical: "สตูล", aliases: []string{"satun"}},
+	{canonical: "สมุทรปราการ", aliases: []string{"samut prakan", "samutprakan"}},
+	{canonical: "สมุทรสงคราม", aliases: []string{"samut songkhram", "samutsongkhram"}},
+	{canonical: "สมุทรสาคร", aliases: []string{"samut sakhon", "samutsakhon"}},
+	{canonical: "สระบุรี", aliases: []string{"saraburi"}},
+	{canonical: "สระแก้ว", aliases: []string{"sa kaeo", "sakaeo"}},
+	{canonical: "สิงห์บุรี", aliases: []string{"sing buri", "singburi"}},
+	{canonical: "สุโขทัย", aliases: []string{"sukhothai"}},
+	{canonical: "สุพรรณบุรี", aliases: []string{"suphan buri", "suphanburi"}},
+	{canonical: "สุราษฎร์ธานี", aliases: []string{"surat thani", "suratthani"}},
+	{canonical: "สุรินทร์", aliases: []string{"surin"}},
+	{canonical: "หนองคาย", aliases: []string{"nong khai", "nongkhai"}},
+	{canonical: "หนองบัวลำภู", aliases: []string{"nong bua lam phu", "nongbualamphu"}},
+	{canonical: "อ่างทอง", aliases: []string{"ang thong", "angthong"}},
+	{canonical: "อำนาจเจริญ", aliases: []string{"amnat charoen", "amnacharoen", "amnat charern"}},
+	{canonical: "อุดรธานี", aliases: []string{"udon thani", "udonthani"}},
+	{canonical: "อุทัยธานี", aliases: []string{"uthai thani", "uthaithani"}},
+	{canonical: "อุตรดิตถ์", aliases: []string{"uttaradit"}},
+	{canonical: "อุบลราชธานี", aliases: []string{"ubon ratchathani", "ubonratchathani"}},
+	{canonical: "Laos", aliases: []string{"laos", "lao", "lao pdr", "ລາວ"}},
+}
@@ -0,0 +1,36 @@
+package services
+
+import "testing"
+
+func TestBuiltinProvinceEntriesHaveRegions(t *testing.T) {
+	entries := builtinProvinceEntries()
+	if len(entries) != 78 {
+		t.Fatalf("expected 77 provinces plus Laos, got %d", len(entries))
+	}
+	for _, e := range entries {
+		if e.region == "" {
+			t.Errorf("%s has no region", e.canonical)
+		}
+	}
+}
+
+func TestCanonicalizeProvinceUsesRegistry(t *testing.T) {
+	original := provinceEntries()
+	defer func() {
+		provinceRegistry.Lock()
+		provinceRegistry.entries = original
+		provinceRegistry.Unlock()
+	}()
+
+	provinceRegistry.Lock()
+	provinceRegistry.entries = append(append([]provinceEntry(nil), original...),
+		provinceEntry{canonical: "Bokeo", aliases: []string{"บ่อแก้ว"}, region: regionLaos, country: "LA"})
+	provinceRegistry.Unlock()
+
+	if got := canonicalizeProvince("บ่อแก้ว"); got != "Bokeo" {
+		t.Fatalf("alias from registry = %q", got)
+	}
+	if got := regionForProvince("Bokeo", ""); got != regionLaos {
+		t.Fatalf("region = %q", got)
+	}
+}
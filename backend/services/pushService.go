@@ -0,0 +1,305 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"yakkaw_dashboard/config"
+	"yakkaw_dashboard/database"
+	"yakkaw_dashboard/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	pushMaxAttempts    = 3
+	pushMaxFailures    = 5 // ลบ subscription ที่ส่งไม่สำเร็จติดกันเกินจำนวนนี้
+	pushWorkers        = 8
+	pushMaxRetryDelay  = 30 * time.Second
+	pushInitialBackoff = time.Second
+)
+
+var (
+	vapidMu    sync.Mutex
+	vapidCache *vapidKeyPair
+)
+
+type PushService struct {
+	DB *gorm.DB
+}
+
+// NewPushService creates a new PushService instance
+func NewPushService(db *gorm.DB) *PushService {
+	return &PushService{DB: db}
+}
+
+// PublicKey คืน VAPID public key สำหรับให้ dashboard ใช้ subscribe
+func (s *PushService) PublicKey() (string, error) {
+	keys, err := loadVAPIDKeys(s.DB)
+	if err != nil {
+		return "", err
+	}
+	return keys.PublicKey(), nil
+}
+
+// RotateVAPIDKeys สร้าง key pair ใหม่ subscription เดิมผูกกับ key เก่าจึงถูกลบทั้งหมด
+// (browser จะ subscribe ใหม่เมื่อเปิด dashboard) ใช้ไม่ได้เมื่อกำหนด key ผ่าน env
+func (s *PushService) RotateVAPIDKeys() (string, error) {
+	if pub, priv := config.VAPIDKeys(); pub != "" || priv != "" {
+		return "", errors.New("VAPID keys are configured via environment variables")
+	}
+	publicKey, privateKey, err := generateVAPIDKeys()
+	if err != nil {
+		return "", err
+	}
+
+	vapidMu.Lock()
+	defer vapidMu.Unlock()
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.VAPIDKey{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.VAPIDKey{PublicKey: publicKey, PrivateKey: privateKey}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("1 = 1").Delete(&models.PushSubscription{}).Error
+	})
+	if err != nil {
+		return "", err
+	}
+	vapidCache = nil
+	return publicKey, nil
+}
+
+// RegisterSubscription บันทึก (หรืออัปเดต) PushSubscription ของ browser โดยใช้ endpoint เป็น key
+func (s *PushService) RegisterSubscription(sub models.PushSubscription) (models.PushSubscription, error) {
+	sub.Endpoint = strings.TrimSpace(sub.Endpoint)
+	if err := validatePushEndpoint(sub.Endpoint); err != nil {
+		return models.PushSubscription{}, err
+	}
+	if _, err := encryptWebPush([]byte("{}"), sub.P256dh, sub.Auth); err != nil {
+		return models.PushSubscription{}, errors.New("invalid subscription keys: " + err.Error())
+	}
+
+	var existing models.PushSubscription
+	err := s.DB.Unscoped().Where("endpoint = ?", sub.Endpoint).First(&existing).Error
+	switch {
+	case err == nil:
+		existing.P256dh = sub.P256dh
+		existing.Auth = sub.Auth
+		existing.UserAgent = sub.UserAgent
+		existing.FailureCount = 0
+		existing.LastError = ""
+		existing.DeletedAt = gorm.DeletedAt{}
+		if err := s.DB.Unscoped().Save(&existing).Error; err != nil {
+			return models.PushSubscription{}, err
+		}
+		return existing, nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		sub.FailureCount = 0
+		if err := s.DB.Create(&sub).Error; err != nil {
+			return models.PushSubscription{}, err
+		}
+		return sub, nil
+	default:
+		return models.PushSubscription{}, err
+	}
+}
+
+// validatePushEndpoint รับเฉพาะ endpoint แบบ https ของ push service ที่รู้จัก (config.PushServiceHosts)
+// เพื่อไม่ให้ใช้ endpoint นี้สั่ง server ส่ง request ไปยัง host อื่น
+func validatePushEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != "https" || u.Host == "" || u.User != nil {
+		return errors.New("endpoint must be an https URL")
+	}
+	if u.Port() != "" && u.Port() != "443" {
+		return errors.New("endpoint must use the default https port")
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range config.PushServiceHosts() {
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return nil
+		}
+	}
+	return errors.New("endpoint is not a known push service")
+}
+
+// DeleteSubscription ลบ subscription ตาม endpoint (เมื่อผู้ใช้ปิดการแจ้งเตือนใน browser)
+func (s *PushService) DeleteSubscription(endpoint string) error {
+	result := s.DB.Unscoped().Where("endpoint = ?", strings.TrimSpace(endpoint)).Delete(&models.PushSubscription{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// loadVAPIDKeys ใช้ key จาก env ก่อน ถ้าไม่มีจะใช้ key ที่เก็บในฐานข้อมูล (สร้างใหม่ครั้งแรก)
+func loadVAPIDKeys(db *gorm.DB) (*vapidKeyPair, error) {
+	vapidMu.Lock()
+	defer vapidMu.Unlock()
+	if vapidCache != nil {
+		return vapidCache, nil
+	}
+
+	publicKey, privateKey := config.VAPIDKeys()
+	if privateKey == "" {
+		var stored models.VAPIDKey
+		err := db.Order("id DESC").First(&stored).Error
+		switch {
+		case err == nil:
+			publicKey, privateKey = stored.PublicKey, stored.PrivateKey
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if publicKey, privateKey, err = generateVAPIDKeys(); err != nil {
+				return nil, err
+			}
+			if err := db.Create(&models.VAPIDKey{PublicKey: publicKey, PrivateKey: privateKey}).Error; err != nil {
+				return nil, err
+			}
+			log.Println("Generated a new VAPID key pair for Web Push")
+		default:
+			return nil, err
+		}
+	}
+
+	keys, err := parseVAPIDKeys(publicKey, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	vapidCache = keys
+	return keys, nil
+}
+
+// pushPayload คือ JSON ที่ service worker ของ dashboard ได้รับใน event "push"
+type pushPayload struct {
+	ID       uint   `json:"id"`
+	Title    string `json:"title"`
+	Body     string `json:"body"`
+	Category string `json:"category,omitempty"`
+	Icon     string `json:"icon,omitempty"`
+}
+
+// SendPushNotification ส่ง notification ไปยังทุก browser ที่ subscribe ไว้ (เรียกใน background)
+func SendPushNotification(notification models.Notification) {
+	var subs []models.PushSubscription
+	if err := database.DB.Find(&subs).Error; err != nil {
+		log.Printf("Error loading push subscriptions: %v", err)
+		return
+	}
+	if len(subs) == 0 {
+		return
+	}
+
+	keys, err := loadVAPIDKeys(database.DB)
+	if err != nil {
+		log.Printf("Web Push disabled: %v", err)
+		return
+	}
+	payload, err := buildPushPayload(notification)
+	if err != nil {
+		log.Printf("Error encoding push payload: %v", err)
+		return
+	}
+
+	subject := config.VAPIDSubject()
+	jobs := make(chan models.PushSubscription)
+	var wg sync.WaitGroup
+	for i := 0; i < pushWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for sub := range jobs {
+				deliverPush(keys, subject, sub, payload)
+			}
+		}()
+	}
+	for _, sub := range subs {
+		jobs <- sub
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// buildPushPayload ตัดข้อความให้พอดีกับขนาดสูงสุดของ Web Push record
+func buildPushPayload(notification models.Notification) ([]byte, error) {
+	p := pushPayload{
+		ID:       notification.ID,
+		Title:    notification.Title,
+		Body:     notification.Message,
+		Category: notification.Category,
+		Icon:     notification.Icon,
+	}
+	for {
+		payload, err := json.Marshal(p)
+		if err != nil || len(payload) <= webPushMaxPayload || p.Body == "" {
+			return payload, err
+		}
+		runes := []rune(p.Body)
+		p.Body = string(runes[:len(runes)*3/4]) + "…"
+		if len(runes) < 8 {
+			p.Body = ""
+		}
+	}
+}
+
+// deliverPush ส่งซ้ำเมื่อส่งไม่ถึงหรือ push service ตอบ 429/5xx และลบ subscription ที่หมดอายุ (404/410)
+func deliverPush(keys *vapidKeyPair, subject string, sub models.PushSubscription, payload []byte) {
+	// subscription ที่บันทึกก่อนจำกัด host หรือหลังเปลี่ยน PUSH_SERVICE_HOSTS
+	if err := validatePushEndpoint(sub.Endpoint); err != nil {
+		log.Printf("Removing push subscription %d: %v", sub.ID, err)
+		database.DB.Unscoped().Delete(&sub)
+		return
+	}
+
+	target := webPushTarget{Endpoint: sub.Endpoint, P256dh: sub.P256dh, Auth: sub.Auth}
+	backoff := pushInitialBackoff
+
+	var lastErr error
+	for attempt := 1; attempt <= pushMaxAttempts; attempt++ {
+		result, err := sendWebPush(keys, subject, target, payload)
+		if err == nil {
+			now := time.Now()
+			database.DB.Model(&sub).Updates(map[string]interface{}{"failure_count": 0, "last_success_at": &now, "last_error": ""})
+			return
+		}
+		lastErr = err
+
+		switch {
+		case errors.Is(err, errWebPushBuild):
+			attempt = pushMaxAttempts // key ของ subscription ใช้ไม่ได้ ส่งซ้ำก็ได้ผลเดิม
+		case result.StatusCode == http.StatusNotFound || result.StatusCode == http.StatusGone:
+			database.DB.Unscoped().Delete(&sub)
+			return
+		case result.StatusCode == 0 || result.StatusCode == http.StatusTooManyRequests || result.StatusCode >= 500:
+			if attempt < pushMaxAttempts {
+				delay := backoff
+				if result.RetryAfter > 0 {
+					delay = result.RetryAfter
+				}
+				if delay > pushMaxRetryDelay {
+					delay = pushMaxRetryDelay
+				}
+				time.Sleep(delay)
+				backoff *= 2
+			}
+		default:
+			attempt = pushMaxAttempts // 4xx อื่น ๆ ส่งซ้ำไปก็ไม่สำเร็จ
+		}
+	}
+
+	if sub.FailureCount+1 >= pushMaxFailures {
+		log.Printf("Removing push subscription %d after %d failures: %v", sub.ID, sub.FailureCount+1, lastErr)
+		database.DB.Unscoped().Delete(&sub)
+		return
+	}
+	database.DB.Model(&sub).Updates(map[string]interface{}{"failure_count": sub.FailureCount + 1, "last_error": lastErr.Error()})
+}
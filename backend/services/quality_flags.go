@@ -0,0 +1,242 @@
+package services
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"yakkaw_dashboard/database"
+	"yakkaw_dashboard/models"
+)
+
+// Quality flags (bitmask) ที่คำนวณตอน ingest และเก็บไว้ใน sensor_data.quality_flags
+const (
+	FlagOutOfRange     = 1 << iota // ค่าอยู่นอกช่วงที่เซนเซอร์วัดได้จริง เช่น PM2.5 = 999
+	FlagSpike                      // พุ่งสูงผิดปกติเมื่อเทียบกับ median ล่าสุดของอุปกรณ์เดียวกัน
+	FlagFlatline                   // ค่าค้างเท่าเดิมติดต่อกันหลายรอบ (เช่นค้างที่ 0)
+	FlagPMInconsistent             // PM2.5 มากกว่า PM10 ซึ่งเป็นไปไม่ได้ทางกายภาพ
+)
+
+const (
+	qualityRecentReadings  = 12  // จำนวนค่าล่าสุดของอุปกรณ์ที่ใช้หา median
+	qualitySpikeFactor     = 3.0 // ถือเป็น spike เมื่อเกิน median × factor
+	qualitySpikeMinDelta   = 50  // และต้องต่างจาก median อย่างน้อยเท่านี้ (µg/m³)
+	qualityFlatlineRepeats = 5   // จำนวนค่าก่อนหน้าที่ต้องเท่ากันทั้งหมดจึงถือว่าค้าง
+	qualityMaxPM25         = 500
+	qualityMaxPM10         = 1000
+)
+
+var qualityFlagNames = []struct {
+	flag int
+	name string
+}{
+	{FlagOutOfRange, "out_of_range"},
+	{FlagSpike, "spike"},
+	{FlagFlatline, "flatline"},
+	{FlagPMInconsistent, "pm_inconsistent"},
+}
+
+// QualityFlagNames แปลง bitmask เป็นชื่อ flag ที่อ่านได้
+func QualityFlagNames(flags int) []string {
+	names := []string{}
+	for _, entry := range qualityFlagNames {
+		if flags&entry.flag != 0 {
+			names = append(names, entry.name)
+		}
+	}
+	return names
+}
+
+// computeQualityFlags ตรวจค่าที่เข้ามาใหม่ โดย recent คือค่า PM2.5 ก่อนหน้าของอุปกรณ์เดียวกัน (ใหม่สุดก่อน)
+func computeQualityFlags(data models.SensorData, recent []int) int {
+	flags := 0
+
+	if data.PM25 < 0 || data.PM25 > qualityMaxPM25 ||
+		data.PM10 < 0 || data.PM10 > qualityMaxPM10 ||
+		data.Humidity < 0 || data.Humidity > 100 {
+		flags |= FlagOutOfRange
+	}
+
+	if data.PM10 > 0 && data.PM25 > data.PM10 {
+		flags |= FlagPMInconsistent
+	}
+
+	if len(recent) >= 3 {
+		median := medianInt(recent)
+		threshold := median * qualitySpikeFactor
+		if threshold < median+qualitySpikeMinDelta {
+			threshold = median + qualitySpikeMinDelta
+		}
+		if float64(data.PM25) > threshold {
+			flags |= FlagSpike
+		}
+	}
+
+	if len(recent) >= qualityFlatlineRepeats {
+		flat := true
+		for _, v := range recent[:qualityFlatlineRepeats] {
+			if v != data.PM25 {
+				flat = false
+				break
+			}
+		}
+		if flat {
+			flags |= FlagFlatline
+		}
+	}
+
+	return flags
+}
+
+// loadRecentPM25 ดึงค่า PM2.5 ล่าสุดของอุปกรณ์ (หนึ่งค่าต่อ timestamp) ที่เก่ากว่า before
+func loadRecentPM25(dvid string, before int64) ([]int, error) {
+	var values []int
+	err := database.DB.Raw(`
+		SELECT pm25 FROM (
+			SELECT DISTINCT ON (timestamp) timestamp, pm25
+			FROM sensor_data
+			WHERE dvid = ? AND timestamp < ?
+			ORDER BY timestamp DESC
+			LIMIT ?
+		) recent
+		ORDER BY timestamp DESC
+	`, dvid, before, qualityRecentReadings).Scan(&values).Error
+	return values, err
+}
+
+// qualityWindow เก็บค่า PM2.5 ก่อนหน้าของอุปกรณ์หนึ่งระหว่างไล่ข้อมูลย้อนหลังตามเวลา
+// ให้ได้ recent แบบเดียวกับ loadRecentPM25 ตอน ingest (หนึ่งค่าต่อ timestamp เฉพาะที่เก่ากว่าแถวปัจจุบัน)
+type qualityWindow struct {
+	recent  []int
+	ts      int64
+	pending int
+	started bool
+}
+
+// flags คำนวณ flag ของแถวถัดไป แถวต้องเรียงตาม timestamp จากเก่าไปใหม่
+func (w *qualityWindow) flags(data models.SensorData) int {
+	if !w.started || data.Timestamp != w.ts {
+		if w.started {
+			w.recent = append([]int{w.pending}, w.recent...)
+			if len(w.recent) > qualityRecentReadings {
+				w.recent = w.recent[:qualityRecentReadings]
+			}
+		}
+		w.ts, w.pending, w.started = data.Timestamp, data.PM25, true
+	}
+	return computeQualityFlags(data, w.recent)
+}
+
+// BackfillQualityFlags คำนวณ quality_flags ของข้อมูลย้อนหลังทั้งหมดใหม่ด้วยเกณฑ์เดียวกับตอน ingest
+// (ข้อมูลที่เก็บก่อนมีการตรวจคุณภาพมี quality_flags = 0) คืนจำนวนแถวที่ flag เปลี่ยน
+func BackfillQualityFlags() (int64, error) {
+	var dvids []string
+	if err := database.DB.Raw("SELECT DISTINCT dvid FROM sensor_data").Scan(&dvids).Error; err != nil {
+		return 0, err
+	}
+
+	var updated int64
+	for _, dvid := range dvids {
+		n, err := backfillDeviceQualityFlags(dvid)
+		updated += n
+		if err != nil {
+			return updated, err
+		}
+	}
+
+	if updated > 0 {
+		if err := RefreshStationSnapshots(); err != nil {
+			log.Printf("Error refreshing station snapshots: %v", err)
+		}
+	}
+	return updated, nil
+}
+
+// qualityBackfillRunning กันไม่ให้ backfill ทั้งตารางซ้อนกันหลายรอบ
+var qualityBackfillRunning atomic.Bool
+
+// BackfillQualityFlagsAsync runs BackfillQualityFlags in the background and logs the result.
+// It returns false without starting when a backfill is already running.
+func BackfillQualityFlagsAsync() bool {
+	if !qualityBackfillRunning.CompareAndSwap(false, true) {
+		return false
+	}
+	go func() {
+		defer qualityBackfillRunning.Store(false)
+		rows, err := BackfillQualityFlags()
+		if err != nil {
+			log.Printf("Error backfilling quality flags (%d rows updated): %v", rows, err)
+			return
+		}
+		log.Printf("Backfilled quality flags (%d rows updated)", rows)
+	}()
+	return true
+}
+
+const qualityBackfillBatch = 500
+
+func backfillDeviceQualityFlags(dvid string) (int64, error) {
+	rows, err := database.DB.Raw(`
+		SELECT id, timestamp, pm25, pm10, humidity, quality_flags
+		FROM sensor_data
+		WHERE dvid = ?
+		ORDER BY timestamp, id
+	`, dvid).Rows()
+	if err != nil {
+		return 0, err
+	}
+
+	type change struct {
+		id    uint
+		flags int
+	}
+	var changes []change
+	var window qualityWindow
+	for rows.Next() {
+		var data models.SensorData
+		if err := rows.Scan(&data.ID, &data.Timestamp, &data.PM25, &data.PM10, &data.Humidity, &data.QualityFlags); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if flags := window.flags(data); flags != data.QualityFlags {
+			changes = append(changes, change{data.ID, flags})
+		}
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	var updated int64
+	for start := 0; start < len(changes); start += qualityBackfillBatch {
+		batch := changes[start:min(start+qualityBackfillBatch, len(changes))]
+		values := make([]string, len(batch))
+		args := make([]interface{}, 0, 2*len(batch))
+		for i, c := range batch {
+			values[i] = "(?::bigint, ?::int)"
+			args = append(args, c.id, c.flags)
+		}
+		result := database.DB.Exec(`
+			UPDATE sensor_data SET quality_flags = v.flags
+			FROM (VALUES `+strings.Join(values, ", ")+`) AS v(id, flags)
+			WHERE sensor_data.id = v.id
+		`, args...)
+		if result.Error != nil {
+			return updated, result.Error
+		}
+		updated += result.RowsAffected
+	}
+	return updated, nil
+}
+
+func medianInt(values []int) float64 {
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return float64(sorted[mid-1]+sorted[mid]) / 2
+	}
+	return float64(sorted[mid])
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"yakkaw_dashboard/models"
+)
+
+func TestComputeQualityFlags(t *testing.T) {
+	recent := []int{20, 22, 18, 25, 21, 19}
+
+	cases := []struct {
+		name   string
+		data   models.SensorData
+		recent []int
+		want   int
+	}{
+		{"normal", models.SensorData{PM25: 24, PM10: 35, Humidity: 60}, recent, 0},
+		{"sentinel 999", models.SensorData{PM25: 999, PM10: 999, Humidity: 60}, recent, FlagOutOfRange | FlagSpike},
+		{"spike", models.SensorData{PM25: 180, PM10: 200, Humidity: 60}, recent, FlagSpike},
+		{"flatline at zero", models.SensorData{PM25: 0, PM10: 0}, []int{0, 0, 0, 0, 0, 0}, FlagFlatline},
+		{"pm25 above pm10", models.SensorData{PM25: 30, PM10: 20, Humidity: 60}, recent, FlagPMInconsistent},
+		{"no history", models.SensorData{PM25: 300, PM10: 320, Humidity: 60}, nil, 0},
+	}
+
+	for _, tc := range cases {
+		if got := computeQualityFlags(tc.data, tc.recent); got != tc.want {
+			t.Fatalf("%s: flags = %v, want %v", tc.name, QualityFlagNames(got), QualityFlagNames(tc.want))
+		}
+	}
+
+	if got := QualityFlagNames(FlagSpike | FlagFlatline); !reflect.DeepEqual(got, []string{"spike", "flatline"}) {
+		t.Fatalf("QualityFlagNames = %v", got)
+	}
+}
+
+func TestQualityWindowMatchesIngest(t *testing.T) {
+	var w qualityWindow
+	readings := []models.SensorData{
+		{Timestamp: 1, PM25: 20, PM10: 30},
+		{Timestamp: 2, PM25: 22, PM10: 30},
+		{Timestamp: 2, PM25: 22, PM10: 30}, // แถวซ้ำ timestamp เดิมไม่นับเป็นค่าก่อนหน้าของกันและกัน
+		{Timestamp: 3, PM25: 18, PM10: 30},
+		{Timestamp: 4, PM25: 150, PM10: 160},
+		{Timestamp: 5, PM25: 21, PM10: 30},
+	}
+	want := []int{0, 0, 0, 0, FlagSpike, 0}
+	for i, r := range readings {
+		if got := w.flags(r); got != want[i] {
+			t.Fatalf("reading %d: flags = %v, want %v", i, QualityFlagNames(got), QualityFlagNames(want[i]))
+		}
+	}
+	if !reflect.DeepEqual(w.recent, []int{150, 18, 22, 20}) {
+		t.Fatalf("recent = %v", w.recent)
+	}
+
+	var flat qualityWindow
+	for i := 0; i < qualityRecentReadings+3; i++ {
+		flat.flags(models.SensorData{Timestamp: int64(i)})
+	}
+	if len(flat.recent) != qualityRecentReadings {
+		t.Fatalf("window length = %d", len(flat.recent))
+	}
+	if got := flat.flags(models.SensorData{Timestamp: 100}); got != FlagFlatline {
+		t.Fatalf("flatline flags = %v", QualityFlagNames(got))
+	}
+}
@@ -0,0 +1,254 @@
+// services/range_ranking.go
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"yakkaw_dashboard/database"
+)
+
+// ค่ามาตรฐานรายวันที่ใช้นับวันเกินมาตรฐาน (exceedance) เมื่อไม่ได้ส่ง threshold มา
+var defaultExceedanceThresholds = map[string]float64{
+	"pm25": 37.5,
+	"pm10": 120,
+	"aqi":  100,
+}
+
+type RangeRankingOptions struct {
+	From      time.Time // inclusive
+	To        time.Time // exclusive
+	Metric    string
+	Group     string
+	By        string // avg | max | exceedance
+	Order     string // desc | asc
+	Threshold float64
+	Limit     int
+	DataOptions
+}
+
+type RangeRankRow struct {
+	Key            string  `json:"key"`
+	Value          float64 `json:"value"`
+	Avg            float64 `json:"avg"`
+	Max            float64 `json:"max"`
+	ExceedanceDays int     `json:"exceedance_days"`
+	Days           int     `json:"days"`
+	Count          int     `json:"count"`
+	Rank           int     `json:"rank"`
+	PrevRank       *int    `json:"prev_rank"`
+	RankChange     *int    `json:"rank_change"` // > 0 = ขยับขึ้น N อันดับ, < 0 = ลงมา N อันดับ
+	Movement       string  `json:"movement"`    // up | down | same | new
+}
+
+type RangeRankingResult struct {
+	From      string         `json:"from"`
+	To        string         `json:"to"`
+	PrevFrom  string         `json:"prev_from"`
+	PrevTo    string         `json:"prev_to"`
+	Metric    string         `json:"metric"`
+	Group     string         `json:"group"`
+	By        string         `json:"by"`
+	Order     string         `json:"order"`
+	Threshold float64        `json:"threshold"`
+	Items     []RangeRankRow `json:"items"`
+}
+
+// GetRangeRanking จัดอันดับตามช่วงวันที่ [From, To) และเทียบอันดับกับช่วงก่อนหน้าที่ยาวเท่ากัน
+func GetRangeRanking(opts RangeRankingOptions) (RangeRankingResult, error) {
+	var result RangeRankingResult
+
+	metricCol, groupCol, err := rankingColumns(opts.Metric, opts.Group)
+	if err != nil {
+		return result, err
+	}
+	if !opts.To.After(opts.From) {
+		return result, fmt.Errorf("invalid range: from must be before to")
+	}
+
+	opts.By = strings.ToLower(opts.By)
+	switch opts.By {
+	case "":
+		opts.By = "avg"
+	case "avg", "max", "exceedance":
+	default:
+		return result, fmt.Errorf("invalid by (expect avg | max | exceedance)")
+	}
+
+	opts.Order = strings.ToLower(opts.Order)
+	switch opts.Order {
+	case "":
+		opts.Order = "desc"
+	case "asc", "desc":
+	default:
+		return result, fmt.Errorf("invalid order (expect asc | desc)")
+	}
+
+	if opts.Threshold <= 0 {
+		threshold, ok := defaultExceedanceThresholds[metricCol]
+		if !ok && opts.By == "exceedance" {
+			return result, fmt.Errorf("threshold is required for metric %s", opts.Metric)
+		}
+		opts.Threshold = threshold
+	}
+
+	length := opts.To.Sub(opts.From)
+	prevFrom := opts.From.Add(-length)
+	prevTo := opts.From
+
+	current, err := queryRangeStats(metricCol, groupCol, opts.From, opts.To, opts.Threshold, opts.DataOptions)
+	if err != nil {
+		return result, err
+	}
+	previous, err := queryRangeStats(metricCol, groupCol, prevFrom, prevTo, opts.Threshold, opts.DataOptions)
+	if err != nil {
+		return result, err
+	}
+
+	assignRangeRanks(current, opts.By, opts.Order)
+	assignRangeRanks(previous, opts.By, opts.Order)
+	applyRankChanges(current, previous)
+
+	if opts.Limit > 0 && len(current) > opts.Limit {
+		current = current[:opts.Limit]
+	}
+
+	const dateLayout = "2006-01-02"
+	result = RangeRankingResult{
+		From:      opts.From.Format(dateLayout),
+		To:        opts.To.Add(-time.Nanosecond).Format(dateLayout),
+		PrevFrom:  prevFrom.Format(dateLayout),
+		PrevTo:    prevTo.Add(-time.Nanosecond).Format(dateLayout),
+		Metric:    opts.Metric,
+		Group:     opts.Group,
+		By:        opts.By,
+		Order:     opts.Order,
+		Threshold: opts.Threshold,
+		Items:     current,
+	}
+	return result, nil
+}
+
+// queryRangeStats คำนวณค่าเฉลี่ยรายวันของแต่ละ group แล้วสรุปเป็น avg/max/จำนวนวันที่เกิน threshold
+func queryRangeStats(metricCol, groupCol string, from, to time.Time, threshold float64, opts DataOptions) ([]RangeRankRow, error) {
+	query := fmt.Sprintf(`
+        WITH daily AS (
+            SELECT
+                %s AS key,
+                date_trunc('day', to_timestamp(timestamp/1000) AT TIME ZONE 'Asia/Bangkok') AS day,
+                AVG(NULLIF(%s,0)) AS day_avg,
+                COUNT(*)          AS cnt
+            FROM sensor_data
+            WHERE (to_timestamp(timestamp/1000) AT TIME ZONE 'Asia/Bangkok') >= ?
+              AND (to_timestamp(timestamp/1000) AT TIME ZONE 'Asia/Bangkok') <  ?
+              AND %s IS NOT NULL
+              AND %s <> ''%s
+            GROUP BY 1, 2
+        )
+        SELECT key,
+               AVG(day_avg)                          AS avg_val,
+               MAX(day_avg)                          AS max_val,
+               COUNT(*) FILTER (WHERE day_avg > ?)   AS exceed_days,
+               COUNT(day_avg)                        AS days,
+               SUM(cnt)                              AS cnt
+        FROM daily
+        GROUP BY key;
+    `, groupCol, opts.metricExpr(metricCol), groupCol, groupCol, opts.qualityClause())
+
+	rows, err := database.DB.Raw(query, from, to, threshold).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []RangeRankRow{}
+	for rows.Next() {
+		var key sql.NullString
+		var avg, maxVal sql.NullFloat64
+		var exceed, days, cnt int
+		if err := rows.Scan(&key, &avg, &maxVal, &exceed, &days, &cnt); err != nil {
+			return nil, err
+		}
+		if !key.Valid || !avg.Valid {
+			continue
+		}
+		res = append(res, RangeRankRow{
+			Key:            key.String,
+			Avg:            roundToTwoDecimals(avg.Float64),
+			Max:            roundToTwoDecimals(maxVal.Float64),
+			ExceedanceDays: exceed,
+			Days:           days,
+			Count:          cnt,
+		})
+	}
+	return res, rows.Err()
+}
+
+// assignRangeRanks เรียงลำดับและให้อันดับแบบ RANK() (ค่าเท่ากันได้อันดับเดียวกัน)
+func assignRangeRanks(rows []RangeRankRow, by, order string) {
+	for i := range rows {
+		switch by {
+		case "max":
+			rows[i].Value = rows[i].Max
+		case "exceedance":
+			rows[i].Value = float64(rows[i].ExceedanceDays)
+		default:
+			rows[i].Value = rows[i].Avg
+		}
+	}
+
+	asc := order == "asc"
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Value != rows[j].Value {
+			if asc {
+				return rows[i].Value < rows[j].Value
+			}
+			return rows[i].Value > rows[j].Value
+		}
+		if rows[i].Avg != rows[j].Avg {
+			if asc {
+				return rows[i].Avg < rows[j].Avg
+			}
+			return rows[i].Avg > rows[j].Avg
+		}
+		return rows[i].Key < rows[j].Key
+	})
+
+	for i := range rows {
+		if i > 0 && rows[i].Value == rows[i-1].Value {
+			rows[i].Rank = rows[i-1].Rank
+		} else {
+			rows[i].Rank = i + 1
+		}
+	}
+}
+
+// applyRankChanges เติม prev_rank และจำนวนอันดับที่ขยับเทียบกับช่วงก่อนหน้า
+func applyRankChanges(current, previous []RangeRankRow) {
+	prevRanks := make(map[string]int, len(previous))
+	for _, row := range previous {
+		prevRanks[row.Key] = row.Rank
+	}
+
+	for i := range current {
+		prev, ok := prevRanks[current[i].Key]
+		if !ok {
+			current[i].Movement = "new"
+			continue
+		}
+		change := prev - current[i].Rank
+		current[i].PrevRank = &prev
+		current[i].RankChange = &change
+		switch {
+		case change > 0:
+			current[i].Movement = "up"
+		case change < 0:
+			current[i].Movement = "down"
+		default:
+			current[i].Movement = "same"
+		}
+	}
+}
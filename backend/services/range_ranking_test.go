@@ -0,0 +1,40 @@
+package services
+
+import "testing"
+
+func TestAssignRangeRanksAndChanges(t *testing.T) {
+	current := []RangeRankRow{
+		{Key: "เชียงราย", Avg: 40, Max: 80, ExceedanceDays: 3},
+		{Key: "เชียงใหม่", Avg: 55, Max: 70, ExceedanceDays: 5},
+		{Key: "น่าน", Avg: 40, Max: 60, ExceedanceDays: 1},
+		{Key: "Laos", Avg: 20, Max: 30, ExceedanceDays: 0},
+	}
+	previous := []RangeRankRow{
+		{Key: "เชียงราย", Avg: 60},
+		{Key: "เชียงใหม่", Avg: 30},
+		{Key: "น่าน", Avg: 45},
+	}
+
+	assignRangeRanks(current, "avg", "desc")
+	assignRangeRanks(previous, "avg", "desc")
+	applyRankChanges(current, previous)
+
+	wantRanks := map[string]int{"เชียงใหม่": 1, "เชียงราย": 2, "น่าน": 2, "Laos": 4}
+	wantMove := map[string]string{"เชียงใหม่": "up", "เชียงราย": "down", "น่าน": "same", "Laos": "new"}
+	for _, row := range current {
+		if row.Rank != wantRanks[row.Key] {
+			t.Fatalf("rank of %s = %d, want %d", row.Key, row.Rank, wantRanks[row.Key])
+		}
+		if row.Movement != wantMove[row.Key] {
+			t.Fatalf("movement of %s = %q, want %q", row.Key, row.Movement, wantMove[row.Key])
+		}
+	}
+	if current[0].RankChange == nil || *current[0].RankChange != 2 {
+		t.Fatalf("expected เชียงใหม่ to move up 2 places, got %v", current[0].RankChange)
+	}
+
+	assignRangeRanks(current, "exceedance", "asc")
+	if current[0].Key != "Laos" || current[0].Value != 0 {
+		t.Fatalf("ascending exceedance ranking should start with the cleanest area, got %+v", current[0])
+	}
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"yakkaw_dashboard/database"
+)
+
+const (
+	DefaultRawReadingLimit = 1000
+	MaxRawReadingLimit     = 10000
+	rawReadingBatchSize    = 5000 // จำนวนแถวต่อรอบของ StreamRawReadings
+)
+
+// RawReadingMetrics คือ column ค่าวัดที่เลือกได้ด้วย ?metrics= (ค่าเริ่มต้นคือทั้งหมด)
+var RawReadingMetrics = []string{
+	"pm25", "pm10", "pm100", "aqi", "temperature", "humidity", "pres",
+	"pm25_calibrated", "pm10_calibrated",
+}
+
+// RawReadingQuery คือเงื่อนไขของ raw readings API ช่วงเวลาเป็น [From, To)
+type RawReadingQuery struct {
+	DVIDs    []string
+	Province string
+	From     time.Time
+	To       time.Time
+	Metrics  []string
+	Limit    int
+	After    *RawReadingCursor
+	DataOptions
+}
+
+// RawReading คือข้อมูลดิบหนึ่งแถว Metrics มีเฉพาะ metric ที่ขอและมีค่า
+type RawReading struct {
+	ID           uint               `json:"id"`
+	DVID         string             `json:"dvid"`
+	Timestamp    int64              `json:"timestamp"`
+	Latitude     float64            `json:"latitude"`
+	Longitude    float64            `json:"longitude"`
+	Place        string             `json:"place"`
+	Address      string             `json:"address"`
+	Province     string             `json:"province"`
+	District     string             `json:"district"`
+	Region       string             `json:"region"`
+	QualityFlags int                `json:"quality_flags"`
+	Metrics      map[string]float64 `json:"metrics"`
+}
+
+// RawReadingCursor คือตำแหน่งของแถวสุดท้ายที่ส่งไปแล้ว (keyset ตาม timestamp, id)
+type RawReadingCursor struct {
+	Timestamp int64
+	ID        uint
+}
+
+// Encode แปลง cursor เป็นข้อความทึบสำหรับส่งให้ client
+func (c RawReadingCursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", c.Timestamp, c.ID)))
+}
+
+// DecodeRawReadingCursor อ่าน cursor ที่ได้จาก Encode
+func DecodeRawReadingCursor(s string) (*RawReadingCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	tsPart, idPart, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	ts, err := strconv.ParseInt(tsPart, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	id, err := strconv.ParseUint(idPart, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return &RawReadingCursor{Timestamp: ts, ID: uint(id)}, nil
+}
+
+// ParseRawReadingMetrics ตรวจ ?metrics=pm25,aqi กับ RawReadingMetrics (ว่าง = ทั้งหมด)
+func ParseRawReadingMetrics(raw string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return RawReadingMetrics, nil
+	}
+	var metrics []string
+	for _, m := range strings.Split(raw, ",") {
+		m = strings.ToLower(strings.TrimSpace(m))
+		if m == "" {
+			continue
+		}
+		if !containsString(RawReadingMetrics, m) {
+			return nil, fmt.Errorf("unsupported metric %q (use %s)", m, strings.Join(RawReadingMetrics, ", "))
+		}
+		if !containsString(metrics, m) {
+			metrics = append(metrics, m)
+		}
+	}
+	if len(metrics) == 0 {
+		return RawReadingMetrics, nil
+	}
+	return metrics, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// rawReadingsSQL สร้าง query แบบ keyset (ไม่ใช้ OFFSET) เรียงตาม timestamp, id
+func rawReadingsSQL(q RawReadingQuery, limit int) (string, []interface{}) {
+	var sb strings.Builder
+	sb.WriteString(`SELECT id, dvid, timestamp, latitude, longitude, COALESCE(place, ''), COALESCE(address, ''),
+		COALESCE(province, ''), COALESCE(district, ''), COALESCE(region, ''), quality_flags`)
+	for _, m := range q.Metrics {
+		sb.WriteString(", " + m)
+	}
+	sb.WriteString(`
+		FROM sensor_data
+		WHERE timestamp >= ? AND timestamp < ?`)
+	args := []interface{}{q.From.UnixMilli(), q.To.UnixMilli()}
+
+	if len(q.DVIDs) > 0 {
+		sb.WriteString(" AND dvid IN ?")
+		args = append(args, q.DVIDs)
+	}
+	if q.Province != "" {
+		sb.WriteString(" AND province = ?")
+		args = append(args, normalizeProvince(q.Province))
+	}
+	if q.After != nil {
+		sb.WriteString(" AND (timestamp, id) > (?, ?)")
+		args = append(args, q.After.Timestamp, q.After.ID)
+	}
+	sb.WriteString(q.qualityClause())
+	sb.WriteString(" ORDER BY timestamp, id LIMIT ?")
+	args = append(args, limit)
+	return sb.String(), args
+}
+
+// ListRawReadings คืนข้อมูลหนึ่งหน้าและ cursor ของหน้าถัดไป (nil เมื่อหมดแล้ว)
+func ListRawReadings(q RawReadingQuery) ([]RawReading, *RawReadingCursor, error) {
+	if q.Limit <= 0 {
+		q.Limit = DefaultRawReadingLimit
+	}
+	if q.Limit > MaxRawReadingLimit {
+		q.Limit = MaxRawReadingLimit
+	}
+
+	// ดึงเกินหนึ่งแถวเพื่อรู้ว่ายังมีหน้าถัดไปหรือไม่
+	readings := make([]RawReading, 0, q.Limit+1)
+	err := eachRawReading(q, q.Limit+1, func(r RawReading) error {
+		readings = append(readings, r)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(readings) <= q.Limit {
+		return readings, nil, nil
+	}
+	readings = readings[:q.Limit]
+	last := readings[len(readings)-1]
+	return readings, &RawReadingCursor{Timestamp: last.Timestamp, ID: last.ID}, nil
+}
+
+// StreamRawReadings ส่งทุกแถวที่ตรงเงื่อนไขให้ fn ทีละแถว โดยดึงเป็นรอบละ rawReadingBatchSize
+// q.Limit > 0 จำกัดจำนวนแถวทั้งหมด หลัง fn ของแต่ละรอบจะเรียก flush (ถ้ามี) เพื่อส่งข้อมูลออกไปก่อน
+func StreamRawReadings(q RawReadingQuery, fn func(RawReading) error, flush func()) error {
+	remaining := q.Limit
+	for {
+		batch := rawReadingBatchSize
+		if q.Limit > 0 && remaining < batch {
+			batch = remaining
+		}
+		if batch == 0 {
+			return nil
+		}
+
+		n := 0
+		err := eachRawReading(q, batch, func(r RawReading) error {
+			n++
+			q.After = &RawReadingCursor{Timestamp: r.Timestamp, ID: r.ID}
+			return fn(r)
+		})
+		if err != nil {
+			return err
+		}
+		if flush != nil {
+			flush()
+		}
+		if n < batch {
+			return nil
+		}
+		remaining -= n
+	}
+}
+
+func eachRawReading(q RawReadingQuery, limit int, fn func(RawReading) error) error {
+	query, args := rawReadingsSQL(q, limit)
+	rows, err := database.DB.Raw(query, args...).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	values := make([]sql.NullFloat64, len(q.Metrics))
+	for rows.Next() {
+		var r RawReading
+		var dvid sql.NullString
+		dest := []interface{}{&r.ID, &dvid, &r.Timestamp, &r.Latitude, &r.Longitude, &r.Place, &r.Address,
+			&r.Province, &r.District, &r.Region, &r.QualityFlags}
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		r.DVID = dvid.String
+		r.Metrics = make(map[string]float64, len(q.Metrics))
+		for i, m := range q.Metrics {
+			if values[i].Valid {
+				r.Metrics[m] = values[i].Float64
+			}
+		}
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRawReadingCursorRoundTrip(t *testing.T) {
+	c := RawReadingCursor{Timestamp: 1717200000123, ID: 98765}
+	got, err := DecodeRawReadingCursor(c.Encode())
+	if err != nil || *got != c {
+		t.Fatalf("round trip = %+v, %v", got, err)
+	}
+	for _, bad := range []string{"not base64!", "MTIz", "YTpi"} {
+		if _, err := DecodeRawReadingCursor(bad); err == nil {
+			t.Errorf("DecodeRawReadingCursor(%q) should fail", bad)
+		}
+	}
+}
+
+func TestParseRawReadingMetrics(t *testing.T) {
+	got, err := ParseRawReadingMetrics(" PM25, aqi,pm25,")
+	if err != nil || !reflect.DeepEqual(got, []string{"pm25", "aqi"}) {
+		t.Fatalf("got %v, %v", got, err)
+	}
+	if got, _ := ParseRawReadingMetrics(""); len(got) != len(RawReadingMetrics) {
+		t.Fatalf("empty metrics should select all, got %v", got)
+	}
+	if _, err := ParseRawReadingMetrics("pm25;drop table"); err == nil {
+		t.Fatal("unknown metric should be rejected")
+	}
+}
+
+func TestRawReadingsSQL(t *testing.T) {
+	from := time.UnixMilli(1000)
+	to := time.UnixMilli(2000)
+	query, args := rawReadingsSQL(RawReadingQuery{
+		DVIDs:    []string{"a1", "b2"},
+		Province: "จ.เชียงราย",
+		From:     from,
+		To:       to,
+		Metrics:  []string{"pm25", "aqi"},
+		After:    &RawReadingCursor{Timestamp: 1500, ID: 7},
+	}, 101)
+
+	for _, part := range []string{
+		"quality_flags, pm25, aqi",
+		"timestamp >= ? AND timestamp < ?",
+		"dvid IN ?",
+		"province = ?",
+		"(timestamp, id) > (?, ?)",
+		"quality_flags = 0",
+		"ORDER BY timestamp, id LIMIT ?",
+	} {
+		if !strings.Contains(query, part) {
+			t.Errorf("query missing %q:\n%s", part, query)
+		}
+	}
+	want := []interface{}{int64(1000), int64(2000), []string{"a1", "b2"}, "เชียงราย", int64(1500), uint(7), 101}
+	if !reflect.DeepEqual(args, want) {
+		t.Fatalf("args = %#v, want %#v", args, want)
+	}
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"yakkaw_dashboard/models"
+)
+
+const (
+	readingStreamBacklog    = 2000 // จำนวน event ล่าสุดที่เก็บไว้ให้ client ต่อด้วย Last-Event-ID
+	readingStreamBufferSize = 256  // event ที่ค้างส่งได้ต่อ client ก่อนถูกตัดการเชื่อมต่อ
+)
+
+// StreamMetrics คือ metric ที่ส่งออกทาง stream (pm25/pm10 เป็นค่าที่ calibrate แล้วถ้ามี)
+var StreamMetrics = []string{"pm25", "pm10", "aqi", "temperature", "humidity"}
+
+// ReadingEvent คือข้อมูลหนึ่งแถวที่เพิ่ง ingest เข้า sensor_data
+type ReadingEvent struct {
+	ID           uint64             `json:"id"`
+	DVID         string             `json:"dvid"`
+	Place        string             `json:"place"`
+	Address      string             `json:"address"`
+	Province     string             `json:"province"`
+	Latitude     float64            `json:"latitude"`
+	Longitude    float64            `json:"longitude"`
+	Timestamp    int64              `json:"timestamp"`
+	QualityFlags int                `json:"quality_flags"`
+	Metrics      map[string]float64 `json:"metrics"`
+}
+
+// ReadingFilter เลือก event ตามจังหวัด/สถานที่/DVID (ค่าว่าง = ทั้งหมด)
+type ReadingFilter struct {
+	Province string
+	Place    string
+	DVID     string
+}
+
+func (f ReadingFilter) matches(ev ReadingEvent) bool {
+	if f.DVID != "" && !strings.EqualFold(f.DVID, ev.DVID) {
+		return false
+	}
+	if f.Place != "" && f.Place != ev.Place {
+		return false
+	}
+	if f.Province != "" && f.Province != ev.Province {
+		return false
+	}
+	return true
+}
+
+// NewReadingFilter สร้าง filter โดยแปลงชื่อจังหวัดให้อยู่ในรูปเดียวกับ ReadingEvent.Province
+func NewReadingFilter(province, place, dvid string) ReadingFilter {
+	return ReadingFilter{
+		Province: normalizeProvince(province),
+		Place:    strings.TrimSpace(place),
+		DVID:     strings.TrimSpace(dvid),
+	}
+}
+
+// ReadingSubscription คือผู้รับ event หนึ่งราย C จะถูกปิดเมื่อ Close หรือเมื่อรับไม่ทัน (Dropped = true)
+type ReadingSubscription struct {
+	C       <-chan ReadingEvent
+	ch      chan ReadingEvent
+	filter  ReadingFilter
+	hub     *ReadingHub
+	dropped bool
+}
+
+// Dropped รายงานว่าถูกตัดเพราะ client รับไม่ทัน (ให้ client reconnect ด้วย Last-Event-ID)
+func (s *ReadingSubscription) Dropped() bool {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	return s.dropped
+}
+
+// Close ยกเลิกการรับ event
+func (s *ReadingSubscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	if _, ok := s.hub.subs[s]; ok {
+		delete(s.hub.subs, s)
+		close(s.ch)
+	}
+}
+
+// ReadingHub เป็น pub/sub ภายใน process สำหรับข้อมูลใหม่ เก็บ event ล่าสุดเป็น ring buffer
+type ReadingHub struct {
+	mu      sync.Mutex
+	nextID  uint64
+	backlog []ReadingEvent
+	start   int
+	subs    map[*ReadingSubscription]struct{}
+}
+
+// Readings คือ hub ที่ FetchAndStoreData publish เข้าไปหลังบันทึกข้อมูลสำเร็จ
+var Readings = NewReadingHub(readingStreamBacklog)
+
+// NewReadingHub สร้าง hub ใหม่ ID เริ่มจากเวลาปัจจุบัน (ms) เพื่อให้ ID เพิ่มขึ้นเสมอแม้ restart
+func NewReadingHub(backlog int) *ReadingHub {
+	return &ReadingHub{
+		nextID:  uint64(time.Now().UnixMilli()) * 1000,
+		backlog: make([]ReadingEvent, 0, backlog),
+		subs:    make(map[*ReadingSubscription]struct{}),
+	}
+}
+
+// Subscribe ลงทะเบียนผู้รับใหม่ และคืน event ใน backlog ที่ใหม่กว่า lastEventID (0 = ไม่ replay)
+func (h *ReadingHub) Subscribe(filter ReadingFilter, lastEventID uint64) (*ReadingSubscription, []ReadingEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var replay []ReadingEvent
+	if lastEventID > 0 {
+		for i := 0; i < len(h.backlog); i++ {
+			ev := h.backlog[(h.start+i)%len(h.backlog)]
+			if ev.ID > lastEventID && filter.matches(ev) {
+				replay = append(replay, ev)
+			}
+		}
+	}
+
+	ch := make(chan ReadingEvent, readingStreamBufferSize)
+	sub := &ReadingSubscription{C: ch, ch: ch, filter: filter, hub: h}
+	h.subs[sub] = struct{}{}
+	return sub, replay
+}
+
+// Publish กำหนด ID ให้ event แล้วส่งให้ผู้รับที่ตรง filter โดยไม่ block
+// ผู้รับที่ buffer เต็มจะถูกตัดออก เพื่อไม่ให้ client ที่ช้าหน่วง ingest
+func (h *ReadingHub) Publish(events []ReadingEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, ev := range events {
+		h.nextID++
+		ev.ID = h.nextID
+		if len(h.backlog) < cap(h.backlog) {
+			h.backlog = append(h.backlog, ev)
+		} else if cap(h.backlog) > 0 {
+			h.backlog[h.start] = ev
+			h.start = (h.start + 1) % cap(h.backlog)
+		}
+
+		for sub := range h.subs {
+			if !sub.filter.matches(ev) {
+				continue
+			}
+			select {
+			case sub.ch <- ev:
+			default:
+				sub.dropped = true
+				delete(h.subs, sub)
+				close(sub.ch)
+			}
+		}
+	}
+}
+
+// newReadingEvent แปลงข้อมูลที่บันทึกแล้วเป็น event ของ stream
+func newReadingEvent(data models.SensorData) ReadingEvent {
+	pm25, pm10 := float64(data.PM25), float64(data.PM10)
+	if data.PM25Calibrated != nil {
+		pm25 = *data.PM25Calibrated
+	}
+	if data.PM10Calibrated != nil {
+		pm10 = *data.PM10Calibrated
+	}
+	return ReadingEvent{
+		DVID:         data.DVID,
+		Place:        data.Place,
+		Address:      data.Address,
+		Province:     storedProvince(data.Province, data.Address),
+		Latitude:     data.Latitude,
+		Longitude:    data.Longitude,
+		Timestamp:    data.Timestamp,
+		QualityFlags: data.QualityFlags,
+		Metrics: map[string]float64{
+			"pm25":        pm25,
+			"pm10":        pm10,
+			"aqi":         float64(data.AQI),
+			"temperature": float64(data.Temperature),
+			"humidity":    float64(data.Humidity),
+		},
+	}
+}
@@ -0,0 +1,45 @@
+package services
+
+import "testing"
+
+func TestReadingHubReplayAndFilter(t *testing.T) {
+	hub := NewReadingHub(3)
+	hub.Publish([]ReadingEvent{{DVID: "A"}, {DVID: "B"}, {DVID: "A"}, {DVID: "A"}})
+
+	// backlog เก็บแค่ 3 event ล่าสุด และ replay เฉพาะที่ตรง filter และใหม่กว่า Last-Event-ID
+	all, replay := hub.Subscribe(ReadingFilter{}, 1)
+	defer all.Close()
+	if len(replay) != 3 || replay[0].DVID != "B" {
+		t.Fatalf("replay = %+v", replay)
+	}
+	onlyA, replay := hub.Subscribe(ReadingFilter{DVID: "a"}, replay[0].ID)
+	defer onlyA.Close()
+	if len(replay) != 2 {
+		t.Fatalf("filtered replay = %+v", replay)
+	}
+
+	hub.Publish([]ReadingEvent{{DVID: "B"}, {DVID: "A"}})
+	if ev := <-onlyA.C; ev.DVID != "A" {
+		t.Fatalf("filtered event = %+v", ev)
+	}
+	if first, second := <-all.C, <-all.C; second.ID != first.ID+1 {
+		t.Fatalf("ids not sequential: %d, %d", first.ID, second.ID)
+	}
+}
+
+func TestReadingHubDropsSlowSubscriber(t *testing.T) {
+	hub := NewReadingHub(10)
+	slow, _ := hub.Subscribe(ReadingFilter{}, 0)
+
+	events := make([]ReadingEvent, readingStreamBufferSize+1)
+	hub.Publish(events)
+
+	received := 0
+	for range slow.C {
+		received++
+	}
+	if received != readingStreamBufferSize || !slow.Dropped() {
+		t.Fatalf("received %d, dropped %v", received, slow.Dropped())
+	}
+	slow.Close() // ต้องปลอดภัยแม้ถูกตัดไปแล้ว
+}
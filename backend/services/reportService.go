@@ -0,0 +1,359 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"yakkaw_dashboard/config"
+	"yakkaw_dashboard/database"
+	"yakkaw_dashboard/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	reportMetric          = "pm25"
+	ReportTriggerSchedule = "schedule"
+	ReportTriggerManual   = "manual"
+)
+
+// ErrNoReportData คืนเมื่อเดือน/จังหวัดที่ขอไม่มีข้อมูลให้สร้างรายงาน
+var ErrNoReportData = errors.New("no readings for this province and month")
+
+// reportData: รายงานใช้ค่าที่ calibrate แล้วและตัดข้อมูลที่ถูก flag ออกเสมอ
+var reportData = DataOptions{}
+
+var reportSlugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+type ReportService struct {
+	DB *gorm.DB
+}
+
+// NewReportService creates a new ReportService instance
+func NewReportService(db *gorm.DB) *ReportService {
+	return &ReportService{DB: db}
+}
+
+// ParseReportMonth แปลง "YYYY-MM" เป็นวันที่ 1 ของเดือนตามเวลาไทย
+func ParseReportMonth(month string) (time.Time, error) {
+	t, err := time.ParseInLocation("2006-01", strings.TrimSpace(month), time.FixedZone("Asia/Bangkok", 7*3600))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid month (expect YYYY-MM)")
+	}
+	return t, nil
+}
+
+// List returns generated reports, newest month first, optionally filtered by province/month
+func (s *ReportService) List(province, month string) ([]models.Report, error) {
+	query := s.DB.Order("month DESC").Order("province ASC")
+	if province != "" {
+		query = query.Where("province = ?", normalizeProvince(province))
+	}
+	if month != "" {
+		query = query.Where("month = ?", month)
+	}
+	var reports []models.Report
+	if err := query.Find(&reports).Error; err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// Get returns a report and the path of its PDF file
+func (s *ReportService) Get(id uint) (models.Report, string, error) {
+	var report models.Report
+	if err := s.DB.First(&report, id).Error; err != nil {
+		return models.Report{}, "", err
+	}
+	return report, filepath.Join(config.ReportsDir(), report.FileName), nil
+}
+
+// Delete removes the report record and its PDF file
+func (s *ReportService) Delete(id uint) error {
+	report, path, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := s.DB.Unscoped().Delete(&report).Error; err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		log.Printf("report %d: failed to remove %s: %v", id, path, err)
+	}
+	return nil
+}
+
+// Generate สร้าง (หรือสร้างทับ) รายงาน PDF ของจังหวัดและเดือนที่ระบุ แล้วบันทึกไฟล์ลง REPORTS_DIR
+func (s *ReportService) Generate(province, month, trigger string) (models.Report, error) {
+	province = normalizeProvince(province)
+	if province == "" {
+		return models.Report{}, fmt.Errorf("province is required")
+	}
+	start, err := ParseReportMonth(month)
+	if err != nil {
+		return models.Report{}, err
+	}
+	if !start.Before(time.Now()) {
+		return models.Report{}, fmt.Errorf("month must not be in the future")
+	}
+
+	data, err := collectMonthlyReport(s.DB, province, start)
+	if err != nil {
+		return models.Report{}, err
+	}
+
+	var font []byte
+	if path := config.ReportFontFile(); path != "" {
+		if font, err = os.ReadFile(path); err != nil {
+			return models.Report{}, fmt.Errorf("report font: %w", err)
+		}
+	}
+	pdf, err := renderMonthlyReport(data, font)
+	if err != nil {
+		return models.Report{}, err
+	}
+
+	dir := config.ReportsDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return models.Report{}, err
+	}
+	fileName := reportFileName(province, start)
+	if err := os.WriteFile(filepath.Join(dir, fileName), pdf, 0o644); err != nil {
+		return models.Report{}, err
+	}
+
+	var report models.Report
+	err = s.DB.Where("province = ? AND month = ?", province, start.Format("2006-01")).First(&report).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Report{}, err
+	}
+	report.Province = province
+	report.Month = start.Format("2006-01")
+	report.FileName = fileName
+	report.Size = int64(len(pdf))
+	report.Trigger = trigger
+	if err := s.DB.Save(&report).Error; err != nil {
+		return models.Report{}, err
+	}
+	return report, nil
+}
+
+// reportFileName ใช้ชื่อจังหวัดภาษาอังกฤษเป็นชื่อไฟล์ (ไม่มีชื่ออังกฤษใช้ hash ของชื่อไทยแทน)
+func reportFileName(province string, month time.Time) string {
+	slug := reportSlugPattern.ReplaceAllString(strings.ToLower(provinceNameEN(province)), "-")
+	slug = strings.Trim(slug, "-")
+	if slug == "" {
+		var h uint32 = 2166136261
+		for _, b := range []byte(province) {
+			h = (h ^ uint32(b)) * 16777619
+		}
+		slug = fmt.Sprintf("province-%08x", h)
+	}
+	return fmt.Sprintf("%s-%s.pdf", slug, month.Format("2006-01"))
+}
+
+// RunScheduledReports สร้างรายงานของเดือนที่แล้วให้ทุกจังหวัดที่มีข้อมูลแต่ยังไม่มีรายงาน
+func RunScheduledReports() error {
+	now := time.Now().In(time.FixedZone("Asia/Bangkok", 7*3600))
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, -1, 0)
+	end := start.AddDate(0, 1, 0)
+	month := start.Format("2006-01")
+
+	var provinces []string
+	err := database.DB.Raw(`
+        SELECT DISTINCT province FROM sensor_data
+        WHERE timestamp >= ? AND timestamp < ?
+          AND province IS NOT NULL AND province <> ''
+          AND province NOT IN (SELECT province FROM reports WHERE month = ? AND deleted_at IS NULL)
+        ORDER BY province
+    `, start.UnixMilli(), end.UnixMilli(), month).Scan(&provinces).Error
+	if err != nil {
+		return err
+	}
+
+	svc := NewReportService(database.DB)
+	for _, province := range provinces {
+		if _, err := svc.Generate(province, month, ReportTriggerSchedule); err != nil {
+			log.Printf("report for %s %s skipped: %v", province, month, err)
+		}
+	}
+	return nil
+}
+
+// collectMonthlyReport ดึงสถิติทั้งหมดของรายงานจาก sensor_data (วันตัดตามเวลาไทย)
+func collectMonthlyReport(db *gorm.DB, province string, start time.Time) (*monthlyReport, error) {
+	end := start.AddDate(0, 1, 0)
+	days := end.AddDate(0, 0, -1).Day()
+	metric := reportData.metricExpr(reportMetric)
+	filter := "province = ? AND timestamp >= ? AND timestamp < ?" + reportData.qualityClause()
+
+	r := &monthlyReport{
+		Province:    province,
+		Month:       start,
+		GeneratedAt: time.Now().In(start.Location()),
+		Threshold:   defaultExceedanceThresholds[reportMetric],
+		DailyMean:   nanSeries(days),
+		DailyMax:    nanSeries(days),
+		Mean:        math.NaN(),
+		PrevMean:    math.NaN(),
+	}
+	for h := range r.HourlyMean {
+		r.HourlyMean[h] = math.NaN()
+	}
+
+	stationDaily := fmt.Sprintf(`
+        SELECT dvid,
+               MAX(place) AS place,
+               date_trunc('day', to_timestamp(timestamp/1000) AT TIME ZONE 'Asia/Bangkok') AS day,
+               AVG(NULLIF(%s,0)) AS day_avg,
+               COUNT(*) AS cnt
+        FROM sensor_data
+        WHERE %s
+        GROUP BY 1, 3
+    `, metric, filter)
+
+	// ค่าเฉลี่ยรายวันของจังหวัด และค่าเฉลี่ยรายวันสูงสุดของสถานีใดสถานีหนึ่ง
+	rows, err := db.Raw(fmt.Sprintf(`
+        WITH station_daily AS (%s)
+        SELECT EXTRACT(DAY FROM day)::int, AVG(day_avg), MAX(day_avg), SUM(cnt)
+        FROM station_daily
+        GROUP BY day
+    `, stationDaily), province, start.UnixMilli(), end.UnixMilli()).Rows()
+	if err != nil {
+		return nil, err
+	}
+	var sum float64
+	var n int
+	for rows.Next() {
+		var day, cnt int
+		var avg, maxVal sql.NullFloat64
+		if err := rows.Scan(&day, &avg, &maxVal, &cnt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		r.Readings += cnt
+		if day < 1 || day > days || !avg.Valid {
+			continue
+		}
+		r.DailyMean[day-1] = avg.Float64
+		r.DailyMax[day-1] = maxVal.Float64
+		sum += avg.Float64
+		n++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if r.Readings == 0 {
+		return nil, ErrNoReportData
+	}
+	if n > 0 {
+		r.Mean = sum / float64(n)
+	}
+
+	// สถิติรายสถานี
+	rows, err = db.Raw(fmt.Sprintf(`
+        WITH station_daily AS (%s)
+        SELECT dvid, MAX(place), AVG(day_avg), MAX(day_avg),
+               COUNT(*) FILTER (WHERE day_avg > ?), COUNT(day_avg)
+        FROM station_daily
+        GROUP BY dvid
+        HAVING COUNT(day_avg) > 0
+        ORDER BY 3 DESC
+    `, stationDaily), province, start.UnixMilli(), end.UnixMilli(), r.Threshold).Rows()
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var st reportStation
+		var place sql.NullString
+		if err := rows.Scan(&st.DVID, &place, &st.Mean, &st.MaxDaily, &st.ExceedanceDays, &st.Days); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		st.Name = place.String
+		r.Stations = append(r.Stations, st)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// รูปแบบรายชั่วโมงของวัน
+	rows, err = db.Raw(fmt.Sprintf(`
+        SELECT EXTRACT(HOUR FROM to_timestamp(timestamp/1000) AT TIME ZONE 'Asia/Bangkok')::int, AVG(NULLIF(%s,0))
+        FROM sensor_data
+        WHERE %s
+        GROUP BY 1
+    `, metric, filter), province, start.UnixMilli(), end.UnixMilli()).Rows()
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var hour int
+		var avg sql.NullFloat64
+		if err := rows.Scan(&hour, &avg); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if hour >= 0 && hour < 24 && avg.Valid {
+			r.HourlyMean[hour] = avg.Float64
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// ค่าเฉลี่ยเดือนก่อนหน้า (เฉลี่ยจากค่ารายวันเหมือนเดือนปัจจุบัน)
+	var prev sql.NullFloat64
+	err = db.Raw(fmt.Sprintf(`
+        SELECT AVG(day_avg) FROM (
+            SELECT date_trunc('day', to_timestamp(timestamp/1000) AT TIME ZONE 'Asia/Bangkok'), AVG(NULLIF(%s,0)) AS day_avg
+            FROM sensor_data
+            WHERE %s
+            GROUP BY 1
+        ) d
+    `, metric, filter), province, start.AddDate(0, -1, 0).UnixMilli(), start.UnixMilli()).Row().Scan(&prev)
+	if err != nil {
+		return nil, err
+	}
+	if prev.Valid {
+		r.PrevMean = prev.Float64
+	}
+
+	// อันดับเทียบกับจังหวัดอื่นในเดือนเดียวกัน
+	ranking, err := GetRangeRanking(RangeRankingOptions{
+		From: start, To: end, Metric: reportMetric, Group: "province", By: "avg", Order: "desc", DataOptions: reportData,
+	})
+	if err != nil {
+		log.Printf("report ranking for %s: %v", province, err)
+	}
+	for _, item := range ranking.Items {
+		if item.Key == province {
+			r.ProvinceRank = item.Rank
+		}
+	}
+	r.ProvinceCount = len(ranking.Items)
+
+	if r.Bands, err = GetAllColorRanges(); err != nil {
+		log.Printf("report color ranges: %v", err)
+	}
+	return r, nil
+}
+
+func nanSeries(n int) []float64 {
+	s := make([]float64, n)
+	for i := range s {
+		s[i] = math.NaN()
+	}
+	return s
+}
@@ -0,0 +1,482 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"yakkaw_dashboard/models"
+	"yakkaw_dashboard/utils"
+)
+
+// monthlyReport คือข้อมูลทั้งหมดที่ใช้วาดรายงานรายเดือนของหนึ่งจังหวัด (ค่า NaN = ไม่มีข้อมูล)
+type monthlyReport struct {
+	Province      string
+	Month         time.Time // วันที่ 1 ของเดือน เวลาไทย
+	GeneratedAt   time.Time
+	Threshold     float64
+	Bands         []models.ColorRange
+	DailyMean     []float64 // หนึ่งค่าต่อวันของเดือน
+	DailyMax      []float64
+	HourlyMean    [24]float64
+	Mean          float64
+	PrevMean      float64
+	Readings      int
+	Stations      []reportStation
+	ProvinceRank  int // 1 = ค่าเฉลี่ยสูงสุด, 0 = ไม่ทราบ
+	ProvinceCount int
+}
+
+type reportStation struct {
+	DVID           string
+	Name           string
+	Mean           float64
+	MaxDaily       float64
+	ExceedanceDays int
+	Days           int
+}
+
+const (
+	reportMargin       = 40.0
+	reportContentWidth = utils.PDFPageWidth - 2*reportMargin
+	reportMaxStations  = 15
+)
+
+// renderMonthlyReport วาดรายงานเป็น PDF สองหน้า fontData ว่าง = ใช้ Helvetica (ชื่อภาษาไทยจะแสดงเป็นภาษาอังกฤษแทน)
+func renderMonthlyReport(r *monthlyReport, fontData []byte) ([]byte, error) {
+	doc := utils.NewPDFDocument()
+	if len(fontData) > 0 {
+		if err := doc.SetTrueTypeFont(fontData); err != nil {
+			return nil, fmt.Errorf("report font: %w", err)
+		}
+	}
+	province := reportProvinceName(doc, r.Province)
+	doc.SetTitle(fmt.Sprintf("Air quality report %s %s", r.Province, r.Month.Format("2006-01")))
+
+	// หน้า 1: สรุป + ปฏิทิน
+	doc.AddPage()
+	doc.SetFillColor(0, 0, 0)
+	doc.Text(reportMargin, 62, 20, true, "Monthly Air Quality Report")
+	doc.Text(reportMargin, 86, 14, false, province+" – "+r.Month.Format("January 2006"))
+	doc.SetFillColor(110, 110, 110)
+	doc.Text(reportMargin, 102, 8, false, fmt.Sprintf("Generated %s (Asia/Bangkok). PM2.5 in µg/m³ from calibrated readings, flagged readings excluded.",
+		r.GeneratedAt.Format("2006-01-02 15:04")))
+
+	drawReportSummary(doc, r, 116)
+	drawReportCalendar(doc, r, 262)
+
+	// หน้า 2: กราฟ + อันดับสถานี
+	doc.AddPage()
+	drawDailyChart(doc, r, 50)
+	drawHourlyChart(doc, r, 300)
+	drawStationTable(doc, r, 500)
+
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// summaryFigures คำนวณค่าสรุปจากข้อมูลรายวัน
+func (r *monthlyReport) summaryFigures() (maxDay, minDay, daysWithData, exceedance int) {
+	maxDay, minDay = -1, -1
+	for i, v := range r.DailyMean {
+		if math.IsNaN(v) {
+			continue
+		}
+		daysWithData++
+		if v > r.Threshold {
+			exceedance++
+		}
+		if maxDay < 0 || v > r.DailyMean[maxDay] {
+			maxDay = i
+		}
+		if minDay < 0 || v < r.DailyMean[minDay] {
+			minDay = i
+		}
+	}
+	return maxDay, minDay, daysWithData, exceedance
+}
+
+func drawReportSummary(doc *utils.PDFDocument, r *monthlyReport, top float64) {
+	maxDay, minDay, daysWithData, exceedance := r.summaryFigures()
+	dayLabel := func(i int) string {
+		if i < 0 {
+			return ""
+		}
+		return r.Month.AddDate(0, 0, i).Format("Mon 2 Jan")
+	}
+	dayValue := func(i int) string {
+		if i < 0 {
+			return "n/a"
+		}
+		return formatReportValue(r.DailyMean[i])
+	}
+
+	change, changeNote := "n/a", "no data for previous month"
+	if !math.IsNaN(r.PrevMean) && !math.IsNaN(r.Mean) {
+		diff := r.Mean - r.PrevMean
+		change = fmt.Sprintf("%+.1f", diff)
+		changeNote = fmt.Sprintf("previous month %s", formatReportValue(r.PrevMean))
+		if r.PrevMean > 0 {
+			change += fmt.Sprintf(" (%+.0f%%)", diff/r.PrevMean*100)
+		}
+	}
+	rank, rankNote := "n/a", ""
+	if r.ProvinceRank > 0 {
+		rank = fmt.Sprintf("%d of %d", r.ProvinceRank, r.ProvinceCount)
+		rankNote = "1 = highest monthly mean"
+	}
+
+	boxes := []struct{ label, value, note string }{
+		{"Monthly mean PM2.5", formatReportValue(r.Mean), "µg/m³"},
+		{"Change vs previous month", change, changeNote},
+		{"Highest daily mean", dayValue(maxDay), dayLabel(maxDay)},
+		{"Lowest daily mean", dayValue(minDay), dayLabel(minDay)},
+		{"Exceedance days", fmt.Sprintf("%d", exceedance), fmt.Sprintf("daily mean > %s µg/m³", formatReportValue(r.Threshold))},
+		{"Days with data", fmt.Sprintf("%d / %d", daysWithData, len(r.DailyMean)), ""},
+		{"Stations / readings", fmt.Sprintf("%d / %d", len(r.Stations), r.Readings), ""},
+		{"Rank among provinces", rank, rankNote},
+	}
+
+	const cols, boxHeight, gap = 4, 62.0, 8.0
+	boxWidth := (reportContentWidth - gap*(cols-1)) / cols
+	doc.SetLineWidth(0.5)
+	for i, b := range boxes {
+		x := reportMargin + float64(i%cols)*(boxWidth+gap)
+		y := top + float64(i/cols)*(boxHeight+gap)
+		doc.SetFillColor(246, 247, 249)
+		doc.SetStrokeColor(210, 214, 220)
+		doc.Rect(x, y, boxWidth, boxHeight, true, true)
+		doc.SetFillColor(90, 90, 90)
+		doc.Text(x+8, y+15, 8, false, b.label)
+		doc.SetFillColor(0, 0, 0)
+		doc.Text(x+8, y+36, 16, true, b.value)
+		doc.SetFillColor(110, 110, 110)
+		doc.Text(x+8, y+52, 7.5, false, b.note)
+	}
+}
+
+func drawReportCalendar(doc *utils.PDFDocument, r *monthlyReport, top float64) {
+	doc.SetFillColor(0, 0, 0)
+	doc.Text(reportMargin, top, 12, true, "Daily mean PM2.5 calendar")
+
+	const headerHeight, cellHeight = 16.0, 44.0
+	cellWidth := reportContentWidth / 7
+	y0 := top + 10
+	for i, name := range []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"} {
+		doc.SetFillColor(90, 90, 90)
+		doc.TextCenter(reportMargin+(float64(i)+0.5)*cellWidth, y0+11, 8, true, name)
+	}
+
+	offset := (int(r.Month.Weekday()) + 6) % 7 // วันจันทร์เป็นคอลัมน์แรก
+	doc.SetLineWidth(1)
+	doc.SetStrokeColor(255, 255, 255)
+	rows := 0
+	for day, v := range r.DailyMean {
+		cell := offset + day
+		col, row := cell%7, cell/7
+		rows = row + 1
+		x := reportMargin + float64(col)*cellWidth
+		y := y0 + headerHeight + float64(row)*cellHeight
+
+		textR, textG, textB := uint8(60), uint8(60), uint8(60)
+		if math.IsNaN(v) {
+			doc.SetFillColor(238, 238, 238)
+		} else {
+			c := reportBandColor(r.Bands, v)
+			doc.SetFillColor(c[0], c[1], c[2])
+			if luminance(c) < 0.5 {
+				textR, textG, textB = 255, 255, 255
+			} else {
+				textR, textG, textB = 0, 0, 0
+			}
+		}
+		doc.Rect(x, y, cellWidth, cellHeight, true, true)
+		doc.SetFillColor(textR, textG, textB)
+		doc.Text(x+4, y+11, 8, false, fmt.Sprintf("%d", day+1))
+		if !math.IsNaN(v) {
+			doc.TextCenter(x+cellWidth/2, y+31, 12, true, formatReportValue(v))
+		}
+	}
+
+	drawBandLegend(doc, r.Bands, y0+headerHeight+float64(rows)*cellHeight+18)
+}
+
+func drawBandLegend(doc *utils.PDFDocument, bands []models.ColorRange, y float64) {
+	if len(bands) == 0 {
+		return
+	}
+	x := reportMargin
+	doc.SetFillColor(90, 90, 90)
+	doc.Text(x, y, 8, true, "Colour bands (µg/m³):")
+	x += doc.TextWidth("Colour bands (µg/m³):", 8, true) + 8
+	for _, b := range bands {
+		label := fmt.Sprintf("%d–%d", b.Min, b.Max)
+		c := parseHexColor(b.Color, 255)
+		doc.SetFillColor(c.R, c.G, c.B)
+		doc.Rect(x, y-8, 14, 10, true, false)
+		doc.SetFillColor(60, 60, 60)
+		doc.Text(x+18, y, 8, false, label)
+		x += 18 + doc.TextWidth(label, 8, false) + 12
+	}
+}
+
+// drawDailyChart วาดกราฟเส้นค่าเฉลี่ยและค่าสูงสุดรายวัน พร้อมเส้นเกณฑ์
+func drawDailyChart(doc *utils.PDFDocument, r *monthlyReport, top float64) {
+	doc.SetFillColor(0, 0, 0)
+	doc.Text(reportMargin, top, 12, true, "Daily PM2.5")
+
+	maxVal := r.Threshold
+	for _, series := range [][]float64{r.DailyMean, r.DailyMax} {
+		for _, v := range series {
+			if !math.IsNaN(v) && v > maxVal {
+				maxVal = v
+			}
+		}
+	}
+	chartX, chartY := reportMargin+30, top+16
+	chartW, chartH := reportContentWidth-30, 190.0
+	_, yMax := drawValueAxis(doc, chartX, chartY, chartW, chartH, maxVal)
+
+	n := len(r.DailyMean)
+	xAt := func(i int) float64 {
+		if n <= 1 {
+			return chartX + chartW/2
+		}
+		return chartX + float64(i)*chartW/float64(n-1)
+	}
+	yAt := func(v float64) float64 { return chartY + chartH - v/yMax*chartH }
+
+	// แกน x: แสดงวันที่ทุก 5 วัน
+	doc.SetFillColor(90, 90, 90)
+	for i := 0; i < n; i++ {
+		if i == 0 || (i+1)%5 == 0 {
+			doc.TextCenter(xAt(i), chartY+chartH+12, 7.5, false, fmt.Sprintf("%d", i+1))
+		}
+	}
+
+	doc.SetStrokeColor(220, 50, 50)
+	doc.SetLineWidth(1)
+	doc.SetDash(4, 3)
+	doc.Line(chartX, yAt(r.Threshold), chartX+chartW, yAt(r.Threshold))
+	doc.SetDash()
+
+	drawSeries := func(values []float64, width float64) {
+		doc.SetLineWidth(width)
+		var run [][2]float64
+		for i, v := range values {
+			if math.IsNaN(v) {
+				doc.Polyline(run)
+				run = nil
+				continue
+			}
+			run = append(run, [2]float64{xAt(i), yAt(v)})
+		}
+		doc.Polyline(run)
+	}
+	doc.SetStrokeColor(160, 160, 160)
+	drawSeries(r.DailyMax, 0.8)
+	doc.SetStrokeColor(30, 90, 200)
+	drawSeries(r.DailyMean, 1.8)
+
+	legendY := chartY + chartH + 28
+	legend := []struct {
+		r, g, b uint8
+		label   string
+	}{
+		{30, 90, 200, "Daily mean"},
+		{160, 160, 160, "Highest station daily mean"},
+		{220, 50, 50, fmt.Sprintf("Threshold %s µg/m³", formatReportValue(r.Threshold))},
+	}
+	x := chartX
+	for _, l := range legend {
+		doc.SetFillColor(l.r, l.g, l.b)
+		doc.Rect(x, legendY-6, 14, 3, true, false)
+		doc.SetFillColor(60, 60, 60)
+		doc.Text(x+18, legendY, 8, false, l.label)
+		x += 18 + doc.TextWidth(l.label, 8, false) + 16
+	}
+}
+
+// drawHourlyChart วาดกราฟแท่งค่าเฉลี่ยตามชั่วโมงของวัน สีตาม ColorRange
+func drawHourlyChart(doc *utils.PDFDocument, r *monthlyReport, top float64) {
+	doc.SetFillColor(0, 0, 0)
+	doc.Text(reportMargin, top, 12, true, "Average PM2.5 by hour of day")
+
+	maxVal := 0.0
+	for _, v := range r.HourlyMean {
+		if !math.IsNaN(v) && v > maxVal {
+			maxVal = v
+		}
+	}
+	chartX, chartY := reportMargin+30, top+16
+	chartW, chartH := reportContentWidth-30, 140.0
+	_, yMax := drawValueAxis(doc, chartX, chartY, chartW, chartH, maxVal)
+
+	slot := chartW / 24
+	for h, v := range r.HourlyMean {
+		x := chartX + float64(h)*slot
+		if !math.IsNaN(v) && v > 0 {
+			c := reportBandColor(r.Bands, v)
+			doc.SetFillColor(c[0], c[1], c[2])
+			barH := v / yMax * chartH
+			doc.Rect(x+slot*0.15, chartY+chartH-barH, slot*0.7, barH, true, false)
+		}
+		if h%3 == 0 {
+			doc.SetFillColor(90, 90, 90)
+			doc.TextCenter(x+slot/2, chartY+chartH+12, 7.5, false, fmt.Sprintf("%02d:00", h))
+		}
+	}
+}
+
+// drawValueAxis วาดกรอบ เส้นกริด และตัวเลขแกน y คืนระยะห่างของ tick และค่าสูงสุดของแกน
+func drawValueAxis(doc *utils.PDFDocument, x, y, w, h, maxVal float64) (float64, float64) {
+	step := niceStep(maxVal, 5)
+	yMax := math.Ceil(maxVal/step) * step
+	if yMax <= 0 {
+		yMax = step
+	}
+	doc.SetLineWidth(0.4)
+	for v := 0.0; v <= yMax+step/2; v += step {
+		ly := y + h - v/yMax*h
+		doc.SetStrokeColor(225, 225, 225)
+		doc.Line(x, ly, x+w, ly)
+		doc.SetFillColor(90, 90, 90)
+		doc.TextRight(x-4, ly+3, 7.5, false, formatReportValue(v))
+	}
+	doc.SetStrokeColor(120, 120, 120)
+	doc.Line(x, y+h, x+w, y+h)
+	doc.Line(x, y, x, y+h)
+	return step, yMax
+}
+
+func drawStationTable(doc *utils.PDFDocument, r *monthlyReport, top float64) {
+	doc.SetFillColor(0, 0, 0)
+	doc.Text(reportMargin, top, 12, true, "Station ranking (highest monthly mean first)")
+
+	type column struct {
+		title string
+		width float64
+		right bool
+	}
+	columns := []column{
+		{"#", 24, true}, {"Station", 215, false}, {"Device", 70, false},
+		{"Mean", 50, true}, {"Max daily", 55, true}, {"Exceed. days", 60, true}, {"Days", 41, true},
+	}
+	const rowHeight = 15.0
+	y := top + 10
+	doc.SetFillColor(235, 238, 242)
+	doc.Rect(reportMargin, y, reportContentWidth, rowHeight, true, false)
+	cell := func(x float64, c column, bold bool, s string) {
+		if c.right {
+			doc.TextRight(x+c.width-4, y+10.5, 8, bold, s)
+		} else {
+			doc.Text(x+4, y+10.5, 8, bold, s)
+		}
+	}
+	x := reportMargin
+	doc.SetFillColor(40, 40, 40)
+	for _, c := range columns {
+		cell(x, c, true, c.title)
+		x += c.width
+	}
+
+	stations := r.Stations
+	if len(stations) > reportMaxStations {
+		stations = stations[:reportMaxStations]
+	}
+	for i, s := range stations {
+		y += rowHeight
+		if i%2 == 1 {
+			doc.SetFillColor(248, 249, 250)
+			doc.Rect(reportMargin, y, reportContentWidth, rowHeight, true, false)
+		}
+		name := s.Name
+		if name == "" || !doc.CanRender(name) {
+			name = s.DVID
+		}
+		name = truncateToWidth(doc, name, columns[1].width-8, 8)
+		values := []string{
+			fmt.Sprintf("%d", i+1), name, s.DVID, formatReportValue(s.Mean), formatReportValue(s.MaxDaily),
+			fmt.Sprintf("%d", s.ExceedanceDays), fmt.Sprintf("%d", s.Days),
+		}
+		x := reportMargin
+		doc.SetFillColor(20, 20, 20)
+		for j, c := range columns {
+			cell(x, c, false, values[j])
+			x += c.width
+		}
+	}
+	if len(stations) == 0 {
+		y += rowHeight
+		doc.SetFillColor(110, 110, 110)
+		doc.Text(reportMargin+4, y+10.5, 8, false, "No station data for this month.")
+	}
+	if extra := len(r.Stations) - len(stations); extra > 0 {
+		doc.SetFillColor(110, 110, 110)
+		doc.Text(reportMargin+4, y+rowHeight+10.5, 8, false, fmt.Sprintf("… and %d more stations", extra))
+	}
+}
+
+// niceStep หาระยะ tick แบบ 1/2/5 × 10^n ให้ได้ราว ticks ช่อง
+func niceStep(maxVal float64, ticks int) float64 {
+	if maxVal <= 0 || math.IsNaN(maxVal) {
+		return 10
+	}
+	raw := maxVal / float64(ticks)
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, m := range []float64{1, 2, 5, 10} {
+		if raw <= m*mag {
+			return m * mag
+		}
+	}
+	return 10 * mag
+}
+
+func formatReportValue(v float64) string {
+	if math.IsNaN(v) {
+		return "n/a"
+	}
+	if v == math.Trunc(v) && math.Abs(v) < 1e6 {
+		return fmt.Sprintf("%.0f", v)
+	}
+	return fmt.Sprintf("%.1f", v)
+}
+
+func reportBandColor(bands []models.ColorRange, v float64) [3]uint8 {
+	if len(bands) == 0 {
+		return [3]uint8{150, 170, 200}
+	}
+	c := parseHexColor(bands[colorBandIndex(bands, v)].Color, 255)
+	return [3]uint8{c.R, c.G, c.B}
+}
+
+func luminance(c [3]uint8) float64 {
+	return (0.299*float64(c[0]) + 0.587*float64(c[1]) + 0.114*float64(c[2])) / 255
+}
+
+// reportProvinceName ใช้ชื่อไทยเมื่อฟอนต์แสดงได้ ไม่เช่นนั้นใช้ชื่อภาษาอังกฤษจาก alias
+func reportProvinceName(doc *utils.PDFDocument, province string) string {
+	if doc.CanRender(province) {
+		return province
+	}
+	if en := provinceNameEN(province); en != "" {
+		return en
+	}
+	return province
+}
+
+func truncateToWidth(doc *utils.PDFDocument, s string, width, size float64) string {
+	if doc.TextWidth(s, size, false) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && doc.TextWidth(string(runes)+"…", size, false) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimSpace(string(runes)) + "…"
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"yakkaw_dashboard/models"
+)
+
+func sampleMonthlyReport() *monthlyReport {
+	month, _ := ParseReportMonth("2024-02")
+	r := &monthlyReport{
+		Province:    "เชียงราย",
+		Month:       month,
+		GeneratedAt: month.AddDate(0, 1, 0),
+		Threshold:   37.5,
+		Bands: []models.ColorRange{
+			{Min: 0, Max: 25, Color: "#00e400"},
+			{Min: 25, Max: 37, Color: "#ffff00"},
+			{Min: 37, Max: 75, Color: "#ff7e00"},
+			{Min: 75, Max: 1000, Color: "#ff0000"},
+		},
+		DailyMean:     nanSeries(29),
+		DailyMax:      nanSeries(29),
+		Mean:          52.3,
+		PrevMean:      40,
+		Readings:      12345,
+		ProvinceRank:  2,
+		ProvinceCount: 17,
+	}
+	for i := 0; i < 27; i++ { // สองวันท้ายไม่มีข้อมูล
+		r.DailyMean[i] = 20 + float64(i*3)
+		r.DailyMax[i] = 30 + float64(i*4)
+	}
+	for h := range r.HourlyMean {
+		r.HourlyMean[h] = 40 + 10*math.Sin(float64(h)/24*2*math.Pi)
+	}
+	for i := 0; i < 20; i++ {
+		r.Stations = append(r.Stations, reportStation{DVID: "ST" + string(rune('A'+i)), Name: "สถานีตรวจวัดคุณภาพอากาศที่มีชื่อยาวมาก", Mean: 60 - float64(i), MaxDaily: 90, ExceedanceDays: 20 - i, Days: 27})
+	}
+	return r
+}
+
+func TestRenderMonthlyReport(t *testing.T) {
+	r := sampleMonthlyReport()
+	if maxDay, minDay, days, exceed := r.summaryFigures(); maxDay != 26 || minDay != 0 || days != 27 || exceed != 21 {
+		t.Fatalf("summaryFigures = %d %d %d %d", maxDay, minDay, days, exceed)
+	}
+
+	pdf, err := renderMonthlyReport(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF-")) || !bytes.Contains(pdf, []byte("/Count 2")) {
+		t.Fatal("expected a two page PDF")
+	}
+
+	// ไม่มีข้อมูลเลยก็ต้องวาดได้ (แสดง n/a)
+	empty := &monthlyReport{Province: "Laos", Month: r.Month, DailyMean: nanSeries(29), DailyMax: nanSeries(29), Mean: math.NaN(), PrevMean: math.NaN()}
+	for h := range empty.HourlyMean {
+		empty.HourlyMean[h] = math.NaN()
+	}
+	if _, err := renderMonthlyReport(empty, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if font, err := os.ReadFile("/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf"); err == nil {
+		if pdf, err := renderMonthlyReport(r, font); err != nil || !bytes.Contains(pdf, []byte("/FontFile2")) {
+			t.Fatalf("render with embedded font: %v", err)
+		}
+	}
+	if _, err := renderMonthlyReport(r, []byte("bad font")); err == nil {
+		t.Error("expected error for invalid font")
+	}
+}
+
+func TestParseReportMonth(t *testing.T) {
+	got, err := ParseReportMonth("2024-03")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 2, 29, 17, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("ParseReportMonth = %v, want %v", got, want)
+	}
+	for _, bad := range []string{"", "2024-13", "2024/03", "March"} {
+		if _, err := ParseReportMonth(bad); err == nil {
+			t.Errorf("ParseReportMonth(%q) should fail", bad)
+		}
+	}
+}
+
+func TestReportNaming(t *testing.T) {
+	month, _ := ParseReportMonth("2024-03")
+	cases := map[string]string{
+		"เชียงราย":      "chiang-rai-2024-03.pdf",
+		"กรุงเทพมหานคร": "bangkok-2024-03.pdf",
+		"Laos": "laos-2024-03.pdf",
+	}
+	for province, want := range cases {
+		if got := reportFileName(province, month); got != want {
+			t.Errorf("reportFileName(%q) = %q, want %q", province, got, want)
+		}
+	}
+	if got := reportFileName("ไม่มีจังหวัดนี้", month); got == "-2024-03.pdf" || !bytes.HasPrefix([]byte(got), []byte("province-")) {
+		t.Errorf("fallback file name = %q", got)
+	}
+	if got := provinceNameEN("เชียงราย"); got != "Chiang Rai" {
+		t.Errorf("provinceNameEN = %q", got)
+	}
+}
+
+func TestNiceStep(t *testing.T) {
+	cases := []struct {
+		max  float64
+		want float64
+	}{{0, 10}, {math.NaN(), 10}, {37, 10}, {90, 20}, {230, 50}, {4, 1}}
+	for _, c := range cases {
+		if got := niceStep(c.max, 5); got != c.want {
+			t.Errorf("niceStep(%v) = %v, want %v", c.max, got, c.want)
+		}
+	}
+}
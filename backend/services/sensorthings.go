@@ -0,0 +1,619 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SensorThings API v1.1 แบบอ่านอย่างเดียว (OGC 18-088) โดยแปลงทะเบียน devices และ sensor_data เป็น entity:
+// Thing/Location/HistoricalLocation/FeatureOfInterest/Sensor = อุปกรณ์หนึ่งเครื่อง (id เดียวกับ devices.id)
+// ObservedProperty = parameter, Datastream = อุปกรณ์ × parameter, Observation = ค่าหนึ่ง parameter ของหนึ่งแถว
+const (
+	STADefaultTop = 100
+	STAMaxTop     = 1000
+
+	staObservationType = "http://www.opengis.net/def/observationType/OGC-OM/2.0/OM_Measurement"
+)
+
+// ErrSTAInvalidRequest คืนเมื่อ path หรือ query option ไม่ถูกต้องหรือยังไม่รองรับ
+var ErrSTAInvalidRequest = errors.New("invalid request")
+
+// staEntitySets เรียงตามลำดับใน service root
+var staEntitySets = []string{
+	"Things", "Locations", "HistoricalLocations", "Datastreams",
+	"Sensors", "ObservedProperties", "Observations", "FeaturesOfInterest",
+}
+
+// staNavigation: entity set → navigation property → entity set ปลายทาง (ชื่อเอกพจน์ = entity เดียว)
+var staNavigation = map[string]map[string]string{
+	"Things":              {"Locations": "Locations", "HistoricalLocations": "HistoricalLocations", "Datastreams": "Datastreams"},
+	"Locations":           {"Things": "Things", "HistoricalLocations": "HistoricalLocations"},
+	"HistoricalLocations": {"Thing": "Things", "Locations": "Locations"},
+	"Datastreams":         {"Thing": "Things", "Sensor": "Sensors", "ObservedProperty": "ObservedProperties", "Observations": "Observations"},
+	"Sensors":             {"Datastreams": "Datastreams"},
+	"ObservedProperties":  {"Datastreams": "Datastreams"},
+	"Observations":        {"Datastream": "Datastreams", "FeatureOfInterest": "FeaturesOfInterest"},
+	"FeaturesOfInterest":  {"Observations": "Observations"},
+}
+
+func staIsEntitySet(name string) bool {
+	_, ok := staNavigation[name]
+	return ok
+}
+
+// staFilter คือความสัมพันธ์ที่ใช้เลือก entity: ค่าศูนย์ = ไม่กรอง
+type staFilter struct {
+	DeviceID uint
+	Key      int
+	RowID    uint
+}
+
+// project เก็บเฉพาะ field ที่ entity set ปลายทางใช้ระบุตัวตน
+func (f staFilter) project(set string) staFilter {
+	switch set {
+	case "ObservedProperties":
+		return staFilter{Key: f.Key}
+	case "Datastreams", "Observations":
+		return staFilter{DeviceID: f.DeviceID, Key: f.Key}
+	default:
+		return staFilter{DeviceID: f.DeviceID}
+	}
+}
+
+type staSegment struct {
+	Name  string
+	ID    uint64
+	HasID bool
+}
+
+// parseSTAPath รองรับ Set, Set(id) และ Set(id)/Navigation
+func parseSTAPath(p string) ([]staSegment, error) {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return nil, nil
+	}
+	parts := strings.Split(p, "/")
+	if len(parts) > 2 {
+		return nil, fmt.Errorf("%w: only Set, Set(id) and Set(id)/Navigation paths are supported", ErrSTAInvalidRequest)
+	}
+
+	segments := make([]staSegment, 0, len(parts))
+	for _, part := range parts {
+		name, rest, hasID := strings.Cut(part, "(")
+		seg := staSegment{Name: name, HasID: hasID}
+		if hasID {
+			raw, ok := strings.CutSuffix(rest, ")")
+			id, err := strconv.ParseUint(strings.Trim(raw, "'"), 10, 64)
+			if !ok || err != nil {
+				return nil, fmt.Errorf("%w: invalid id in %q", ErrSTAInvalidRequest, part)
+			}
+			seg.ID = id
+		}
+		segments = append(segments, seg)
+	}
+
+	if !staIsEntitySet(segments[0].Name) {
+		return nil, fmt.Errorf("%w: unknown entity set %q", ErrSTAInvalidRequest, segments[0].Name)
+	}
+	if len(segments) == 2 {
+		if !segments[0].HasID || segments[1].HasID {
+			return nil, fmt.Errorf("%w: navigation needs the form Set(id)/Navigation", ErrSTAInvalidRequest)
+		}
+		if _, ok := staNavigation[segments[0].Name][segments[1].Name]; !ok {
+			return nil, fmt.Errorf("%w: %s has no navigation property %q", ErrSTAInvalidRequest, segments[0].Name, segments[1].Name)
+		}
+	}
+	return segments, nil
+}
+
+type staExpand struct {
+	Name string
+	Top  int // -1 = STADefaultTop
+}
+
+// staQuery คือ query option ที่รองรับ: $top $skip $count $expand และสำหรับ Observations $filter/$orderby ตามเวลา
+// และ $skiptoken (ตำแหน่งถัดไปจาก @iot.nextLink)
+type staQuery struct {
+	Top       int
+	Skip      int
+	SkipToken *RawReadingCursor
+	Count     bool
+	Expand    []staExpand
+	Ascending bool
+	OrderBy   bool // ส่ง $orderby ตามเวลามา (ใช้ได้เฉพาะ Observations)
+	Conds     []openDataTimeCond
+}
+
+func parseSTAQuery(params url.Values) (staQuery, error) {
+	q := staQuery{Top: STADefaultTop}
+	var err error
+	if v := params.Get("$top"); v != "" {
+		if q.Top, err = strconv.Atoi(v); err != nil || q.Top < 0 {
+			return q, fmt.Errorf("%w: $top must be a non-negative integer", ErrSTAInvalidRequest)
+		}
+		if q.Top > STAMaxTop {
+			q.Top = STAMaxTop
+		}
+	}
+	if v := params.Get("$skip"); v != "" {
+		if q.Skip, err = strconv.Atoi(v); err != nil || q.Skip < 0 {
+			return q, fmt.Errorf("%w: $skip must be a non-negative integer", ErrSTAInvalidRequest)
+		}
+		if q.Skip > OpenDataMaxOffset {
+			return q, fmt.Errorf("%w: $skip must be at most %d, follow @iot.nextLink or narrow $filter instead", ErrSTAInvalidRequest, OpenDataMaxOffset)
+		}
+	}
+	if v := params.Get("$skiptoken"); v != "" {
+		if q.SkipToken, err = DecodeRawReadingCursor(v); err != nil {
+			return q, fmt.Errorf("%w: invalid $skiptoken", ErrSTAInvalidRequest)
+		}
+		if q.Skip > 0 {
+			return q, fmt.Errorf("%w: $skip cannot be combined with $skiptoken", ErrSTAInvalidRequest)
+		}
+	}
+	switch params.Get("$count") {
+	case "", "false":
+	case "true":
+		q.Count = true
+	default:
+		return q, fmt.Errorf("%w: $count must be true or false", ErrSTAInvalidRequest)
+	}
+	if q.Expand, err = parseSTAExpand(params.Get("$expand")); err != nil {
+		return q, err
+	}
+	if v := strings.TrimSpace(params.Get("$orderby")); v != "" {
+		fields := strings.Fields(v)
+		dir := "asc"
+		if len(fields) == 2 {
+			dir = strings.ToLower(fields[1])
+		}
+		switch {
+		case len(fields) > 2 || (dir != "asc" && dir != "desc"):
+			return q, fmt.Errorf("%w: invalid $orderby", ErrSTAInvalidRequest)
+		case fields[0] == "phenomenonTime" || fields[0] == "resultTime":
+			q.OrderBy, q.Ascending = true, dir == "asc"
+		case (fields[0] == "@iot.id" || fields[0] == "id") && dir == "asc":
+			// ลำดับปกติของทุก collection ที่ไม่ใช่ Observations
+		default:
+			return q, fmt.Errorf("%w: $orderby supports phenomenonTime/resultTime on Observations and @iot.id asc", ErrSTAInvalidRequest)
+		}
+	}
+	if q.Conds, err = parseSTAFilter(params.Get("$filter")); err != nil {
+		return q, err
+	}
+	return q, nil
+}
+
+// parseSTAExpand อ่าน $expand=Locations,Datastreams($top=5) (ชั้นเดียว option ที่รองรับคือ $top)
+func parseSTAExpand(raw string) ([]staExpand, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var items []string
+	depth, start := 0, 0
+	for i, r := range raw {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				items = append(items, raw[start:i])
+				start = i + 1
+			}
+		}
+	}
+	items = append(items, raw[start:])
+
+	expands := make([]staExpand, 0, len(items))
+	for _, item := range items {
+		name, opts, hasOpts := strings.Cut(strings.TrimSpace(item), "(")
+		e := staExpand{Name: name, Top: -1}
+		if hasOpts {
+			opts, ok := strings.CutSuffix(opts, ")")
+			top, isTop := strings.CutPrefix(opts, "$top=")
+			n, err := strconv.Atoi(top)
+			if !ok || !isTop || err != nil || n < 0 {
+				return nil, fmt.Errorf("%w: only $top is supported inside $expand", ErrSTAInvalidRequest)
+			}
+			e.Top = min(n, STAMaxTop)
+		}
+		if strings.Contains(e.Name, "/") || e.Name == "" {
+			return nil, fmt.Errorf("%w: nested $expand is not supported", ErrSTAInvalidRequest)
+		}
+		expands = append(expands, e)
+	}
+	return expands, nil
+}
+
+var (
+	staAndSplit = regexp.MustCompile(`(?i)\s+and\s+`)
+	staFilterOp = map[string]string{"eq": "=", "gt": ">", "ge": ">=", "lt": "<", "le": "<="}
+)
+
+// parseSTAFilter รองรับเฉพาะการเทียบเวลาที่เชื่อมด้วย and เช่น phenomenonTime ge 2024-01-01T00:00:00Z
+func parseSTAFilter(raw string) ([]openDataTimeCond, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	var conds []openDataTimeCond
+	for _, clause := range staAndSplit.Split(raw, -1) {
+		fields := strings.Fields(strings.Trim(strings.TrimSpace(clause), "()"))
+		if len(fields) != 3 || (fields[0] != "phenomenonTime" && fields[0] != "resultTime") {
+			return nil, fmt.Errorf("%w: $filter supports phenomenonTime/resultTime comparisons joined by and", ErrSTAInvalidRequest)
+		}
+		op, ok := staFilterOp[strings.ToLower(fields[1])]
+		if !ok {
+			return nil, fmt.Errorf("%w: unsupported $filter operator %q", ErrSTAInvalidRequest, fields[1])
+		}
+		t, err := time.Parse(time.RFC3339Nano, strings.Trim(fields[2], "'"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: $filter time must be ISO 8601 (e.g. 2024-01-01T00:00:00Z)", ErrSTAInvalidRequest)
+		}
+		conds = append(conds, openDataTimeCond{Op: op, Time: t})
+	}
+	return conds, nil
+}
+
+// staRecord คือ entity ที่สร้าง JSON แล้ว พร้อมความสัมพันธ์สำหรับ navigation/$expand
+type staRecord struct {
+	filter staFilter
+	body   map[string]interface{}
+	cursor RawReadingCursor // เฉพาะ Observations: ตำแหน่งสำหรับ $skiptoken
+}
+
+type staResolver struct {
+	base     string
+	stations []openDataStation
+	loaded   bool
+}
+
+// SensorThings ตอบ request ของ SensorThings API ที่ resourcePath (ต่อจาก base เช่น "Things(1)/Datastreams")
+// คืน ErrOpenDataNotFound เมื่อไม่พบ entity และ ErrSTAInvalidRequest เมื่อ request ไม่รองรับ
+func SensorThings(base, resourcePath string, params url.Values) (interface{}, error) {
+	segments, err := parseSTAPath(resourcePath)
+	if err != nil {
+		return nil, err
+	}
+	r := &staResolver{base: strings.TrimSuffix(base, "/")}
+	if len(segments) == 0 {
+		return r.serviceRoot(), nil
+	}
+	q, err := parseSTAQuery(params)
+	if err != nil {
+		return nil, err
+	}
+
+	set := segments[0].Name
+	if !segments[0].HasID {
+		return r.collection(set, staFilter{}, q, resourcePath, params)
+	}
+	record, err := r.entity(set, segments[0].ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) == 1 {
+		records := []staRecord{record}
+		if err := r.expand(set, records, q.Expand); err != nil {
+			return nil, err
+		}
+		return record.body, nil
+	}
+
+	nav := segments[1].Name
+	target := staNavigation[set][nav]
+	if staIsEntitySet(nav) {
+		return r.collection(target, record.filter.project(target), q, resourcePath, params)
+	}
+	records, _, _, err := r.list(target, record.filter.project(target), staQuery{Top: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, ErrOpenDataNotFound
+	}
+	if err := r.expand(target, records, q.Expand); err != nil {
+		return nil, err
+	}
+	return records[0].body, nil
+}
+
+func (r *staResolver) serviceRoot() map[string]interface{} {
+	sets := make([]map[string]string, 0, len(staEntitySets))
+	for _, name := range staEntitySets {
+		sets = append(sets, map[string]string{"name": name, "url": r.base + "/" + name})
+	}
+	return map[string]interface{}{
+		"value": sets,
+		"serverSettings": map[string]interface{}{
+			"conformance": []string{
+				"http://www.opengis.net/spec/iot_sensing/1.1/req/datamodel",
+				"http://www.opengis.net/spec/iot_sensing/1.1/req/resource-path/resource-path-to-entities",
+				"http://www.opengis.net/spec/iot_sensing/1.1/req/request-data",
+			},
+		},
+	}
+}
+
+func (r *staResolver) collection(set string, f staFilter, q staQuery, resourcePath string, params url.Values) (map[string]interface{}, error) {
+	if set != "Observations" && (q.OrderBy || len(q.Conds) > 0 || q.SkipToken != nil) {
+		return nil, fmt.Errorf("%w: $filter, $orderby by time and $skiptoken are only supported on Observations", ErrSTAInvalidRequest)
+	}
+	records, total, more, err := r.list(set, f, q)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.expand(set, records, q.Expand); err != nil {
+		return nil, err
+	}
+
+	values := make([]map[string]interface{}, 0, len(records))
+	for _, rec := range records {
+		values = append(values, rec.body)
+	}
+	response := map[string]interface{}{"value": values}
+	if q.Count {
+		if total < 0 {
+			if total, err = countOpenDataObservations(r.observationQuery(f, q)); err != nil {
+				return nil, err
+			}
+		}
+		response["@iot.count"] = total
+	}
+	if more {
+		next := url.Values{}
+		for k, v := range params {
+			next[k] = v
+		}
+		next.Set("$top", strconv.Itoa(q.Top))
+		if set == "Observations" && len(records) > 0 {
+			// Observations ใช้ keyset ต่อจากรายการสุดท้าย แทน OFFSET ที่ช้าลงตามความลึก
+			next.Del("$skip")
+			next.Set("$skiptoken", records[len(records)-1].cursor.Encode())
+		} else {
+			next.Set("$skip", strconv.Itoa(q.Skip+q.Top))
+		}
+		response["@iot.nextLink"] = r.base + "/" + strings.Trim(resourcePath, "/") + "?" + next.Encode()
+	}
+	return response, nil
+}
+
+// entity โหลด entity เดียวตาม id ของ entity set นั้น
+func (r *staResolver) entity(set string, id uint64) (staRecord, error) {
+	if id == 0 {
+		return staRecord{}, ErrOpenDataNotFound
+	}
+	var f staFilter
+	switch set {
+	case "ObservedProperties":
+		f.Key = int(id)
+	case "Datastreams", "Observations":
+		rowOrDevice, p, ok := splitOpenDataID(id)
+		if !ok {
+			return staRecord{}, ErrOpenDataNotFound
+		}
+		f.Key = p.Key
+		if set == "Datastreams" {
+			f.DeviceID = rowOrDevice
+		} else {
+			f.RowID = rowOrDevice
+		}
+	default:
+		f.DeviceID = uint(id)
+	}
+	records, _, _, err := r.list(set, f, staQuery{Top: 1})
+	if err != nil {
+		return staRecord{}, err
+	}
+	if len(records) == 0 {
+		return staRecord{}, ErrOpenDataNotFound
+	}
+	return records[0], nil
+}
+
+// list คืน entity ตาม filter และ $top/$skip; total = -1 เมื่อยังไม่ได้นับ (Observations), more = ยังมีหน้าถัดไป
+func (r *staResolver) list(set string, f staFilter, q staQuery) ([]staRecord, int64, bool, error) {
+	if set == "Observations" {
+		oq := r.observationQuery(f, q)
+		oq.Limit = q.Top + 1
+		observations, err := listOpenDataObservations(oq)
+		if err != nil {
+			return nil, 0, false, err
+		}
+		more := len(observations) > q.Top
+		if more {
+			observations = observations[:q.Top]
+		}
+		records := make([]staRecord, 0, len(observations))
+		for _, o := range observations {
+			records = append(records, staRecord{
+				filter: staFilter{DeviceID: o.DeviceID, Key: o.Param.Key, RowID: o.RowID},
+				body:   r.observation(o),
+				cursor: RawReadingCursor{Timestamp: o.Timestamp, ID: uint(o.ID())},
+			})
+		}
+		return records, -1, more, nil
+	}
+
+	var records []staRecord
+	if set == "ObservedProperties" {
+		for _, p := range openDataParameters {
+			if f.Key == 0 || f.Key == p.Key {
+				records = append(records, staRecord{filter: staFilter{Key: p.Key}, body: r.observedProperty(p)})
+			}
+		}
+	} else {
+		stations, err := r.loadStations()
+		if err != nil {
+			return nil, 0, false, err
+		}
+		for _, st := range stations {
+			if f.DeviceID != 0 && st.ID != f.DeviceID {
+				continue
+			}
+			if set == "Datastreams" {
+				for _, p := range openDataParameters {
+					if f.Key == 0 || f.Key == p.Key {
+						records = append(records, staRecord{filter: staFilter{DeviceID: st.ID, Key: p.Key}, body: r.datastream(st, p)})
+					}
+				}
+				continue
+			}
+			records = append(records, staRecord{filter: staFilter{DeviceID: st.ID}, body: r.deviceEntity(set, st)})
+		}
+	}
+
+	total := int64(len(records))
+	start := min(q.Skip, len(records))
+	end := min(start+q.Top, len(records))
+	return records[start:end], total, end < len(records), nil
+}
+
+func (r *staResolver) observationQuery(f staFilter, q staQuery) openDataObservationQuery {
+	return openDataObservationQuery{
+		DeviceID: f.DeviceID, Key: f.Key, RowID: f.RowID,
+		Conds: q.Conds, Ascending: q.Ascending, Limit: q.Top, Offset: q.Skip, After: q.SkipToken,
+	}
+}
+
+// expand ใส่ entity ที่เกี่ยวข้องตาม $expand ลงใน body ของแต่ละ record
+func (r *staResolver) expand(set string, records []staRecord, expands []staExpand) error {
+	for _, e := range expands {
+		target, ok := staNavigation[set][e.Name]
+		if !ok {
+			return fmt.Errorf("%w: %s has no navigation property %q", ErrSTAInvalidRequest, set, e.Name)
+		}
+		top := e.Top
+		if top < 0 {
+			top = STADefaultTop
+		}
+		for i := range records {
+			children, _, _, err := r.list(target, records[i].filter.project(target), staQuery{Top: top})
+			if err != nil {
+				return err
+			}
+			if !staIsEntitySet(e.Name) {
+				if len(children) > 0 {
+					records[i].body[e.Name] = children[0].body
+				} else {
+					records[i].body[e.Name] = nil
+				}
+				continue
+			}
+			bodies := make([]map[string]interface{}, 0, len(children))
+			for _, c := range children {
+				bodies = append(bodies, c.body)
+			}
+			records[i].body[e.Name] = bodies
+		}
+	}
+	return nil
+}
+
+func (r *staResolver) loadStations() ([]openDataStation, error) {
+	if !r.loaded {
+		stations, err := loadOpenDataStations("")
+		if err != nil {
+			return nil, err
+		}
+		r.stations, r.loaded = stations, true
+	}
+	return r.stations, nil
+}
+
+// newEntity สร้าง body พร้อม @iot.id, @iot.selfLink และ navigationLink ทุกตัวของ entity set
+func (r *staResolver) newEntity(set string, id uint64) map[string]interface{} {
+	self := fmt.Sprintf("%s/%s(%d)", r.base, set, id)
+	body := map[string]interface{}{"@iot.id": id, "@iot.selfLink": self}
+	for nav := range staNavigation[set] {
+		body[nav+"@iot.navigationLink"] = self + "/" + nav
+	}
+	return body
+}
+
+func staPoint(lon, lat float64) map[string]interface{} {
+	return map[string]interface{}{"type": "Point", "coordinates": []float64{lon, lat}}
+}
+
+func staTime(ms int64) string {
+	return time.UnixMilli(ms).UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+// deviceEntity สร้าง entity ที่มีหนึ่งตัวต่ออุปกรณ์ (ไม่เปิดเผยข้อมูลผู้ติดต่อ)
+func (r *staResolver) deviceEntity(set string, st openDataStation) map[string]interface{} {
+	body := r.newEntity(set, uint64(st.ID))
+	properties := map[string]interface{}{
+		"dvid": st.DVID, "province": st.Province, "district": st.District, "subdistrict": st.Subdistrict,
+		"region": st.Region, "country": st.Country,
+	}
+	switch set {
+	case "Things":
+		body["name"] = st.Name()
+		body["description"] = strings.TrimSpace("Air quality sensor " + st.DVID + " " + st.Address)
+		properties["deploy_date"] = st.DeployDate.UTC().Format(time.RFC3339)
+		if st.Latest != nil {
+			properties["status"] = st.Latest.Status
+		}
+		body["properties"] = properties
+	case "Locations":
+		body["name"] = st.Name()
+		body["description"] = st.Address
+		body["encodingType"] = "application/geo+json"
+		body["location"] = staPoint(st.Longitude, st.Latitude)
+		body["properties"] = properties
+	case "FeaturesOfInterest":
+		body["name"] = st.Name()
+		body["description"] = st.Address
+		body["encodingType"] = "application/geo+json"
+		body["feature"] = staPoint(st.Longitude, st.Latitude)
+	case "HistoricalLocations":
+		body["time"] = st.DeployDate.UTC().Format(time.RFC3339)
+	case "Sensors":
+		model := strings.TrimSpace(st.Models)
+		if model == "" {
+			model = "unknown"
+		}
+		body["name"] = model
+		body["description"] = "Low-cost particulate matter sensor " + model + " (" + st.DVID + ")"
+		body["encodingType"] = "text/plain"
+		body["metadata"] = model
+	}
+	return body
+}
+
+func (r *staResolver) observedProperty(p openDataParameter) map[string]interface{} {
+	body := r.newEntity("ObservedProperties", uint64(p.Key))
+	body["name"] = p.DisplayName
+	body["definition"] = p.Definition
+	body["description"] = p.DisplayName + " (" + p.Name + ")"
+	return body
+}
+
+func (r *staResolver) datastream(st openDataStation, p openDataParameter) map[string]interface{} {
+	body := r.newEntity("Datastreams", openDataSeriesID(st.ID, p.Key))
+	body["name"] = p.DisplayName + " at " + st.Name()
+	body["description"] = p.DisplayName + " measured by " + st.DVID
+	body["observationType"] = staObservationType
+	body["unitOfMeasurement"] = map[string]string{"name": p.UnitName, "symbol": p.UnitSymbol, "definition": p.UnitDefinition}
+	body["observedArea"] = staPoint(st.Longitude, st.Latitude)
+	body["properties"] = map[string]interface{}{"dvid": st.DVID, "parameter": p.Name}
+	return body
+}
+
+func (r *staResolver) observation(o openDataObservation) map[string]interface{} {
+	body := r.newEntity("Observations", o.ID())
+	body["phenomenonTime"] = staTime(o.Timestamp)
+	body["resultTime"] = staTime(o.Timestamp)
+	body["result"] = roundToTwoDecimals(o.Value)
+	// navigation ของ observation ชี้ไปที่ datastream/อุปกรณ์ของแถวนั้นโดยตรง
+	body["Datastream@iot.navigationLink"] = fmt.Sprintf("%s/Datastreams(%d)", r.base, openDataSeriesID(o.DeviceID, o.Param.Key))
+	body["FeatureOfInterest@iot.navigationLink"] = fmt.Sprintf("%s/FeaturesOfInterest(%d)", r.base, o.DeviceID)
+	return body
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"yakkaw_dashboard/database"
+	"yakkaw_dashboard/models"
+)
+
+// locationCache จำผลของ (พิกัด, address) ที่เคย resolve แล้ว เพราะสถานีส่งพิกัดเดิมทุกรอบ ingest
+var locationCache = struct {
+	sync.Mutex
+	entries map[string]AdminLocation
+}{entries: make(map[string]AdminLocation)}
+
+func resetLocationCache() {
+	locationCache.Lock()
+	locationCache.entries = make(map[string]AdminLocation)
+	locationCache.Unlock()
+}
+
+// ResolveLocation หาจังหวัด/อำเภอ/ภาคจากพิกัดด้วยชุดขอบเขต และใช้การแยก address เมื่อพิกัดไม่อยู่ในขอบเขตใด
+// หรือเมื่อชุดขอบเขตเป็นแบบประมาณ (Source = approximate) แต่ address ระบุจังหวัดอื่น
+func ResolveLocation(lat, lon float64, address string) AdminLocation {
+	key := fmt.Sprintf("%.5f|%.5f|%s", lat, lon, address)
+	locationCache.Lock()
+	loc, ok := locationCache.entries[key]
+	locationCache.Unlock()
+	if ok {
+		return loc
+	}
+
+	parts := parseThaiAddress(address)
+	loc, ok = LocateByCoordinates(lat, lon)
+	if ok && loc.Source == "approximate" && parts.Province != "" && parts.Province != loc.Province {
+		// พื้นที่โดยประมาณคลาดได้ใกล้เขตจังหวัด จังหวัดที่เขียนไว้ใน address จึงน่าเชื่อกว่า
+		ok = false
+	}
+	if !ok {
+		loc = AdminLocation{}
+		if parts.Province != "" {
+			loc = AdminLocation{
+				Province:    parts.Province,
+				District:    parts.District,
+				Subdistrict: parts.Subdistrict,
+				Region:      regionForProvince(parts.Province, ""),
+				Source:      "address",
+			}
+		}
+	} else if parts.Province == loc.Province {
+		// ใช้อำเภอ/ตำบลจาก address เฉพาะเมื่อจังหวัดตรงกับที่พิกัดบอก (อำเภอโดยประมาณให้ address มาก่อน)
+		if loc.District == "" || (loc.Source == "approximate" && parts.District != "") {
+			loc.District = parts.District
+		}
+		if loc.District == parts.District {
+			loc.Subdistrict = parts.Subdistrict
+		}
+	}
+
+	locationCache.Lock()
+	locationCache.entries[key] = loc
+	locationCache.Unlock()
+	return loc
+}
+
+// storedProvince ใช้ province ที่บันทึกไว้กับข้อมูล และแยกจาก address สำหรับแถวเก่าที่ยังไม่มี
+func storedProvince(province, address string) string {
+	if province != "" {
+		return province
+	}
+	return deriveProvince(address)
+}
+
+// applyReadingLocation เติม province/district/subdistrict/region ให้ข้อมูลก่อนบันทึก
+func applyReadingLocation(data *models.SensorData) {
+	loc := ResolveLocation(data.Latitude, data.Longitude, data.Address)
+	data.Province, data.District, data.Subdistrict, data.Region = loc.Province, loc.District, loc.Subdistrict, loc.Region
+}
+
+// applyDeviceLocation คำนวณตำแหน่งทางปกครองของอุปกรณ์จากพิกัด/address ที่ admin กรอก
+func applyDeviceLocation(device *models.Device) {
+	loc := ResolveLocation(device.Latitude, device.Longitude, device.Address)
+	device.Province, device.District, device.Subdistrict, device.Region = loc.Province, loc.District, loc.Subdistrict, loc.Region
+	device.LocationSource = loc.Source
+}
+
+// RebuildStationLocations คำนวณตำแหน่งของทุกอุปกรณ์และแถวใน sensor_data ใหม่
+// all = false จะเติมเฉพาะแถวที่ยังไม่มี province (ใช้ตอนเริ่มระบบ), true ใช้หลังเปลี่ยนชุดขอบเขตหรือ alias
+func RebuildStationLocations(all bool) (int64, error) {
+	var devices []models.Device
+	if err := database.DB.Find(&devices).Error; err != nil {
+		return 0, err
+	}
+	for i := range devices {
+		applyDeviceLocation(&devices[i])
+		if err := database.DB.Model(&devices[i]).Select("Province", "District", "Subdistrict", "Region", "LocationSource").Updates(&devices[i]).Error; err != nil {
+			return 0, err
+		}
+	}
+
+	pending := ""
+	if !all {
+		pending = " WHERE province IS NULL OR province = ''"
+	}
+	var groups []struct {
+		Latitude  float64
+		Longitude float64
+		Address   string
+	}
+	if err := database.DB.Raw(`
+		SELECT DISTINCT latitude, longitude, COALESCE(address, '') AS address
+		FROM sensor_data` + pending).Scan(&groups).Error; err != nil {
+		return 0, err
+	}
+
+	var updated int64
+	for _, g := range groups {
+		loc := ResolveLocation(g.Latitude, g.Longitude, g.Address)
+		result := database.DB.Exec(`
+			UPDATE sensor_data SET province = ?, district = ?, subdistrict = ?, region = ?
+			WHERE latitude = ? AND longitude = ? AND COALESCE(address, '') = ?
+			  AND (province IS DISTINCT FROM ? OR district IS DISTINCT FROM ?
+			       OR subdistrict IS DISTINCT FROM ? OR region IS DISTINCT FROM ?)
+		`, loc.Province, loc.District, loc.Subdistrict, loc.Region, g.Latitude, g.Longitude, g.Address,
+			loc.Province, loc.District, loc.Subdistrict, loc.Region)
+		if result.Error != nil {
+			return updated, result.Error
+		}
+		updated += result.RowsAffected
+	}
+
+	if err := RefreshStationSnapshots(); err != nil {
+		log.Printf("Error refreshing station snapshots: %v", err)
+	}
+	return updated, nil
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"yakkaw_dashboard/database"
+	"yakkaw_dashboard/models"
+)
+
+const (
+	stationActiveWindow = deviceHealthWindowDays * 24 * time.Hour // อุปกรณ์ที่ไม่ส่งข้อมูลนานกว่านี้ไม่นับเป็น active
+	stationTrendMinDiff = 2.0                                     // µg/m³ ที่ต้องเปลี่ยนจึงถือว่า rising/falling
+)
+
+// StationSnapshot คือค่าล่าสุดของอุปกรณ์หนึ่งเครื่อง
+type StationSnapshot struct {
+	DVID          string    `json:"dvid"`
+	Place         string    `json:"place"`
+	Address       string    `json:"address"`
+	Province      string    `json:"province"`
+	District      string    `json:"district,omitempty"`
+	Subdistrict   string    `json:"subdistrict,omitempty"`
+	Region        string    `json:"region,omitempty"`
+	Latitude      float64   `json:"latitude"`
+	Longitude     float64   `json:"longitude"`
+	Timestamp     int64     `json:"timestamp"`
+	ObservedAt    time.Time `json:"observed_at"`
+	AgeMinutes    float64   `json:"age_minutes"`
+	Status        string    `json:"status"` // online | stale | offline
+	PM25          float64   `json:"pm25"`   // ค่าที่ calibrate แล้วถ้ามี
+	PM10          float64   `json:"pm10"`
+	PM25Raw       int       `json:"pm25_raw"`
+	PM10Raw       int       `json:"pm10_raw"`
+	PM100         int       `json:"pm100"`
+	AQI           int       `json:"aqi"`
+	Temperature   int       `json:"temperature"`
+	Humidity      int       `json:"humidity"`
+	Pressure      int       `json:"pressure"`
+	Color         string    `json:"color"`
+	ColorMin      int       `json:"color_min"`
+	ColorMax      int       `json:"color_max"`
+	Trend         string    `json:"trend"` // rising | falling | steady | unknown
+	PM25Change1h  *float64  `json:"pm25_change_1h,omitempty"`
+	UpstreamTrend string    `json:"upstream_trend,omitempty"`
+	UpstreamColor string    `json:"upstream_color,omitempty"`
+}
+
+// StationFilter: ค่าศูนย์ = ไม่กรอง
+type StationFilter struct {
+	Province string
+	BBox     *[4]float64 // minLon, minLat, maxLon, maxLat
+	MaxAge   time.Duration
+	Status   string
+}
+
+var stationCache struct {
+	sync.RWMutex
+	stations    []StationSnapshot
+	refreshedAt time.Time
+}
+
+// RefreshStationSnapshots โหลดค่าล่าสุดของทุกอุปกรณ์ใหม่เข้า cache (เรียกหลัง ingest)
+func RefreshStationSnapshots() error {
+	stations, err := loadStationSnapshots(time.Now())
+	if err != nil {
+		return err
+	}
+	stationCache.Lock()
+	stationCache.stations = stations
+	stationCache.refreshedAt = time.Now()
+	stationCache.Unlock()
+	invalidateGridCache()
+	invalidateChartImageCache()
+	return nil
+}
+
+// GetLatestStations คืนค่าล่าสุดของทุกสถานีจาก cache (โหลดครั้งแรกเมื่อ cache ยังว่าง)
+// age และ status คำนวณใหม่ ณ เวลาที่เรียก
+func GetLatestStations(filter StationFilter) ([]StationSnapshot, time.Time, error) {
+	stationCache.RLock()
+	refreshedAt := stationCache.refreshedAt
+	stationCache.RUnlock()
+	if refreshedAt.IsZero() {
+		if err := RefreshStationSnapshots(); err != nil {
+			return nil, time.Time{}, err
+		}
+	}
+
+	stationCache.RLock()
+	cached := stationCache.stations
+	refreshedAt = stationCache.refreshedAt
+	stationCache.RUnlock()
+
+	return filterStationSnapshots(cached, filter, time.Now()), refreshedAt, nil
+}
+
+// filterStationSnapshots คำนวณ age/status ณ now แล้วกรองตาม filter
+func filterStationSnapshots(stations []StationSnapshot, filter StationFilter, now time.Time) []StationSnapshot {
+	province := normalizeProvince(filter.Province)
+	result := make([]StationSnapshot, 0, len(stations))
+	for _, s := range stations {
+		age := now.Sub(s.ObservedAt)
+		s.AgeMinutes = roundToTwoDecimals(age.Minutes())
+		s.Status = deviceStatus(age)
+
+		if province != "" && s.Province != province {
+			continue
+		}
+		if filter.BBox != nil {
+			b := filter.BBox
+			if s.Longitude < b[0] || s.Latitude < b[1] || s.Longitude > b[2] || s.Latitude > b[3] {
+				continue
+			}
+		}
+		if filter.MaxAge > 0 && age > filter.MaxAge {
+			continue
+		}
+		if filter.Status != "" && s.Status != filter.Status {
+			continue
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+func loadStationSnapshots(now time.Time) ([]StationSnapshot, error) {
+	type latestRow struct {
+		models.SensorData
+		PrevPM25 *float64
+	}
+	var rows []latestRow
+	// latest = ค่าล่าสุดที่ไม่ถูก flag (ค่าผิดปกติล่าสุดไม่แทนที่ค่าที่ใช้ได้ก่อนหน้า)
+	// prev_pm25 = ค่าเฉลี่ยช่วง 45–90 นาทีก่อนค่าล่าสุด ใช้คำนวณแนวโน้มราว 1 ชั่วโมง
+	if err := database.DB.Raw(`
+		WITH latest AS (
+			SELECT DISTINCT ON (dvid) *
+			FROM sensor_data
+			WHERE timestamp >= ? AND dvid IS NOT NULL AND dvid <> '' AND quality_flags = 0
+			ORDER BY dvid, timestamp DESC
+		)
+		SELECT latest.*,
+		       (SELECT AVG(COALESCE(p.pm25_calibrated, p.pm25))
+		          FROM sensor_data p
+		         WHERE p.dvid = latest.dvid
+		           AND p.quality_flags = 0
+		           AND p.timestamp BETWEEN latest.timestamp - 90*60*1000 AND latest.timestamp - 45*60*1000
+		       ) AS prev_pm25
+		FROM latest
+	`, now.Add(-stationActiveWindow).UnixMilli()).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	bands, err := GetAllColorRanges()
+	if err != nil {
+		return nil, err
+	}
+
+	stations := make([]StationSnapshot, 0, len(rows))
+	for _, row := range rows {
+		d := row.SensorData
+		s := StationSnapshot{
+			DVID:          d.DVID,
+			Place:         d.Place,
+			Address:       d.Address,
+			Province:      storedProvince(d.Province, d.Address),
+			District:      d.District,
+			Subdistrict:   d.Subdistrict,
+			Region:        d.Region,
+			Latitude:      d.Latitude,
+			Longitude:     d.Longitude,
+			Timestamp:     d.Timestamp,
+			ObservedAt:    time.UnixMilli(d.Timestamp),
+			PM25:          float64(d.PM25),
+			PM10:          float64(d.PM10),
+			PM25Raw:       d.PM25,
+			PM10Raw:       d.PM10,
+			PM100:         d.PM100,
+			AQI:           d.AQI,
+			Temperature:   d.Temperature,
+			Humidity:      d.Humidity,
+			Pressure:      d.Pres,
+			UpstreamTrend: d.Trend,
+			UpstreamColor: d.Color,
+		}
+		if s.Region == "" {
+			s.Region = regionForProvince(s.Province, "")
+		}
+		if d.PM25Calibrated != nil {
+			s.PM25 = *d.PM25Calibrated
+		}
+		if d.PM10Calibrated != nil {
+			s.PM10 = *d.PM10Calibrated
+		}
+		if len(bands) > 0 {
+			band := bands[colorBandIndex(bands, s.PM25)]
+			s.Color, s.ColorMin, s.ColorMax = band.Color, band.Min, band.Max
+		}
+		s.Trend, s.PM25Change1h = stationTrend(s.PM25, row.PrevPM25)
+		stations = append(stations, s)
+	}
+
+	sort.Slice(stations, func(i, j int) bool { return stations[i].DVID < stations[j].DVID })
+	return stations, nil
+}
+
+// stationTrend เทียบค่าล่าสุดกับค่าเฉลี่ยราว 1 ชั่วโมงก่อน (ต้องต่างกันอย่างน้อย 2 µg/m³ หรือ 10%)
+func stationTrend(current float64, previous *float64) (string, *float64) {
+	if previous == nil {
+		return "unknown", nil
+	}
+	change := roundToTwoDecimals(current - *previous)
+	threshold := math.Max(stationTrendMinDiff, 0.1*math.Abs(*previous))
+	switch {
+	case change >= threshold:
+		return "rising", &change
+	case change <= -threshold:
+		return "falling", &change
+	default:
+		return "steady", &change
+	}
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStationTrend(t *testing.T) {
+	ptr := func(v float64) *float64 { return &v }
+	cases := []struct {
+		current  float64
+		previous *float64
+		want     string
+		change   *float64
+	}{
+		{30, nil, "unknown", nil},
+		{30, ptr(20), "rising", ptr(10)},
+		{10, ptr(20), "falling", ptr(-10)},
+		{21.5, ptr(20), "steady", ptr(1.5)}, // ต่ำกว่า 2 µg/m³
+		{12, ptr(10), "rising", ptr(2)},     // ถึงเกณฑ์ขั้นต่ำพอดี
+		{105, ptr(100), "steady", ptr(5)},   // ต่ำกว่า 10% ของค่าก่อนหน้า
+		{90, ptr(100), "falling", ptr(-10)}, // ถึง 10% พอดี
+	}
+	for _, tc := range cases {
+		trend, change := stationTrend(tc.current, tc.previous)
+		if trend != tc.want {
+			t.Errorf("stationTrend(%v, %v) = %s, want %s", tc.current, tc.previous, trend, tc.want)
+		}
+		if (change == nil) != (tc.change == nil) || (change != nil && *change != *tc.change) {
+			t.Errorf("stationTrend(%v, %v) change = %v, want %v", tc.current, tc.previous, change, tc.change)
+		}
+	}
+}
+
+func TestFilterStationSnapshots(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	stations := []StationSnapshot{
+		{DVID: "online", Province: "เชียงใหม่", Latitude: 18.79, Longitude: 98.98, ObservedAt: now.Add(-10 * time.Minute)},
+		{DVID: "stale", Province: "เชียงใหม่", Latitude: 18.9, Longitude: 99.0, ObservedAt: now.Add(-time.Hour)},
+		{DVID: "offline", Province: "ลำพูน", Latitude: 18.58, Longitude: 99.01, ObservedAt: now.Add(-5 * time.Hour)},
+	}
+
+	cases := []struct {
+		name   string
+		filter StationFilter
+		want   []string
+	}{
+		{"all", StationFilter{}, []string{"online", "stale", "offline"}},
+		{"status online", StationFilter{Status: "online"}, []string{"online"}},
+		{"status stale", StationFilter{Status: "stale"}, []string{"stale"}},
+		{"status offline", StationFilter{Status: "offline"}, []string{"offline"}},
+		{"province alias", StationFilter{Province: "Chiang Mai"}, []string{"online", "stale"}},
+		{"max age", StationFilter{MaxAge: 2 * time.Hour}, []string{"online", "stale"}},
+		{"bbox", StationFilter{BBox: &[4]float64{98.9, 18.7, 99.05, 18.85}}, []string{"online"}},
+		{"province and status", StationFilter{Province: "ลำพูน", Status: "online"}, nil},
+	}
+	for _, tc := range cases {
+		got := filterStationSnapshots(stations, tc.filter, now)
+		var ids []string
+		for _, s := range got {
+			ids = append(ids, s.DVID)
+			if s.Status != s.DVID {
+				t.Errorf("%s: %s has status %s", tc.name, s.DVID, s.Status)
+			}
+		}
+		if len(ids) != len(tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, ids, tc.want)
+			continue
+		}
+		for i := range ids {
+			if ids[i] != tc.want[i] {
+				t.Errorf("%s: got %v, want %v", tc.name, ids, tc.want)
+				break
+			}
+		}
+	}
+
+	if got := filterStationSnapshots(stations, StationFilter{}, now); got[0].AgeMinutes != 10 {
+		t.Errorf("age_minutes = %v, want 10", got[0].AgeMinutes)
+	}
+}
@@ -0,0 +1,499 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"yakkaw_dashboard/config"
+	"yakkaw_dashboard/database"
+	"yakkaw_dashboard/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DeliverySent        = "sent"
+	DeliveryFailed      = "failed"
+	DeliveryRateLimited = "rate_limited"
+
+	subscriptionWindowMinutes = 60
+
+	subscriptionWorkers        = 4
+	subscriptionQueueSize      = 256
+	subscriptionMaxAttempts    = 3
+	subscriptionInitialBackoff = 2 * time.Second
+	subscriptionRetryCooldown  = 5 * time.Minute // รอก่อนส่งซ้ำหลังส่งไม่สำเร็จ เพิ่มเท่าตัวทุกครั้งที่ล้มเหลว
+	subscriptionMaxCooldown    = 6 * time.Hour
+)
+
+type SubscriptionService struct {
+	DB *gorm.DB
+}
+
+// NewSubscriptionService creates a new SubscriptionService instance
+func NewSubscriptionService(db *gorm.DB) *SubscriptionService {
+	return &SubscriptionService{DB: db}
+}
+
+// ListSubscriptions returns all subscriptions of a user
+func (s *SubscriptionService) ListSubscriptions(userID uint) ([]models.AlertSubscription, error) {
+	var subs []models.AlertSubscription
+	if err := s.DB.Where("user_id = ?", userID).Order("id ASC").Find(&subs).Error; err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+// CreateSubscription validates and stores a new subscription for the user
+func (s *SubscriptionService) CreateSubscription(userID uint, sub models.AlertSubscription) (models.AlertSubscription, error) {
+	sub.UserID = userID
+	if err := validateSubscription(&sub); err != nil {
+		return models.AlertSubscription{}, err
+	}
+	token, err := newRandomToken()
+	if err != nil {
+		return models.AlertSubscription{}, err
+	}
+	sub.UnsubscribeToken = token
+	sub.Active = true
+	sub.Triggered = false
+	sub.EmailConfirmed = false
+	if err := resetEmailConfirmation(&sub); err != nil {
+		return models.AlertSubscription{}, err
+	}
+
+	if err := s.DB.Create(&sub).Error; err != nil {
+		return models.AlertSubscription{}, err
+	}
+	sendSubscriptionConfirmation(&sub)
+	return sub, nil
+}
+
+// UpdateSubscription replaces the settings of a user's subscription and re-arms it
+func (s *SubscriptionService) UpdateSubscription(userID, id uint, input models.AlertSubscription) (models.AlertSubscription, error) {
+	var sub models.AlertSubscription
+	if err := s.DB.Where("user_id = ?", userID).First(&sub, id).Error; err != nil {
+		return models.AlertSubscription{}, err
+	}
+
+	previousEmail := sub.Email
+	sub.ScopeType = input.ScopeType
+	sub.ScopeValue = input.ScopeValue
+	sub.Metric = input.Metric
+	sub.Threshold = input.Threshold
+	sub.Channel = input.Channel
+	sub.Email = input.Email
+	sub.WebhookURL = input.WebhookURL
+	sub.WebhookSecret = input.WebhookSecret
+	sub.Active = input.Active
+	sub.Triggered = false
+	if err := validateSubscription(&sub); err != nil {
+		return models.AlertSubscription{}, err
+	}
+	// เปลี่ยนอีเมลต้องยืนยันใหม่ ส่วนอีเมลที่ยังไม่ยืนยันจะได้ลิงก์ใหม่ทุกครั้งที่แก้ไข
+	if sub.Email != previousEmail {
+		sub.EmailConfirmed = false
+	}
+	if err := resetEmailConfirmation(&sub); err != nil {
+		return models.AlertSubscription{}, err
+	}
+
+	if err := s.DB.Save(&sub).Error; err != nil {
+		return models.AlertSubscription{}, err
+	}
+	sendSubscriptionConfirmation(&sub)
+	return sub, nil
+}
+
+// DeleteSubscription removes a user's subscription
+func (s *SubscriptionService) DeleteSubscription(userID, id uint) error {
+	result := s.DB.Where("user_id = ?", userID).Delete(&models.AlertSubscription{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Unsubscribe ปิด subscription จาก token ในลิงก์ยกเลิก (ไม่ต้อง login)
+func (s *SubscriptionService) Unsubscribe(token string) error {
+	if strings.TrimSpace(token) == "" {
+		return gorm.ErrRecordNotFound
+	}
+	result := s.DB.Model(&models.AlertSubscription{}).
+		Where("unsubscribe_token = ?", token).
+		Update("active", false)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ConfirmEmail ยืนยันอีเมลของ subscription จาก token ในลิงก์ที่ส่งไปยังอีเมลนั้น (ไม่ต้อง login)
+func (s *SubscriptionService) ConfirmEmail(token string) error {
+	if strings.TrimSpace(token) == "" {
+		return gorm.ErrRecordNotFound
+	}
+	result := s.DB.Model(&models.AlertSubscription{}).
+		Where("confirm_token = ?", token).
+		Updates(map[string]interface{}{"email_confirmed": true, "confirm_token": ""})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// resetEmailConfirmation สร้าง token ยืนยันใหม่ให้ subscription อีเมลที่ยังไม่ได้ยืนยัน
+func resetEmailConfirmation(sub *models.AlertSubscription) error {
+	if sub.Channel != "email" || sub.EmailConfirmed {
+		sub.ConfirmToken = ""
+		return nil
+	}
+	token, err := newRandomToken()
+	if err != nil {
+		return err
+	}
+	sub.ConfirmToken = token
+	return nil
+}
+
+// sendSubscriptionConfirmation ส่งลิงก์ยืนยันไปยังอีเมลที่ยังไม่ได้ยืนยัน ถ้าส่งไม่สำเร็จผู้ใช้ขอลิงก์ใหม่ได้ด้วยการแก้ไข subscription
+func sendSubscriptionConfirmation(sub *models.AlertSubscription) {
+	if sub.ConfirmToken == "" {
+		return
+	}
+	link := config.PublicBaseURL() + "/subscriptions/confirm?token=" + url.QueryEscape(sub.ConfirmToken)
+	body := fmt.Sprintf("มีการสมัครรับแจ้งเตือน %s ของ %s ด้วยอีเมลนี้\n\nยืนยันอีเมลเพื่อเริ่มรับแจ้งเตือน: %s\n\nหากคุณไม่ได้สมัคร ไม่ต้องดำเนินการใดๆ จะไม่มีอีเมลแจ้งเตือนส่งมาอีก\n",
+		strings.ToUpper(sub.Metric), sub.ScopeValue, link)
+	if err := sendEmail([]string{sub.Email}, "[Yakkaw] Confirm your alert subscription", body); err != nil {
+		log.Printf("Error sending confirmation for subscription %d: %v", sub.ID, err)
+	}
+}
+
+// ListDeliveries returns the newest delivery logs of a user
+func (s *SubscriptionService) ListDeliveries(userID uint, limit int) ([]models.AlertDelivery, error) {
+	var deliveries []models.AlertDelivery
+	if err := s.DB.Where("user_id = ?", userID).Order("created_at DESC").Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func validateSubscription(sub *models.AlertSubscription) error {
+	sub.Metric = strings.ToLower(strings.TrimSpace(sub.Metric))
+	if sub.Metric == "" {
+		sub.Metric = "pm25"
+	}
+	if _, _, err := rankingColumns(sub.Metric, "address"); err != nil {
+		return err
+	}
+
+	sub.ScopeType = strings.ToLower(strings.TrimSpace(sub.ScopeType))
+	sub.ScopeValue = strings.TrimSpace(sub.ScopeValue)
+	switch sub.ScopeType {
+	case "province":
+		sub.ScopeValue = normalizeProvince(sub.ScopeValue)
+	case "place":
+	default:
+		return errors.New("scope_type must be province or place")
+	}
+	if sub.ScopeValue == "" {
+		return errors.New("scope_value is required")
+	}
+
+	if sub.Threshold <= 0 {
+		return errors.New("threshold must be greater than 0")
+	}
+
+	sub.Channel = strings.ToLower(strings.TrimSpace(sub.Channel))
+	switch sub.Channel {
+	case "email":
+		addr, err := mail.ParseAddress(strings.TrimSpace(sub.Email))
+		if err != nil {
+			return errors.New("a valid email is required for the email channel")
+		}
+		sub.Email = addr.Address
+		sub.WebhookURL = ""
+		sub.WebhookSecret = ""
+	case "webhook":
+		u, err := validatePublicWebhookURL(sub.WebhookURL)
+		if err != nil {
+			return err
+		}
+		sub.WebhookURL = u.String()
+		sub.Email = ""
+		// webhook ต้องเซ็นเสมอ ถ้าผู้ใช้ไม่กำหนด secret จะสร้างให้ (คืนให้ผู้ใช้ใน response)
+		if sub.WebhookSecret == "" {
+			secret, err := newRandomToken()
+			if err != nil {
+				return err
+			}
+			sub.WebhookSecret = secret
+		}
+	default:
+		return errors.New("channel must be email or webhook")
+	}
+	return nil
+}
+
+func newRandomToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// UnsubscribeURL คืนลิงก์สำหรับยกเลิก subscription (ใส่ในอีเมลและ payload ของ webhook)
+func UnsubscribeURL(token string) string {
+	return config.PublicBaseURL() + "/subscriptions/unsubscribe?token=" + url.QueryEscape(token)
+}
+
+// EvaluateSubscriptions ตรวจค่าเฉลี่ย 60 นาทีล่าสุดของทุก subscription ที่เปิดอยู่และยืนยันอีเมลแล้ว (เรียกหลัง ingest)
+// แจ้งเตือนเมื่อค่าขึ้นถึง threshold หนึ่งครั้ง และแจ้งใหม่ได้เมื่อค่าลดลงต่ำกว่า threshold ก่อน
+// การส่งทำใน worker เบื้องหลัง รอบ ingest จึงไม่ต้องรอ SMTP หรือ webhook ที่ช้า
+func EvaluateSubscriptions() error {
+	var subs []models.AlertSubscription
+	if err := database.DB.Where("active = ? AND (channel <> ? OR email_confirmed = ?)", true, "email", true).Find(&subs).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	cache := make(map[string]map[string]float64)
+	for i := range subs {
+		sub := &subs[i]
+		key := sub.ScopeType + ":" + sub.Metric
+		values, ok := cache[key]
+		if !ok {
+			var err error
+			values, err = aggregateRuleScope(models.AlertRule{
+				Metric:        sub.Metric,
+				ScopeType:     sub.ScopeType,
+				WindowMinutes: subscriptionWindowMinutes,
+				Aggregation:   "avg",
+			}, now)
+			if err != nil {
+				return err
+			}
+			cache[key] = values
+		}
+
+		value, ok := values[sub.ScopeValue]
+		if !ok {
+			continue
+		}
+
+		// triggered ถูกตั้งโดย worker เมื่อส่งสำเร็จ ที่นี่จึงเขียนเฉพาะตอนค่าลดลงต่ำกว่า threshold
+		updates := map[string]interface{}{"last_value": value}
+		switch {
+		case value >= sub.Threshold && !sub.Triggered:
+			enqueueSubscriptionAlert(*sub, value)
+		case value < sub.Threshold:
+			subscriptionRetries.reset(sub.ID)
+			if sub.Triggered {
+				updates["triggered"] = false
+			}
+		}
+		if err := database.DB.Model(sub).Updates(updates).Error; err != nil {
+			log.Printf("Error saving subscription %d: %v", sub.ID, err)
+		}
+	}
+	return nil
+}
+
+type subscriptionJob struct {
+	sub   models.AlertSubscription
+	value float64
+}
+
+var (
+	subscriptionQueue       = make(chan subscriptionJob, subscriptionQueueSize)
+	subscriptionWorkersOnce sync.Once
+	subscriptionRetries     = newSubscriptionBackoff()
+)
+
+// enqueueSubscriptionAlert ส่ง subscription เข้าคิว เว้นแต่กำลังส่งอยู่หรือยังไม่พ้นช่วงรอหลังส่งไม่สำเร็จ
+func enqueueSubscriptionAlert(sub models.AlertSubscription, value float64) {
+	if !subscriptionRetries.reserve(sub.ID, time.Now()) {
+		return
+	}
+	subscriptionWorkersOnce.Do(func() {
+		for i := 0; i < subscriptionWorkers; i++ {
+			go runSubscriptionWorker()
+		}
+	})
+	select {
+	case subscriptionQueue <- subscriptionJob{sub: sub, value: value}:
+	default:
+		subscriptionRetries.unreserve(sub.ID)
+		log.Printf("Subscription delivery queue is full, subscription %d will be retried next cycle", sub.ID)
+	}
+}
+
+func runSubscriptionWorker() {
+	for job := range subscriptionQueue {
+		sent := deliverSubscriptionAlert(&job.sub, job.value, time.Now())
+		if sent {
+			if err := database.DB.Model(&models.AlertSubscription{}).Where("id = ?", job.sub.ID).Updates(map[string]interface{}{
+				"triggered":        true,
+				"last_notified_at": job.sub.LastNotifiedAt,
+			}).Error; err != nil {
+				log.Printf("Error saving subscription %d: %v", job.sub.ID, err)
+			}
+		}
+		subscriptionRetries.release(job.sub.ID, sent, time.Now())
+	}
+}
+
+// subscriptionBackoff จำ subscription ที่อยู่ในคิว และเวลาที่ส่งใหม่ได้หลังส่งไม่สำเร็จหรือติด rate limit
+type subscriptionBackoff struct {
+	mu       sync.Mutex
+	pending  map[uint]bool
+	retryAt  map[uint]time.Time
+	failures map[uint]int
+}
+
+func newSubscriptionBackoff() *subscriptionBackoff {
+	return &subscriptionBackoff{pending: map[uint]bool{}, retryAt: map[uint]time.Time{}, failures: map[uint]int{}}
+}
+
+// reserve คืน true และจองไว้เมื่อส่ง subscription นี้ได้ตอนนี้
+func (b *subscriptionBackoff) reserve(id uint, now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.pending[id] || now.Before(b.retryAt[id]) {
+		return false
+	}
+	b.pending[id] = true
+	return true
+}
+
+func (b *subscriptionBackoff) unreserve(id uint) {
+	b.mu.Lock()
+	delete(b.pending, id)
+	b.mu.Unlock()
+}
+
+// release ปลดการจองหลังส่งเสร็จ ถ้าไม่สำเร็จจะรอ subscriptionRetryCooldown × 2^(ครั้งที่ล้มเหลว-1) ก่อนส่งใหม่
+func (b *subscriptionBackoff) release(id uint, sent bool, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.pending, id)
+	if sent {
+		delete(b.retryAt, id)
+		delete(b.failures, id)
+		return
+	}
+	b.failures[id]++
+	cooldown := subscriptionRetryCooldown
+	for i := 1; i < b.failures[id] && cooldown < subscriptionMaxCooldown; i++ {
+		cooldown *= 2
+	}
+	b.retryAt[id] = now.Add(min(cooldown, subscriptionMaxCooldown))
+}
+
+// reset ล้างช่วงรอเมื่อค่าลดลงต่ำกว่า threshold แล้ว
+func (b *subscriptionBackoff) reset(id uint) {
+	b.mu.Lock()
+	delete(b.retryAt, id)
+	delete(b.failures, id)
+	b.mu.Unlock()
+}
+
+// deliverSubscriptionAlert ส่งแจ้งเตือนตามช่องทางของ subscription (ถ้ายังไม่เกิน rate limit ของผู้ใช้)
+// ส่งซ้ำพร้อม backoff เมื่อส่งไม่สำเร็จ บันทึกผลสุดท้ายลง alert_deliveries หนึ่งแถว และคืน true เมื่อส่งสำเร็จ
+func deliverSubscriptionAlert(sub *models.AlertSubscription, value float64, now time.Time) bool {
+	notification := subscriptionNotification(sub, value)
+	delivery := models.AlertDelivery{
+		SubscriptionID: sub.ID,
+		UserID:         sub.UserID,
+		Channel:        sub.Channel,
+		Target:         sub.Email + sub.WebhookURL,
+		Title:          notification.Title,
+		Value:          value,
+	}
+
+	var sentLastHour int64
+	if err := database.DB.Model(&models.AlertDelivery{}).
+		Where("user_id = ? AND status = ? AND created_at >= ?", sub.UserID, DeliverySent, now.Add(-time.Hour)).
+		Count(&sentLastHour).Error; err != nil {
+		log.Printf("Error counting deliveries for user %d: %v", sub.UserID, err)
+	}
+
+	if sentLastHour >= int64(config.SubscriptionRateLimit()) {
+		delivery.Status = DeliveryRateLimited
+	} else {
+		var err error
+		backoff := subscriptionInitialBackoff
+		for attempt := 1; attempt <= subscriptionMaxAttempts; attempt++ {
+			if err = sendSubscriptionAlert(sub, notification, value); err == nil {
+				break
+			}
+			if attempt < subscriptionMaxAttempts {
+				time.Sleep(backoff)
+				backoff *= 2
+			}
+		}
+		if err != nil {
+			delivery.Status = DeliveryFailed
+			delivery.Error = err.Error()
+		} else {
+			delivery.Status = DeliverySent
+			notifiedAt := time.Now()
+			sub.LastNotifiedAt = &notifiedAt
+		}
+	}
+	if err := database.DB.Create(&delivery).Error; err != nil {
+		log.Printf("Error logging delivery for subscription %d: %v", sub.ID, err)
+	}
+	return delivery.Status == DeliverySent
+}
+
+func sendSubscriptionAlert(sub *models.AlertSubscription, notification models.Notification, value float64) error {
+	switch sub.Channel {
+	case "email":
+		body := fmt.Sprintf("%s\n\nยกเลิกการแจ้งเตือนนี้: %s\n", notification.Message, UnsubscribeURL(sub.UnsubscribeToken))
+		return sendEmail([]string{sub.Email}, "[Yakkaw] "+notification.Title, body)
+	case "webhook":
+		return postPublicWebhook(sub.WebhookURL, sub.WebhookSecret, map[string]interface{}{
+			"event":           "subscription.threshold",
+			"subscription_id": sub.ID,
+			"scope_type":      sub.ScopeType,
+			"scope_value":     sub.ScopeValue,
+			"metric":          sub.Metric,
+			"threshold":       sub.Threshold,
+			"value":           value,
+			"title":           notification.Title,
+			"message":         notification.Message,
+			"category":        notification.Category,
+			"unsubscribe_url": UnsubscribeURL(sub.UnsubscribeToken),
+		})
+	}
+	return fmt.Errorf("unknown channel %q", sub.Channel)
+}
+
+// subscriptionNotification สร้างเนื้อหาแจ้งเตือนในรูปแบบเดียวกับ models.Notification
+func subscriptionNotification(sub *models.AlertSubscription, value float64) models.Notification {
+	metric := strings.ToUpper(sub.Metric)
+	return models.Notification{
+		Title:    fmt.Sprintf("%s in %s reached %.1f", metric, sub.ScopeValue, value),
+		Message:  fmt.Sprintf("The %d-minute average %s in %s is %.1f, at or above your alert threshold of %.1f.", subscriptionWindowMinutes, metric, sub.ScopeValue, value, sub.Threshold),
+		Category: defaultAlertCategory,
+	}
+}
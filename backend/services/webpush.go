@@ -0,0 +1,239 @@
+package services
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	webPushRecordSize = 4096
+	// ขนาด payload สูงสุดที่ใส่ได้ใน record เดียว (record size - header 86 bytes - tag 16 - delimiter 1)
+	webPushMaxPayload = 3993
+	webPushTTL        = 24 * time.Hour
+)
+
+// vapidKeyPair คือ key ของ application server สำหรับเซ็น VAPID JWT (ES256)
+type vapidKeyPair struct {
+	private   *ecdsa.PrivateKey
+	publicRaw []byte // uncompressed P-256 point (65 bytes)
+}
+
+// webPushTarget คือข้อมูลจาก PushSubscription ของ browser
+type webPushTarget struct {
+	Endpoint string
+	P256dh   string
+	Auth     string
+}
+
+// webPushResult: StatusCode = 0 เมื่อส่งไม่ถึง push service
+type webPushResult struct {
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+// errWebPushBuild คือ error ตอนเข้ารหัสหรือสร้าง request ก่อนส่ง ซึ่งส่งซ้ำไปก็ไม่สำเร็จ
+var errWebPushBuild = errors.New("cannot build push message")
+
+var webPushClient = &http.Client{Timeout: 15 * time.Second}
+
+// b64url decodes base64url with or without padding (browsers and tools differ).
+func b64url(s string) ([]byte, error) {
+	if b, err := base64.RawURLEncoding.DecodeString(s); err == nil {
+		return b, nil
+	}
+	return base64.URLEncoding.DecodeString(s)
+}
+
+// generateVAPIDKeys สร้าง key pair ใหม่ในรูปแบบ base64url (public = จุด uncompressed, private = scalar 32 bytes)
+func generateVAPIDKeys() (string, string, error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		base64.RawURLEncoding.EncodeToString(key.Bytes()), nil
+}
+
+// parseVAPIDKeys ตรวจว่า private key ตรงกับ public key แล้วแปลงเป็น ecdsa สำหรับเซ็น JWT
+func parseVAPIDKeys(publicKey, privateKey string) (*vapidKeyPair, error) {
+	rawPriv, err := b64url(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	priv, err := ecdh.P256().NewPrivateKey(rawPriv)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	pub := priv.PublicKey().Bytes()
+	if publicKey != "" {
+		rawPub, err := b64url(publicKey)
+		if err != nil || !bytes.Equal(rawPub, pub) {
+			return nil, errors.New("VAPID public key does not match the private key")
+		}
+	}
+
+	return &vapidKeyPair{
+		private: &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(pub[1:33]),
+				Y:     new(big.Int).SetBytes(pub[33:65]),
+			},
+			D: new(big.Int).SetBytes(rawPriv),
+		},
+		publicRaw: pub,
+	}, nil
+}
+
+// PublicKey คืน application server key สำหรับ pushManager.subscribe({applicationServerKey})
+func (k *vapidKeyPair) PublicKey() string {
+	return base64.RawURLEncoding.EncodeToString(k.publicRaw)
+}
+
+// authorization สร้าง header "vapid t=<jwt>, k=<public key>" (RFC 8292) สำหรับ origin ของ endpoint
+func (k *vapidKeyPair) authorization(endpoint, subject string, now time.Time) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", errors.New("invalid push endpoint")
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": now.Add(12 * time.Hour).Unix(),
+		"sub": subject,
+	})
+	signed, err := token.SignedString(k.private)
+	if err != nil {
+		return "", err
+	}
+	return "vapid t=" + signed + ", k=" + k.PublicKey(), nil
+}
+
+// encryptWebPush เข้ารหัส payload แบบ aes128gcm ตาม RFC 8291 (record เดียว)
+func encryptWebPush(payload []byte, p256dh, authSecret string) ([]byte, error) {
+	if len(payload) > webPushMaxPayload {
+		return nil, fmt.Errorf("payload too large (%d bytes)", len(payload))
+	}
+	uaRaw, err := b64url(p256dh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh: %w", err)
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(uaRaw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh: %w", err)
+	}
+	auth, err := b64url(authSecret)
+	if err != nil || len(auth) == 0 {
+		return nil, errors.New("invalid auth secret")
+	}
+
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	sharedSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	asRaw := asPrivate.PublicKey().Bytes()
+
+	// IKM = HKDF(auth_secret, ecdh_secret, "WebPush: info\0" || ua_public || as_public, 32)
+	keyInfo := append(append([]byte("WebPush: info\x00"), uaRaw...), asRaw...)
+	ikm, err := hkdfBytes(sharedSecret, auth, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdfBytes(ikm, salt, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdfBytes(ikm, salt, []byte("Content-Encoding: nonce\x00"), 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// 0x02 = delimiter ของ record สุดท้าย (ไม่มี padding)
+	plaintext := append(append([]byte(nil), payload...), 0x02)
+
+	header := make([]byte, 0, 21+len(asRaw))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, webPushRecordSize)
+	header = append(header, byte(len(asRaw)))
+	header = append(header, asRaw...)
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+func hkdfBytes(secret, salt, info []byte, length int) ([]byte, error) {
+	out := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, info), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// sendWebPush เข้ารหัส payload และส่งไปยัง push service ของ browser หนึ่งครั้ง
+func sendWebPush(keys *vapidKeyPair, subject string, target webPushTarget, payload []byte) (webPushResult, error) {
+	body, err := encryptWebPush(payload, target.P256dh, target.Auth)
+	if err != nil {
+		return webPushResult{}, fmt.Errorf("%w: %v", errWebPushBuild, err)
+	}
+	authorization, err := keys.authorization(target.Endpoint, subject, time.Now())
+	if err != nil {
+		return webPushResult{}, fmt.Errorf("%w: %v", errWebPushBuild, err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, target.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return webPushResult{}, fmt.Errorf("%w: %v", errWebPushBuild, err)
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", fmt.Sprint(int(webPushTTL.Seconds())))
+	req.Header.Set("Urgency", "high")
+
+	resp, err := webPushClient.Do(req)
+	if err != nil {
+		return webPushResult{}, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	result := webPushResult{StatusCode: resp.StatusCode}
+	if seconds, err := time.ParseDuration(resp.Header.Get("Retry-After") + "s"); err == nil && seconds > 0 {
+		result.RetryAfter = seconds
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return result, fmt.Errorf("push service responded with status %d", resp.StatusCode)
+	}
+	return result, nil
+}
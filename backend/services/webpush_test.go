@@ -0,0 +1,138 @@
+package services
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// decryptWebPush ถอดรหัสแบบเดียวกับ browser (RFC 8291) เพื่อยืนยันผลของ encryptWebPush
+func decryptWebPush(t *testing.T, body []byte, ua *ecdh.PrivateKey, auth []byte) []byte {
+	t.Helper()
+	salt := body[:16]
+	if rs := binary.BigEndian.Uint32(body[16:20]); rs != webPushRecordSize {
+		t.Fatalf("record size = %d", rs)
+	}
+	idLen := int(body[20])
+	asPublic, err := ecdh.P256().NewPublicKey(body[21 : 21+idLen])
+	if err != nil {
+		t.Fatalf("sender key: %v", err)
+	}
+	shared, err := ua.ECDH(asPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyInfo := append(append([]byte("WebPush: info\x00"), ua.PublicKey().Bytes()...), asPublic.Bytes()...)
+	ikm, _ := hkdfBytes(shared, auth, keyInfo, 32)
+	cek, _ := hkdfBytes(ikm, salt, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce, _ := hkdfBytes(ikm, salt, []byte("Content-Encoding: nonce\x00"), 12)
+
+	block, _ := aes.NewCipher(cek)
+	gcm, _ := cipher.NewGCM(block)
+	plain, err := gcm.Open(nil, nonce, body[21+idLen:], nil)
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if plain[len(plain)-1] != 0x02 {
+		t.Fatalf("missing last-record delimiter")
+	}
+	return plain[:len(plain)-1]
+}
+
+func TestEncryptWebPushRoundTrip(t *testing.T) {
+	ua, _ := ecdh.P256().GenerateKey(rand.Reader)
+	auth := make([]byte, 16)
+	rand.Read(auth)
+
+	payload := []byte(`{"title":"PM2.5 สูง","body":"เชียงใหม่ 92.0"}`)
+	body, err := encryptWebPush(payload,
+		base64.RawURLEncoding.EncodeToString(ua.PublicKey().Bytes()),
+		base64.URLEncoding.EncodeToString(auth))
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if got := decryptWebPush(t, body, ua, auth); !bytes.Equal(got, payload) {
+		t.Fatalf("payload = %q", got)
+	}
+
+	if _, err := encryptWebPush(make([]byte, webPushMaxPayload+1), base64.RawURLEncoding.EncodeToString(ua.PublicKey().Bytes()), "AAAA"); err == nil {
+		t.Fatal("expected oversized payload to be rejected")
+	}
+}
+
+func TestVAPIDAuthorization(t *testing.T) {
+	pub, priv, err := generateVAPIDKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := parseVAPIDKeys(pub, priv)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if _, err := parseVAPIDKeys("BAAA", priv); err == nil {
+		t.Fatal("expected mismatched public key to be rejected")
+	}
+
+	header, err := keys.authorization("https://fcm.googleapis.com/fcm/send/abc", "mailto:ops@example.com", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(header, "vapid t=") || !strings.HasSuffix(header, ", k="+pub) {
+		t.Fatalf("header = %q", header)
+	}
+
+	raw := strings.TrimSuffix(strings.TrimPrefix(header, "vapid t="), ", k="+pub)
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(raw, claims, func(*jwt.Token) (interface{}, error) {
+		return &keys.private.PublicKey, nil
+	}); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if claims["aud"] != "https://fcm.googleapis.com" || claims["sub"] != "mailto:ops@example.com" {
+		t.Fatalf("claims = %v", claims)
+	}
+}
+
+func TestValidatePushEndpoint(t *testing.T) {
+	for _, endpoint := range []string{
+		"https://fcm.googleapis.com/fcm/send/abc",
+		"https://updates.push.services.mozilla.com/wpush/v2/abc",
+		"https://web.push.apple.com/abc",
+		"https://wns2-par02p.notify.windows.com/w/?token=abc",
+	} {
+		if err := validatePushEndpoint(endpoint); err != nil {
+			t.Errorf("validatePushEndpoint(%q) = %v", endpoint, err)
+		}
+	}
+	for _, endpoint := range []string{
+		"http://fcm.googleapis.com/fcm/send/abc",
+		"https://fcm.googleapis.com:8443/fcm/send/abc",
+		"https://evilfcm.googleapis.com.example.com/abc",
+		"https://notfcm.googleapis.com.attacker.net/abc",
+		"https://127.0.0.1/abc",
+		"https://169.254.169.254/latest/meta-data",
+		"https://example.com/push",
+	} {
+		if err := validatePushEndpoint(endpoint); err == nil {
+			t.Errorf("validatePushEndpoint(%q) should fail", endpoint)
+		}
+	}
+}
+
+func TestSendWebPushBuildErrorIsPermanent(t *testing.T) {
+	_, err := sendWebPush(nil, "mailto:a@b.c", webPushTarget{Endpoint: "https://fcm.googleapis.com/x", P256dh: "bad", Auth: "bad"}, []byte("{}"))
+	if !errors.Is(err, errWebPushBuild) {
+		t.Fatalf("err = %v, want a build error", err)
+	}
+}
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"math"
+	"strings"
+	"time"
+
+	"yakkaw_dashboard/utils"
+)
+
+// ErrWidgetNotFound คืนเมื่อไม่พบสถานีหรือจังหวัดที่ขอ widget/badge
+var ErrWidgetNotFound = errors.New("station or province not found")
+
+// WidgetStatus คือค่าปัจจุบันที่แสดงบน badge และ widget ของสถานีหรือจังหวัด
+type WidgetStatus struct {
+	Kind       string    `json:"kind"` // station | province
+	ID         string    `json:"id"`   // dvid หรือชื่อจังหวัด
+	Name       string    `json:"name"`
+	Province   string    `json:"province"`
+	PM25       *float64  `json:"pm25"` // nil = ไม่มีสถานีที่ยังส่งข้อมูล
+	Color      string    `json:"color"`
+	ColorMin   int       `json:"color_min"`
+	ColorMax   int       `json:"color_max"`
+	Status     string    `json:"status"` // online | stale | offline
+	ObservedAt time.Time `json:"observed_at"`
+	Stations   int       `json:"stations"` // จำนวนสถานีที่นำมาเฉลี่ย (จังหวัด)
+}
+
+// WidgetOptions: Theme = light | dark, Lang = en | th
+type WidgetOptions struct {
+	Theme string
+	Lang  string
+}
+
+// GetStationWidgetStatus คืนค่าล่าสุดของสถานีจาก station snapshot cache
+func GetStationWidgetStatus(dvid string) (WidgetStatus, error) {
+	stations, _, err := GetLatestStations(StationFilter{})
+	if err != nil {
+		return WidgetStatus{}, err
+	}
+	for _, s := range stations {
+		if s.DVID != dvid {
+			continue
+		}
+		status := WidgetStatus{
+			Kind: "station", ID: s.DVID, Name: s.Place, Province: s.Province,
+			Color: s.Color, ColorMin: s.ColorMin, ColorMax: s.ColorMax,
+			Status: s.Status, ObservedAt: s.ObservedAt, Stations: 1,
+		}
+		if status.Name == "" {
+			status.Name = s.DVID
+		}
+		if s.Status != "offline" {
+			pm25 := roundToTwoDecimals(s.PM25)
+			status.PM25 = &pm25
+		}
+		return status, nil
+	}
+	return WidgetStatus{}, ErrWidgetNotFound
+}
+
+// GetProvinceWidgetStatus เฉลี่ยค่าล่าสุดของสถานีในจังหวัดที่ยังไม่ offline
+func GetProvinceWidgetStatus(province string) (WidgetStatus, error) {
+	province = normalizeProvince(province)
+	if province == "" {
+		return WidgetStatus{}, ErrWidgetNotFound
+	}
+	stations, _, err := GetLatestStations(StationFilter{Province: province})
+	if err != nil {
+		return WidgetStatus{}, err
+	}
+	if len(stations) == 0 {
+		return WidgetStatus{}, ErrWidgetNotFound
+	}
+
+	status := WidgetStatus{Kind: "province", ID: province, Name: province, Province: province, Status: "offline"}
+	var sum float64
+	for _, s := range stations {
+		if s.ObservedAt.After(status.ObservedAt) {
+			status.ObservedAt = s.ObservedAt
+		}
+		if s.Status == "offline" {
+			continue
+		}
+		sum += s.PM25
+		status.Stations++
+		if status.Status != "online" {
+			status.Status = s.Status
+		}
+	}
+	if status.Stations == 0 {
+		return status, nil
+	}
+
+	pm25 := roundToTwoDecimals(sum / float64(status.Stations))
+	status.PM25 = &pm25
+	bands, err := GetAllColorRanges()
+	if err != nil {
+		return WidgetStatus{}, err
+	}
+	if len(bands) > 0 {
+		band := bands[colorBandIndex(bands, pm25)]
+		status.Color, status.ColorMin, status.ColorMax = band.Color, band.Min, band.Max
+	}
+	return status, nil
+}
+
+// widgetView คือข้อความและสีที่ผ่านการเลือกภาษา/ธีมแล้ว ใช้ร่วมกันทั้ง badge และ widget
+type widgetView struct {
+	Title, Label, Value, Unit, Band, Status           string
+	Time, Updated                                     string // เวลาอัปเดตแบบสั้น และแบบมีคำนำหน้า
+	Color, TextOnColor, Background, Foreground, Muted string
+	Lang                                              string
+	Refresh                                           int
+}
+
+func newWidgetView(s WidgetStatus, opts WidgetOptions) widgetView {
+	thai := opts.Lang == "th"
+	v := widgetView{
+		Title: s.Name, Label: "PM2.5", Unit: "µg/m³", Lang: "en", Refresh: 300,
+		Color: s.Color, Background: "#ffffff", Foreground: "#212121", Muted: "#6e6e6e",
+	}
+	if thai {
+		v.Lang = "th"
+	}
+	if opts.Theme == "dark" {
+		v.Background, v.Foreground, v.Muted = "#1e1e1e", "#f2f2f2", "#a8a8a8"
+	}
+	if s.Kind == "province" && s.Stations > 0 {
+		if thai {
+			v.Status = fmt.Sprintf("เฉลี่ยจาก %d สถานี", s.Stations)
+		} else {
+			v.Status = fmt.Sprintf("Average of %d stations", s.Stations)
+		}
+	}
+
+	if s.PM25 == nil {
+		v.Value, v.Color = "n/a", "#9e9e9e"
+		if thai {
+			v.Value = "ไม่มีข้อมูล"
+		}
+	} else {
+		v.Value = formatWidgetValue(*s.PM25)
+		v.Band = fmt.Sprintf("%d–%d µg/m³", s.ColorMin, s.ColorMax)
+	}
+	if v.Color == "" {
+		v.Color = "#9e9e9e"
+	}
+	c := parseHexColor(v.Color, 255)
+	v.TextOnColor = "#ffffff"
+	if luminance([3]uint8{c.R, c.G, c.B}) > 0.6 {
+		v.TextOnColor = "#212121"
+	}
+
+	if !s.ObservedAt.IsZero() {
+		t := s.ObservedAt.In(time.FixedZone("Asia/Bangkok", 7*3600))
+		if thai {
+			v.Time = fmt.Sprintf("%s น. %d %s", t.Format("15:04"), t.Day(), chartMonthsTH[t.Month()-1])
+			v.Updated = "อัปเดต " + v.Time
+		} else {
+			v.Time = t.Format("15:04, 2 Jan")
+			v.Updated = "Updated " + v.Time
+		}
+	}
+	if s.Status == "stale" || (s.Status == "offline" && s.PM25 == nil) {
+		switch {
+		case thai && s.Status == "stale":
+			v.Status = strings.TrimSpace(v.Status + " (ข้อมูลล่าช้า)")
+		case thai:
+			v.Status = "สถานีไม่ได้ส่งข้อมูล"
+		case s.Status == "stale":
+			v.Status = strings.TrimSpace(v.Status + " (delayed)")
+		default:
+			v.Status = "Station offline"
+		}
+	}
+	return v
+}
+
+func formatWidgetValue(v float64) string {
+	if v >= 100 || v == math.Trunc(v) {
+		return fmt.Sprintf("%.0f", v)
+	}
+	return fmt.Sprintf("%.1f", v)
+}
+
+// RenderWidgetBadge วาด badge SVG แบบ shields.io: ด้านซ้ายชื่อ ด้านขวาค่า PM2.5 ตามสีระดับ และเวลาอัปเดต
+func RenderWidgetBadge(s WidgetStatus, opts WidgetOptions) []byte {
+	v := newWidgetView(s, opts)
+	fonts := loadChartFonts()
+	const size = 11.0
+	left := v.Label + " · " + v.Title
+	right := v.Value
+	if s.PM25 != nil {
+		right += " " + v.Unit
+	}
+	if v.Time != "" {
+		right += " · " + v.Time
+	}
+
+	leftW := math.Ceil(fonts.TextWidth(left, size)) + 12
+	rightW := math.Ceil(fonts.TextWidth(right, size)) + 12
+	leftBg := "#555555"
+	if opts.Theme == "dark" {
+		leftBg = "#2b2b2b"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="20" role="img" aria-label="%s: %s">`, leftW+rightW, utils.XMLEscape(left), utils.XMLEscape(right))
+	fmt.Fprintf(&b, `<title>%s: %s</title>`, utils.XMLEscape(left), utils.XMLEscape(right))
+	fmt.Fprintf(&b, `<clipPath id="r"><rect width="%.0f" height="20" rx="3"/></clipPath><g clip-path="url(#r)">`, leftW+rightW)
+	fmt.Fprintf(&b, `<rect width="%.0f" height="20" fill="%s"/><rect x="%.0f" width="%.0f" height="20" fill="%s"/></g>`, leftW, leftBg, leftW, rightW, utils.XMLEscape(v.Color))
+	b.WriteString(`<g font-family="'DejaVu Sans', Verdana, 'Noto Sans Thai', Tahoma, sans-serif" font-size="11">`)
+	fmt.Fprintf(&b, `<text x="6" y="14" fill="#ffffff" textLength="%.0f" lengthAdjust="spacingAndGlyphs">%s</text>`, leftW-12, utils.XMLEscape(left))
+	fmt.Fprintf(&b, `<text x="%.0f" y="14" fill="%s" textLength="%.0f" lengthAdjust="spacingAndGlyphs">%s</text>`, leftW+6, v.TextOnColor, rightW-12, utils.XMLEscape(right))
+	b.WriteString(`</g></svg>`)
+	return []byte(b.String())
+}
+
+var widgetTemplate = template.Must(template.New("widget").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta http-equiv="refresh" content="{{.Refresh}}">
+<title>{{.Label}} · {{.Title}}</title>
+<style>
+html,body{margin:0;height:100%;background:{{.Background}};color:{{.Foreground}};font-family:'Noto Sans Thai',Sarabun,'DejaVu Sans',Tahoma,sans-serif}
+.w{box-sizing:border-box;height:100%;padding:12px 14px;display:flex;flex-direction:column;justify-content:space-between}
+.t{font-size:14px;font-weight:600;white-space:nowrap;overflow:hidden;text-overflow:ellipsis}
+.v{display:flex;align-items:center;gap:10px;margin:8px 0}
+.b{background:{{.Color}};color:{{.TextOnColor}};border-radius:8px;padding:6px 12px;font-size:28px;font-weight:700;line-height:1.1}
+.u{font-size:12px;color:{{.Muted}}}
+.m{font-size:11px;color:{{.Muted}}}
+</style>
+</head>
+<body>
+<div class="w">
+<div class="t" title="{{.Title}}">{{.Title}}</div>
+<div class="v"><span class="b">{{.Value}}</span><span class="u">{{.Label}}<br>{{.Unit}}{{if .Band}}<br>{{.Band}}{{end}}</span></div>
+<div class="m">{{.Updated}}{{if .Status}} · {{.Status}}{{end}}</div>
+</div>
+</body>
+</html>
+`))
+
+// RenderWidgetHTML สร้างหน้า HTML ขนาดเล็กสำหรับฝังด้วย iframe (รีเฟรชตัวเองทุก 5 นาที)
+func RenderWidgetHTML(s WidgetStatus, opts WidgetOptions) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := widgetTemplate.Execute(&buf, newWidgetView(s, opts)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewWidgetView(t *testing.T) {
+	pm25 := 37.4
+	observed := time.Date(2024, 3, 5, 2, 30, 0, 0, time.UTC) // 09:30 เวลาไทย
+	s := WidgetStatus{
+		Kind: "province", Name: "เชียงใหม่", PM25: &pm25, Color: "#ffff00",
+		ColorMin: 25, ColorMax: 37, Status: "stale", ObservedAt: observed, Stations: 3,
+	}
+
+	v := newWidgetView(s, WidgetOptions{Theme: "dark", Lang: "th"})
+	if v.Value != "37.4" {
+		t.Fatalf("Value = %q", v.Value)
+	}
+	if v.Background != "#1e1e1e" || v.TextOnColor != "#212121" {
+		t.Fatalf("dark theme on yellow: bg %q text %q", v.Background, v.TextOnColor)
+	}
+	if v.Time != "09:30 น. 5 มี.ค." {
+		t.Fatalf("Time = %q", v.Time)
+	}
+	if v.Status != "เฉลี่ยจาก 3 สถานี (ข้อมูลล่าช้า)" {
+		t.Fatalf("Status = %q", v.Status)
+	}
+
+	v = newWidgetView(WidgetStatus{Kind: "station", Name: "x", Status: "offline"}, WidgetOptions{Lang: "en"})
+	if v.Value != "n/a" || v.Color != "#9e9e9e" || v.Status != "Station offline" || v.Updated != "" {
+		t.Fatalf("offline view = %+v", v)
+	}
+}
+
+func TestRenderWidgetBadge(t *testing.T) {
+	pm25 := 120.4
+	svg := string(RenderWidgetBadge(WidgetStatus{
+		Kind: "station", Name: `A & <B>`, PM25: &pm25, Color: "#ff0000", Status: "online",
+	}, WidgetOptions{}))
+
+	if !strings.HasPrefix(svg, "<svg ") || !strings.HasSuffix(svg, "</svg>") {
+		t.Fatalf("not an SVG document: %s", svg)
+	}
+	for _, want := range []string{`fill="#ff0000"`, "120 µg/m³", "A &amp; &lt;B&gt;"} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("badge missing %q: %s", want, svg)
+		}
+	}
+	if strings.Contains(svg, "<B>") {
+		t.Errorf("station name not escaped: %s", svg)
+	}
+}
+
+func TestRenderWidgetHTML(t *testing.T) {
+	pm25 := 12.0
+	out, err := RenderWidgetHTML(WidgetStatus{
+		Kind: "station", Name: `<script>alert(1)</script>`, PM25: &pm25, Color: "#00e400",
+		ColorMin: 0, ColorMax: 25, Status: "online", ObservedAt: time.Now(),
+	}, WidgetOptions{Theme: "light", Lang: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(out)
+	if strings.Contains(html, "<script>") {
+		t.Fatalf("station name not escaped: %s", html)
+	}
+	if strings.Contains(html, "ZgotmplZ") {
+		t.Fatalf("CSS colour rejected by html/template: %s", html)
+	}
+	for _, want := range []string{"background:#00e400", ">12<", "0–25 µg/m³", `http-equiv="refresh"`} {
+		if !strings.Contains(html, want) {
+			t.Errorf("widget missing %q", want)
+		}
+	}
+}
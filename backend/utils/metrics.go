@@ -0,0 +1,263 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Minimal Prometheus text exposition format (version 0.0.4) without the client library:
+// counters, gauges and histograms with labels, plus a writer for values read at scrape time.
+
+// MetricsContentType is the Content-Type of the text exposition format.
+const MetricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultDurationBuckets are latency buckets in seconds, the same as the Prometheus client defaults.
+var DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// MetricsWriter builds one exposition document. Families must be written with Header
+// before their samples.
+type MetricsWriter struct {
+	buf bytes.Buffer
+}
+
+func NewMetricsWriter() *MetricsWriter {
+	return &MetricsWriter{}
+}
+
+// Header writes the HELP and TYPE lines of a family (typ is counter, gauge or histogram).
+func (w *MetricsWriter) Header(name, typ, help string) {
+	help = strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+	fmt.Fprintf(&w.buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// Sample writes one sample; labels are name/value pairs.
+func (w *MetricsWriter) Sample(name string, value float64, labels ...string) {
+	w.buf.WriteString(name)
+	if len(labels) > 0 {
+		w.buf.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				w.buf.WriteByte(',')
+			}
+			w.buf.WriteString(labels[i])
+			w.buf.WriteString(`="`)
+			w.buf.WriteString(escapeLabelValue(labels[i+1]))
+			w.buf.WriteByte('"')
+		}
+		w.buf.WriteByte('}')
+	}
+	w.buf.WriteByte(' ')
+	w.buf.WriteString(formatMetricValue(value))
+	w.buf.WriteByte('\n')
+}
+
+func (w *MetricsWriter) Bytes() []byte {
+	return w.buf.Bytes()
+}
+
+func escapeLabelValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+func formatMetricValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// metricFamily is a labelled metric whose series are kept in memory.
+type metricFamily struct {
+	name, help, typ string
+	labels          []string
+	mu              sync.Mutex
+	series          map[string]*metricSeries
+}
+
+type metricSeries struct {
+	labelValues []string
+	value       float64
+	counts      []uint64 // per bucket, histograms only
+	sum         float64
+	count       uint64
+}
+
+func (f *metricFamily) get(labelValues []string) *metricSeries {
+	if len(labelValues) != len(f.labels) {
+		panic(fmt.Sprintf("metric %s: got %d label values, want %d", f.name, len(labelValues), len(f.labels)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	s, ok := f.series[key]
+	if !ok {
+		s = &metricSeries{labelValues: append([]string(nil), labelValues...)}
+		f.series[key] = s
+	}
+	return s
+}
+
+// sorted returns the series ordered by label values so the output is stable.
+func (f *metricFamily) sorted() []*metricSeries {
+	keys := make([]string, 0, len(f.series))
+	for k := range f.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	series := make([]*metricSeries, 0, len(keys))
+	for _, k := range keys {
+		series = append(series, f.series[k])
+	}
+	return series
+}
+
+func (f *metricFamily) labelPairs(values []string, extra ...string) []string {
+	pairs := make([]string, 0, 2*len(values)+len(extra))
+	for i, v := range values {
+		pairs = append(pairs, f.labels[i], v)
+	}
+	return append(pairs, extra...)
+}
+
+// writeTo writes counters and gauges: one sample per series.
+func (f *metricFamily) writeTo(w *MetricsWriter) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header(f.name, f.typ, f.help)
+	for _, s := range f.sorted() {
+		w.Sample(f.name, s.value, f.labelPairs(s.labelValues)...)
+	}
+}
+
+// CounterVec is a monotonically increasing value per label combination.
+type CounterVec struct {
+	metricFamily
+}
+
+// Add increases the counter; negative values are ignored.
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		return
+	}
+	c.mu.Lock()
+	c.get(labelValues).value += v
+	c.mu.Unlock()
+}
+
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// GaugeVec is a value that can go up and down per label combination.
+type GaugeVec struct {
+	metricFamily
+}
+
+func (g *GaugeVec) Set(v float64, labelValues ...string) {
+	g.mu.Lock()
+	g.get(labelValues).value = v
+	g.mu.Unlock()
+}
+
+// HistogramVec counts observations into cumulative buckets per label combination.
+type HistogramVec struct {
+	metricFamily
+	buckets []float64
+}
+
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := h.get(labelValues)
+	if s.counts == nil {
+		s.counts = make([]uint64, len(h.buckets))
+	}
+	for i, upper := range h.buckets {
+		if v <= upper {
+			s.counts[i]++
+		}
+	}
+	s.sum += v
+	s.count++
+}
+
+func (h *HistogramVec) writeTo(w *MetricsWriter) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	w.Header(h.name, h.typ, h.help)
+	for _, s := range h.sorted() {
+		for i, upper := range h.buckets {
+			w.Sample(h.name+"_bucket", float64(s.counts[i]), h.labelPairs(s.labelValues, "le", formatMetricValue(upper))...)
+		}
+		w.Sample(h.name+"_bucket", float64(s.count), h.labelPairs(s.labelValues, "le", "+Inf")...)
+		w.Sample(h.name+"_sum", s.sum, h.labelPairs(s.labelValues)...)
+		w.Sample(h.name+"_count", float64(s.count), h.labelPairs(s.labelValues)...)
+	}
+}
+
+// MetricsRegistry holds the metrics written by WriteTo, in registration order.
+type MetricsRegistry struct {
+	mu       sync.Mutex
+	names    map[string]bool
+	families []interface{ writeTo(*MetricsWriter) }
+}
+
+func NewMetricsRegistry() *MetricsRegistry {
+	return &MetricsRegistry{names: map[string]bool{}}
+}
+
+// Metrics is the process-wide registry served on /metrics.
+var Metrics = NewMetricsRegistry()
+
+func (r *MetricsRegistry) register(name string, f interface{ writeTo(*MetricsWriter) }) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.names[name] {
+		panic("metric " + name + " registered twice")
+	}
+	r.names[name] = true
+	r.families = append(r.families, f)
+}
+
+func newMetricFamily(name, help, typ string, labels []string) metricFamily {
+	return metricFamily{name: name, help: help, typ: typ, labels: labels, series: map[string]*metricSeries{}}
+}
+
+// CounterVec creates and registers a counter.
+func (r *MetricsRegistry) CounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{newMetricFamily(name, help, "counter", labels)}
+	r.register(name, c)
+	return c
+}
+
+// GaugeVec creates and registers a gauge.
+func (r *MetricsRegistry) GaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{newMetricFamily(name, help, "gauge", labels)}
+	r.register(name, g)
+	return g
+}
+
+// HistogramVec creates and registers a histogram; buckets must be sorted ascending.
+func (r *MetricsRegistry) HistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{metricFamily: newMetricFamily(name, help, "histogram", labels), buckets: buckets}
+	r.register(name, h)
+	return h
+}
+
+// WriteTo writes every registered metric.
+func (r *MetricsRegistry) WriteTo(w *MetricsWriter) {
+	r.mu.Lock()
+	families := append([]interface{ writeTo(*MetricsWriter) }(nil), r.families...)
+	r.mu.Unlock()
+	for _, f := range families {
+		f.writeTo(w)
+	}
+}
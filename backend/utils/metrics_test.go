@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestMetricsRegistryExposition(t *testing.T) {
+	r := NewMetricsRegistry()
+	requests := r.CounterVec("http_requests_total", "Requests.\nBy route.", "route", "status")
+	latency := r.HistogramVec("latency_seconds", "Latency.", []float64{0.1, 1}, "route")
+	gauge := r.GaugeVec("temperature", "Temperature.")
+
+	requests.Inc("/b", "200")
+	requests.Add(2, "/a", "500")
+	requests.Add(-5, "/a", "500") // counters never decrease
+	latency.Observe(0.05, "/a")
+	latency.Observe(0.5, "/a")
+	latency.Observe(3, "/a")
+	gauge.Set(-1.5)
+
+	w := NewMetricsWriter()
+	r.WriteTo(w)
+	want := `# HELP http_requests_total Requests.\nBy route.
+# TYPE http_requests_total counter
+http_requests_total{route="/a",status="500"} 2
+http_requests_total{route="/b",status="200"} 1
+# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="/a",le="0.1"} 1
+latency_seconds_bucket{route="/a",le="1"} 2
+latency_seconds_bucket{route="/a",le="+Inf"} 3
+latency_seconds_sum{route="/a"} 3.55
+latency_seconds_count{route="/a"} 3
+# HELP temperature Temperature.
+# TYPE temperature gauge
+temperature -1.5
+`
+	if got := string(w.Bytes()); got != want {
+		t.Fatalf("exposition:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestMetricsWriterEscaping(t *testing.T) {
+	w := NewMetricsWriter()
+	w.Sample("station_pm25", math.Inf(1), "place", "a \"b\"\\c\nd")
+	if got, want := string(w.Bytes()), `station_pm25{place="a \"b\"\\c\nd"} +Inf`+"\n"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestMetricsRegistryRejectsDuplicates(t *testing.T) {
+	r := NewMetricsRegistry()
+	r.CounterVec("dup_total", "")
+	defer func() {
+		if recover() == nil {
+			t.Fatal("registering the same name twice should panic")
+		}
+	}()
+	r.GaugeVec("dup_total", "")
+}
+
+func TestMetricsLabelCountMismatch(t *testing.T) {
+	r := NewMetricsRegistry()
+	c := r.CounterVec("labelled_total", "", "route")
+	defer func() {
+		if p := recover(); p == nil || !strings.Contains(p.(string), "labelled_total") {
+			t.Fatalf("panic = %v", p)
+		}
+	}()
+	c.Inc()
+}
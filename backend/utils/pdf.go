@@ -0,0 +1,390 @@
+package utils
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf16"
+)
+
+// ขนาดกระดาษ A4 แนวตั้งในหน่วย point (1/72 นิ้ว)
+const (
+	PDFPageWidth  = 595.28
+	PDFPageHeight = 841.89
+)
+
+// PDFDocument สร้างไฟล์ PDF อย่างง่าย (ข้อความ เส้น สี่เหลี่ยม) โดยไม่พึ่ง library ภายนอก
+// พิกัดทั้งหมดวัดจากมุมซ้ายบนของหน้า แกน y ชี้ลง
+// ค่าเริ่มต้นใช้ Helvetica ซึ่งแสดงได้เฉพาะอักษรละติน ต้องการภาษาไทยให้ฝังฟอนต์ด้วย SetTrueTypeFont
+type PDFDocument struct {
+	title string
+	pages []*bytes.Buffer
+	cur   *bytes.Buffer
+	font  *trueTypeFont
+	used  map[uint16]rune // glyph ที่ใช้จริง สำหรับตาราง W และ ToUnicode
+	fill  string          // สีพื้นปัจจุบัน ใช้เป็นสีขอบของตัวหนาจำลอง
+}
+
+// NewPDFDocument สร้างเอกสารเปล่า (ต้องเรียก AddPage ก่อนวาด)
+func NewPDFDocument() *PDFDocument {
+	return &PDFDocument{used: make(map[uint16]rune)}
+}
+
+// SetTitle กำหนดชื่อเอกสารใน metadata
+func (d *PDFDocument) SetTitle(title string) {
+	d.title = title
+}
+
+// SetTrueTypeFont ฝังฟอนต์ .ttf ทั้งไฟล์และใช้กับข้อความทั้งหมด (ตัวหนาจำลองด้วยการลากเส้นขอบ)
+func (d *PDFDocument) SetTrueTypeFont(data []byte) error {
+	font, err := parseTrueType(data)
+	if err != nil {
+		return err
+	}
+	d.font = font
+	return nil
+}
+
+// CanRender บอกว่าฟอนต์ปัจจุบันแสดงทุกตัวอักษรใน s ได้หรือไม่
+func (d *PDFDocument) CanRender(s string) bool {
+	for _, r := range s {
+		if d.font != nil {
+			if _, ok := d.font.glyphByRune[r]; !ok && r != ' ' {
+				return false
+			}
+		} else if _, ok := winAnsiCode(r); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// AddPage เริ่มหน้าใหม่ การวาดหลังจากนี้จะอยู่ในหน้านี้
+func (d *PDFDocument) AddPage() {
+	d.cur = new(bytes.Buffer)
+	d.fill = ""
+	d.pages = append(d.pages, d.cur)
+}
+
+// SetFillColor กำหนดสีพื้นของสี่เหลี่ยมและสีข้อความ
+func (d *PDFDocument) SetFillColor(r, g, b uint8) {
+	d.fill = fmt.Sprintf("%s %s %s", pdfNum(float64(r)/255), pdfNum(float64(g)/255), pdfNum(float64(b)/255))
+	fmt.Fprintf(d.cur, "%s rg\n", d.fill)
+}
+
+// SetStrokeColor กำหนดสีเส้น
+func (d *PDFDocument) SetStrokeColor(r, g, b uint8) {
+	fmt.Fprintf(d.cur, "%s %s %s RG\n", pdfNum(float64(r)/255), pdfNum(float64(g)/255), pdfNum(float64(b)/255))
+}
+
+// SetLineWidth กำหนดความหนาเส้น (point)
+func (d *PDFDocument) SetLineWidth(w float64) {
+	fmt.Fprintf(d.cur, "%s w\n", pdfNum(w))
+}
+
+// SetDash กำหนดเส้นประ (ไม่ส่งค่า = เส้นทึบ)
+func (d *PDFDocument) SetDash(pattern ...float64) {
+	parts := make([]string, len(pattern))
+	for i, p := range pattern {
+		parts[i] = pdfNum(p)
+	}
+	fmt.Fprintf(d.cur, "[%s] 0 d\n", strings.Join(parts, " "))
+}
+
+// Rect วาดสี่เหลี่ยมที่มุมซ้ายบน (x, y)
+func (d *PDFDocument) Rect(x, y, w, h float64, fill, stroke bool) {
+	op := "S"
+	switch {
+	case fill && stroke:
+		op = "B"
+	case fill:
+		op = "f"
+	}
+	fmt.Fprintf(d.cur, "%s %s %s %s re %s\n", pdfNum(x), pdfNum(PDFPageHeight-y-h), pdfNum(w), pdfNum(h), op)
+}
+
+// Line วาดเส้นตรงจาก (x1, y1) ถึง (x2, y2)
+func (d *PDFDocument) Line(x1, y1, x2, y2 float64) {
+	d.Polyline([][2]float64{{x1, y1}, {x2, y2}})
+}
+
+// Polyline วาดเส้นต่อจุดตามลำดับ
+func (d *PDFDocument) Polyline(points [][2]float64) {
+	if len(points) < 2 {
+		return
+	}
+	for i, p := range points {
+		op := "l"
+		if i == 0 {
+			op = "m"
+		}
+		fmt.Fprintf(d.cur, "%s %s %s\n", pdfNum(p[0]), pdfNum(PDFPageHeight-p[1]), op)
+	}
+	d.cur.WriteString("S\n")
+}
+
+// TextWidth คืนความกว้างของข้อความในหน่วย point
+func (d *PDFDocument) TextWidth(s string, size float64, bold bool) float64 {
+	total := 0
+	for _, r := range s {
+		if d.font != nil {
+			total += d.font.advance(d.font.glyphByRune[r])
+			continue
+		}
+		code, ok := winAnsiCode(r)
+		if !ok {
+			code = '?'
+		}
+		total += helveticaWidth(code, bold)
+	}
+	return float64(total) * size / 1000
+}
+
+// Text เขียนข้อความโดยให้ baseline อยู่ที่ y
+func (d *PDFDocument) Text(x, y, size float64, bold bool, s string) {
+	if s == "" {
+		return
+	}
+	font := "/F1"
+	if bold && d.font == nil {
+		font = "/F2"
+	}
+	fakeBold := bold && d.font != nil
+	if fakeBold {
+		// ฟอนต์ที่ฝังมีน้ำหนักเดียว ใช้ render mode 2 (fill + stroke ด้วยสีเดียวกัน) ให้ดูหนาขึ้น
+		fill := d.fill
+		if fill == "" {
+			fill = "0 0 0"
+		}
+		fmt.Fprintf(d.cur, "q %s RG %s w ", fill, pdfNum(size*0.03))
+	}
+	fmt.Fprintf(d.cur, "BT %s %s Tf ", font, pdfNum(size))
+	if fakeBold {
+		d.cur.WriteString("2 Tr ")
+	}
+	fmt.Fprintf(d.cur, "%s %s Td %s Tj ET\n", pdfNum(x), pdfNum(PDFPageHeight-y), d.encodeText(s))
+	if fakeBold {
+		d.cur.WriteString("Q\n")
+	}
+}
+
+// TextRight เขียนข้อความชิดขวาที่ x
+func (d *PDFDocument) TextRight(x, y, size float64, bold bool, s string) {
+	d.Text(x-d.TextWidth(s, size, bold), y, size, bold, s)
+}
+
+// TextCenter เขียนข้อความกึ่งกลางที่ x
+func (d *PDFDocument) TextCenter(x, y, size float64, bold bool, s string) {
+	d.Text(x-d.TextWidth(s, size, bold)/2, y, size, bold, s)
+}
+
+func (d *PDFDocument) encodeText(s string) string {
+	var b strings.Builder
+	if d.font != nil {
+		b.WriteByte('<')
+		for _, r := range s {
+			gid := d.font.glyphByRune[r]
+			if _, ok := d.used[gid]; !ok {
+				d.used[gid] = r
+			}
+			fmt.Fprintf(&b, "%04X", gid)
+		}
+		b.WriteByte('>')
+		return b.String()
+	}
+
+	b.WriteByte('(')
+	for _, r := range s {
+		code, ok := winAnsiCode(r)
+		if !ok {
+			code = '?'
+		}
+		switch code {
+		case '(', ')', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(code)
+		default:
+			if code < 32 || code > 126 {
+				fmt.Fprintf(&b, "\\%03o", code)
+			} else {
+				b.WriteByte(code)
+			}
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// WriteTo เขียนไฟล์ PDF ทั้งหมด
+func (d *PDFDocument) WriteTo(w io.Writer) (int64, error) {
+	var objects [][]byte
+	add := func(body string) int {
+		objects = append(objects, []byte(body))
+		return len(objects)
+	}
+	addStream := func(dict string, data []byte) int {
+		var z bytes.Buffer
+		zw := zlib.NewWriter(&z)
+		zw.Write(data)
+		zw.Close()
+		body := fmt.Sprintf("<< %s /Length %d /Filter /FlateDecode >>\nstream\n", dict, z.Len())
+		return add(body + z.String() + "\nendstream")
+	}
+
+	catalog := add("")
+	pagesID := add("")
+	fonts := d.writeFonts(add, addStream)
+
+	var kids []string
+	for _, page := range d.pages {
+		content := addStream("", page.Bytes())
+		id := add(fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources << /Font << %s >> >> /Contents %d 0 R >>",
+			pagesID, pdfNum(PDFPageWidth), pdfNum(PDFPageHeight), fonts, content))
+		kids = append(kids, fmt.Sprintf("%d 0 R", id))
+	}
+	objects[catalog-1] = []byte(fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesID))
+	objects[pagesID-1] = []byte(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+	info := add(fmt.Sprintf("<< /Title %s /Producer (yakkaw_dashboard) >>", pdfUTF16(d.title)))
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n", i+1)
+		out.Write(obj)
+		out.WriteString("\nendobj\n")
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, catalog, info, xref)
+	return out.WriteTo(w)
+}
+
+// writeFonts สร้าง object ของฟอนต์และคืนรายการใน /Resources /Font
+func (d *PDFDocument) writeFonts(add func(string) int, addStream func(string, []byte) int) string {
+	if d.font == nil {
+		regular := add("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+		bold := add("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+		return fmt.Sprintf("/F1 %d 0 R /F2 %d 0 R", regular, bold)
+	}
+
+	f := d.font
+	gids := make([]int, 0, len(d.used))
+	for gid := range d.used {
+		gids = append(gids, int(gid))
+	}
+	sort.Ints(gids)
+
+	var widths, cmap strings.Builder
+	for _, gid := range gids {
+		fmt.Fprintf(&widths, "%d [%d] ", gid, f.advance(uint16(gid)))
+	}
+	cmap.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	for start := 0; start < len(gids); start += 100 {
+		end := start + 100
+		if end > len(gids) {
+			end = len(gids)
+		}
+		fmt.Fprintf(&cmap, "%d beginbfchar\n", end-start)
+		for _, gid := range gids[start:end] {
+			fmt.Fprintf(&cmap, "<%04X> <", gid)
+			for _, u := range utf16.Encode([]rune{d.used[uint16(gid)]}) {
+				fmt.Fprintf(&cmap, "%04X", u)
+			}
+			cmap.WriteString(">\n")
+		}
+		cmap.WriteString("endbfchar\n")
+	}
+	cmap.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend")
+
+	fontFile := addStream(fmt.Sprintf("/Length1 %d", len(f.data)), f.data)
+	descriptor := add(fmt.Sprintf("<< /Type /FontDescriptor /FontName /EmbeddedFont /Flags 32 /FontBBox [%d %d %d %d] "+
+		"/ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		f.scale(f.bbox[0]), f.scale(f.bbox[1]), f.scale(f.bbox[2]), f.scale(f.bbox[3]),
+		f.scale(f.ascent), f.scale(f.descent), f.scale(f.ascent), fontFile))
+	cidFont := add(fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /EmbeddedFont "+
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> "+
+		"/FontDescriptor %d 0 R /CIDToGIDMap /Identity /DW %d /W [%s] >>",
+		descriptor, f.advance(0), strings.TrimSpace(widths.String())))
+	toUnicode := addStream("", []byte(cmap.String()))
+	font := add(fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /EmbeddedFont /Encoding /Identity-H "+
+		"/DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>", cidFont, toUnicode))
+	return fmt.Sprintf("/F1 %d 0 R", font)
+}
+
+// pdfNum เขียนตัวเลขทศนิยมไม่เกิน 2 ตำแหน่งโดยตัดศูนย์ท้าย
+func pdfNum(v float64) string {
+	s := strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.2f", v), "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
+
+// pdfUTF16 เขียน text string แบบ UTF-16BE พร้อม BOM (ใช้กับ metadata ที่อาจเป็นภาษาไทย)
+func pdfUTF16(s string) string {
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	b.WriteByte('>')
+	return b.String()
+}
+
+// winAnsiExtras คืออักขระนอกช่วง Latin-1 ที่ WinAnsiEncoding มีให้และรายงานใช้
+var winAnsiExtras = map[rune]byte{'–': 0x96, '—': 0x97, '•': 0x95, '…': 0x85, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94}
+
+func winAnsiCode(r rune) (byte, bool) {
+	if code, ok := winAnsiExtras[r]; ok {
+		return code, true
+	}
+	if (r >= 32 && r <= 126) || (r >= 160 && r <= 255) {
+		return byte(r), true
+	}
+	return 0, false
+}
+
+// ความกว้างของ Helvetica / Helvetica-Bold (AFM มาตรฐาน) สำหรับรหัส 32–126
+var (
+	helveticaWidths = [95]int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}
+	helveticaBoldWidths = [95]int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+	helveticaExtraWidths = map[byte]int{0x96: 556, 0x97: 1000, 0x95: 350, 0x85: 1000, 0x91: 222, 0x92: 222, 0x93: 333, 0x94: 333, 0xB0: 400, 0xB3: 333, 0xB5: 556}
+)
+
+func helveticaWidth(code byte, bold bool) int {
+	if code >= 32 && code <= 126 {
+		if bold {
+			return helveticaBoldWidths[code-32]
+		}
+		return helveticaWidths[code-32]
+	}
+	if w, ok := helveticaExtraWidths[code]; ok {
+		return w
+	}
+	return 556
+}
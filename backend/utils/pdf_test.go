@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// checkXref ตรวจว่าทุก offset ในตาราง xref ชี้ไปที่ "N 0 obj" ที่ถูกต้อง
+func checkXref(t *testing.T, pdf []byte) {
+	t.Helper()
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(pdf)
+	if m == nil {
+		t.Fatal("missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(pdf[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point to xref", xref)
+	}
+	lines := strings.Split(string(pdf[xref:]), "\n")
+	var count int
+	fmt.Sscanf(lines[1], "0 %d", &count)
+	for i := 1; i < count; i++ {
+		off, _ := strconv.Atoi(strings.Fields(lines[2+i])[0])
+		want := fmt.Sprintf("%d 0 obj\n", i)
+		if !bytes.HasPrefix(pdf[off:], []byte(want)) {
+			t.Fatalf("xref entry %d points to %q", i, pdf[off:off+10])
+		}
+	}
+}
+
+func TestPDFDocumentHelvetica(t *testing.T) {
+	doc := NewPDFDocument()
+	doc.SetTitle("รายงาน")
+	doc.AddPage()
+	doc.SetFillColor(255, 0, 0)
+	doc.Rect(10, 10, 100, 50, true, false)
+	doc.Text(20, 40, 12, true, "PM2.5 (µg/m³) – 12\\3")
+	doc.AddPage()
+	doc.Polyline([][2]float64{{0, 0}, {10, 10}, {20, 0}})
+
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	pdf := buf.Bytes()
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")) {
+		t.Fatal("missing PDF header")
+	}
+	checkXref(t, pdf)
+	for _, want := range []string{"/Count 2", "/BaseFont /Helvetica-Bold", "/Title <FEFF0E230E320E220E070E320E19>"} {
+		if !bytes.Contains(pdf, []byte(want)) {
+			t.Errorf("PDF missing %q", want)
+		}
+	}
+
+	if got := doc.TextWidth("Hello", 10, false); math.Abs(got-22.78) > 1e-9 {
+		t.Errorf("TextWidth(Hello) = %v", got)
+	}
+	if helveticaWidths[94] != 584 || helveticaBoldWidths[94] != 584 {
+		t.Error("width tables must cover 32–126")
+	}
+	if doc.CanRender("เชียงราย") || !doc.CanRender("Chiang Rai – 37.5 µg/m³") {
+		t.Error("CanRender mismatch for Helvetica")
+	}
+	if got := doc.encodeText("a(b)\\µ"); got != `(a\(b\)\\\265)` {
+		t.Errorf("encodeText = %s", got)
+	}
+}
+
+func TestPDFDocumentTrueType(t *testing.T) {
+	data, err := os.ReadFile("/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf")
+	if err != nil {
+		t.Skip("DejaVuSans.ttf not available")
+	}
+	doc := NewPDFDocument()
+	if err := doc.SetTrueTypeFont(data); err != nil {
+		t.Fatal(err)
+	}
+	if doc.font.glyphByRune['A'] == 0 || doc.font.unitsPerEm == 0 {
+		t.Fatal("cmap/head not parsed")
+	}
+	if !doc.CanRender("µg/m³ Ωmega") {
+		t.Error("DejaVu Sans should render µ, ³ and Ω")
+	}
+	doc.AddPage()
+	doc.Text(10, 20, 10, true, "AΩ")
+
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	checkXref(t, buf.Bytes())
+	for _, want := range []string{"/Subtype /Type0", "/FontFile2", "/ToUnicode", "/CIDToGIDMap /Identity"} {
+		if !bytes.Contains(buf.Bytes(), []byte(want)) {
+			t.Errorf("PDF missing %q", want)
+		}
+	}
+
+	if err := NewPDFDocument().SetTrueTypeFont([]byte("not a font")); err == nil {
+		t.Error("expected error for invalid font data")
+	}
+}
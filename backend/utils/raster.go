@@ -0,0 +1,350 @@
+package utils
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"sort"
+)
+
+// rasterSubsamples คือจำนวน scanline ย่อยต่อ pixel ที่ใช้คำนวณ anti-aliasing ในแนวตั้ง
+// (แนวนอนคำนวณพื้นที่ที่ครอบคลุมจริง)
+const rasterSubsamples = 4
+
+// FontSet คือรายการฟอนต์ TrueType ที่ใช้วาดข้อความ ตัวอักษรที่ฟอนต์แรกไม่มีจะหาในฟอนต์ถัดไป
+// (เช่น DejaVu สำหรับละติน/ตัวเลข ตามด้วยฟอนต์ไทย) ไม่รองรับ shaping สระ/วรรณยุกต์จึงวางตาม glyph ของฟอนต์
+type FontSet struct {
+	fonts []*trueTypeFont
+}
+
+// Add เพิ่มฟอนต์ .ttf ท้ายรายการ
+func (fs *FontSet) Add(data []byte) error {
+	font, err := parseTrueType(data)
+	if err != nil {
+		return err
+	}
+	fs.fonts = append(fs.fonts, font)
+	return nil
+}
+
+// Empty บอกว่ายังไม่มีฟอนต์ (วาดข้อความไม่ได้)
+func (fs *FontSet) Empty() bool {
+	return fs == nil || len(fs.fonts) == 0
+}
+
+// glyph คืนฟอนต์และ glyph id ของ r (ไม่พบคืน font ตัวแรกกับ glyph 0)
+func (fs *FontSet) glyph(r rune) (*trueTypeFont, uint16) {
+	for _, f := range fs.fonts {
+		if gid, ok := f.glyphByRune[r]; ok {
+			return f, gid
+		}
+	}
+	return fs.fonts[0], 0
+}
+
+// TextWidth คืนความกว้างของข้อความเป็น pixel ที่ขนาด size (ไม่มีฟอนต์ใช้ค่าประมาณ 0.55 em ต่อตัวอักษร)
+func (fs *FontSet) TextWidth(s string, size float64) float64 {
+	if fs.Empty() {
+		return float64(len([]rune(s))) * size * 0.55
+	}
+	total := 0.0
+	for _, r := range s {
+		f, gid := fs.glyph(r)
+		total += float64(f.advance(gid)) / 1000
+	}
+	return total * size
+}
+
+// Canvas วาดภาพ raster แบบ anti-aliased (สี่เหลี่ยม เส้น polygon ข้อความ) แล้ว encode เป็น PNG
+// พิกัดเป็น pixel วัดจากมุมซ้ายบน
+type Canvas struct {
+	img   *image.NRGBA
+	fonts *FontSet
+}
+
+// NewCanvas สร้างภาพขนาด w×h ที่มีสีพื้น bg (fonts เป็น nil ได้ ข้อความจะไม่ถูกวาด)
+func NewCanvas(w, h int, bg color.NRGBA, fonts *FontSet) *Canvas {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = bg.R, bg.G, bg.B, bg.A
+	}
+	return &Canvas{img: img, fonts: fonts}
+}
+
+// FillRect ระบายสี่เหลี่ยม
+func (c *Canvas) FillRect(x, y, w, h float64, col color.NRGBA) {
+	c.FillPolygons([][][2]float64{{{x, y}, {x + w, y}, {x + w, y + h}, {x, y + h}}}, col)
+}
+
+// Polyline วาดเส้นหนา width ผ่านจุดตามลำดับ (รอยต่อมน)
+func (c *Canvas) Polyline(points [][2]float64, width float64, col color.NRGBA) {
+	if len(points) < 2 || width <= 0 {
+		return
+	}
+	half := width / 2
+	var polys [][][2]float64
+	for i := 1; i < len(points); i++ {
+		p0, p1 := points[i-1], points[i]
+		dx, dy := p1[0]-p0[0], p1[1]-p0[1]
+		length := math.Hypot(dx, dy)
+		if length == 0 {
+			continue
+		}
+		nx, ny := -dy/length*half, dx/length*half
+		polys = append(polys, [][2]float64{
+			{p0[0] + nx, p0[1] + ny}, {p1[0] + nx, p1[1] + ny},
+			{p1[0] - nx, p1[1] - ny}, {p0[0] - nx, p0[1] - ny},
+		})
+	}
+	if width > 1.5 {
+		for _, p := range points[1 : len(points)-1] {
+			polys = append(polys, circlePolygon(p[0], p[1], half))
+		}
+	}
+	c.FillPolygons(polys, col)
+}
+
+// DashedLine วาดเส้นประจาก (x1, y1) ถึง (x2, y2)
+func (c *Canvas) DashedLine(x1, y1, x2, y2, width, dash float64, col color.NRGBA) {
+	length := math.Hypot(x2-x1, y2-y1)
+	if length == 0 || dash <= 0 {
+		return
+	}
+	ux, uy := (x2-x1)/length, (y2-y1)/length
+	for d := 0.0; d < length; d += 2 * dash {
+		e := math.Min(d+dash, length)
+		c.Polyline([][2]float64{{x1 + ux*d, y1 + uy*d}, {x1 + ux*e, y1 + uy*e}}, width, col)
+	}
+}
+
+// FillCircle ระบายวงกลม
+func (c *Canvas) FillCircle(x, y, r float64, col color.NRGBA) {
+	c.FillPolygons([][][2]float64{circlePolygon(x, y, r)}, col)
+}
+
+// circlePolygon ประมาณวงกลมด้วย polygon ทิศทางเดียวกับสี่เหลี่ยมของ Polyline (nonzero จึงไม่เกิดรู)
+func circlePolygon(x, y, r float64) [][2]float64 {
+	n := int(math.Max(8, math.Min(48, r*4)))
+	points := make([][2]float64, n)
+	for i := range points {
+		a := -2 * math.Pi * float64(i) / float64(n)
+		points[i] = [2]float64{x + r*math.Cos(a), y - r*math.Sin(a)}
+	}
+	return points
+}
+
+// TextWidth คืนความกว้างของข้อความเป็น pixel
+func (c *Canvas) TextWidth(s string, size float64) float64 {
+	return c.fonts.TextWidth(s, size)
+}
+
+// Text เขียนข้อความโดยให้ baseline อยู่ที่ y (ไม่มีฟอนต์จะไม่วาดอะไร)
+func (c *Canvas) Text(x, y, size float64, col color.NRGBA, s string) {
+	if c.fonts.Empty() || s == "" {
+		return
+	}
+	var polys [][][2]float64
+	pen := x
+	for _, r := range s {
+		f, gid := c.fonts.glyph(r)
+		scale := size / float64(f.unitsPerEm)
+		for _, contour := range f.outline(gid) {
+			if poly := flattenContour(contour, pen, y, scale); len(poly) > 2 {
+				polys = append(polys, poly)
+			}
+		}
+		pen += float64(f.advance(gid)) / 1000 * size
+	}
+	c.FillPolygons(polys, col)
+}
+
+// flattenContour แปลง contour แบบ quadratic B-spline ของ TrueType เป็น polygon ในพิกัด pixel
+func flattenContour(contour []glyphPoint, ox, oy, scale float64) [][2]float64 {
+	n := len(contour)
+	if n == 0 {
+		return nil
+	}
+	pt := func(p glyphPoint) [2]float64 { return [2]float64{ox + p.x*scale, oy - p.y*scale} }
+	mid := func(a, b glyphPoint) glyphPoint { return glyphPoint{x: (a.x + b.x) / 2, y: (a.y + b.y) / 2, on: true} }
+
+	// เริ่มจากจุดที่อยู่บนเส้น (ถ้าไม่มีเลยใช้จุดกึ่งกลางของสอง control point แรก)
+	startIdx := -1
+	for i, p := range contour {
+		if p.on {
+			startIdx = i
+			break
+		}
+	}
+	var start glyphPoint
+	if startIdx < 0 {
+		start, startIdx = mid(contour[0], contour[1%n]), 0
+	} else {
+		start = contour[startIdx]
+	}
+
+	steps := int(math.Max(2, math.Min(8, scale*400)))
+	out := [][2]float64{pt(start)}
+	prev := start
+	var ctrl *glyphPoint
+	emitCurve := func(c, to glyphPoint) {
+		for s := 1; s <= steps; s++ {
+			t := float64(s) / float64(steps)
+			u := 1 - t
+			out = append(out, pt(glyphPoint{
+				x: u*u*prev.x + 2*u*t*c.x + t*t*to.x,
+				y: u*u*prev.y + 2*u*t*c.y + t*t*to.y,
+			}))
+		}
+	}
+	for i := 1; i <= n; i++ {
+		p := contour[(startIdx+i)%n]
+		if i == n {
+			p = start
+		}
+		switch {
+		case p.on && ctrl == nil:
+			out = append(out, pt(p))
+			prev = p
+		case p.on:
+			emitCurve(*ctrl, p)
+			prev, ctrl = p, nil
+		case ctrl == nil:
+			cp := p
+			ctrl = &cp
+		default:
+			m := mid(*ctrl, p)
+			emitCurve(*ctrl, m)
+			prev = m
+			cp := p
+			ctrl = &cp
+		}
+	}
+	if ctrl != nil {
+		emitCurve(*ctrl, start)
+	}
+	return out
+}
+
+type rasterEdge struct {
+	x0, y0, x1, y1 float64
+	dir            int
+}
+
+// FillPolygons ระบาย polygon หลายรูปพร้อมกันด้วยกฎ nonzero winding
+func (c *Canvas) FillPolygons(polys [][][2]float64, col color.NRGBA) {
+	bounds := c.img.Bounds()
+	var edges []rasterEdge
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, poly := range polys {
+		for i := range poly {
+			a, b := poly[i], poly[(i+1)%len(poly)]
+			if a[1] == b[1] {
+				continue
+			}
+			e := rasterEdge{a[0], a[1], b[0], b[1], 1}
+			if a[1] > b[1] {
+				e = rasterEdge{b[0], b[1], a[0], a[1], -1}
+			}
+			edges = append(edges, e)
+			minY, maxY = math.Min(minY, e.y0), math.Max(maxY, e.y1)
+		}
+	}
+	if len(edges) == 0 {
+		return
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i].y0 < edges[j].y0 })
+
+	width := bounds.Dx()
+	y0 := int(math.Max(0, math.Floor(minY)))
+	y1 := int(math.Min(float64(bounds.Dy()), math.Ceil(maxY)))
+	cover := make([]float64, width+1)
+	type crossing struct {
+		x   float64
+		dir int
+	}
+	var xs []crossing
+	for py := y0; py < y1; py++ {
+		for i := range cover {
+			cover[i] = 0
+		}
+		touched := false
+		for s := 0; s < rasterSubsamples; s++ {
+			sy := float64(py) + (float64(s)+0.5)/rasterSubsamples
+			xs = xs[:0]
+			for _, e := range edges {
+				if e.y0 > sy {
+					break
+				}
+				if sy >= e.y1 {
+					continue
+				}
+				xs = append(xs, crossing{e.x0 + (sy-e.y0)*(e.x1-e.x0)/(e.y1-e.y0), e.dir})
+			}
+			if len(xs) < 2 {
+				continue
+			}
+			sort.Slice(xs, func(i, j int) bool { return xs[i].x < xs[j].x })
+			winding := 0
+			for i := 0; i < len(xs)-1; i++ {
+				winding += xs[i].dir
+				if winding != 0 {
+					addCoverage(cover, xs[i].x, xs[i+1].x, 1.0/rasterSubsamples)
+					touched = true
+				}
+			}
+		}
+		if touched {
+			c.blendRow(py, cover[:width], col)
+		}
+	}
+}
+
+// addCoverage เพิ่มพื้นที่ของช่วง [xa, xb) ลงใน cover โดยคิดเศษของ pixel ที่ขอบ
+func addCoverage(cover []float64, xa, xb, weight float64) {
+	limit := float64(len(cover) - 1)
+	xa, xb = math.Max(0, math.Min(xa, limit)), math.Max(0, math.Min(xb, limit))
+	if xb <= xa {
+		return
+	}
+	ia, ib := int(xa), int(xb)
+	if ia == ib {
+		cover[ia] += (xb - xa) * weight
+		return
+	}
+	cover[ia] += (float64(ia+1) - xa) * weight
+	for i := ia + 1; i < ib; i++ {
+		cover[i] += weight
+	}
+	cover[ib] += (xb - float64(ib)) * weight
+}
+
+func (c *Canvas) blendRow(y int, cover []float64, col color.NRGBA) {
+	row := c.img.Pix[y*c.img.Stride:]
+	for x, cv := range cover {
+		if cv <= 0 {
+			continue
+		}
+		a := math.Min(cv, 1) * float64(col.A) / 255
+		p := row[4*x : 4*x+4]
+		dstA := float64(p[3]) / 255
+		outA := a + dstA*(1-a)
+		if outA == 0 {
+			continue
+		}
+		blend := func(src uint8, dst uint8) uint8 {
+			return uint8(math.Round((float64(src)*a + float64(dst)*dstA*(1-a)) / outA))
+		}
+		p[0], p[1], p[2], p[3] = blend(col.R, p[0]), blend(col.G, p[1]), blend(col.B, p[2]), uint8(math.Round(outA*255))
+	}
+}
+
+// PNG encode ภาพเป็น PNG
+func (c *Canvas) PNG() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, c.img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"testing"
+)
+
+func TestCanvasFillCoverage(t *testing.T) {
+	c := NewCanvas(10, 10, color.NRGBA{255, 255, 255, 255}, nil)
+	// สี่เหลี่ยมครอบ pixel (2..5) เต็ม และครึ่ง pixel ที่ขอบขวา x = 5.5
+	c.FillRect(2, 2, 3.5, 4, color.NRGBA{0, 0, 0, 255})
+
+	at := func(x, y int) uint8 { return c.img.NRGBAAt(x, y).R }
+	if at(3, 3) != 0 {
+		t.Errorf("inside pixel = %d, want 0", at(3, 3))
+	}
+	if at(8, 8) != 255 || at(1, 3) != 255 {
+		t.Error("outside pixels should stay white")
+	}
+	if v := at(5, 3); math.Abs(float64(v)-127.5) > 2 {
+		t.Errorf("half covered pixel = %d, want ~128", v)
+	}
+
+	// เส้นที่ลากทับกันไปกลับต้องไม่เกิดรู (nonzero winding)
+	c.Polyline([][2]float64{{0, 8}, {9, 8}, {0, 8}}, 2, color.NRGBA{255, 0, 0, 255})
+	if got := c.img.NRGBAAt(4, 8); got.R != 255 || got.G != 0 {
+		t.Errorf("overlapping stroke pixel = %v", got)
+	}
+
+	img, err := c.PNG()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := png.Decode(bytes.NewReader(img)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCanvasText(t *testing.T) {
+	data, err := os.ReadFile("/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf")
+	if err != nil {
+		t.Skip("DejaVuSans.ttf not available")
+	}
+	fonts := &FontSet{}
+	if err := fonts.Add(data); err != nil {
+		t.Fatal(err)
+	}
+	if w := fonts.TextWidth("Hello", 10); math.Abs(w-25.31) > 0.01 {
+		t.Errorf("TextWidth(Hello) = %v", w)
+	}
+	// Å เป็น composite glyph (A + วงกลม)
+	if len(fonts.fonts[0].outline(fonts.fonts[0].glyphByRune['Å'])) < 3 {
+		t.Error("composite glyph Å should have the contours of A and the ring")
+	}
+
+	c := NewCanvas(60, 30, color.NRGBA{255, 255, 255, 255}, fonts)
+	c.Text(2, 22, 20, color.NRGBA{0, 0, 0, 255}, "Å8")
+	dark := 0
+	for i := 0; i < len(c.img.Pix); i += 4 {
+		if c.img.Pix[i] < 128 {
+			dark++
+		}
+	}
+	if dark < 50 || dark > 600 {
+		t.Errorf("unexpected number of inked pixels: %d", dark)
+	}
+
+	if (&FontSet{}).TextWidth("abcd", 10) != 22 {
+		t.Error("empty font set should estimate 0.55 em per character")
+	}
+}
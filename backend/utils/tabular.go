@@ -0,0 +1,311 @@
+package utils
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TableWriter เขียนตารางทีละแถวลง io.Writer โดยไม่ต้องเก็บทั้งตารางไว้ในหน่วยความจำ
+// ค่าในแถวรองรับ string, ตัวเลข, *float64, time.Time (วันที่+เวลา), Date (วันที่อย่างเดียว) และ nil (ช่องว่าง)
+type TableWriter interface {
+	WriteRow(values ...interface{}) error
+	Close() error
+}
+
+// Date คือค่าที่ต้องการให้แสดงเป็นวันที่อย่างเดียว (ใช้ปี/เดือน/วันตาม location ของ Time)
+type Date struct {
+	time.Time
+}
+
+const (
+	csvDateLayout     = "2006-01-02"
+	csvDateTimeLayout = "2006-01-02 15:04:05"
+)
+
+type csvTableWriter struct {
+	w *csv.Writer
+}
+
+// NewCSVTableWriter เขียน CSV แบบ UTF-8 ขึ้นต้นด้วย BOM เพื่อให้ Excel อ่านภาษาไทยได้ถูกต้อง
+func NewCSVTableWriter(w io.Writer) (TableWriter, error) {
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return nil, err
+	}
+	return &csvTableWriter{w: csv.NewWriter(w)}, nil
+}
+
+func (t *csvTableWriter) WriteRow(values ...interface{}) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = csvValue(v)
+	}
+	return t.w.Write(record)
+}
+
+func (t *csvTableWriter) Close() error {
+	t.w.Flush()
+	return t.w.Error()
+}
+
+func csvValue(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return csvText(x)
+	case Date:
+		if x.IsZero() {
+			return ""
+		}
+		return x.Format(csvDateLayout)
+	case time.Time:
+		if x.IsZero() {
+			return ""
+		}
+		return x.Format(csvDateTimeLayout)
+	case *float64:
+		if x == nil {
+			return ""
+		}
+		return csvValue(*x)
+	}
+	if f, ok := numericValue(v); ok {
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return ""
+		}
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return csvText(fmt.Sprint(v))
+}
+
+// csvText ใส่ ' นำหน้าข้อความที่ขึ้นต้นด้วย = + - @ (หรือ tab/CR) เพื่อไม่ให้ Excel ตีความเป็นสูตร
+// (CSV injection) ส่วน XLSX เขียนข้อความเป็น inline string จึงไม่ถูกตีความเป็นสูตรอยู่แล้ว
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func numericValue(v interface{}) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case float32:
+		return float64(x), true
+	case int:
+		return float64(x), true
+	case int32:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	case uint:
+		return float64(x), true
+	}
+	return 0, false
+}
+
+// style index ใน xl/styles.xml ด้านล่าง
+const (
+	xlsxStyleDate     = 1
+	xlsxStyleDateTime = 2
+	xlsxStyleHeader   = 3
+)
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+</Types>`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`
+
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<numFmts count="2"><numFmt numFmtId="164" formatCode="yyyy-mm-dd"/><numFmt numFmtId="165" formatCode="yyyy-mm-dd hh:mm:ss"/></numFmts>
+<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>
+<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="4">
+<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>
+<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>
+</cellXfs>
+<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>
+</styleSheet>`
+
+type xlsxTableWriter struct {
+	zw  *zip.Writer
+	buf *bufio.Writer
+	row int
+}
+
+// NewXLSXTableWriter เขียนไฟล์ Excel (sheet เดียว) แถวแรกคือหัวตาราง ตัวหนาและตรึงไว้
+// ใช้ inline string ทั้งหมดจึงเขียนต่อเนื่องได้โดยไม่ต้องรู้จำนวนแถวล่วงหน้า
+func NewXLSXTableWriter(w io.Writer, sheetName string) (TableWriter, error) {
+	zw := zip.NewWriter(w)
+	workbook := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="` + XMLEscape(xlsxSheetName(sheetName)) + `" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", workbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, p := range parts {
+		fw, err := zw.Create(p.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(fw, p.body); err != nil {
+			return nil, err
+		}
+	}
+
+	fw, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	buf := bufio.NewWriter(fw)
+	if _, err := buf.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>
+<sheetData>`); err != nil {
+		return nil, err
+	}
+	return &xlsxTableWriter{zw: zw, buf: buf}, nil
+}
+
+func (t *xlsxTableWriter) WriteRow(values ...interface{}) error {
+	t.row++
+	fmt.Fprintf(t.buf, `<row r="%d">`, t.row)
+	for i, v := range values {
+		ref := xlsxColumnName(i) + strconv.Itoa(t.row)
+		style := 0
+		if t.row == 1 {
+			style = xlsxStyleHeader
+		}
+		t.writeCell(ref, style, v)
+	}
+	_, err := t.buf.WriteString("</row>")
+	return err
+}
+
+func (t *xlsxTableWriter) writeCell(ref string, style int, v interface{}) {
+	styleAttr := ""
+	if style != 0 {
+		styleAttr = fmt.Sprintf(` s="%d"`, style)
+	}
+	switch x := v.(type) {
+	case nil:
+		return
+	case *float64:
+		if x != nil {
+			t.writeCell(ref, style, *x)
+		}
+		return
+	case Date:
+		if !x.IsZero() {
+			fmt.Fprintf(t.buf, `<c r="%s" s="%d"><v>%s</v></c>`, ref, xlsxStyleDate, excelSerial(x.Time, true))
+		}
+		return
+	case time.Time:
+		if !x.IsZero() {
+			fmt.Fprintf(t.buf, `<c r="%s" s="%d"><v>%s</v></c>`, ref, xlsxStyleDateTime, excelSerial(x, false))
+		}
+		return
+	}
+	if f, ok := numericValue(v); ok {
+		if !math.IsNaN(f) && !math.IsInf(f, 0) {
+			fmt.Fprintf(t.buf, `<c r="%s"%s><v>%s</v></c>`, ref, styleAttr, strconv.FormatFloat(f, 'f', -1, 64))
+		}
+		return
+	}
+	s := fmt.Sprint(v)
+	if s == "" {
+		return
+	}
+	fmt.Fprintf(t.buf, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, styleAttr, XMLEscape(s))
+}
+
+func (t *xlsxTableWriter) Close() error {
+	if _, err := t.buf.WriteString(`</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	if err := t.buf.Flush(); err != nil {
+		return err
+	}
+	return t.zw.Close()
+}
+
+// excelSerial แปลงเวลาตามนาฬิกาของ location นั้นเป็นเลขวันแบบ Excel (นับจาก 1899-12-30)
+func excelSerial(t time.Time, dateOnly bool) string {
+	y, m, d := t.Date()
+	wall := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	if !dateOnly {
+		h, mi, s := t.Clock()
+		wall = time.Date(y, m, d, h, mi, s, 0, time.UTC)
+	}
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	days := wall.Sub(epoch).Hours() / 24
+	return strconv.FormatFloat(math.Round(days*86400)/86400, 'f', -1, 64)
+}
+
+// xlsxColumnName: 0 → A, 25 → Z, 26 → AA
+func xlsxColumnName(i int) string {
+	name := ""
+	for i >= 0 {
+		name = string(rune('A'+i%26)) + name
+		i = i/26 - 1
+	}
+	return name
+}
+
+// xlsxSheetName ตัดอักขระที่ Excel ไม่อนุญาตในชื่อ sheet และจำกัดไม่เกิน 31 ตัวอักษร
+func xlsxSheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if r := []rune(name); len(r) > 31 {
+		name = string(r[:31])
+	}
+	if name == "" {
+		name = "Sheet1"
+	}
+	return name
+}
+
+// XMLEscape escapes s for XML text and attribute values.
+func XMLEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
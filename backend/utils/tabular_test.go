@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCSVTableWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewCSVTableWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bkk := time.FixedZone("Asia/Bangkok", 7*3600)
+	pm := 12.5
+	rows := [][]interface{}{
+		{"เวลา", "วันที่", "สถานี", "PM2.5", "ค่าปรับเทียบ"},
+		{time.Date(2024, 3, 1, 8, 30, 0, 0, bkk), Date{time.Date(2024, 3, 1, 0, 0, 0, 0, bkk)}, "เชียงราย, \"A\"", 37, &pm},
+		{nil, Date{}, "", 1.25, (*float64)(nil)},
+		{"=HYPERLINK(\"http://x\")", "+1", "-1+2", "@SUM(A1)", -2.5},
+	}
+	for _, r := range rows {
+		if err := w.WriteRow(r...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "\ufeffเวลา,วันที่,สถานี,PM2.5,ค่าปรับเทียบ\n" +
+		"2024-03-01 08:30:00,2024-03-01,\"เชียงราย, \"\"A\"\"\",37,12.5\n" +
+		",,,1.25,\n" +
+		"\"'=HYPERLINK(\"\"http://x\"\")\",'+1,'-1+2,'@SUM(A1),-2.5\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("csv =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestXLSXTableWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewXLSXTableWriter(&buf, "ranking: daily")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bkk := time.FixedZone("Asia/Bangkok", 7*3600)
+	w.WriteRow("Date", "Province", "Avg")
+	w.WriteRow(Date{time.Date(2024, 1, 1, 0, 0, 0, 0, bkk)}, "เชียงใหม่ <&>", 42.5)
+	w.WriteRow(time.Date(1900, 3, 1, 12, 0, 0, 0, bkk), nil, 3)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(b)
+
+		dec := xml.NewDecoder(bytes.NewReader(b))
+		for {
+			if _, err := dec.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s is not well-formed: %v", f.Name, err)
+			}
+		}
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Fatalf("missing part %s", name)
+		}
+	}
+	if !strings.Contains(files["xl/workbook.xml"], `name="ranking_ daily"`) {
+		t.Errorf("sheet name not sanitised: %s", files["xl/workbook.xml"])
+	}
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	for _, cell := range []string{
+		`<c r="A1" s="3" t="inlineStr"><is><t xml:space="preserve">Date</t></is></c>`,
+		`<c r="A2" s="1"><v>45292</v></c>`,
+		`<c r="B2" t="inlineStr"><is><t xml:space="preserve">เชียงใหม่ &lt;&amp;&gt;</t></is></c>`,
+		`<c r="C2"><v>42.5</v></c>`,
+		`<c r="A3" s="2"><v>61.5</v></c>`,
+		`<c r="C3"><v>3</v></c>`,
+	} {
+		if !strings.Contains(sheet, cell) {
+			t.Errorf("sheet missing %s", cell)
+		}
+	}
+	if strings.Contains(sheet, `r="B3"`) {
+		t.Error("nil value should leave the cell empty")
+	}
+}
+
+func TestXLSXColumnName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := xlsxColumnName(i); got != want {
+			t.Errorf("xlsxColumnName(%d) = %s, want %s", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,399 @@
+package utils
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// trueTypeFont เก็บเฉพาะข้อมูลที่ต้องใช้ฝังฟอนต์ลง PDF (ไม่รองรับ shaping/kerning)
+type trueTypeFont struct {
+	data        []byte
+	unitsPerEm  int
+	ascent      int
+	descent     int
+	bbox        [4]int
+	advances    []uint16 // advance width ตาม glyph id (ช่วงท้ายใช้ค่าสุดท้ายซ้ำ)
+	glyphByRune map[rune]uint16
+	loca        []uint32 // offset ของแต่ละ glyph ในตาราง glyf (ว่าง = ไม่มี outline ให้วาดแบบ raster)
+	glyf        []byte
+}
+
+// glyphPoint คือจุดบน outline ในหน่วยของฟอนต์ (แกน y ชี้ขึ้น) on = จุดบนเส้น, ไม่ใช่ = control point ของ quadratic curve
+type glyphPoint struct {
+	x, y float64
+	on   bool
+}
+
+// parseTrueType อ่านตาราง head, hhea, hmtx และ cmap (format 4 หรือ 12) ของไฟล์ .ttf
+func parseTrueType(data []byte) (*trueTypeFont, error) {
+	if len(data) < 12 {
+		return nil, fmt.Errorf("font file too short")
+	}
+	switch binary.BigEndian.Uint32(data) {
+	case 0x00010000, 0x74727565: // 1.0, "true"
+	default:
+		return nil, fmt.Errorf("not a TrueType font (OpenType/CFF and collections are not supported)")
+	}
+
+	tables := make(map[string][]byte)
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	for i := 0; i < numTables; i++ {
+		rec := 12 + 16*i
+		if rec+16 > len(data) {
+			return nil, fmt.Errorf("truncated table directory")
+		}
+		tag := string(data[rec : rec+4])
+		offset := int(binary.BigEndian.Uint32(data[rec+8:]))
+		length := int(binary.BigEndian.Uint32(data[rec+12:]))
+		if offset < 0 || length < 0 || offset+length > len(data) {
+			return nil, fmt.Errorf("table %s out of range", tag)
+		}
+		tables[tag] = data[offset : offset+length]
+	}
+	for _, tag := range []string{"head", "hhea", "hmtx", "cmap"} {
+		if _, ok := tables[tag]; !ok {
+			return nil, fmt.Errorf("missing %s table", tag)
+		}
+	}
+
+	f := &trueTypeFont{data: data}
+	head := tables["head"]
+	if len(head) < 54 {
+		return nil, fmt.Errorf("invalid head table")
+	}
+	f.unitsPerEm = int(binary.BigEndian.Uint16(head[18:]))
+	if f.unitsPerEm == 0 {
+		return nil, fmt.Errorf("invalid unitsPerEm")
+	}
+	for i := range f.bbox {
+		f.bbox[i] = int(int16(binary.BigEndian.Uint16(head[36+2*i:])))
+	}
+
+	hhea := tables["hhea"]
+	if len(hhea) < 36 {
+		return nil, fmt.Errorf("invalid hhea table")
+	}
+	f.ascent = int(int16(binary.BigEndian.Uint16(hhea[4:])))
+	f.descent = int(int16(binary.BigEndian.Uint16(hhea[6:])))
+	numHMetrics := int(binary.BigEndian.Uint16(hhea[34:]))
+	hmtx := tables["hmtx"]
+	if numHMetrics == 0 || len(hmtx) < 4*numHMetrics {
+		return nil, fmt.Errorf("invalid hmtx table")
+	}
+	f.advances = make([]uint16, numHMetrics)
+	for i := range f.advances {
+		f.advances[i] = binary.BigEndian.Uint16(hmtx[4*i:])
+	}
+
+	glyphs, err := parseCmap(tables["cmap"])
+	if err != nil {
+		return nil, err
+	}
+	f.glyphByRune = glyphs
+
+	// loca/glyf ใช้เฉพาะตอนวาด raster ถ้าอ่านไม่ได้ก็ยังฝังลง PDF ได้ตามปกติ
+	if loca, ok := tables["loca"]; ok {
+		if glyf, ok := tables["glyf"]; ok {
+			long := int16(binary.BigEndian.Uint16(head[50:])) == 1
+			f.loca = parseLoca(loca, long, len(glyf))
+			f.glyf = glyf
+		}
+	}
+	return f, nil
+}
+
+func parseLoca(loca []byte, long bool, glyfLen int) []uint32 {
+	var offsets []uint32
+	if long {
+		for i := 0; i+4 <= len(loca); i += 4 {
+			offsets = append(offsets, binary.BigEndian.Uint32(loca[i:]))
+		}
+	} else {
+		for i := 0; i+2 <= len(loca); i += 2 {
+			offsets = append(offsets, uint32(binary.BigEndian.Uint16(loca[i:]))*2)
+		}
+	}
+	for _, off := range offsets {
+		if int(off) > glyfLen {
+			return nil
+		}
+	}
+	return offsets
+}
+
+// outline คืน contour ของ glyph (รองรับ composite glyph ที่ซ้อนกันไม่เกิน 8 ชั้น)
+func (f *trueTypeFont) outline(gid uint16) [][]glyphPoint {
+	return f.glyphContours(gid, 0)
+}
+
+func (f *trueTypeFont) glyphContours(gid uint16, depth int) [][]glyphPoint {
+	if depth > 8 || int(gid)+1 >= len(f.loca) {
+		return nil
+	}
+	start, end := f.loca[gid], f.loca[gid+1]
+	if end <= start {
+		return nil
+	}
+	g := f.glyf[start:end]
+	if len(g) < 10 {
+		return nil
+	}
+	numContours := int(int16(binary.BigEndian.Uint16(g)))
+	if numContours < 0 {
+		return f.compositeContours(g[10:], depth)
+	}
+	return simpleContours(g[10:], numContours)
+}
+
+func simpleContours(g []byte, numContours int) [][]glyphPoint {
+	if len(g) < 2*numContours+2 {
+		return nil
+	}
+	ends := make([]int, numContours)
+	for i := range ends {
+		ends[i] = int(binary.BigEndian.Uint16(g[2*i:]))
+	}
+	if numContours == 0 {
+		return nil
+	}
+	numPoints := ends[numContours-1] + 1
+	pos := 2 * numContours
+	pos += 2 + int(binary.BigEndian.Uint16(g[pos:])) // ข้าม instructions
+
+	flags := make([]byte, 0, numPoints)
+	for len(flags) < numPoints {
+		if pos >= len(g) {
+			return nil
+		}
+		flag := g[pos]
+		pos++
+		flags = append(flags, flag)
+		if flag&0x08 != 0 && pos < len(g) {
+			for n := int(g[pos]); n > 0 && len(flags) < numPoints; n-- {
+				flags = append(flags, flag)
+			}
+			pos++
+		}
+	}
+
+	readCoords := func(shortBit, sameBit byte) []float64 {
+		coords := make([]float64, numPoints)
+		v := 0
+		for i, flag := range flags {
+			switch {
+			case flag&shortBit != 0:
+				if pos >= len(g) {
+					return nil
+				}
+				d := int(g[pos])
+				pos++
+				if flag&sameBit == 0 {
+					d = -d
+				}
+				v += d
+			case flag&sameBit == 0:
+				if pos+2 > len(g) {
+					return nil
+				}
+				v += int(int16(binary.BigEndian.Uint16(g[pos:])))
+				pos += 2
+			}
+			coords[i] = float64(v)
+		}
+		return coords
+	}
+	xs := readCoords(0x02, 0x10)
+	ys := readCoords(0x04, 0x20)
+	if xs == nil || ys == nil {
+		return nil
+	}
+
+	contours := make([][]glyphPoint, 0, numContours)
+	first := 0
+	for _, last := range ends {
+		if last < first || last >= numPoints {
+			return nil
+		}
+		contour := make([]glyphPoint, 0, last-first+1)
+		for i := first; i <= last; i++ {
+			contour = append(contour, glyphPoint{x: xs[i], y: ys[i], on: flags[i]&0x01 != 0})
+		}
+		contours = append(contours, contour)
+		first = last + 1
+	}
+	return contours
+}
+
+func (f *trueTypeFont) compositeContours(g []byte, depth int) [][]glyphPoint {
+	var contours [][]glyphPoint
+	pos := 0
+	for {
+		if pos+4 > len(g) {
+			return contours
+		}
+		flags := binary.BigEndian.Uint16(g[pos:])
+		gid := binary.BigEndian.Uint16(g[pos+2:])
+		pos += 4
+
+		var dx, dy float64
+		if flags&0x0001 != 0 { // ARG_1_AND_2_ARE_WORDS
+			if pos+4 > len(g) {
+				return contours
+			}
+			dx, dy = float64(int16(binary.BigEndian.Uint16(g[pos:]))), float64(int16(binary.BigEndian.Uint16(g[pos+2:])))
+			pos += 4
+		} else {
+			if pos+2 > len(g) {
+				return contours
+			}
+			dx, dy = float64(int8(g[pos])), float64(int8(g[pos+1]))
+			pos += 2
+		}
+		if flags&0x0002 == 0 { // จัดตำแหน่งด้วยการจับคู่จุด ไม่รองรับ ใช้ offset 0
+			dx, dy = 0, 0
+		}
+
+		f2dot14 := func() float64 {
+			if pos+2 > len(g) {
+				return 1
+			}
+			v := float64(int16(binary.BigEndian.Uint16(g[pos:]))) / 16384
+			pos += 2
+			return v
+		}
+		a, b, c, d := 1.0, 0.0, 0.0, 1.0
+		switch {
+		case flags&0x0008 != 0: // WE_HAVE_A_SCALE
+			a = f2dot14()
+			d = a
+		case flags&0x0040 != 0: // WE_HAVE_AN_X_AND_Y_SCALE
+			a, d = f2dot14(), f2dot14()
+		case flags&0x0080 != 0: // WE_HAVE_A_TWO_BY_TWO
+			a, b, c, d = f2dot14(), f2dot14(), f2dot14(), f2dot14()
+		}
+
+		for _, contour := range f.glyphContours(gid, depth+1) {
+			out := make([]glyphPoint, len(contour))
+			for i, p := range contour {
+				out[i] = glyphPoint{x: a*p.x + c*p.y + dx, y: b*p.x + d*p.y + dy, on: p.on}
+			}
+			contours = append(contours, out)
+		}
+		if flags&0x0020 == 0 { // MORE_COMPONENTS
+			return contours
+		}
+	}
+}
+
+func parseCmap(cmap []byte) (map[rune]uint16, error) {
+	if len(cmap) < 4 {
+		return nil, fmt.Errorf("invalid cmap table")
+	}
+	// เลือก subtable Unicode: 3/10 (full) > 0/x > 3/1 (BMP)
+	best, bestScore := -1, 0
+	n := int(binary.BigEndian.Uint16(cmap[2:]))
+	for i := 0; i < n; i++ {
+		rec := 4 + 8*i
+		if rec+8 > len(cmap) {
+			break
+		}
+		platform := binary.BigEndian.Uint16(cmap[rec:])
+		encoding := binary.BigEndian.Uint16(cmap[rec+2:])
+		offset := int(binary.BigEndian.Uint32(cmap[rec+4:]))
+		score := 0
+		switch {
+		case platform == 3 && encoding == 10:
+			score = 3
+		case platform == 0:
+			score = 2
+		case platform == 3 && encoding == 1:
+			score = 1
+		}
+		if score > bestScore && offset+4 <= len(cmap) {
+			format := binary.BigEndian.Uint16(cmap[offset:])
+			if format == 4 || format == 12 {
+				best, bestScore = offset, score
+			}
+		}
+	}
+	if best < 0 {
+		return nil, fmt.Errorf("no Unicode cmap (format 4 or 12)")
+	}
+
+	sub := cmap[best:]
+	glyphs := make(map[rune]uint16)
+	switch binary.BigEndian.Uint16(sub) {
+	case 4:
+		if len(sub) < 14 {
+			return nil, fmt.Errorf("invalid cmap format 4")
+		}
+		segCount := int(binary.BigEndian.Uint16(sub[6:])) / 2
+		ends := 14
+		starts := ends + 2*segCount + 2
+		deltas := starts + 2*segCount
+		rangeOffsets := deltas + 2*segCount
+		if rangeOffsets+2*segCount > len(sub) {
+			return nil, fmt.Errorf("invalid cmap format 4")
+		}
+		for s := 0; s < segCount; s++ {
+			end := int(binary.BigEndian.Uint16(sub[ends+2*s:]))
+			start := int(binary.BigEndian.Uint16(sub[starts+2*s:]))
+			delta := binary.BigEndian.Uint16(sub[deltas+2*s:])
+			ro := int(binary.BigEndian.Uint16(sub[rangeOffsets+2*s:]))
+			for c := start; c <= end && c != 0xFFFF; c++ {
+				var gid uint16
+				if ro == 0 {
+					gid = uint16(c) + delta
+				} else {
+					idx := rangeOffsets + 2*s + ro + 2*(c-start)
+					if idx+2 > len(sub) {
+						continue
+					}
+					if gid = binary.BigEndian.Uint16(sub[idx:]); gid != 0 {
+						gid += delta
+					}
+				}
+				if gid != 0 {
+					glyphs[rune(c)] = gid
+				}
+			}
+		}
+	case 12:
+		if len(sub) < 16 {
+			return nil, fmt.Errorf("invalid cmap format 12")
+		}
+		groups := int(binary.BigEndian.Uint32(sub[12:]))
+		for g := 0; g < groups; g++ {
+			rec := 16 + 12*g
+			if rec+12 > len(sub) {
+				break
+			}
+			start := binary.BigEndian.Uint32(sub[rec:])
+			end := binary.BigEndian.Uint32(sub[rec+4:])
+			gid := binary.BigEndian.Uint32(sub[rec+8:])
+			if end-start > 0x10FFFF {
+				continue
+			}
+			for c := start; c <= end; c++ {
+				if g := gid + (c - start); g != 0 && g <= 0xFFFF {
+					glyphs[rune(c)] = uint16(g)
+				}
+			}
+		}
+	}
+	return glyphs, nil
+}
+
+// advance คืนความกว้างของ glyph ในหน่วย 1/1000 em ตามที่ PDF ใช้
+func (f *trueTypeFont) advance(gid uint16) int {
+	i := int(gid)
+	if i >= len(f.advances) {
+		i = len(f.advances) - 1
+	}
+	return int(f.advances[i]) * 1000 / f.unitsPerEm
+}
+
+// scale แปลงค่าจากหน่วยของฟอนต์เป็น 1/1000 em
+func (f *trueTypeFont) scale(v int) int {
+	return v * 1000 / f.unitsPerEm
+}